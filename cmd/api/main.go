@@ -1,13 +1,35 @@
 package main
 
 import (
+	"net"
+	"time"
+
+	auditlog "github.com/iamonah/merchcore/internal/app/audit"
 	"github.com/iamonah/merchcore/internal/app/auth"
+	apps "github.com/iamonah/merchcore/internal/app/oauth"
+	store "github.com/iamonah/merchcore/internal/app/tenant"
 	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/audit/auditdb"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/billing/billingdb"
+	"github.com/iamonah/merchcore/internal/domain/cleanup"
+	"github.com/iamonah/merchcore/internal/domain/cleanup/cleanupdb"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	"github.com/iamonah/merchcore/internal/domain/customdomain/customdomaindb"
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/iamonah/merchcore/internal/domain/oauth/oauthdb"
+	"github.com/iamonah/merchcore/internal/domain/store/catalog"
+	"github.com/iamonah/merchcore/internal/domain/store/catalog/catalogdb"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/tenant/tenantdb"
 	"github.com/iamonah/merchcore/internal/domain/users"
 	"github.com/iamonah/merchcore/internal/domain/users/userdb"
 	"github.com/iamonah/merchcore/internal/infra/cache"
 	"github.com/iamonah/merchcore/internal/infra/database"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/certs"
+	"github.com/iamonah/merchcore/internal/sdk/geoip"
 	"github.com/iamonah/merchcore/internal/sdk/jobs"
 	"github.com/iamonah/merchcore/internal/sdk/logger"
 	"github.com/iamonah/merchcore/internal/sdk/mailer"
@@ -42,18 +64,71 @@ func main() {
 	}
 	logger.Info().Msg("migration done")
 
-	jwtMaker := authz.NewJWTMaker(cfg.Auth.TokenSymmetricKey)
+	retiring := make([]authz.RetiringKeyFile, 0, len(cfg.Auth.RetiringKeys))
+	for _, k := range cfg.Auth.RetiringKeys {
+		expiresAt, _ := time.Parse(time.RFC3339, k.ExpiresAt) // format checked by config validation
+		retiring = append(retiring, authz.RetiringKeyFile{Path: k.File, ExpiresAt: expiresAt})
+	}
+	keyRing, err := authz.LoadKeyRing(cfg.Auth.SigningKeyFile, retiring...)
+	if err != nil {
+		log.Fatal().Err(err).Msg("signing keys load failed")
+	}
+	tokenMaker := authz.NewAsymmetricMaker(keyRing)
+	if cfg.Auth.TokenSymmetricKey != "" {
+		tokenMaker.AcceptLegacyKey(cfg.Auth.TokenSymmetricKey)
+		logger.Warn().Msg("tokens signed with the deprecated symmetric key are still accepted")
+	}
+	secretBox, err := authz.NewSecretBox(cfg.Auth.MFAEncryptionKey)
+	if err != nil {
+		log.Fatal().Err(err).Msg("secret box init failed")
+	}
 	redisClient := jobs.NewJobClient(cfg.Redis, logger)
 	mailer := mailer.NewMailTrap(&cfg.Mailer)
 	cache := cache.NewCache(&cfg.Redis)
 
+	trxManager := database.NewTRXManager(dbClient.Pool, logger)
+
+	var breached *users.BreachedList
+	if cfg.Password.BreachedList != "" {
+		breached, err = users.LoadBreachedList(cfg.Password.BreachedList)
+		if err != nil {
+			log.Fatal().Err(err).Msg("breached password list load failed")
+		}
+		logger.Info().Int("hashes", breached.Len()).Msg("breached password list loaded")
+	}
+
+	var geo *geoip.DB
+	if cfg.Auth.GeoIPFile != "" {
+		geo, err = geoip.Open(cfg.Auth.GeoIPFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("geoip database load failed")
+		}
+		logger.Info().Int("ranges", geo.Len()).Msg("geoip database loaded")
+	}
+
+	//auditbusiness
+	abusiness, err := audit.NewAuditBusiness(
+		audit.WithAuditRepository(auditdb.NewAuditStore(dbClient.Pool)),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("audit business init failed")
+	}
+
 	//userbusiness
 	ubusiness, err := users.NewUserBusiness(
 		users.WithUserRepository(userdb.Newuserdb(dbClient.Pool)),
-		users.WithTrxManager(database.NewTRXManager(dbClient.Pool, logger)),
-		users.WithAuthz(&jwtMaker),
+		users.WithTrxManager(trxManager),
+		users.WithAuthz(tokenMaker),
+		users.WithIDTokenVerifier(authz.NewGoogleVerifier(cfg.Auth.GoogleClientID)),
+		users.WithSecretBox(secretBox),
 		users.WithConfigs(cfg),
 		users.WithCache(cache),
+		users.WithLockNotifier(redisClient),
+		users.WithAuditor(abusiness),
+		users.WithPasswordPolicy(users.NewPasswordPolicy(cfg.Password, breached)),
+		users.WithSignInNotifier(redisClient),
+		users.WithIPLocator(geo),
+		users.WithLogger(logger),
 	)
 	if err != nil {
 		log.Fatal().Err(err).Msg("user business init failed")
@@ -61,7 +136,8 @@ func main() {
 	}
 	//userservice
 	userService, err := auth.NewUserService(
-		auth.WithAuth(jwtMaker),
+		auth.WithAuth(tokenMaker),
+		auth.WithKeyRing(keyRing),
 		auth.WithUserBusiness(ubusiness),
 		auth.WithJob(redisClient),
 		auth.WithLog(logger),
@@ -70,10 +146,118 @@ func main() {
 		log.Fatal().Err(err).Msg("user service init failed")
 	}
 
-	mux := router.SetupRouter(userService, logger, &jwtMaker)
+	//tenantbusiness
+	tbusiness, err := tenant.NewTenantBusiness(
+		tenant.WithTenantRepository(tenantdb.NewTenantStore(dbClient.Pool)),
+		tenant.WithTransactor(trxManager),
+		tenant.WithAuditor(abusiness),
+		tenant.WithCache(cache),
+		tenant.WithStatusHooks(redisClient),
+		tenant.WithTrialNotifier(redisClient),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("tenant business init failed")
+	}
+	//customdomainbusiness
+	dbusiness, err := customdomain.NewDomainBusiness(
+		customdomain.WithDomainRepository(customdomaindb.NewDomainStore(dbClient.Pool)),
+		customdomain.WithTransactor(trxManager),
+		customdomain.WithResolver(&net.Resolver{}),
+		customdomain.WithCertProber(certs.NewProber(10*time.Second)),
+		customdomain.WithCache(cache),
+		customdomain.WithAuditor(abusiness),
+		customdomain.WithEntitlements(tbusiness),
+		customdomain.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("custom domain business init failed")
+	}
+	//billingbusiness, no payment provider is integrated yet
+	bbusiness, err := billing.NewBillingBusiness(
+		billing.WithBillingRepository(billingdb.NewBillingStore(dbClient.Pool)),
+		billing.WithTransactor(trxManager),
+		billing.WithStoreStatus(tbusiness),
+		billing.WithAuditor(abusiness),
+		billing.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("billing business init failed")
+	}
+	//catalogbusiness
+	cgbusiness, err := catalog.NewCatalogBusiness(
+		catalog.WithCatalogRepository(catalogdb.NewCatalogDB(dbClient.Pool)),
+		catalog.WithEntitlements(tbusiness),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("catalog business init failed")
+	}
+	//tenantservice
+	tenantService, err := store.NewTenantService(
+		store.WithTenantBusiness(tbusiness),
+		store.WithDomainBusiness(dbusiness),
+		store.WithBillingBusiness(bbusiness),
+		store.WithCatalogBusiness(cgbusiness),
+		store.WithUserBusiness(ubusiness),
+		store.WithInvitationURL(cfg.Auth.InvitationURL),
+		store.WithJob(redisClient),
+		store.WithLog(logger),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("tenant service init failed")
+	}
+
+	//auditservice
+	auditService, err := auditlog.NewAuditService(
+		auditlog.WithAuditBusiness(abusiness),
+		auditlog.WithLog(logger),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("audit service init failed")
+	}
+
+	//oauthbusiness
+	obusiness, err := oauth.NewOAuthBusiness(
+		oauth.WithOAuthRepository(oauthdb.NewOAuthStore(dbClient.Pool)),
+		oauth.WithTransactor(trxManager),
+		oauth.WithTokenMaker(tokenMaker),
+		oauth.WithPermissionResolver(tbusiness),
+		oauth.WithCache(cache),
+		oauth.WithAuditor(abusiness),
+		oauth.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("oauth business init failed")
+	}
+	//oauthservice
+	oauthService, err := apps.NewOAuthService(
+		apps.WithOAuthBusiness(obusiness),
+		apps.WithLog(logger),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("oauth service init failed")
+	}
+
+	//cleanupbusiness, for cleanup tasks this process picks up
+	cbusiness, err := cleanup.NewCleanupBusiness(
+		cleanup.WithCleanupRepository(cleanupdb.NewCleanupStore(dbClient.Pool)),
+		cleanup.WithCache(cache),
+		cleanup.WithAccountPurger(ubusiness),
+		cleanup.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cleanup business init failed")
+	}
+
+	mux := router.SetupRouter(userService, tenantService, auditService, oauthService, logger, tokenMaker, ubusiness, tbusiness, tbusiness, ubusiness, tbusiness)
 
 	go func() {
-		if err := jobs.RunJobService(cfg.Redis, logger, mailer); err != nil {
+		if err := jobs.RunJobService(cfg.Redis, logger, mailer, jobs.Processors{
+			Accounts: ubusiness,
+			Cleaner:  cbusiness,
+			Domains:  dbusiness,
+			Trials:   tbusiness,
+			Billing:  bbusiness,
+		}); err != nil {
 			logger.Fatal().Err(err).Msg("redis job failed")
 		}
 	}()
@@ -1 +1,160 @@
 package main
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/audit/auditdb"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/billing/billingdb"
+	"github.com/iamonah/merchcore/internal/domain/cleanup"
+	"github.com/iamonah/merchcore/internal/domain/cleanup/cleanupdb"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	"github.com/iamonah/merchcore/internal/domain/customdomain/customdomaindb"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/tenant/tenantdb"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/domain/users/userdb"
+	"github.com/iamonah/merchcore/internal/infra/cache"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/iamonah/merchcore/internal/sdk/certs"
+	"github.com/iamonah/merchcore/internal/sdk/jobs"
+	"github.com/iamonah/merchcore/internal/sdk/logger"
+	"github.com/iamonah/merchcore/internal/sdk/mailer"
+
+	_ "expvar"
+
+	"github.com/rs/zerolog/log"
+)
+
+// The worker processes queued jobs and runs the periodic scheduler.
+// Migrations are left to the api, start it first.
+func main() {
+	cfg, err := config.LoadConfig(".")
+	if err != nil {
+		log.Fatal().Err(err).Msg("config load failed")
+	}
+	log.Info().Msg("config loaded")
+
+	logger, err := logger.SetupLog(cfg, cfg.Observability.ServiceName)
+	if err != nil {
+		log.Fatal().Err(err).Msg("log setup failed")
+	}
+	log.Info().Msg("logger ready")
+
+	dbClient, err := database.NewDB(cfg, logger)
+	if err != nil {
+		log.Fatal().Err(err).Msg("db init failed")
+	}
+	logger.Info().Msg("db connected")
+	defer dbClient.Close()
+
+	mailer := mailer.NewMailTrap(&cfg.Mailer)
+	cache := cache.NewCache(&cfg.Redis)
+	defer cache.Close()
+
+	trxManager := database.NewTRXManager(dbClient.Pool, logger)
+
+	jobClient := jobs.NewJobClient(cfg.Redis, logger)
+	defer jobClient.CloseClient()
+
+	//auditbusiness
+	abusiness, err := audit.NewAuditBusiness(
+		audit.WithAuditRepository(auditdb.NewAuditStore(dbClient.Pool)),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("audit business init failed")
+	}
+
+	//tenantbusiness, for the trial scan and purged accounts
+	tbusiness, err := tenant.NewTenantBusiness(
+		tenant.WithTenantRepository(tenantdb.NewTenantStore(dbClient.Pool)),
+		tenant.WithTransactor(trxManager),
+		tenant.WithAuditor(abusiness),
+		tenant.WithCache(cache),
+		tenant.WithStatusHooks(jobClient),
+		tenant.WithTrialNotifier(jobClient),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("tenant business init failed")
+	}
+
+	//userbusiness, only what the account jobs use
+	ubusiness, err := users.NewUserBusiness(
+		users.WithUserRepository(userdb.Newuserdb(dbClient.Pool)),
+		users.WithTrxManager(trxManager),
+		users.WithConfigs(cfg),
+		users.WithCache(cache),
+		users.WithAuditor(abusiness),
+		users.WithPasswordPolicy(users.NewPasswordPolicy(cfg.Password, nil)),
+		users.WithStoreArchiver(tbusiness),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("user business init failed")
+	}
+
+	//cleanupbusiness
+	cbusiness, err := cleanup.NewCleanupBusiness(
+		cleanup.WithCleanupRepository(cleanupdb.NewCleanupStore(dbClient.Pool)),
+		cleanup.WithCache(cache),
+		cleanup.WithAccountPurger(ubusiness),
+		cleanup.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cleanup business init failed")
+	}
+
+	//customdomainbusiness
+	dbusiness, err := customdomain.NewDomainBusiness(
+		customdomain.WithDomainRepository(customdomaindb.NewDomainStore(dbClient.Pool)),
+		customdomain.WithTransactor(trxManager),
+		customdomain.WithResolver(&net.Resolver{}),
+		customdomain.WithCertProber(certs.NewProber(10*time.Second)),
+		customdomain.WithCache(cache),
+		customdomain.WithAuditor(abusiness),
+		customdomain.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("custom domain business init failed")
+	}
+
+	//billingbusiness, for the billing run, no payment provider is integrated yet
+	bbusiness, err := billing.NewBillingBusiness(
+		billing.WithBillingRepository(billingdb.NewBillingStore(dbClient.Pool)),
+		billing.WithTransactor(trxManager),
+		billing.WithStoreStatus(tbusiness),
+		billing.WithAuditor(abusiness),
+		billing.WithConfigs(cfg),
+	)
+	if err != nil {
+		log.Fatal().Err(err).Msg("billing business init failed")
+	}
+
+	if addr := cfg.Observability.MetricsAddr; addr != "" {
+		go func() {
+			logger.Info().Str("addr", addr).Msg("metrics listening")
+			if err := http.ListenAndServe(addr, nil); err != nil {
+				logger.Error().Err(err).Msg("metrics server failed")
+			}
+		}()
+	}
+
+	go func() {
+		if err := jobs.RunJobService(cfg.Redis, logger, mailer, jobs.Processors{
+			Accounts: ubusiness,
+			Cleaner:  cbusiness,
+			Domains:  dbusiness,
+			Trials:   tbusiness,
+			Billing:  bbusiness,
+		}); err != nil {
+			logger.Fatal().Err(err).Msg("redis job failed")
+		}
+	}()
+
+	if err := jobs.RunScheduler(cfg, logger); err != nil {
+		logger.Fatal().Err(err).Msg("scheduler failed")
+	}
+}
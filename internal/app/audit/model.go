@@ -0,0 +1,55 @@
+package auditlog
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+)
+
+type AuditEntryResp struct {
+	ID             uuid.UUID      `json:"id"`
+	TenantID       *uuid.UUID     `json:"tenant_id,omitempty"`
+	ActorKind      string         `json:"actor_kind"`
+	ActorID        *uuid.UUID     `json:"actor_id,omitempty"`
+	ImpersonatorID *uuid.UUID     `json:"impersonator_id,omitempty"`
+	APIKeyID       *uuid.UUID     `json:"api_key_id,omitempty"`
+	Action         string         `json:"action"`
+	TargetType     string         `json:"target_type"`
+	TargetID       string         `json:"target_id"`
+	Before         map[string]any `json:"before,omitempty"`
+	After          map[string]any `json:"after,omitempty"`
+	ClientIP       string         `json:"client_ip,omitempty"`
+	ReqID          string         `json:"req_id,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+}
+
+type AuditLogResp struct {
+	Entries []AuditEntryResp `json:"entries"`
+	Total   int              `json:"total"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+func toAuditLogResp(p *audit.Page) AuditLogResp {
+	entries := make([]AuditEntryResp, 0, len(p.Entries))
+	for _, e := range p.Entries {
+		entries = append(entries, AuditEntryResp{
+			ID:             e.ID,
+			TenantID:       e.TenantID,
+			ActorKind:      string(e.ActorKind),
+			ActorID:        e.ActorID,
+			ImpersonatorID: e.ImpersonatorID,
+			APIKeyID:       e.APIKeyID,
+			Action:         e.Action,
+			TargetType:     e.TargetType,
+			TargetID:       e.TargetID,
+			Before:         e.Before,
+			After:          e.After,
+			ClientIP:       e.ClientIP,
+			ReqID:          e.ReqID,
+			CreatedAt:      e.CreatedAt,
+		})
+	}
+	return AuditLogResp{Entries: entries, Total: p.Total, Limit: p.Limit, Offset: p.Offset}
+}
@@ -0,0 +1,45 @@
+package auditlog
+
+import (
+	"errors"
+
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/rs/zerolog"
+)
+
+type AuditService struct {
+	log   *zerolog.Logger
+	audit *audit.AuditBusiness
+}
+
+type AuditConfiguration func(as *AuditService) error
+
+func NewAuditService(cfgs ...AuditConfiguration) (*AuditService, error) {
+	as := &AuditService{}
+	for _, cfg := range cfgs {
+		if err := cfg(as); err != nil {
+			return nil, err
+		}
+	}
+	if as.log == nil {
+		return nil, errors.New("logger is required")
+	}
+	if as.audit == nil {
+		return nil, errors.New("audit business is required")
+	}
+	return as, nil
+}
+
+func WithAuditBusiness(ab *audit.AuditBusiness) AuditConfiguration {
+	return func(as *AuditService) error {
+		as.audit = ab
+		return nil
+	}
+}
+
+func WithLog(log *zerolog.Logger) AuditConfiguration {
+	return func(as *AuditService) error {
+		as.log = log
+		return nil
+	}
+}
@@ -0,0 +1,136 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// DeleteMe schedules the account for deletion and signs it out everywhere.
+// Signing in again before purge_at cancels the deletion.
+func (us *UserService) DeleteMe(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req DeleteMeReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	purgeAt, err := us.users.DeleteAccount(r.Context(), pl.UserID, req.Password)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "deleteaccount: user[%s]: %s", pl.UserID, err)
+	}
+
+	if err := us.job.AccountPurgeJob(pl.UserID, purgeAt); err != nil {
+		return errs.Newf(errs.Internal, "accountpurgejob: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.account_delete").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Time("purge_at", purgeAt).
+		Msg("account scheduled for deletion")
+
+	resp := DeleteMeResp{
+		Message: "account scheduled for deletion, sign in before purge_at to cancel",
+		PurgeAt: purgeAt,
+	}
+	if err := base.WriteJSON(w, http.StatusAccepted, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// RequestDataExport queues a copy of the user's data, the download link is
+// sent by email once the archive is built.
+func (us *UserService) RequestDataExport(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	export, err := us.users.RequestDataExport(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "requestdataexport: user[%s]: %s", pl.UserID, err)
+	}
+
+	if err := us.job.DataExportJob(export.ID, pl.UserID); err != nil {
+		return errs.Newf(errs.Internal, "dataexportjob: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.data_export_request").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Str("export_id", export.ID.String()).
+		Msg("data export requested")
+
+	resp := DataExportResp{ExportID: export.ID, Status: string(export.Status)}
+	if err := base.WriteJSON(w, http.StatusAccepted, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// DownloadDataExport is opened from the export email, the token in the
+// query string stands in for the bearer token.
+func (us *UserService) DownloadDataExport(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	exportID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		return errs.New(errs.InvalidArgument, errors.New("missing token"))
+	}
+
+	export, err := us.users.DownloadDataExport(r.Context(), exportID, token)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "downloaddataexport: export[%s]: %s", exportID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.data_export_download").
+		Str("req_id", reqID).
+		Str("user_id", export.UserID.String()).
+		Str("export_id", export.ID.String()).
+		Msg("data export downloaded")
+
+	filename := fmt.Sprintf("merchcore-export-%s.zip", export.CreatedAt.Format("20060102"))
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(export.Archive); err != nil {
+		return errs.Newf(errs.Internal, "write archive: %s", err)
+	}
+	return nil
+}
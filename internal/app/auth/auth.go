@@ -69,7 +69,7 @@ func (us *UserService) ActivateUser(w http.ResponseWriter, r *http.Request) erro
 		return errs.New(errs.InvalidArgument, err)
 	}
 
-	if err := us.users.ActivateUser(r.Context(), pl.UserID, t.Token); err != nil {
+	if err := us.users.ActivateUser(r.Context(), pl.UserID, t.Token, base.GetClientIP(r)); err != nil {
 		if derr, ok := errs.IsDomainError(err); ok {
 			return errs.New(derr.Code, err)
 		}
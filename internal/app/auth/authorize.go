@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/mail"
 
+	"github.com/iamonah/merchcore/internal/domain/users"
 	"github.com/iamonah/merchcore/internal/sdk/base"
 	"github.com/iamonah/merchcore/internal/sdk/errs"
 )
@@ -29,7 +30,7 @@ func (us *UserService) Authenticate(w http.ResponseWriter, r *http.Request) erro
 		return errs.New(errs.Unauthenticated, errors.New("invalid credentials"))
 	}
 
-	user, err := us.users.Authenticate(r.Context(), email, req.Password)
+	user, err := us.users.Authenticate(r.Context(), email, req.Password, base.GetClientIP(r))
 	if err != nil {
 		if derr, ok := errs.IsDomainError(err); ok {
 			return errs.New(derr.Code, derr)
@@ -37,15 +38,90 @@ func (us *UserService) Authenticate(w http.ResponseWriter, r *http.Request) erro
 		return errs.Newf(errs.Internal, "authenticate: user[%s]: %s", email.Address, err)
 	}
 
-	clientIP := base.GetClientIP(r)
-	userAgent := r.UserAgent()
+	if err := us.signIn(w, r, user); err != nil {
+		return err
+	}
+
+	us.log.Info().
+		Str("event", "user.signin").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("authenticate: success")
 
-	session, err := us.users.CreateSession(r.Context(), user, userAgent, clientIP)
+	return nil
+}
+
+func (us *UserService) AuthenticateGoogle(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req GoogleSignInReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := us.users.AuthenticateGoogle(r.Context(), req.IDToken, toGoogleSignUp(req))
 	if err != nil {
 		if derr, ok := errs.IsDomainError(err); ok {
 			return errs.New(derr.Code, derr)
 		}
-		return errs.Newf(errs.Internal, "authenticate: user[%s]: %s", user.UserID, err)
+		return errs.Newf(errs.Internal, "authenticategoogle: reqID[%s]: %s", reqID, err)
+	}
+
+	if err := us.signIn(w, r, user); err != nil {
+		return err
+	}
+
+	us.log.Info().
+		Str("event", "user.signin_google").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("authenticate google: success")
+
+	return nil
+}
+
+// signIn finishes a successful first factor. Users with two-factor
+// authentication get an mfa_pending token instead of a session.
+func (us *UserService) signIn(w http.ResponseWriter, r *http.Request, user users.User) error {
+	mfaEnabled, err := us.users.IsMFAEnabled(r.Context(), user.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "ismfaenabled: user[%s]: %s", user.UserID, err)
+	}
+
+	if mfaEnabled {
+		token, err := us.users.CreateMFAChallenge(r.Context(), user.UserID)
+		if err != nil {
+			return errs.Newf(errs.Internal, "createmfachallenge: user[%s]: %s", user.UserID, err)
+		}
+
+		resp := MFAChallengeResp{
+			MFARequired: true,
+			MFAToken:    token.Plaintext,
+			ExpiresAt:   token.Expiry,
+		}
+		if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+			return errs.Newf(errs.Internal, "writejson: %s", err)
+		}
+		return nil
+	}
+
+	return us.writeSession(w, r, user)
+}
+
+func (us *UserService) writeSession(w http.ResponseWriter, r *http.Request, user users.User) error {
+	session, err := us.users.CreateSession(r.Context(), user, r.UserAgent(), base.GetClientIP(r))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "createsession: user[%s]: %s", user.UserID, err)
 	}
 
 	resp := UserSignInResp{
@@ -60,13 +136,6 @@ func (us *UserService) Authenticate(w http.ResponseWriter, r *http.Request) erro
 	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
 		return errs.Newf(errs.Internal, "writejson: %s", err)
 	}
-
-	us.log.Info().
-		Str("event", "user.signin").
-		Str("req_id", reqID).
-		Str("user_id", user.UserID.String()).
-		Msg("authenticate: success")
-
 	return nil
 }
 
@@ -113,17 +182,27 @@ func (us *UserService) RenewAccessToken(w http.ResponseWriter, r *http.Request)
 	if err != nil {
 		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
 	}
-	token, err := us.users.RenewAccessToken(r.Context(), pl)
+	token, err := us.users.RenewAccessToken(r.Context(), pl, r.UserAgent(), base.GetClientIP(r))
 	if err != nil {
+		if errors.Is(err, users.ErrRefreshTokenReused) {
+			us.log.Warn().
+				Str("event", "user.refresh_token_reuse").
+				Str("req_id", reqID).
+				Str("user_id", pl.UserID.String()).
+				Str("session_id", pl.ID).
+				Msg("session family revoked")
+		}
 		if derr, ok := errs.IsDomainError(err); ok {
 			return errs.New(derr.Code, derr)
 		}
-		return errs.Newf(errs.Internal, "renewaccess: user[%s]: %s", token.UserId, err)
+		return errs.Newf(errs.Internal, "renewaccess: user[%s]: %s", pl.UserID, err)
 	}
 
 	resp := RenewAccessTokenResp{
-		AccessToken:          token.AccessToken,
-		AccessTokenExpiresAt: token.AcessExpiresAt,
+		AccessToken:           token.AccessToken,
+		AccessTokenExpiresAt:  token.AcessExpiresAt,
+		RefreshToken:          token.RefreshToken,
+		RefreshTokenExpiresAt: token.RefreshExpiresAt,
 	}
 
 	us.log.Info().
@@ -58,7 +58,7 @@ func (us *UserService) ResetPassword(w http.ResponseWriter, r *http.Request) err
 		return errs.New(errs.InvalidArgument, errors.New("passwords do not match"))
 	}
 
-	userID, err := us.users.PasswordReset(r.Context(), req.NewPassword, req.Token)
+	userID, err := us.users.PasswordReset(r.Context(), req.NewPassword, req.Token, base.GetClientIP(r))
 	if err != nil {
 		if derr, ok := errs.IsDomainError(err); ok {
 			return errs.New(derr.Code, derr)
@@ -100,6 +100,9 @@ func (us *UserService) ChangePassword(w http.ResponseWriter, r *http.Request) er
 
 	user, err := us.users.ChangePassword(r.Context(), pl.UserID, req.OldPassword, req.NewPassword)
 	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
 		return errs.Newf(errs.Internal, "changepassword: userID[%v]: %s", pl.UserID, err)
 	}
 
@@ -0,0 +1,110 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// Impersonate lets a system admin act as another user to debug their
+// account. Every request made with the returned token is audited.
+func (us *UserService) Impersonate(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	targetID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req ImpersonateReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	clientIP := base.GetClientIP(r)
+	imp, token, err := us.users.Impersonate(r.Context(), pl.UserID, targetID, req.Reason, clientIP)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "impersonate: admin[%s] user[%s]: %s", pl.UserID, targetID, err)
+	}
+
+	target, err := us.users.GetUser(r.Context(), targetID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getuser: user[%s]: %s", targetID, err)
+	}
+
+	us.log.Warn().
+		Str("event", "admin.impersonation_start").
+		Str("req_id", reqID).
+		Str("admin_id", pl.UserID.String()).
+		Str("user_id", targetID.String()).
+		Str("session_id", imp.ID.String()).
+		Str("client_ip", clientIP).
+		Str("reason", imp.Reason).
+		Msg("impersonation started")
+
+	resp := ImpersonateResp{
+		SessionID:            imp.ID,
+		AccessToken:          token,
+		AccessTokenExpiresAt: imp.ExpiresAt,
+		User:                 toUserResp(target),
+	}
+	if err := base.WriteJSON(w, http.StatusCreated, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// StopImpersonation ends an impersonation session. It may be called with
+// the admin's own token or with the impersonation token itself.
+func (us *UserService) StopImpersonation(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	sessionID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	adminID := pl.UserID
+	if pl.IsImpersonated() {
+		adminID = pl.ImpersonatorID
+	}
+
+	if err := us.users.StopImpersonation(r.Context(), adminID, sessionID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "stopimpersonation: admin[%s]: %s", adminID, err)
+	}
+
+	us.log.Warn().
+		Str("event", "admin.impersonation_stop").
+		Str("req_id", reqID).
+		Str("admin_id", adminID.String()).
+		Str("session_id", sessionID.String()).
+		Msg("impersonation stopped")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
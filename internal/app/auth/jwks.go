@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// JWKS publishes the public signing keys so other services can verify
+// tokens without sharing a secret.
+func (us *UserService) JWKS(w http.ResponseWriter, r *http.Request) error {
+	if us.keys == nil {
+		return errs.Newf(errs.Unavailable, "jwks: no keyring configured")
+	}
+
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := base.WriteJSON(w, http.StatusOK, us.keys.JWKS(time.Now())); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/mail"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (us *UserService) RequestMagicLink(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req MagicLinkReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	email, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, errors.New("invalid email"))
+	}
+
+	user, link, err := us.users.RequestMagicLink(r.Context(), email)
+	if err != nil {
+		return errs.Newf(errs.Internal, "requestmagiclink: %s", err)
+	}
+
+	// same answer whether or not the account exists
+	data := map[string]string{"message": "sign-in link sent if account exists"}
+	if user == nil {
+		if err := base.WriteJSON(w, http.StatusOK, data); err != nil {
+			return errs.Newf(errs.Internal, "writejson: %s", err)
+		}
+		return nil
+	}
+
+	if err := us.job.MagicLinkEmailJob(user.Email.Address, user.FirstName, link, user.UserID); err != nil {
+		return errs.Newf(errs.Internal, "magiclinkjob: user[%s]: %s", user.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.magic_link_request").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("magic link sent")
+
+	if err := base.WriteJSON(w, http.StatusOK, data); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) VerifyMagicLink(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req TokenReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := us.users.VerifyMagicLink(r.Context(), req.Token, base.GetClientIP(r))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "verifymagiclink: reqID[%s]: %s", reqID, err)
+	}
+
+	if err := us.signIn(w, r, user); err != nil {
+		return err
+	}
+
+	us.log.Info().
+		Str("event", "user.signin_magic_link").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("magic link verify: success")
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// second step of sign-in for users with two-factor authentication
+func (us *UserService) VerifyMFA(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req MFAVerifyReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := us.users.VerifyMFAChallenge(r.Context(), req.MFAToken, req.Code, base.GetClientIP(r))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "verifymfachallenge: reqID[%s]: %s", reqID, err)
+	}
+
+	if err := us.writeSession(w, r, user); err != nil {
+		return err
+	}
+
+	us.log.Info().
+		Str("event", "user.signin_mfa").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("mfa verify: success")
+
+	return nil
+}
+
+func (us *UserService) EnrollMFA(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	enrollment, err := us.users.EnrollMFA(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "enrollmfa: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.mfa_enroll").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Msg("mfa enrollment started")
+
+	resp := MFAEnrollResp{Secret: enrollment.Secret, URI: enrollment.URI}
+	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) ConfirmMFA(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req MFAConfirmReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	codes, err := us.users.ConfirmMFA(r.Context(), pl.UserID, req.Code)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "confirmmfa: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.mfa_enable").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Msg("mfa enabled")
+
+	if err := base.WriteJSON(w, http.StatusOK, MFAConfirmResp{RecoveryCodes: codes}); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) DisableMFA(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req MFADisableReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := us.users.DisableMFA(r.Context(), pl.UserID, req.Password, req.Code); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "disablemfa: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.mfa_disable").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Msg("mfa disabled")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/sdk/base"
 )
 
 type UserCreateReq struct {
@@ -50,6 +51,23 @@ type UserSignInReq struct {
 	Password string `json:"password" validate:"required"`
 }
 
+type GoogleSignInReq struct {
+	IDToken     string `json:"id_token" validate:"required"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name"`
+	PhoneNumber string `json:"phone_number"`
+	Country     string `json:"country"`
+}
+
+func toGoogleSignUp(req GoogleSignInReq) users.GoogleSignUp {
+	return users.GoogleSignUp{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		PhoneNumber: req.PhoneNumber,
+		Country:     req.Country,
+	}
+}
+
 type TokenReq struct {
 	Token string `json:"token" validate:"required"`
 }
@@ -92,6 +110,46 @@ type ForgotPasswordReq struct {
 	Email string `json:"email"`
 }
 
+type MagicLinkReq struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type UpdateMeReq struct {
+	FirstName   *string `json:"first_name"`
+	LastName    *string `json:"last_name"`
+	PhoneNumber *string `json:"phone_number"`
+	Country     *string `json:"country"`
+}
+
+func toUpdateUser(req UpdateMeReq, version time.Time) users.UpdateUser {
+	return users.UpdateUser{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		PhoneNumber: req.PhoneNumber,
+		Country:     req.Country,
+		UpdatedAt:   version,
+	}
+}
+
+type EmailChangeReq struct {
+	NewEmail string `json:"new_email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type DeleteMeReq struct {
+	Password string `json:"password"`
+}
+
+type DeleteMeResp struct {
+	Message string    `json:"message"`
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+type DataExportResp struct {
+	ExportID uuid.UUID `json:"export_id"`
+	Status   string    `json:"status"`
+}
+
 type ResetPasswordReq struct {
 	Token           string `json:"token"`
 	NewPassword     string `json:"new_password"`
@@ -109,6 +167,94 @@ type RenewAccessTokenReq struct {
 }
 
 type RenewAccessTokenResp struct {
+	AccessToken           string    `json:"access_token"`
+	AccessTokenExpiresAt  time.Time `json:"access_token_expires_at"`
+	RefreshToken          string    `json:"refresh_token"`
+	RefreshTokenExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+type MFAChallengeResp struct {
+	MFARequired bool      `json:"mfa_required"`
+	MFAToken    string    `json:"mfa_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+type MFAVerifyReq struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required"`
+}
+
+type MFAEnrollResp struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MFAConfirmReq struct {
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAConfirmResp struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type MFADisableReq struct {
+	Password string `json:"password"`
+	Code     string `json:"code"`
+}
+
+type SessionResp struct {
+	ID           uuid.UUID `json:"id"`
+	Device       string    `json:"device"`
+	DeviceType   string    `json:"device_type"`
+	Browser      string    `json:"browser"`
+	OS           string    `json:"os"`
+	ClientIP     string    `json:"client_ip"`
+	LastActiveAt time.Time `json:"last_active_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	Current      bool      `json:"current"`
+}
+
+type SessionListResp struct {
+	Sessions []SessionResp `json:"sessions"`
+}
+
+type RevokeAllSessionsResp struct {
+	Revoked int `json:"revoked"`
+}
+
+// the family ID is exposed as the session ID, it survives token refreshes
+func toSessionListResp(sessions []users.Session, current uuid.UUID) SessionListResp {
+	resp := SessionListResp{Sessions: make([]SessionResp, 0, len(sessions))}
+	for _, s := range sessions {
+		ua := base.ParseUserAgent(s.UserAgent)
+		resp.Sessions = append(resp.Sessions, SessionResp{
+			ID:           s.FamilyID,
+			Device:       ua.Label(),
+			DeviceType:   ua.Device,
+			Browser:      ua.Browser,
+			OS:           ua.OS,
+			ClientIP:     s.ClientIP,
+			LastActiveAt: s.CreatedAt,
+			ExpiresAt:    s.ExpiresAt,
+			Current:      s.FamilyID == current,
+		})
+	}
+	return resp
+}
+
+type ImpersonateReq struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+type ImpersonateResp struct {
+	SessionID            uuid.UUID `json:"session_id"`
 	AccessToken          string    `json:"access_token"`
 	AccessTokenExpiresAt time.Time `json:"access_token_expires_at"`
+	User                 UserResp  `json:"user"`
+}
+
+type ReportSignInResp struct {
+	Message             string    `json:"message"`
+	ResetToken          string    `json:"reset_token"`
+	ResetTokenExpiresAt time.Time `json:"reset_token_expires_at"`
 }
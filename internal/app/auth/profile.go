@@ -0,0 +1,184 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// the profile version is its updated_at in microseconds, the precision
+// postgres stores
+func userETag(updatedAt time.Time) string {
+	return strconv.Quote(strconv.FormatInt(updatedAt.UnixMicro(), 10))
+}
+
+func parseUserETag(v string) (time.Time, error) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "W/")
+	raw, err := strconv.Unquote(v)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed If-Match header")
+	}
+	micros, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("malformed If-Match header")
+	}
+	return time.UnixMicro(micros), nil
+}
+
+func (us *UserService) GetMe(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	user, err := us.users.GetUser(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "getuser: user[%s]: %s", pl.UserID, err)
+	}
+
+	w.Header().Set("ETag", userETag(user.UpdatedAT))
+	if err := base.WriteJSON(w, http.StatusOK, toUserResp(user)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// UpdateMe requires the ETag from GET /me in If-Match, a stale version is
+// answered with 409 so the client can reload and retry.
+func (us *UserService) UpdateMe(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	if ifMatch == "" {
+		return errs.New(errs.FailedPrecondition, errors.New("missing If-Match header"))
+	}
+	version, err := parseUserETag(ifMatch)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req UpdateMeReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := us.users.UpdateUser(r.Context(), pl.UserID, toUpdateUser(req, version))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "updateuser: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.profile_update").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Msg("profile updated")
+
+	w.Header().Set("ETag", userETag(user.UpdatedAT))
+	if err := base.WriteJSON(w, http.StatusOK, toUserResp(*user)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) RequestEmailChange(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req EmailChangeReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	email, err := mail.ParseAddress(req.NewEmail)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, errors.New("invalid email"))
+	}
+
+	user, token, err := us.users.RequestEmailChange(r.Context(), pl.UserID, email, req.Password)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "requestemailchange: user[%s]: %s", pl.UserID, err)
+	}
+
+	if err := us.job.EmailChangeJob(email.Address, user.FirstName, token.Plaintext, user.UserID); err != nil {
+		return errs.Newf(errs.Internal, "emailchangejob: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.email_change_request").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Msg("email change requested")
+
+	data := map[string]string{"message": "confirmation sent to the new email address"}
+	if err := base.WriteJSON(w, http.StatusAccepted, data); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) ConfirmEmailChange(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req TokenReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := us.users.ConfirmEmailChange(r.Context(), req.Token)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "confirmemailchange: reqID[%s]: %s", reqID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.email_change_confirm").
+		Str("req_id", reqID).
+		Str("user_id", user.UserID.String()).
+		Msg("email changed")
+
+	w.Header().Set("ETag", userETag(user.UpdatedAT))
+	if err := base.WriteJSON(w, http.StatusOK, toUserResp(user)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -9,7 +9,8 @@ import (
 )
 
 type UserService struct {
-	auth  authz.JWTAuthMaker
+	auth  authz.TokenMaker
+	keys  *authz.KeyRing
 	log   *zerolog.Logger
 	job   jobs.JobService
 	users users.ExtUserBusiness
@@ -35,13 +36,20 @@ func WithUserBusiness(ub *users.UserBusiness) UserConfiguration {
 	}
 }
 
-func WithAuth(auth authz.JWTAuthMaker) UserConfiguration {
+func WithAuth(auth authz.TokenMaker) UserConfiguration {
 	return func(us *UserService) error {
 		us.auth = auth
 		return nil
 	}
 }
 
+func WithKeyRing(keys *authz.KeyRing) UserConfiguration {
+	return func(us *UserService) error {
+		us.keys = keys
+		return nil
+	}
+}
+
 func WithLog(log *zerolog.Logger) UserConfiguration {
 	return func(us *UserService) error {
 		us.log = log
@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (us *UserService) ListSessions(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	sessions, err := us.users.ListSessions(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "listsessions: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.sessions_list").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Int("count", len(sessions)).
+		Msg("list sessions: success")
+
+	if err := base.WriteJSON(w, http.StatusOK, toSessionListResp(sessions, pl.SessionID)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) RevokeSession(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	sessionID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := us.users.RevokeSession(r.Context(), pl.UserID, sessionID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "revokesession: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.session_revoke").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Str("session_id", sessionID.String()).
+		Bool("current", sessionID == pl.SessionID).
+		Msg("revoke session: success")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) RevokeAllSessions(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	revoked, err := us.users.RevokeAllSessions(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "revokeallsessions: user[%s]: %s", pl.UserID, err)
+	}
+
+	us.log.Info().
+		Str("event", "user.sessions_revoke_all").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Int("revoked", revoked).
+		Msg("revoke all sessions: success")
+
+	if err := base.WriteJSON(w, http.StatusOK, RevokeAllSessionsResp{Revoked: revoked}); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (us *UserService) ReportSignIn(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req TokenReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	reset, err := us.users.ReportSignIn(r.Context(), req.Token, base.GetClientIP(r))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "reportsignin: reqID[%s]: %s", reqID, err)
+	}
+
+	us.log.Warn().
+		Str("event", "user.signin_report").
+		Str("req_id", reqID).
+		Str("user_id", reset.UserID.String()).
+		Msg("report signin: success")
+
+	resp := ReportSignInResp{
+		Message:             "the session was signed out, choose a new password to continue",
+		ResetToken:          reset.Plaintext,
+		ResetTokenExpiresAt: reset.Expiry,
+	}
+	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package apps
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// Consent backs the consent screen: the frontend passes on the query of
+// /oauth/authorize and shows the user which app wants what. Once a store is
+// picked with ?tenant_id= the user's right to install there is checked too.
+func (os *OAuthService) Consent(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	q := r.URL.Query()
+	req := oauth.AuthorizeRequest{
+		ResponseType:        q.Get("response_type"),
+		ClientID:            q.Get("client_id"),
+		RedirectURI:         q.Get("redirect_uri"),
+		Scope:               q.Get("scope"),
+		State:               q.Get("state"),
+		CodeChallenge:       q.Get("code_challenge"),
+		CodeChallengeMethod: q.Get("code_challenge_method"),
+	}
+	if v := q.Get("tenant_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return errs.New(errs.InvalidArgument, fmt.Errorf("invalid tenant_id: %q", v))
+		}
+		req.TenantID = id
+	}
+
+	consent, err := os.oauth.Consent(r.Context(), pl.UserID, pl.RoleID, req)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "consent: user[%s] client[%s]: %s", pl.UserID, req.ClientID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toConsentResp(consent)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// Authorize takes the user's decision and returns where the frontend sends
+// the browser next, back to the app either way.
+func (os *OAuthService) Authorize(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req AuthorizeReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	redirect, err := os.oauth.Authorize(r.Context(), pl.UserID, pl.RoleID, req.toDomain(), req.Approve)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "authorize: user[%s] client[%s]: %s", pl.UserID, req.ClientID, err)
+	}
+
+	os.log.Info().
+		Str("event", "oauth.authorize").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Str("client_id", req.ClientID).
+		Str("tenant_id", req.TenantID.String()).
+		Bool("approved", req.Approve).
+		Msg("oauth authorization decided")
+
+	if err := base.WriteJSON(w, http.StatusOK, AuthorizeResp{RedirectTo: redirect}); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package apps
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (os *OAuthService) RegisterClient(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req RegisterClientReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	client, err := os.oauth.RegisterClient(r.Context(), pl.UserID, oauth.ClientCreate{
+		Name:         req.Name,
+		RedirectURIs: req.RedirectURIs,
+		Scopes:       req.Scopes,
+		Public:       req.Public,
+	})
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "registerclient: user[%s]: %s", pl.UserID, err)
+	}
+
+	os.log.Info().
+		Str("event", "oauth.client_register").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Str("client_id", client.ID.String()).
+		Strs("scopes", client.Scopes.Strings()).
+		Bool("confidential", client.IsConfidential()).
+		Msg("oauth client registered")
+
+	resp := RegisterClientResp{ClientResp: toClientResp(*client), ClientSecret: client.Secret}
+	if err := base.WriteJSON(w, http.StatusCreated, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (os *OAuthService) ListClients(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	clients, err := os.oauth.ListClients(r.Context(), pl.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "listclients: user[%s]: %s", pl.UserID, err)
+	}
+
+	resp := make([]ClientResp, 0, len(clients))
+	for _, c := range clients {
+		resp = append(resp, toClientResp(c))
+	}
+	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (os *OAuthService) DeleteClient(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	clientID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := os.oauth.DeleteClient(r.Context(), pl.UserID, clientID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "deleteclient: user[%s] client[%s]: %s", pl.UserID, clientID, err)
+	}
+
+	os.log.Info().
+		Str("event", "oauth.client_delete").
+		Str("req_id", reqID).
+		Str("user_id", pl.UserID.String()).
+		Str("client_id", clientID.String()).
+		Msg("oauth client deleted")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package apps
+
+import (
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// ListInstalledApps returns the apps installed in a store. The route checks
+// the caller may manage them.
+func (os *OAuthService) ListInstalledApps(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	grants, err := os.oauth.ListInstalledApps(r.Context(), tenantID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "listinstalledapps: tenant[%s]: %s", tenantID, err)
+	}
+
+	resp := make([]InstalledAppResp, 0, len(grants))
+	for _, g := range grants {
+		resp = append(resp, toInstalledAppResp(g))
+	}
+	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (os *OAuthService) UninstallApp(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	appID, err := base.GetPathUUID(r, "app_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := os.oauth.UninstallApp(r.Context(), tenantID, appID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "uninstallapp: tenant[%s] app[%s]: %s", tenantID, appID, err)
+	}
+
+	os.log.Info().
+		Str("event", "app.uninstall").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("app_id", appID.String()).
+		Msg("app uninstalled")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package apps
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+)
+
+type RegisterClientReq struct {
+	Name         string   `json:"name" validate:"required"`
+	RedirectURIs []string `json:"redirect_uris" validate:"required,min=1"`
+	Scopes       []string `json:"scopes" validate:"required,min=1"`
+	// Public clients (single page and mobile apps) cannot keep a secret.
+	Public bool `json:"public"`
+}
+
+type ClientResp struct {
+	ClientID     uuid.UUID `json:"client_id"`
+	Name         string    `json:"name"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	Confidential bool      `json:"confidential"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type RegisterClientResp struct {
+	ClientResp
+	// ClientSecret is only ever shown here.
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+func toClientResp(c oauth.Client) ClientResp {
+	return ClientResp{
+		ClientID:     c.ID,
+		Name:         c.Name,
+		RedirectURIs: c.RedirectURIs,
+		Scopes:       c.Scopes.Strings(),
+		Confidential: c.IsConfidential(),
+		CreatedAt:    c.CreatedAt,
+	}
+}
+
+type ConsentResp struct {
+	ClientID    uuid.UUID  `json:"client_id"`
+	ClientName  string     `json:"client_name"`
+	Scopes      []string   `json:"scopes"`
+	TenantID    *uuid.UUID `json:"tenant_id,omitempty"`
+	RedirectURI string     `json:"redirect_uri"`
+	State       string     `json:"state,omitempty"`
+}
+
+func toConsentResp(c *oauth.Consent) ConsentResp {
+	resp := ConsentResp{
+		ClientID:    c.Client.ID,
+		ClientName:  c.Client.Name,
+		Scopes:      c.Scopes.Strings(),
+		RedirectURI: c.RedirectURI,
+		State:       c.State,
+	}
+	if c.TenantID != uuid.Nil {
+		resp.TenantID = &c.TenantID
+	}
+	return resp
+}
+
+// AuthorizeReq is the consent screen's answer, the authorization request
+// it was shown for plus the store picked and the user's decision.
+type AuthorizeReq struct {
+	ResponseType        string    `json:"response_type" validate:"required"`
+	ClientID            string    `json:"client_id" validate:"required"`
+	RedirectURI         string    `json:"redirect_uri"`
+	Scope               string    `json:"scope"`
+	State               string    `json:"state"`
+	CodeChallenge       string    `json:"code_challenge" validate:"required"`
+	CodeChallengeMethod string    `json:"code_challenge_method" validate:"required"`
+	TenantID            uuid.UUID `json:"tenant_id" validate:"required"`
+	Approve             bool      `json:"approve"`
+}
+
+func (req AuthorizeReq) toDomain() oauth.AuthorizeRequest {
+	return oauth.AuthorizeRequest{
+		ResponseType:        req.ResponseType,
+		ClientID:            req.ClientID,
+		RedirectURI:         req.RedirectURI,
+		Scope:               req.Scope,
+		State:               req.State,
+		CodeChallenge:       req.CodeChallenge,
+		CodeChallengeMethod: req.CodeChallengeMethod,
+		TenantID:            req.TenantID,
+	}
+}
+
+type AuthorizeResp struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// TokenResp is the RFC 6749 section 5.1 response, TenantID says which store
+// the tokens are good for.
+type TokenResp struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresIn    int64     `json:"expires_in"`
+	RefreshToken string    `json:"refresh_token"`
+	Scope        string    `json:"scope"`
+	TenantID     uuid.UUID `json:"tenant_id"`
+}
+
+func toTokenResp(t *oauth.Tokens) TokenResp {
+	return TokenResp{
+		AccessToken:  t.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(time.Until(t.ExpiresAt).Seconds()),
+		RefreshToken: t.RefreshToken,
+		Scope:        strings.Join(t.Scopes.Strings(), " "),
+		TenantID:     t.TenantID,
+	}
+}
+
+// IntrospectResp is the RFC 7662 response, only Active is set for inactive
+// tokens.
+type IntrospectResp struct {
+	Active    bool   `json:"active"`
+	Scope     string `json:"scope,omitempty"`
+	ClientID  string `json:"client_id,omitempty"`
+	Sub       string `json:"sub,omitempty"`
+	TenantID  string `json:"tenant_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Exp       int64  `json:"exp,omitempty"`
+	Iat       int64  `json:"iat,omitempty"`
+}
+
+func toIntrospectResp(in *oauth.Introspection) IntrospectResp {
+	if !in.Active {
+		return IntrospectResp{}
+	}
+	resp := IntrospectResp{
+		Active:    true,
+		Scope:     strings.Join(in.Scopes.Strings(), " "),
+		ClientID:  in.ClientID.String(),
+		Sub:       in.UserID.String(),
+		TenantID:  in.TenantID.String(),
+		TokenType: in.TokenType,
+		Exp:       in.ExpiresAt.Unix(),
+	}
+	if !in.IssuedAt.IsZero() {
+		resp.Iat = in.IssuedAt.Unix()
+	}
+	return resp
+}
+
+// ErrorResp is the RFC 6749 section 5.2 error body of the token endpoints.
+type ErrorResp struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+type InstalledAppResp struct {
+	ID          uuid.UUID `json:"id"`
+	ClientID    uuid.UUID `json:"client_id"`
+	Name        string    `json:"name"`
+	Scopes      []string  `json:"scopes"`
+	InstalledBy uuid.UUID `json:"installed_by"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+func toInstalledAppResp(g oauth.Grant) InstalledAppResp {
+	return InstalledAppResp{
+		ID:          g.ID,
+		ClientID:    g.ClientID,
+		Name:        g.ClientName,
+		Scopes:      g.Scopes.Strings(),
+		InstalledBy: g.UserID,
+		InstalledAt: g.CreatedAt,
+	}
+}
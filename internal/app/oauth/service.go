@@ -0,0 +1,45 @@
+package apps
+
+import (
+	"errors"
+
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/rs/zerolog"
+)
+
+type OAuthService struct {
+	log   *zerolog.Logger
+	oauth *oauth.OAuthBusiness
+}
+
+type OAuthConfiguration func(os *OAuthService) error
+
+func NewOAuthService(cfgs ...OAuthConfiguration) (*OAuthService, error) {
+	os := &OAuthService{}
+	for _, cfg := range cfgs {
+		if err := cfg(os); err != nil {
+			return nil, err
+		}
+	}
+	if os.log == nil {
+		return nil, errors.New("logger is required")
+	}
+	if os.oauth == nil {
+		return nil, errors.New("oauth business is required")
+	}
+	return os, nil
+}
+
+func WithOAuthBusiness(ob *oauth.OAuthBusiness) OAuthConfiguration {
+	return func(os *OAuthService) error {
+		os.oauth = ob
+		return nil
+	}
+}
+
+func WithLog(log *zerolog.Logger) OAuthConfiguration {
+	return func(os *OAuthService) error {
+		os.log = log
+		return nil
+	}
+}
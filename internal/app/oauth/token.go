@@ -0,0 +1,156 @@
+package apps
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// Token is the OAuth2 token endpoint. Like introspection and revocation it
+// speaks the protocol's own form requests and error bodies, apps use stock
+// OAuth2 libraries against it.
+func (os *OAuthService) Token(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	if err := r.ParseForm(); err != nil {
+		return writeOAuthError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "malformed form body"}, false)
+	}
+
+	creds, basic := clientCredentials(r)
+	tokens, err := os.oauth.Token(r.Context(), oauth.TokenRequest{
+		GrantType:    r.PostForm.Get("grant_type"),
+		Code:         r.PostForm.Get("code"),
+		RedirectURI:  r.PostForm.Get("redirect_uri"),
+		CodeVerifier: r.PostForm.Get("code_verifier"),
+		RefreshToken: r.PostForm.Get("refresh_token"),
+		Client:       creds,
+	})
+	if err != nil {
+		var oerr *oauth.Error
+		if errors.As(err, &oerr) {
+			os.log.Warn().
+				Str("event", "oauth.token").
+				Str("req_id", reqID).
+				Str("client_id", creds.ClientID).
+				Str("error", oerr.Code).
+				Msg(oerr.Description)
+			return writeOAuthError(w, oerr, basic)
+		}
+		return errs.Newf(errs.Internal, "token: client[%s]: %s", creds.ClientID, err)
+	}
+
+	os.log.Info().
+		Str("event", "oauth.token").
+		Str("req_id", reqID).
+		Str("client_id", creds.ClientID).
+		Str("grant_type", r.PostForm.Get("grant_type")).
+		Str("tenant_id", tokens.TenantID.String()).
+		Msg("oauth tokens issued")
+
+	noStore(w)
+	if err := base.WriteJSON(w, http.StatusOK, toTokenResp(tokens)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (os *OAuthService) Introspect(w http.ResponseWriter, r *http.Request) error {
+	if err := r.ParseForm(); err != nil {
+		return writeOAuthError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "malformed form body"}, false)
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		return writeOAuthError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "token is required"}, false)
+	}
+
+	creds, basic := clientCredentials(r)
+	in, err := os.oauth.Introspect(r.Context(), creds, token)
+	if err != nil {
+		var oerr *oauth.Error
+		if errors.As(err, &oerr) {
+			return writeOAuthError(w, oerr, basic)
+		}
+		return errs.Newf(errs.Internal, "introspect: client[%s]: %s", creds.ClientID, err)
+	}
+
+	noStore(w)
+	if err := base.WriteJSON(w, http.StatusOK, toIntrospectResp(in)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// Revoke answers 200 for unknown tokens too (RFC 7009 section 2.2).
+func (os *OAuthService) Revoke(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	if err := r.ParseForm(); err != nil {
+		return writeOAuthError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "malformed form body"}, false)
+	}
+	token := r.PostForm.Get("token")
+	if token == "" {
+		return writeOAuthError(w, &oauth.Error{Code: oauth.CodeInvalidRequest, Description: "token is required"}, false)
+	}
+
+	creds, basic := clientCredentials(r)
+	if err := os.oauth.Revoke(r.Context(), creds, token); err != nil {
+		var oerr *oauth.Error
+		if errors.As(err, &oerr) {
+			return writeOAuthError(w, oerr, basic)
+		}
+		return errs.Newf(errs.Internal, "revoke: client[%s]: %s", creds.ClientID, err)
+	}
+
+	os.log.Info().
+		Str("event", "oauth.revoke").
+		Str("req_id", reqID).
+		Str("client_id", creds.ClientID).
+		Msg("oauth token revoked")
+
+	w.WriteHeader(http.StatusOK)
+	return nil
+}
+
+// clientCredentials reads HTTP Basic auth, whose parts are form encoded
+// (RFC 6749 section 2.3.1), or else client_id and client_secret from the
+// body. basic reports which was used.
+func clientCredentials(r *http.Request) (creds oauth.ClientCredentials, basic bool) {
+	if id, secret, ok := r.BasicAuth(); ok {
+		creds.ClientID, _ = url.QueryUnescape(id)
+		creds.Secret, _ = url.QueryUnescape(secret)
+		return creds, true
+	}
+	creds.ClientID = r.PostForm.Get("client_id")
+	creds.Secret = r.PostForm.Get("client_secret")
+	return creds, false
+}
+
+func writeOAuthError(w http.ResponseWriter, oerr *oauth.Error, basic bool) error {
+	status := http.StatusBadRequest
+	if oerr.Code == oauth.CodeInvalidClient {
+		status = http.StatusUnauthorized
+		if basic {
+			w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		}
+	}
+
+	noStore(w)
+	resp := ErrorResp{Error: oerr.Code, ErrorDescription: oerr.Description}
+	if err := base.WriteJSON(w, status, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func noStore(w http.ResponseWriter) {
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Pragma", "no-cache")
+}
@@ -0,0 +1,132 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (ts *TenantService) CreateAPIKey(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req CreateAPIKeyReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	scopes := make([]permission.Permission, 0, len(req.Scopes))
+	for _, v := range req.Scopes {
+		p, err := permission.Parse(v)
+		if err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+		scopes = append(scopes, p)
+	}
+
+	key, err := ts.tenants.CreateAPIKey(r.Context(), tenantID, pl.UserID, req.Name, scopes, req.ExpiresAt)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "createapikey: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.api_key_create").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("api_key_id", key.ID.String()).
+		Str("prefix", key.Prefix).
+		Strs("scopes", key.Scopes.Strings()).
+		Msg("api key created")
+
+	resp := CreateAPIKeyResp{APIKeyResp: toAPIKeyResp(*key), Key: key.Secret}
+	if err := base.WriteJSON(w, http.StatusCreated, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) ListAPIKeys(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	keys, err := ts.tenants.ListAPIKeys(r.Context(), tenantID, pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "listapikeys: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	resp := make([]APIKeyResp, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, toAPIKeyResp(k))
+	}
+	if err := base.WriteJSON(w, http.StatusOK, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) RevokeAPIKey(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	keyID, err := base.GetPathUUID(r, "key_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.RevokeAPIKey(r.Context(), tenantID, pl.UserID, keyID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "revokeapikey: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.api_key_revoke").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("api_key_id", keyID.String()).
+		Msg("api key revoked")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
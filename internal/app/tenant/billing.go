@@ -0,0 +1,160 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	tenantdom "github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// ListPlans lists the plans on sale and their prices.
+func (ts *TenantService) ListPlans(w http.ResponseWriter, r *http.Request) error {
+	if err := base.WriteJSON(w, http.StatusOK, toPlanCatalogResp(billing.Catalog())); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) GetSubscription(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	sub, err := ts.billing.GetSubscription(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "getsubscription: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toSubscriptionResp(sub)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// ChangePlan moves the store to another plan or billing interval. Moves
+// that cost more are charged before the response.
+func (ts *TenantService) ChangePlan(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req ChangePlanReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	plan, err := tenantdom.ParsePlanType(req.Plan)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	interval, err := billing.ParseInterval(req.Interval)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	sub, inv, err := ts.billing.ChangePlan(r.Context(), tenantID, billing.PlanRequest{
+		Plan:     plan,
+		Interval: interval,
+		ActorID:  pl.UserID,
+	})
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "changeplan: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "billing.plan_change").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("plan", string(plan)).
+		Str("interval", string(interval)).
+		Msg("plan changed")
+
+	if err := base.WriteJSON(w, http.StatusOK, toPlanChangeResp(sub, inv)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) ListInvoices(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	invoices, err := ts.billing.ListInvoices(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "listinvoices: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toInvoiceListResp(invoices)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// PayInvoice charges an overdue invoice now. Paying the last one lifts a
+// suspension billing put on the store.
+func (ts *TenantService) PayInvoice(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	invoiceID, err := base.GetPathUUID(r, "invoice_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	inv, err := ts.billing.PayInvoice(r.Context(), tenantID, invoiceID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "payinvoice: tenant[%s] invoice[%s]: %s", tenantID, invoiceID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "billing.invoice_paid").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("invoice_id", invoiceID.String()).
+		Msg("invoice paid")
+
+	if err := base.WriteJSON(w, http.StatusOK, toInvoiceResp(*inv)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (ts *TenantService) GetDomain(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	d, err := ts.domains.GetDomain(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "getdomain: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toCustomDomainResp(d, ts.domains.EdgeHost())); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// AddDomain records the store's custom domain and returns the DNS records
+// to publish before asking for it to be verified.
+func (ts *TenantService) AddDomain(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req AddDomainReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	d, err := ts.domains.AddDomain(r.Context(), tenantID, req.Domain)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "adddomain: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.domain_add").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("domain", d.Domain).
+		Msg("custom domain added")
+
+	if err := base.WriteJSON(w, http.StatusCreated, toCustomDomainResp(d, ts.domains.EdgeHost())); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// VerifyDomain starts polling DNS for the TXT challenge, the response
+// comes back before it is found.
+func (ts *TenantService) VerifyDomain(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	d, err := ts.domains.StartVerification(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "startverification: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.domain_verify").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("domain", d.Domain).
+		Msg("custom domain verification started")
+
+	if err := base.WriteJSON(w, http.StatusAccepted, toCustomDomainResp(d, ts.domains.EdgeHost())); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) RemoveDomain(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.domains.RemoveDomain(r.Context(), tenantID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "removedomain: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.domain_remove").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Msg("custom domain removed")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// AskCertificate is the edge's on-demand TLS check: it orders a
+// certificate for a domain only on a 200. Anything not verified gets a 404.
+func (ts *TenantService) AskCertificate(w http.ResponseWriter, r *http.Request) error {
+	domain := r.URL.Query().Get("domain")
+	if domain == "" {
+		return errs.New(errs.InvalidArgument, errors.New("domain required"))
+	}
+
+	ok, err := ts.domains.AllowCertificate(r.Context(), domain)
+	if err != nil {
+		return errs.Newf(errs.Internal, "allowcertificate: domain[%s]: %s", domain, err)
+	}
+	if !ok {
+		return errs.New(errs.NotFound, errors.New("unknown domain"))
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
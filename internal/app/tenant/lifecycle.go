@@ -0,0 +1,111 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	tenantdom "github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// GoLive opens the store to shoppers, only its owner may.
+func (ts *TenantService) GoLive(w http.ResponseWriter, r *http.Request) error {
+	return ts.changeStatus(w, r, tenantdom.TenantStatusActive, tenantdom.TriggerOwner)
+}
+
+// PauseStore puts the store back in maintenance, only its owner may.
+func (ts *TenantService) PauseStore(w http.ResponseWriter, r *http.Request) error {
+	return ts.changeStatus(w, r, tenantdom.TenantStatusMaintenance, tenantdom.TriggerOwner)
+}
+
+func (ts *TenantService) SuspendStore(w http.ResponseWriter, r *http.Request) error {
+	return ts.changeStatus(w, r, tenantdom.TenantStatusSuspended, tenantdom.TriggerPlatform)
+}
+
+func (ts *TenantService) ArchiveStore(w http.ResponseWriter, r *http.Request) error {
+	return ts.changeStatus(w, r, tenantdom.TenantStatusArchived, tenantdom.TriggerPlatform)
+}
+
+// ReinstateStore lifts a suspension, the store goes back to the status it
+// had before.
+func (ts *TenantService) ReinstateStore(w http.ResponseWriter, r *http.Request) error {
+	return ts.changeStatus(w, r, "", tenantdom.TriggerPlatform)
+}
+
+// changeStatus moves the store in the path to status to, or out of its
+// suspension when to is empty. The body may carry a reason.
+func (ts *TenantService) changeStatus(w http.ResponseWriter, r *http.Request, to tenantdom.TenantStatus, trigger tenantdom.Trigger) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req StatusChangeReq
+	if r.ContentLength != 0 {
+		if err := base.ReadJSON(r, &req); err != nil {
+			return errs.New(errs.InvalidArgument, err)
+		}
+	}
+
+	actorID := pl.UserID
+	var te *tenantdom.TenantProfile
+	if to == "" {
+		te, err = ts.tenants.Reinstate(r.Context(), tenantID, trigger, &actorID, req.Reason)
+	} else {
+		te, err = ts.tenants.ChangeStatus(r.Context(), tenantID, tenantdom.StatusRequest{
+			To:      to,
+			Reason:  req.Reason,
+			Trigger: trigger,
+			ActorID: &actorID,
+		})
+	}
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "changestatus: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.status_change").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("status", string(te.Status)).
+		Str("trigger", string(trigger)).
+		Msg("store status changed")
+
+	if err := base.WriteJSON(w, http.StatusOK, toStoreStatusResp(te)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) ListStatusHistory(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	history, err := ts.tenants.StatusHistory(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "statushistory: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toStatusHistoryResp(history)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
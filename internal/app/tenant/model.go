@@ -1,8 +1,17 @@
 package store
 
 import (
+	"time"
+
 	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	storecatalog "github.com/iamonah/merchcore/internal/domain/store/catalog/storecatalog"
 	tenantdom "github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+	"github.com/shopspring/decimal"
 )
 
 type CreateTenantRequest struct {
@@ -51,7 +60,6 @@ func toTenantCreateInput(userID uuid.UUID, req CreateTenantRequest) tenantdom.Cr
 		BusinessName:      req.BusinessName,
 		Description:       req.Description,
 		Subdomain:         req.Subdomain,
-		Domain:            req.Domain,
 		LogoURL:           req.LogoURL,
 		BusinessMode:      req.BusinessMode,
 		BusinessCategory:  req.BusinessCategory,
@@ -72,6 +80,11 @@ type CreateTenantResponse struct {
 	Status            string    `json:"status"`
 	BusinessMode      string    `json:"business_mode"`
 	NumberOfEmployees int32     `json:"number_of_employees"`
+	// CustomDomain is set when the store asked for a domain of its own, it
+	// is served once verified. CustomDomainError says why it could not be
+	// added, the store is created regardless.
+	CustomDomain      *CustomDomainResp `json:"custom_domain,omitempty"`
+	CustomDomainError string            `json:"custom_domain_error,omitempty"`
 }
 
 func toCreateTenantResponse(t *tenantdom.TenantProfile) CreateTenantResponse {
@@ -87,3 +100,488 @@ func toCreateTenantResponse(t *tenantdom.TenantProfile) CreateTenantResponse {
 		NumberOfEmployees: t.NumberOfEmployees,
 	}
 }
+
+type StorefrontResp struct {
+	ID           uuid.UUID `json:"id"`
+	BusinessName string    `json:"business_name"`
+	LogoURL      string    `json:"logo_url,omitempty"`
+	Domain       string    `json:"domain"`
+	Subdomain    string    `json:"subdomain"`
+	Status       string    `json:"status"`
+	BusinessMode string    `json:"business_mode"`
+}
+
+func toStorefrontResp(t *tenantdom.TenantProfile) StorefrontResp {
+	return StorefrontResp{
+		ID:           t.ID,
+		BusinessName: t.BusinessName,
+		LogoURL:      t.LogoURL,
+		Domain:       *t.Domain,
+		Subdomain:    *t.Subdomain,
+		Status:       string(t.Status),
+		BusinessMode: string(t.BusinessMode),
+	}
+}
+
+type StatusChangeReq struct {
+	Reason string `json:"reason,omitempty"`
+}
+
+type StoreStatusResp struct {
+	ID        uuid.UUID `json:"id"`
+	Status    string    `json:"status"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func toStoreStatusResp(t *tenantdom.TenantProfile) StoreStatusResp {
+	return StoreStatusResp{ID: t.ID, Status: string(t.Status), UpdatedAt: t.UpdatedAt}
+}
+
+type StatusChangeResp struct {
+	ID        uuid.UUID  `json:"id"`
+	From      string     `json:"from"`
+	To        string     `json:"to"`
+	Reason    string     `json:"reason,omitempty"`
+	Trigger   string     `json:"trigger"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type StatusHistoryResp struct {
+	History []StatusChangeResp `json:"history"`
+}
+
+func toStatusHistoryResp(history []tenantdom.StatusChange) StatusHistoryResp {
+	resp := StatusHistoryResp{History: make([]StatusChangeResp, 0, len(history))}
+	for _, c := range history {
+		resp.History = append(resp.History, StatusChangeResp{
+			ID:        c.ID,
+			From:      string(c.From),
+			To:        string(c.To),
+			Reason:    c.Reason,
+			Trigger:   string(c.Trigger),
+			ActorID:   c.ActorID,
+			CreatedAt: c.CreatedAt,
+		})
+	}
+	return resp
+}
+
+type AddDomainReq struct {
+	Domain string `json:"domain" validate:"required"`
+}
+
+// DNSRecord is a record the merchant publishes at their DNS provider.
+type DNSRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type CustomDomainResp struct {
+	ID            uuid.UUID   `json:"id"`
+	Domain        string      `json:"domain"`
+	Status        string      `json:"status"`
+	Records       []DNSRecord `json:"records"`
+	Attempts      int         `json:"attempts"`
+	LastError     string      `json:"last_error,omitempty"`
+	NextCheckAt   *time.Time  `json:"next_check_at,omitempty"`
+	VerifiedAt    *time.Time  `json:"verified_at,omitempty"`
+	CertExpiresAt *time.Time  `json:"cert_expires_at,omitempty"`
+	CreatedAt     time.Time   `json:"created_at"`
+}
+
+// toCustomDomainResp lists the TXT challenge to publish and the CNAME
+// pointing the domain at the edge, edgeHost.
+func toCustomDomainResp(d *customdomain.CustomDomain, edgeHost string) CustomDomainResp {
+	return CustomDomainResp{
+		ID:     d.ID,
+		Domain: d.Domain,
+		Status: string(d.Status),
+		Records: []DNSRecord{
+			{Type: "TXT", Name: d.ChallengeName(), Value: d.ChallengeValue()},
+			{Type: "CNAME", Name: d.Domain, Value: edgeHost},
+		},
+		Attempts:      d.Attempts,
+		LastError:     d.LastError,
+		NextCheckAt:   d.NextCheckAt,
+		VerifiedAt:    d.VerifiedAt,
+		CertExpiresAt: d.CertExpiresAt,
+		CreatedAt:     d.CreatedAt,
+	}
+}
+
+type InviteMemberReq struct {
+	Email string `json:"email" validate:"required,email"`
+	Role  string `json:"role" validate:"required"`
+}
+
+type UpdateMemberRoleReq struct {
+	Role         string     `json:"role" validate:"required"`
+	CustomRoleID *uuid.UUID `json:"custom_role_id,omitempty"`
+}
+
+type InvitationTokenReq struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type MemberResp struct {
+	UserID       uuid.UUID  `json:"user_id"`
+	Email        string     `json:"email"`
+	FirstName    string     `json:"first_name"`
+	LastName     string     `json:"last_name"`
+	Role         string     `json:"role"`
+	CustomRoleID *uuid.UUID `json:"custom_role_id,omitempty"`
+	JoinedAt     time.Time  `json:"joined_at"`
+}
+
+type InvitationResp struct {
+	ID        uuid.UUID `json:"id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TeamResp struct {
+	Members     []MemberResp     `json:"members"`
+	Invitations []InvitationResp `json:"invitations"`
+}
+
+func toMemberResp(m tenantdom.Member) MemberResp {
+	return MemberResp{
+		UserID:       m.UserID,
+		Email:        m.Email,
+		FirstName:    m.FirstName,
+		LastName:     m.LastName,
+		Role:         m.Role.String(),
+		CustomRoleID: m.CustomRoleID,
+		JoinedAt:     m.JoinedAt,
+	}
+}
+
+func toInvitationResp(inv tenantdom.Invitation) InvitationResp {
+	return InvitationResp{
+		ID:        inv.ID,
+		Email:     inv.Email,
+		Role:      inv.Role.String(),
+		ExpiresAt: inv.ExpiresAt,
+		CreatedAt: inv.CreatedAt,
+	}
+}
+
+func toTeamResp(members []tenantdom.Member, invitations []tenantdom.Invitation) TeamResp {
+	resp := TeamResp{
+		Members:     make([]MemberResp, 0, len(members)),
+		Invitations: make([]InvitationResp, 0, len(invitations)),
+	}
+	for _, m := range members {
+		resp.Members = append(resp.Members, toMemberResp(m))
+	}
+	for _, inv := range invitations {
+		resp.Invitations = append(resp.Invitations, toInvitationResp(inv))
+	}
+	return resp
+}
+
+type RoleReq struct {
+	Name        string   `json:"name" validate:"required,max=64"`
+	Description string   `json:"description" validate:"max=256"`
+	Permissions []string `json:"permissions" validate:"required,min=1"`
+}
+
+type RoleResp struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	BuiltIn     bool      `json:"built_in"`
+	CreatedAt   time.Time `json:"created_at,omitzero"`
+	UpdatedAt   time.Time `json:"updated_at,omitzero"`
+}
+
+type RolesResp struct {
+	Roles       []RoleResp `json:"roles"`
+	Permissions []string   `json:"permissions"`
+}
+
+func toRoleResp(cr tenantdom.CustomRole) RoleResp {
+	return RoleResp{
+		ID:          cr.ID,
+		Name:        cr.Name,
+		Description: cr.Description,
+		Permissions: cr.Permissions.Strings(),
+		CreatedAt:   cr.CreatedAt,
+		UpdatedAt:   cr.UpdatedAt,
+	}
+}
+
+// toRolesResp lists the built-in team roles ahead of the store's own, along
+// with the permissions a custom role may grant.
+func toRolesResp(custom []tenantdom.CustomRole) RolesResp {
+	resp := RolesResp{Roles: make([]RoleResp, 0, len(custom)+2)}
+	for _, r := range []role.Role{role.StoreAdmin, role.Staff} {
+		resp.Roles = append(resp.Roles, RoleResp{
+			Name:        r.String(),
+			Permissions: permission.ForStoreRole(r).Strings(),
+			BuiltIn:     true,
+		})
+	}
+	for _, cr := range custom {
+		resp.Roles = append(resp.Roles, toRoleResp(cr))
+	}
+	for _, p := range permission.All() {
+		if p.IsStoreScoped() {
+			resp.Permissions = append(resp.Permissions, p.String())
+		}
+	}
+	return resp
+}
+
+type CreateAPIKeyReq struct {
+	Name      string     `json:"name" validate:"required,max=64"`
+	Scopes    []string   `json:"scopes" validate:"required,min=1"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+type APIKeyResp struct {
+	ID         uuid.UUID  `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	CreatedBy  uuid.UUID  `json:"created_by"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateAPIKeyResp is the only time the key is shown.
+type CreateAPIKeyResp struct {
+	APIKeyResp
+	Key string `json:"key"`
+}
+
+func toAPIKeyResp(k tenantdom.APIKey) APIKeyResp {
+	return APIKeyResp{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     k.Prefix,
+		Scopes:     k.Scopes.Strings(),
+		CreatedBy:  k.CreatedBy,
+		ExpiresAt:  k.ExpiresAt,
+		LastUsedAt: k.LastUsedAt,
+		RevokedAt:  k.RevokedAt,
+		CreatedAt:  k.CreatedAt,
+	}
+}
+
+type TrialResp struct {
+	Plan         string     `json:"plan"`
+	TrialStartAt *time.Time `json:"trial_start_at"`
+	TrialEndAt   *time.Time `json:"trial_end_at"`
+	Active       bool       `json:"active"`
+	DaysLeft     int        `json:"days_left"`
+	// OnExpiry is "suspend" or the plan the store moves to.
+	OnExpiry string `json:"on_expiry"`
+}
+
+func toTrialResp(t tenantdom.Trial) TrialResp {
+	onExpiry := string(t.Outcome.DowngradeTo)
+	if t.Outcome.Suspend {
+		onExpiry = "suspend"
+	}
+	return TrialResp{
+		Plan:         string(t.Plan),
+		TrialStartAt: t.StartAt,
+		TrialEndAt:   t.EndAt,
+		Active:       t.Active,
+		DaysLeft:     t.DaysLeft,
+		OnExpiry:     onExpiry,
+	}
+}
+
+type MoneyResp struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func toMoneyResp(m money.Money) MoneyResp {
+	return MoneyResp{Amount: m.Amount.StringFixed(2), Currency: string(m.Currency)}
+}
+
+type PlanOfferResp struct {
+	Plan    string    `json:"plan"`
+	Monthly MoneyResp `json:"monthly"`
+	Annual  MoneyResp `json:"annual"`
+}
+
+type PlanCatalogResp struct {
+	Plans []PlanOfferResp `json:"plans"`
+}
+
+func toPlanCatalogResp(offers []billing.Offer) PlanCatalogResp {
+	resp := PlanCatalogResp{Plans: make([]PlanOfferResp, 0, len(offers))}
+	for _, o := range offers {
+		resp.Plans = append(resp.Plans, PlanOfferResp{
+			Plan:    string(o.Plan),
+			Monthly: toMoneyResp(o.Price.Monthly),
+			Annual:  toMoneyResp(o.Price.Annual),
+		})
+	}
+	return resp
+}
+
+type ChangePlanReq struct {
+	Plan     string `json:"plan" validate:"required"`
+	Interval string `json:"interval" validate:"required"`
+}
+
+type SubscriptionResp struct {
+	ID                 uuid.UUID  `json:"id"`
+	Plan               string     `json:"plan"`
+	Interval           string     `json:"interval"`
+	Status             string     `json:"status"`
+	CurrentPeriodStart time.Time  `json:"current_period_start"`
+	CurrentPeriodEnd   time.Time  `json:"current_period_end"`
+	PendingPlan        string     `json:"pending_plan,omitempty"`
+	Credit             MoneyResp  `json:"credit"`
+	PastDueSince       *time.Time `json:"past_due_since,omitempty"`
+}
+
+func toSubscriptionResp(s *billing.Subscription) SubscriptionResp {
+	return SubscriptionResp{
+		ID:                 s.ID,
+		Plan:               string(s.Plan),
+		Interval:           string(s.Interval),
+		Status:             string(s.Status),
+		CurrentPeriodStart: s.CurrentPeriodStart,
+		CurrentPeriodEnd:   s.CurrentPeriodEnd,
+		PendingPlan:        string(s.PendingPlan),
+		Credit:             toMoneyResp(s.Credit),
+		PastDueSince:       s.PastDueSince,
+	}
+}
+
+type InvoiceResp struct {
+	ID            uuid.UUID  `json:"id"`
+	Kind          string     `json:"kind"`
+	Status        string     `json:"status"`
+	Plan          string     `json:"plan"`
+	Interval      string     `json:"interval"`
+	PeriodStart   time.Time  `json:"period_start"`
+	PeriodEnd     time.Time  `json:"period_end"`
+	Amount        MoneyResp  `json:"amount"`
+	CreditApplied MoneyResp  `json:"credit_applied"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+	LastError     string     `json:"last_error,omitempty"`
+	PaidAt        *time.Time `json:"paid_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+func toInvoiceResp(inv billing.Invoice) InvoiceResp {
+	return InvoiceResp{
+		ID:            inv.ID,
+		Kind:          string(inv.Kind),
+		Status:        string(inv.Status),
+		Plan:          string(inv.Plan),
+		Interval:      string(inv.Interval),
+		PeriodStart:   inv.PeriodStart,
+		PeriodEnd:     inv.PeriodEnd,
+		Amount:        toMoneyResp(inv.Amount),
+		CreditApplied: toMoneyResp(inv.CreditApplied),
+		Attempts:      inv.Attempts,
+		NextAttemptAt: inv.NextAttemptAt,
+		LastError:     inv.LastError,
+		PaidAt:        inv.PaidAt,
+		CreatedAt:     inv.CreatedAt,
+	}
+}
+
+type InvoiceListResp struct {
+	Invoices []InvoiceResp `json:"invoices"`
+}
+
+func toInvoiceListResp(invoices []billing.Invoice) InvoiceListResp {
+	resp := InvoiceListResp{Invoices: make([]InvoiceResp, 0, len(invoices))}
+	for _, inv := range invoices {
+		resp.Invoices = append(resp.Invoices, toInvoiceResp(inv))
+	}
+	return resp
+}
+
+type PlanChangeResp struct {
+	Subscription SubscriptionResp `json:"subscription"`
+	// Invoice is what the change was billed, absent when it waits for the
+	// end of the period.
+	Invoice *InvoiceResp `json:"invoice,omitempty"`
+}
+
+func toPlanChangeResp(sub *billing.Subscription, inv *billing.Invoice) PlanChangeResp {
+	resp := PlanChangeResp{Subscription: toSubscriptionResp(sub)}
+	if inv != nil {
+		ir := toInvoiceResp(*inv)
+		resp.Invoice = &ir
+	}
+	return resp
+}
+
+type QuotaResp struct {
+	Quota string `json:"quota"`
+	Used  int64  `json:"used"`
+	// Limit is null when the plan does not cap the quota.
+	Limit *int64 `json:"limit"`
+	// Enforced is false for limits shown but not refused yet.
+	Enforced bool `json:"enforced"`
+}
+
+type UsageResp struct {
+	TenantID     uuid.UUID   `json:"tenant_id"`
+	Plan         string      `json:"plan"`
+	CustomDomain bool        `json:"custom_domain"`
+	Quotas       []QuotaResp `json:"quotas"`
+}
+
+func toUsageResp(tenantID uuid.UUID, u *tenantdom.Usage) UsageResp {
+	resp := UsageResp{
+		TenantID:     tenantID,
+		Plan:         string(u.Plan),
+		CustomDomain: u.Entitlements.CustomDomain,
+		Quotas:       make([]QuotaResp, 0, len(tenantdom.Quotas)),
+	}
+	for _, q := range tenantdom.Quotas {
+		qr := QuotaResp{Quota: string(q), Used: u.Used[q], Enforced: q.Enforced()}
+		if limit := u.Entitlements.Limit(q); limit != tenantdom.Unlimited {
+			qr.Limit = &limit
+		}
+		resp.Quotas = append(resp.Quotas, qr)
+	}
+	return resp
+}
+
+type CreateProductReq struct {
+	Name        string          `json:"name" validate:"required,max=255"`
+	Description string          `json:"description"`
+	Price       decimal.Decimal `json:"price"`
+}
+
+type ProductResp struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Price       MoneyResp `json:"price"`
+	Active      bool      `json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func toProductResp(p storecatalog.Product) ProductResp {
+	return ProductResp{
+		ID:          uuid.UUID(p.ID),
+		Name:        p.Name,
+		Description: p.Description,
+		Price:       toMoneyResp(p.Price),
+		Active:      p.Active,
+		CreatedAt:   p.CreatedAt,
+	}
+}
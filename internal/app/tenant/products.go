@@ -0,0 +1,59 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// CreateProduct adds a product to the store's catalog, refused once the
+// store's plan has no room for another.
+func (ts *TenantService) CreateProduct(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req CreateProductReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	// the catalog sells in the currency plans are billed in
+	p, err := ts.catalog.CreateProduct(r.Context(), tenantID, req.Name, req.Description, money.New(req.Price, billing.Currency))
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "createproduct: tenant[%s]: %s", tenantID, err)
+	}
+
+	resp := toProductResp(*p)
+	ts.log.Info().
+		Str("event", "catalog.product_create").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("product_id", resp.ID.String()).
+		Msg("product created")
+
+	if err := base.WriteJSON(w, http.StatusCreated, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func (ts *TenantService) ListRoles(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	roles, err := ts.tenants.ListRoles(r.Context(), tenantID, pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "listroles: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toRolesResp(roles)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) CreateRole(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	req, perms, err := readRoleReq(r)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cr, err := ts.tenants.CreateRole(r.Context(), tenantID, pl.UserID, req.Name, req.Description, perms)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "createrole: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.role_create").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("role_id", cr.ID.String()).
+		Strs("permissions", cr.Permissions.Strings()).
+		Msg("custom role created")
+
+	if err := base.WriteJSON(w, http.StatusCreated, toRoleResp(*cr)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) UpdateRole(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	roleID, err := base.GetPathUUID(r, "role_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	req, perms, err := readRoleReq(r)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	cr, err := ts.tenants.UpdateRole(r.Context(), tenantID, pl.UserID, roleID, req.Name, req.Description, perms)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "updaterole: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.role_update").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("role_id", cr.ID.String()).
+		Strs("permissions", cr.Permissions.Strings()).
+		Msg("custom role updated")
+
+	if err := base.WriteJSON(w, http.StatusOK, toRoleResp(*cr)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) DeleteRole(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	roleID, err := base.GetPathUUID(r, "role_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.DeleteRole(r.Context(), tenantID, pl.UserID, roleID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "deleterole: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.role_delete").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("role_id", roleID.String()).
+		Msg("custom role deleted")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func readRoleReq(r *http.Request) (RoleReq, []permission.Permission, error) {
+	var req RoleReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return RoleReq{}, nil, err
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return RoleReq{}, nil, err
+	}
+
+	perms := make([]permission.Permission, 0, len(req.Permissions))
+	for _, v := range req.Permissions {
+		p, err := permission.Parse(v)
+		if err != nil {
+			return RoleReq{}, nil, err
+		}
+		perms = append(perms, p)
+	}
+	return req, perms, nil
+}
@@ -3,15 +3,24 @@ package store
 import (
 	"errors"
 
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	"github.com/iamonah/merchcore/internal/domain/store/catalog"
 	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/users"
 	"github.com/iamonah/merchcore/internal/sdk/jobs"
 	"github.com/rs/zerolog"
 )
 
 type TenantService struct {
-	log     *zerolog.Logger
-	job     jobs.JobService
-	tenants *tenant.TenantBusiness
+	log           *zerolog.Logger
+	job           jobs.JobService
+	tenants       *tenant.TenantBusiness
+	domains       *customdomain.DomainBusiness
+	billing       *billing.BillingBusiness
+	catalog       *catalog.CatalogBusiness
+	users         users.ExtUserBusiness
+	invitationURL string
 }
 
 type TenantConfiguration func(ts *TenantService) error
@@ -29,6 +38,15 @@ func NewTenantService(cfgs ...TenantConfiguration) (*TenantService, error) {
 	if ts.tenants == nil {
 		return nil, errors.New("tenant business is required")
 	}
+	if ts.domains == nil {
+		return nil, errors.New("custom domain business is required")
+	}
+	if ts.billing == nil {
+		return nil, errors.New("billing business is required")
+	}
+	if ts.catalog == nil {
+		return nil, errors.New("catalog business is required")
+	}
 	return ts, nil
 }
 
@@ -44,6 +62,27 @@ func WithTenantBusiness(tb *tenant.TenantBusiness) TenantConfiguration {
 	}
 }
 
+func WithDomainBusiness(db *customdomain.DomainBusiness) TenantConfiguration {
+	return func(ts *TenantService) error {
+		ts.domains = db
+		return nil
+	}
+}
+
+func WithBillingBusiness(bb *billing.BillingBusiness) TenantConfiguration {
+	return func(ts *TenantService) error {
+		ts.billing = bb
+		return nil
+	}
+}
+
+func WithCatalogBusiness(cb *catalog.CatalogBusiness) TenantConfiguration {
+	return func(ts *TenantService) error {
+		ts.catalog = cb
+		return nil
+	}
+}
+
 func WithLog(log *zerolog.Logger) TenantConfiguration {
 	return func(ts *TenantService) error {
 		ts.log = log
@@ -57,3 +96,19 @@ func WithJob(job jobs.JobService) TenantConfiguration {
 		return nil
 	}
 }
+
+func WithUserBusiness(ub users.ExtUserBusiness) TenantConfiguration {
+	return func(ts *TenantService) error {
+		ts.users = ub
+		return nil
+	}
+}
+
+// WithInvitationURL sets the page invitees land on, the token is added as
+// a query parameter.
+func WithInvitationURL(url string) TenantConfiguration {
+	return func(ts *TenantService) error {
+		ts.invitationURL = url
+		return nil
+	}
+}
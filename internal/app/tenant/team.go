@@ -0,0 +1,367 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+
+	tenantdom "github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"github.com/iamonah/merchcore/internal/sdk/jobs"
+)
+
+// ListTeam is also open to API keys, they read the team as the member who
+// created them.
+func (ts *TenantService) ListTeam(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetPrincipalCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	members, invitations, err := ts.tenants.ListTeam(r.Context(), tenantID, pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "listteam: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toTeamResp(members, invitations)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) InviteTeamMember(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req InviteMemberReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	email, err := mail.ParseAddress(req.Email)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, errors.New("invalid email"))
+	}
+	memberRole, err := tenantdom.ParseMemberRole(req.Role)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	inviter, err := ts.users.GetUser(r.Context(), pl.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getuser: user[%s]: %s", pl.UserID, err)
+	}
+
+	inv, err := ts.tenants.InviteMember(r.Context(), tenantID, pl.UserID, email, memberRole)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "invitemember: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	link, err := url.Parse(ts.invitationURL)
+	if err != nil {
+		return errs.Newf(errs.Internal, "parseinvitationurl: %s", err)
+	}
+	q := link.Query()
+	q.Set("token", inv.Token)
+	link.RawQuery = q.Encode()
+
+	err = ts.job.StaffInviteEmailJob(jobs.StaffInvitePayload{
+		Email:        inv.Email,
+		BusinessName: inv.BusinessName,
+		InviterName:  fmt.Sprintf("%s %s", inviter.FirstName, inviter.LastName),
+		Role:         inv.Role.String(),
+		Link:         link.String(),
+		TenantID:     tenantID,
+	})
+	if err != nil {
+		return errs.Newf(errs.Internal, "staffinviteemailjob: tenant[%s]: %s", tenantID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.member_invite").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("invitation_id", inv.ID.String()).
+		Str("role", inv.Role.String()).
+		Msg("team member invited")
+
+	if err := base.WriteJSON(w, http.StatusCreated, toInvitationResp(*inv)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) RevokeInvitation(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	invitationID, err := base.GetPathUUID(r, "id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.RevokeInvitation(r.Context(), tenantID, pl.UserID, invitationID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "revokeinvitation: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.invitation_revoke").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("invitation_id", invitationID.String()).
+		Msg("invitation revoked")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// AcceptInvitation joins the signed-in user to the store. The account must
+// own the invited address and have verified it.
+func (ts *TenantService) AcceptInvitation(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	var req InvitationTokenReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	user, err := ts.users.GetUser(r.Context(), pl.UserID)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getuser: user[%s]: %s", pl.UserID, err)
+	}
+	if !user.IsVerified {
+		return errs.New(errs.FailedPrecondition, errors.New("verify your email before accepting invitations"))
+	}
+
+	member, err := ts.tenants.AcceptInvitation(r.Context(), req.Token, user.UserID, user.GetEmail())
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "acceptinvitation: user[%s]: %s", pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.member_join").
+		Str("req_id", reqID).
+		Str("tenant_id", member.TenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("role", member.Role.String()).
+		Msg("invitation accepted")
+
+	if err := base.WriteJSON(w, http.StatusOK, toMemberResp(*member)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) RejectInvitation(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+
+	var req InvitationTokenReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.RejectInvitation(r.Context(), req.Token); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "rejectinvitation: reqID[%s]: %s", reqID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.invitation_reject").
+		Str("req_id", reqID).
+		Msg("invitation rejected")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) UpdateTeamMemberRole(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	memberID, err := base.GetPathUUID(r, "user_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	var req UpdateMemberRoleReq
+	if err := base.ReadJSON(r, &req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	if err := errs.NewValidate(req); err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	memberRole, err := tenantdom.ParseMemberRole(req.Role)
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	member, err := ts.tenants.UpdateMemberRole(r.Context(), tenantID, pl.UserID, memberID, memberRole, req.CustomRoleID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "updatememberrole: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.member_role_update").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("member_id", memberID.String()).
+		Str("role", member.Role.String()).
+		Msg("team member role updated")
+
+	if err := base.WriteJSON(w, http.StatusOK, toMemberResp(*member)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+func (ts *TenantService) RemoveTeamMember(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+	memberID, err := base.GetPathUUID(r, "user_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.RemoveMember(r.Context(), tenantID, memberID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "removemember: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.member_remove").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Str("member_id", memberID.String()).
+		Msg("team member removed")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// LeaveTeam takes the caller off the store's team. It sits on its own route
+// so members without team:manage can still leave.
+func (ts *TenantService) LeaveTeam(w http.ResponseWriter, r *http.Request) error {
+	reqID, err := base.GetReqIDCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "getreqidCTX: %s", err)
+	}
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	if err := ts.tenants.LeaveTeam(r.Context(), tenantID, pl.UserID); err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "leaveteam: tenant[%s] user[%s]: %s", tenantID, pl.UserID, err)
+	}
+
+	ts.log.Info().
+		Str("event", "tenant.member_leave").
+		Str("req_id", reqID).
+		Str("tenant_id", tenantID.String()).
+		Str("user_id", pl.UserID.String()).
+		Msg("team member left")
+
+	if err := base.WriteJSON(w, http.StatusNoContent, nil); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -41,7 +41,36 @@ func (ts *TenantService) CreateTenant(w http.ResponseWriter, r *http.Request) er
 		Str("user_id", pl.UserID.String()).
 		Msg("tenant created")
 
-	if err := base.WriteJSON(w, http.StatusCreated, toCreateTenantResponse(profile)); err != nil {
+	resp := toCreateTenantResponse(profile)
+	if req.Domain != nil && *req.Domain != "" {
+		d, err := ts.domains.AddDomain(r.Context(), profile.ID, *req.Domain)
+		if err != nil {
+			derr, ok := errs.IsDomainError(err)
+			if !ok {
+				return errs.Newf(errs.Internal, "adddomain: reqID[%s] tenant[%s]: %s", reqID, profile.ID, err)
+			}
+			resp.CustomDomainError = derr.Error()
+		} else {
+			cd := toCustomDomainResp(d, ts.domains.EdgeHost())
+			resp.CustomDomain = &cd
+		}
+	}
+
+	if err := base.WriteJSON(w, http.StatusCreated, resp); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// GetStorefront returns the public face of the store the request's host
+// resolved to.
+func (ts *TenantService) GetStorefront(w http.ResponseWriter, r *http.Request) error {
+	te, err := base.GetTenantCTX(r)
+	if err != nil {
+		return errs.Newf(errs.Internal, "gettenantCTX: %s", err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toStorefrontResp(te)); err != nil {
 		return errs.Newf(errs.Internal, "writejson: %s", err)
 	}
 	return nil
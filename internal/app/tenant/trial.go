@@ -0,0 +1,29 @@
+package store
+
+import (
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// GetTrial shows the store's trial countdown and what happens when it ends.
+func (ts *TenantService) GetTrial(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	trial, err := ts.tenants.GetTrial(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "gettrial: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toTrialResp(trial)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// GetUsage shows what the store consumes of each plan limit.
+func (ts *TenantService) GetUsage(w http.ResponseWriter, r *http.Request) error {
+	tenantID, err := base.GetPathUUID(r, "tenant_id")
+	if err != nil {
+		return errs.New(errs.InvalidArgument, err)
+	}
+
+	usage, err := ts.tenants.Usage(r.Context(), tenantID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "usage: tenant[%s]: %s", tenantID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toUsageResp(tenantID, usage)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
+
+// GetCallerUsage is GetUsage for the caller's store, found from who they
+// are rather than the path.
+func (ts *TenantService) GetCallerUsage(w http.ResponseWriter, r *http.Request) error {
+	pl, err := base.GetJWTPayloadCTX(r)
+	if err != nil {
+		return errs.New(errs.Unauthenticated, errors.New("unauthorized"))
+	}
+
+	tenantID, usage, err := ts.tenants.CallerUsage(r.Context(), pl.UserID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok {
+			return errs.New(derr.Code, derr)
+		}
+		return errs.Newf(errs.Internal, "callerusage: user[%s]: %s", pl.UserID, err)
+	}
+
+	if err := base.WriteJSON(w, http.StatusOK, toUsageResp(tenantID, usage)); err != nil {
+		return errs.Newf(errs.Internal, "writejson: %s", err)
+	}
+	return nil
+}
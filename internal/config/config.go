@@ -13,6 +13,12 @@ type Config struct {
 	Server        ServerConfig        `mapstructure:"SERVER"`
 	Database      DatabaseConfig      `mapstructure:"DATABASE"`
 	Auth          AuthConfig          `mapstructure:"AUTH"`
+	Password      PasswordConfig      `mapstructure:"PASSWORD"`
+	OAuth         OAuthConfig         `mapstructure:"OAUTH"`
+	Cleanup       CleanupConfig       `mapstructure:"CLEANUP"`
+	CustomDomain  CustomDomainConfig  `mapstructure:"CUSTOM_DOMAIN"`
+	Trial         TrialConfig         `mapstructure:"TRIAL"`
+	Billing       BillingConfig       `mapstructure:"BILLING"`
 	Redis         RedisConfig         `mapstructure:"REDIS"`
 	Mailer        MailerConfig        `mapstructure:"MAILER"`
 	Observability ObservabilityConfig `mapstructure:"OBSERVABILITY"`
@@ -64,8 +70,90 @@ type MailerConfig struct {
 type AuthConfig struct {
 	AccessTokenLifeTime  time.Duration `mapstructure:"ACCESS_TOKEN_LIFETIME" validate:"required"`
 	RefreshTokenLifeTime time.Duration `mapstructure:"REFRESH_TOKEN_LIFETIME" validate:"required"`
-	TokenSymmetricKey    string        `mapstructure:"SYMMETRIC_KEY" validate:"required,min=24"`
+	SigningKeyFile       string        `mapstructure:"SIGNING_KEY_FILE" validate:"required"`
+	RetiringKeys         []RetiringKey `mapstructure:"RETIRING_KEYS" validate:"dive"`
+	// Deprecated: TokenSymmetricKey signed tokens before the keyring did.
+	// When set, tokens it signed still verify. Unset it once
+	// RefreshTokenLifeTime has passed since the keyring went live.
+	TokenSymmetricKey    string        `mapstructure:"SYMMETRIC_KEY" validate:"omitempty,min=24"`
 	GoogleClientID       string        `mapstructure:"GOOGLE_CLIENT_ID" validate:"required"`
+	MFAEncryptionKey     string        `mapstructure:"MFA_ENCRYPTION_KEY" validate:"required,min=32"`
+	MagicLinkURL         string        `mapstructure:"MAGIC_LINK_URL" validate:"required,url"`
+	DataExportURL        string        `mapstructure:"DATA_EXPORT_URL" validate:"required,url"`
+	InvitationURL        string        `mapstructure:"INVITATION_URL" validate:"required,url"`
+	AccountDeletionGrace time.Duration `mapstructure:"ACCOUNT_DELETION_GRACE" validate:"required"`
+	ImpersonationTTL     time.Duration `mapstructure:"IMPERSONATION_TTL" validate:"required"`
+	SignInReportURL      string        `mapstructure:"SIGNIN_REPORT_URL" validate:"required,url"`
+	// GeoIPFile is an optional DB-IP style range CSV used to place new
+	// sign-ins in the alert email.
+	GeoIPFile string `mapstructure:"GEOIP_FILE"`
+}
+
+// PasswordConfig is the policy for new passwords. MinClasses counts
+// lowercase, uppercase, digits and symbols. History is how many previous
+// passwords cannot be reused. BreachedList is an optional Pwned Passwords
+// style SHA-1 dump, gzipped or not, checked offline.
+type PasswordConfig struct {
+	MinLength    int    `mapstructure:"MIN_LENGTH" validate:"required,min=8"`
+	MinClasses   int    `mapstructure:"MIN_CLASSES" validate:"min=0,max=4"`
+	History      int    `mapstructure:"HISTORY" validate:"min=0,max=24"`
+	BreachedList string `mapstructure:"BREACHED_LIST"`
+	// Argon2id tuning, zero keeps the default. Memory is in KiB. Changing
+	// them upgrades each hash on its owner's next sign-in.
+	HashMemory      uint32 `mapstructure:"HASH_MEMORY" validate:"omitempty,min=8192"`
+	HashIterations  uint32 `mapstructure:"HASH_ITERATIONS" validate:"omitempty,min=1"`
+	HashParallelism uint8  `mapstructure:"HASH_PARALLELISM" validate:"omitempty,min=1"`
+}
+
+// OAuthConfig is for the tokens third-party apps get. CodeLifeTime is how
+// long an authorization code waits to be exchanged, keep it short.
+type OAuthConfig struct {
+	AccessTokenLifeTime  time.Duration `mapstructure:"ACCESS_TOKEN_LIFETIME" validate:"required"`
+	RefreshTokenLifeTime time.Duration `mapstructure:"REFRESH_TOKEN_LIFETIME" validate:"required"`
+	CodeLifeTime         time.Duration `mapstructure:"CODE_LIFETIME" validate:"required,max=10m"`
+}
+
+// CleanupConfig drives the worker's periodic sweep of expired rows.
+// BatchSize bounds each DELETE so no run holds long locks. Unverified
+// accounts older than UnverifiedRetention are removed.
+type CleanupConfig struct {
+	Interval            time.Duration `mapstructure:"INTERVAL" validate:"required,min=1m"`
+	BatchSize           int           `mapstructure:"BATCH_SIZE" validate:"required,min=1,max=10000"`
+	UnverifiedRetention time.Duration `mapstructure:"UNVERIFIED_RETENTION" validate:"required,min=24h"`
+}
+
+// TrialConfig drives the worker's scan of store trials, CheckInterval is
+// how often it looks for trials to remind about or end. Keep it well under
+// a day so the one day reminder goes out in time.
+type TrialConfig struct {
+	CheckInterval time.Duration `mapstructure:"CHECK_INTERVAL" validate:"required,min=1m,max=6h"`
+}
+
+// BillingConfig drives plan subscriptions. A store whose renewal is still
+// unpaid GracePeriod after it first failed is suspended. RunInterval is how
+// often the worker renews, retries payments and applies the grace period.
+type BillingConfig struct {
+	GracePeriod time.Duration `mapstructure:"GRACE_PERIOD" validate:"required,min=24h"`
+	RunInterval time.Duration `mapstructure:"RUN_INTERVAL" validate:"required,min=1m,max=6h"`
+}
+
+// CustomDomainConfig is for merchants serving their store on their own
+// domain. EdgeHost is the CNAME target they are told to point it at.
+// A domain whose TXT challenge is not found within VerifyWindow fails, a
+// pending or failed one is dropped once left alone that long again.
+// CheckInterval is how often the worker polls DNS and the served
+// certificates.
+type CustomDomainConfig struct {
+	EdgeHost      string        `mapstructure:"EDGE_HOST" validate:"required,hostname"`
+	VerifyWindow  time.Duration `mapstructure:"VERIFY_WINDOW" validate:"required,min=1h"`
+	CheckInterval time.Duration `mapstructure:"CHECK_INTERVAL" validate:"required,min=30s"`
+}
+
+// RetiringKey is a previous signing key kept for verification only until
+// ExpiresAt (RFC 3339), which should be past the longest token lifetime.
+type RetiringKey struct {
+	File      string `mapstructure:"FILE" validate:"required"`
+	ExpiresAt string `mapstructure:"EXPIRES_AT" validate:"required,datetime=2006-01-02T15:04:05Z07:00"`
 }
 
 type AWSS3Config struct {
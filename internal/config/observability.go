@@ -8,6 +8,9 @@ import (
 type ObservabilityConfig struct {
 	ServiceName string `mapstructure:"SERVICE_NAME" validate:"required"`
 	Environment string `mapstructure:"ENVIRONMENT" validate:"required"`
+	// MetricsAddr is where the worker serves its expvar metrics on
+	// /debug/vars, empty turns it off.
+	MetricsAddr string `mapstructure:"METRICS_ADDR"`
 }
 
 func (c *ObservabilityConfig) Validate() error {
@@ -62,4 +65,4 @@ type NewRelicConfig struct {
 
 func (c *ObservabilityConfig) IsProduction() bool {
 	return c.Environment == "production"
-}
\ No newline at end of file
+}
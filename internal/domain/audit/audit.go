@@ -0,0 +1,113 @@
+package audit
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ActorKind string
+
+const (
+	ActorUser   ActorKind = "user"
+	ActorAPIKey ActorKind = "api_key"
+	// ActorApp is a third-party app acting for the user who installed it.
+	ActorApp ActorKind = "app"
+	// ActorSystem covers jobs and anything else without a caller.
+	ActorSystem ActorKind = "system"
+)
+
+// Entry is one change in the audit log. Before and After only hold the
+// fields that changed, never secrets.
+type Entry struct {
+	ID             uuid.UUID
+	TenantID       *uuid.UUID
+	ActorKind      ActorKind
+	ActorID        *uuid.UUID
+	ImpersonatorID *uuid.UUID
+	APIKeyID       *uuid.UUID
+	Action         string
+	TargetType     string
+	TargetID       string
+	Before         map[string]any
+	After          map[string]any
+	ClientIP       string
+	ReqID          string
+	CreatedAt      time.Time
+}
+
+// Actor is the authenticated caller, set on the context by the transport
+// layer once the request is authenticated.
+type Actor struct {
+	Kind           ActorKind
+	UserID         uuid.UUID
+	ImpersonatorID uuid.UUID
+	APIKeyID       uuid.UUID
+}
+
+// Request carries where the change came from.
+type Request struct {
+	ReqID    string
+	ClientIP string
+}
+
+type actorKey struct{}
+type requestKey struct{}
+
+func WithActor(ctx context.Context, a Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, a)
+}
+
+func WithRequest(ctx context.Context, r Request) context.Context {
+	return context.WithValue(ctx, requestKey{}, r)
+}
+
+func actorFromContext(ctx context.Context) (Actor, bool) {
+	a, ok := ctx.Value(actorKey{}).(Actor)
+	return a, ok
+}
+
+func requestFromContext(ctx context.Context) Request {
+	r, _ := ctx.Value(requestKey{}).(Request)
+	return r
+}
+
+// Diff drops the keys whose value did not change, what is left is the
+// before/after pair worth keeping.
+func Diff(before, after map[string]any) (map[string]any, map[string]any) {
+	b := make(map[string]any)
+	a := make(map[string]any)
+	for k, v := range before {
+		if nv, ok := after[k]; !ok || !reflect.DeepEqual(v, nv) {
+			b[k] = v
+		}
+	}
+	for k, v := range after {
+		if ov, ok := before[k]; !ok || !reflect.DeepEqual(ov, v) {
+			a[k] = v
+		}
+	}
+	return b, a
+}
+
+// Filter narrows a query of the log. Zero values match everything.
+type Filter struct {
+	TenantID   *uuid.UUID
+	ActorID    *uuid.UUID
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+	Offset     int
+}
+
+type Page struct {
+	Entries []Entry
+	Total   int
+	Limit   int
+	Offset  int
+}
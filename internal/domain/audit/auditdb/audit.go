@@ -0,0 +1,142 @@
+package auditdb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type auditStore struct {
+	conn database.DBTX
+}
+
+func NewAuditStore(conn *pgxpool.Pool) *auditStore {
+	return &auditStore{conn: conn}
+}
+
+// InsertEntry joins the transaction on ctx, if any.
+func (as *auditStore) InsertEntry(ctx context.Context, e *audit.Entry) error {
+	conn := database.GetTXFromContext(ctx, as.conn)
+
+	const query = `
+		INSERT INTO audit_logs (
+			id, tenant_id, actor_kind, actor_id, impersonator_id, api_key_id,
+			action, target_type, target_id, before, after, client_ip, req_id
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query,
+		e.ID,
+		e.TenantID,
+		e.ActorKind,
+		e.ActorID,
+		e.ImpersonatorID,
+		e.APIKeyID,
+		e.Action,
+		e.TargetType,
+		e.TargetID,
+		e.Before,
+		e.After,
+		e.ClientIP,
+		e.ReqID,
+	).Scan(&e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", audit.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (as *auditStore) ListEntries(ctx context.Context, f audit.Filter) ([]audit.Entry, int, error) {
+	conn := database.GetTXFromContext(ctx, as.conn)
+
+	where, args := filterClause(f)
+
+	var total int
+	countQuery := `SELECT count(*) FROM audit_logs` + where
+	if err := conn.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", audit.ErrDatabase, err)
+	}
+
+	args = append(args, f.Limit, f.Offset)
+	query := `
+		SELECT id, tenant_id, actor_kind, actor_id, impersonator_id, api_key_id,
+		       action, target_type, target_id, before, after, client_ip, req_id, created_at
+		FROM audit_logs` + where + `
+		ORDER BY created_at DESC, id
+		LIMIT $` + strconv.Itoa(len(args)-1) + ` OFFSET $` + strconv.Itoa(len(args))
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", audit.ErrDatabase, err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (audit.Entry, error) {
+		var e audit.Entry
+		err := row.Scan(
+			&e.ID,
+			&e.TenantID,
+			&e.ActorKind,
+			&e.ActorID,
+			&e.ImpersonatorID,
+			&e.APIKeyID,
+			&e.Action,
+			&e.TargetType,
+			&e.TargetID,
+			&e.Before,
+			&e.After,
+			&e.ClientIP,
+			&e.ReqID,
+			&e.CreatedAt,
+		)
+		return e, err
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("%w: %w", audit.ErrDatabase, err)
+	}
+	return entries, total, nil
+}
+
+func filterClause(f audit.Filter) (string, []any) {
+	var (
+		conds []string
+		args  []any
+	)
+	add := func(cond string, v any) {
+		args = append(args, v)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.TenantID != nil {
+		add("tenant_id = $%d", *f.TenantID)
+	}
+	if f.ActorID != nil {
+		add("actor_id = $%d", *f.ActorID)
+	}
+	if f.Action != "" {
+		add("action = $%d", f.Action)
+	}
+	if f.TargetType != "" {
+		add("target_type = $%d", f.TargetType)
+	}
+	if f.TargetID != "" {
+		add("target_id = $%d", f.TargetID)
+	}
+	if f.From != nil {
+		add("created_at >= $%d", *f.From)
+	}
+	if f.To != nil {
+		add("created_at < $%d", *f.To)
+	}
+
+	if len(conds) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
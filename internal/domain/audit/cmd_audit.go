@@ -0,0 +1,108 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	defaultLimit = 50
+	maxLimit     = 200
+)
+
+// Recorder is what the other domains depend on to write the log.
+type Recorder interface {
+	Record(ctx context.Context, e Entry) error
+}
+
+var _ Recorder = (*AuditBusiness)(nil)
+
+type AuditBusiness struct {
+	storer Repository
+}
+
+type AuditBusinessCfg func(ab *AuditBusiness) error
+
+func NewAuditBusiness(cfgs ...AuditBusinessCfg) (*AuditBusiness, error) {
+	ab := &AuditBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(ab); err != nil {
+			return nil, err
+		}
+	}
+	if ab.storer == nil {
+		return nil, errors.New("audit repository is required")
+	}
+	return ab, nil
+}
+
+func WithAuditRepository(st Repository) AuditBusinessCfg {
+	return func(ab *AuditBusiness) error {
+		ab.storer = st
+		return nil
+	}
+}
+
+// Record writes e, filling in the caller and request from ctx where e does
+// not say otherwise. Call it with the context of the transaction making the
+// change so the entry commits or rolls back with it.
+func (ab *AuditBusiness) Record(ctx context.Context, e Entry) error {
+	if e.ActorKind == "" {
+		e.ActorKind = ActorSystem
+		if a, ok := actorFromContext(ctx); ok {
+			e.ActorKind = a.Kind
+			if e.ActorID == nil && a.UserID != uuid.Nil {
+				e.ActorID = &a.UserID
+			}
+			if a.ImpersonatorID != uuid.Nil {
+				e.ImpersonatorID = &a.ImpersonatorID
+			}
+			if a.APIKeyID != uuid.Nil {
+				e.APIKeyID = &a.APIKeyID
+			}
+		} else if e.ActorID != nil {
+			e.ActorKind = ActorUser
+		}
+	}
+
+	req := requestFromContext(ctx)
+	if e.ReqID == "" {
+		e.ReqID = req.ReqID
+	}
+	if e.ClientIP == "" {
+		e.ClientIP = req.ClientIP
+	}
+	e.ID = uuid.New()
+
+	if err := ab.storer.InsertEntry(ctx, &e); err != nil {
+		return fmt.Errorf("insertentry: %w", err)
+	}
+	return nil
+}
+
+// List returns a page of the log, newest first, with the number of entries
+// matching f.
+func (ab *AuditBusiness) List(ctx context.Context, f Filter) (*Page, error) {
+	if f.Limit <= 0 {
+		f.Limit = defaultLimit
+	}
+	if f.Limit > maxLimit {
+		return nil, errs.NewDomainError(errs.InvalidArgument, fmt.Errorf("limit cannot be more than %d", maxLimit))
+	}
+	if f.Offset < 0 {
+		return nil, errs.NewDomainError(errs.InvalidArgument, errors.New("offset cannot be negative"))
+	}
+	if f.From != nil && f.To != nil && f.To.Before(*f.From) {
+		return nil, errs.NewDomainError(errs.InvalidArgument, errors.New("to must not be before from"))
+	}
+
+	entries, total, err := ab.storer.ListEntries(ctx, f)
+	if err != nil {
+		return nil, fmt.Errorf("listentries: %w", err)
+	}
+	return &Page{Entries: entries, Total: total, Limit: f.Limit, Offset: f.Offset}, nil
+}
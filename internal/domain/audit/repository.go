@@ -0,0 +1,13 @@
+package audit
+
+import (
+	"context"
+	"errors"
+)
+
+var ErrDatabase = errors.New("database error")
+
+type Repository interface {
+	InsertEntry(ctx context.Context, e *Entry) error
+	ListEntries(ctx context.Context, f Filter) ([]Entry, int, error)
+}
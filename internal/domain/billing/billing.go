@@ -0,0 +1,331 @@
+package billing
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/shopspring/decimal"
+)
+
+// Interval is how often a subscription renews.
+type Interval string
+
+var intervals = make(map[string]Interval)
+
+func newInterval(v string) Interval {
+	i := Interval(v)
+	intervals[v] = i
+	return i
+}
+
+var (
+	Monthly = newInterval("monthly")
+	Annual  = newInterval("annual")
+)
+
+func ParseInterval(v string) (Interval, error) {
+	i, ok := intervals[strings.ToLower(v)]
+	if !ok {
+		return "", fmt.Errorf("invalid billing interval: %v", v)
+	}
+	return i, nil
+}
+
+// periodEnd is when a billing period of i starting at start ends.
+func (i Interval) periodEnd(start time.Time) time.Time {
+	if i == Annual {
+		return start.AddDate(1, 0, 0)
+	}
+	return start.AddDate(0, 1, 0)
+}
+
+// Currency is what plans are sold in.
+const Currency = money.USD
+
+// Price is what a plan costs for each interval.
+type Price struct {
+	Monthly money.Money
+	Annual  money.Money
+}
+
+func (p Price) For(i Interval) money.Money {
+	if i == Annual {
+		return p.Annual
+	}
+	return p.Monthly
+}
+
+func usd(v int64) money.Money {
+	return money.New(decimal.NewFromInt(v), Currency)
+}
+
+// catalog is what each plan sells for, a year costs ten months.
+var catalog = map[tenant.PlanType]Price{
+	tenant.FreePlan:       {Monthly: usd(0), Annual: usd(0)},
+	tenant.CorePlan:       {Monthly: usd(29), Annual: usd(290)},
+	tenant.ProPlan:        {Monthly: usd(79), Annual: usd(790)},
+	tenant.EnterprisePlan: {Monthly: usd(299), Annual: usd(2990)},
+}
+
+// Offer is a plan as listed to store owners.
+type Offer struct {
+	Plan  tenant.PlanType
+	Price Price
+}
+
+// Catalog lists the plans, cheapest first.
+func Catalog() []Offer {
+	offers := make([]Offer, 0, len(catalog))
+	for plan, price := range catalog {
+		offers = append(offers, Offer{Plan: plan, Price: price})
+	}
+	slices.SortFunc(offers, func(a, b Offer) int {
+		return a.Price.Monthly.Amount.Cmp(b.Price.Monthly.Amount)
+	})
+	return offers
+}
+
+func PlanPrice(plan tenant.PlanType, interval Interval) (money.Money, error) {
+	p, ok := catalog[plan]
+	if !ok {
+		return money.Money{}, fmt.Errorf("%w: %s", ErrUnknownPlan, plan)
+	}
+	return p.For(interval), nil
+}
+
+type Status string
+
+var statuses = make(map[string]Status)
+
+func newStatus(v string) Status {
+	s := Status(v)
+	statuses[v] = s
+	return s
+}
+
+// A subscription is incomplete until its first invoice is paid. A renewal
+// that cannot be collected makes it past_due, the retries and the grace
+// period run from then. Once the grace period is over it is unpaid and the
+// store is suspended until the overdue invoices are paid. canceled ones
+// went back to the free plan.
+var (
+	StatusIncomplete = newStatus("incomplete")
+	StatusActive     = newStatus("active")
+	StatusPastDue    = newStatus("past_due")
+	StatusUnpaid     = newStatus("unpaid")
+	StatusCanceled   = newStatus("canceled")
+)
+
+func ParseStatus(v string) (Status, error) {
+	s, ok := statuses[strings.ToLower(v)]
+	if !ok {
+		return "", fmt.Errorf("invalid subscription status: %v", v)
+	}
+	return s, nil
+}
+
+type Subscription struct {
+	ID                 uuid.UUID
+	TenantID           uuid.UUID
+	Plan               tenant.PlanType
+	Interval           Interval
+	Status             Status
+	CurrentPeriodStart time.Time
+	CurrentPeriodEnd   time.Time
+	// PendingPlan replaces Plan when the period ends, it is set when the
+	// store moves to the free plan. Empty when nothing is scheduled.
+	PendingPlan tenant.PlanType
+	// Credit is what is left of plans paid for and given up early, it is
+	// taken off the next invoices.
+	Credit       money.Money
+	PastDueSince *time.Time
+	// SuspendedAt is when billing suspended the store. Paying only lifts a
+	// suspension billing put in place.
+	SuspendedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// IsLive reports whether the store is subscribed to its plan, paid up or
+// not.
+func (s Subscription) IsLive() bool {
+	return s.Status == StatusActive || s.Status == StatusPastDue || s.Status == StatusUnpaid
+}
+
+type InvoiceKind string
+
+const (
+	// KindSubscription is the first period of a new subscription.
+	KindSubscription InvoiceKind = "subscription"
+	// KindProration is the difference owed for a plan change mid-period.
+	KindProration InvoiceKind = "proration"
+	KindRenewal   InvoiceKind = "renewal"
+)
+
+type InvoiceStatus string
+
+const (
+	InvoiceOpen InvoiceStatus = "open"
+	InvoicePaid InvoiceStatus = "paid"
+	// InvoiceVoid is a plan change whose payment was declined, nothing is
+	// owed on it.
+	InvoiceVoid InvoiceStatus = "void"
+)
+
+type Invoice struct {
+	ID             uuid.UUID
+	TenantID       uuid.UUID
+	SubscriptionID uuid.UUID
+	Kind           InvoiceKind
+	Status         InvoiceStatus
+	Plan           tenant.PlanType
+	Interval       Interval
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	// Amount is what is charged, CreditApplied was taken off it already.
+	Amount        money.Money
+	CreditApplied money.Money
+	Attempts      int
+	NextAttemptAt *time.Time
+	LastError     string
+	ProviderRef   string
+	PaidAt        *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Quote is what moving a subscription to a plan and interval costs.
+type Quote struct {
+	Plan        tenant.PlanType
+	Interval    Interval
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	// Amount is charged now, CreditApplied of the subscription's credit was
+	// taken off it.
+	Amount        money.Money
+	CreditApplied money.Money
+	// Credit is the subscription's credit afterwards.
+	Credit money.Money
+}
+
+// quote prices the move of sub to plan and interval at now. A subscription
+// that is not live starts a new period, charged in full. Otherwise what is
+// left of the current period is credited at the old price and charged at
+// the new one, unless the interval changes, which starts a new period. A
+// move that comes out cheaper adds to the credit, nothing is paid back.
+func quote(sub *Subscription, plan tenant.PlanType, interval Interval, now time.Time) (Quote, error) {
+	newPrice, err := PlanPrice(plan, interval)
+	if err != nil {
+		return Quote{}, err
+	}
+	q := Quote{Plan: plan, Interval: interval}
+	credit := decimal.Zero
+	if sub != nil {
+		credit = sub.Credit.Amount
+	}
+
+	if sub == nil || !sub.IsLive() || interval != sub.Interval {
+		q.PeriodStart, q.PeriodEnd = now, interval.periodEnd(now)
+		net := newPrice.Amount
+		if sub != nil && sub.IsLive() {
+			oldPrice, err := PlanPrice(sub.Plan, sub.Interval)
+			if err != nil {
+				return Quote{}, err
+			}
+			net = net.Sub(oldPrice.Amount.Mul(remainingShare(sub, now)))
+		}
+		q.settle(net, credit)
+		return q, nil
+	}
+
+	oldPrice, err := PlanPrice(sub.Plan, sub.Interval)
+	if err != nil {
+		return Quote{}, err
+	}
+	q.PeriodStart, q.PeriodEnd = sub.CurrentPeriodStart, sub.CurrentPeriodEnd
+	share := remainingShare(sub, now)
+	q.settle(newPrice.Amount.Sub(oldPrice.Amount).Mul(share), credit)
+	return q, nil
+}
+
+// renewalQuote prices the period following sub's current one.
+func renewalQuote(sub *Subscription) (Quote, error) {
+	price, err := PlanPrice(sub.Plan, sub.Interval)
+	if err != nil {
+		return Quote{}, err
+	}
+	q := Quote{
+		Plan:        sub.Plan,
+		Interval:    sub.Interval,
+		PeriodStart: sub.CurrentPeriodEnd,
+		PeriodEnd:   sub.Interval.periodEnd(sub.CurrentPeriodEnd),
+	}
+	q.settle(price.Amount, sub.Credit.Amount)
+	return q, nil
+}
+
+// remainingShare is the part of sub's current period still to come.
+func remainingShare(sub *Subscription, now time.Time) decimal.Decimal {
+	total := sub.CurrentPeriodEnd.Sub(sub.CurrentPeriodStart)
+	left := sub.CurrentPeriodEnd.Sub(now)
+	switch {
+	case total <= 0 || left <= 0:
+		return decimal.Zero
+	case left >= total:
+		return decimal.NewFromInt(1)
+	}
+	return decimal.NewFromInt(int64(left)).Div(decimal.NewFromInt(int64(total)))
+}
+
+// settle sets what is charged for net with credit available. A negative
+// net adds to the credit, a positive one uses the credit up first.
+func (q *Quote) settle(net, credit decimal.Decimal) {
+	net = net.Round(2)
+	if net.IsNegative() {
+		credit = credit.Add(net.Neg())
+		net = decimal.Zero
+	}
+	applied := decimal.Min(net, credit)
+	q.Amount = money.New(net.Sub(applied), Currency)
+	q.CreditApplied = money.New(applied, Currency)
+	q.Credit = money.New(credit.Sub(applied), Currency)
+}
+
+// newInvoice bills q to sub. One with nothing to charge is paid at once.
+func newInvoice(sub *Subscription, kind InvoiceKind, q Quote, now time.Time) *Invoice {
+	inv := &Invoice{
+		ID:             uuid.New(),
+		TenantID:       sub.TenantID,
+		SubscriptionID: sub.ID,
+		Kind:           kind,
+		Status:         InvoiceOpen,
+		Plan:           q.Plan,
+		Interval:       q.Interval,
+		PeriodStart:    q.PeriodStart,
+		PeriodEnd:      q.PeriodEnd,
+		Amount:         q.Amount,
+		CreditApplied:  q.CreditApplied,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if !q.Amount.Amount.IsPositive() {
+		inv.Status = InvoicePaid
+		inv.PaidAt = &now
+	}
+	return inv
+}
+
+// RunReport is what one billing run did.
+type RunReport struct {
+	Renewed    int
+	Canceled   int
+	Collected  int
+	Failed     int
+	Suspended  int
+	Reinstated int
+}
@@ -0,0 +1,404 @@
+package billingdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/shopspring/decimal"
+)
+
+type billingStore struct {
+	conn database.DBTX
+}
+
+var _ billing.Repository = (*billingStore)(nil)
+
+func NewBillingStore(conn *pgxpool.Pool) *billingStore {
+	return &billingStore{conn: conn}
+}
+
+const subscriptionColumns = `id, tenant_id, plan, billing_interval, status, current_period_start, current_period_end,
+		       COALESCE(pending_plan::text, ''), credit_amount, currency, past_due_since, suspended_at, created_at, updated_at`
+
+func scanSubscription(row pgx.Row) (billing.Subscription, error) {
+	var (
+		s                               billing.Subscription
+		plan, interval, status, pending string
+		credit                          decimal.Decimal
+		currency                        string
+	)
+	err := row.Scan(
+		&s.ID,
+		&s.TenantID,
+		&plan,
+		&interval,
+		&status,
+		&s.CurrentPeriodStart,
+		&s.CurrentPeriodEnd,
+		&pending,
+		&credit,
+		&currency,
+		&s.PastDueSince,
+		&s.SuspendedAt,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return s, err
+	}
+	s.Plan = tenant.PlanType(plan)
+	s.Interval = billing.Interval(interval)
+	s.Status = billing.Status(status)
+	s.PendingPlan = tenant.PlanType(pending)
+	s.Credit = money.New(credit, money.Currency(currency))
+	return s, nil
+}
+
+func (bs *billingStore) CreateSubscription(ctx context.Context, s *billing.Subscription) error {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		INSERT INTO subscriptions (id, tenant_id, plan, billing_interval, status, current_period_start,
+		                           current_period_end, pending_plan, credit_amount, currency, past_due_since,
+		                           suspended_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, '')::plan_type, $9, $10, $11, $12, $13, $14)
+	`
+	_, err := conn.Exec(ctx, query,
+		s.ID,
+		s.TenantID,
+		s.Plan,
+		s.Interval,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.PendingPlan,
+		s.Credit.Amount,
+		s.Credit.Currency,
+		s.PastDueSince,
+		s.SuspendedAt,
+		s.CreatedAt,
+		s.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (bs *billingStore) GetSubscription(ctx context.Context, tenantID uuid.UUID) (*billing.Subscription, error) {
+	return bs.getSubscription(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE tenant_id = $1`, tenantID)
+}
+
+func (bs *billingStore) LockSubscription(ctx context.Context, tenantID uuid.UUID) (*billing.Subscription, error) {
+	return bs.getSubscription(ctx, `SELECT `+subscriptionColumns+` FROM subscriptions WHERE tenant_id = $1 FOR UPDATE`, tenantID)
+}
+
+func (bs *billingStore) getSubscription(ctx context.Context, query string, tenantID uuid.UUID) (*billing.Subscription, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	s, err := scanSubscription(conn.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, billing.ErrSubscriptionNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return &s, nil
+}
+
+func (bs *billingStore) UpdateSubscription(ctx context.Context, s *billing.Subscription) error {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		UPDATE subscriptions
+		SET plan = $2, billing_interval = $3, status = $4, current_period_start = $5, current_period_end = $6,
+		    pending_plan = NULLIF($7, '')::plan_type, credit_amount = $8, currency = $9, past_due_since = $10,
+		    suspended_at = $11, updated_at = $12
+		WHERE id = $1
+	`
+	res, err := conn.Exec(ctx, query,
+		s.ID,
+		s.Plan,
+		s.Interval,
+		s.Status,
+		s.CurrentPeriodStart,
+		s.CurrentPeriodEnd,
+		s.PendingPlan,
+		s.Credit.Amount,
+		s.Credit.Currency,
+		s.PastDueSince,
+		s.SuspendedAt,
+		s.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return billing.ErrSubscriptionNotFound
+	}
+	return nil
+}
+
+func (bs *billingStore) listSubscriptions(ctx context.Context, query string, args ...any) ([]billing.Subscription, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	subs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (billing.Subscription, error) {
+		return scanSubscription(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return subs, nil
+}
+
+func (bs *billingStore) ListDueRenewals(ctx context.Context, now time.Time, limit int) ([]billing.Subscription, error) {
+	const query = `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE status = 'active' AND current_period_end <= $1
+		ORDER BY current_period_end
+		LIMIT $2
+	`
+	return bs.listSubscriptions(ctx, query, now, limit)
+}
+
+func (bs *billingStore) ListGraceEnded(ctx context.Context, pastDueBefore time.Time, limit int) ([]billing.Subscription, error) {
+	const query = `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE status = 'past_due' AND past_due_since < $1
+		ORDER BY past_due_since
+		LIMIT $2
+	`
+	return bs.listSubscriptions(ctx, query, pastDueBefore, limit)
+}
+
+func (bs *billingStore) ListPaidSuspended(ctx context.Context, limit int) ([]billing.Subscription, error) {
+	const query = `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE status = 'active' AND suspended_at IS NOT NULL
+		ORDER BY suspended_at
+		LIMIT $1
+	`
+	return bs.listSubscriptions(ctx, query, limit)
+}
+
+const invoiceColumns = `id, tenant_id, subscription_id, kind, status, plan, billing_interval, period_start, period_end,
+		       amount, credit_applied, currency, attempts, next_attempt_at, COALESCE(last_error, ''),
+		       COALESCE(provider_ref, ''), paid_at, created_at, updated_at`
+
+func scanInvoice(row pgx.Row) (billing.Invoice, error) {
+	var (
+		inv                                billing.Invoice
+		kind, status, plan, interval, curr string
+		amount, credit                     decimal.Decimal
+	)
+	err := row.Scan(
+		&inv.ID,
+		&inv.TenantID,
+		&inv.SubscriptionID,
+		&kind,
+		&status,
+		&plan,
+		&interval,
+		&inv.PeriodStart,
+		&inv.PeriodEnd,
+		&amount,
+		&credit,
+		&curr,
+		&inv.Attempts,
+		&inv.NextAttemptAt,
+		&inv.LastError,
+		&inv.ProviderRef,
+		&inv.PaidAt,
+		&inv.CreatedAt,
+		&inv.UpdatedAt,
+	)
+	if err != nil {
+		return inv, err
+	}
+	inv.Kind = billing.InvoiceKind(kind)
+	inv.Status = billing.InvoiceStatus(status)
+	inv.Plan = tenant.PlanType(plan)
+	inv.Interval = billing.Interval(interval)
+	inv.Amount = money.New(amount, money.Currency(curr))
+	inv.CreditApplied = money.New(credit, money.Currency(curr))
+	return inv, nil
+}
+
+func (bs *billingStore) CreateInvoice(ctx context.Context, inv *billing.Invoice) error {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		INSERT INTO invoices (id, tenant_id, subscription_id, kind, status, plan, billing_interval, period_start,
+		                      period_end, amount, credit_applied, currency, attempts, next_attempt_at, last_error,
+		                      provider_ref, paid_at, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, NULLIF($15, ''), NULLIF($16, ''), $17, $18, $19)
+	`
+	_, err := conn.Exec(ctx, query,
+		inv.ID,
+		inv.TenantID,
+		inv.SubscriptionID,
+		inv.Kind,
+		inv.Status,
+		inv.Plan,
+		inv.Interval,
+		inv.PeriodStart,
+		inv.PeriodEnd,
+		inv.Amount.Amount,
+		inv.CreditApplied.Amount,
+		inv.Amount.Currency,
+		inv.Attempts,
+		inv.NextAttemptAt,
+		inv.LastError,
+		inv.ProviderRef,
+		inv.PaidAt,
+		inv.CreatedAt,
+		inv.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "invoices_pending_change_uq" {
+			return billing.ErrChangePending
+		}
+		return fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (bs *billingStore) GetInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*billing.Invoice, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1 AND tenant_id = $2`
+	inv, err := scanInvoice(conn.QueryRow(ctx, query, invoiceID, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, billing.ErrInvoiceNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return &inv, nil
+}
+
+// UpdateInvoice only touches open invoices, a paid or void one is final. It
+// reports ErrInvoiceClosed for those.
+func (bs *billingStore) UpdateInvoice(ctx context.Context, inv *billing.Invoice) error {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		UPDATE invoices
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_error = NULLIF($5, ''),
+		    provider_ref = NULLIF($6, ''), paid_at = $7, updated_at = $8
+		WHERE id = $1 AND status = 'open'
+	`
+	res, err := conn.Exec(ctx, query,
+		inv.ID,
+		inv.Status,
+		inv.Attempts,
+		inv.NextAttemptAt,
+		inv.LastError,
+		inv.ProviderRef,
+		inv.PaidAt,
+		inv.UpdatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return billing.ErrInvoiceClosed
+	}
+	return nil
+}
+
+func (bs *billingStore) ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]billing.Invoice, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `SELECT ` + invoiceColumns + ` FROM invoices WHERE tenant_id = $1 ORDER BY created_at DESC`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	invoices, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (billing.Invoice, error) {
+		return scanInvoice(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return invoices, nil
+}
+
+func (bs *billingStore) HasOpenRenewals(ctx context.Context, subscriptionID, except uuid.UUID) (bool, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		SELECT EXISTS (
+			SELECT 1 FROM invoices
+			WHERE subscription_id = $1 AND id <> $2 AND kind = 'renewal' AND status = 'open'
+		)
+	`
+	var open bool
+	if err := conn.QueryRow(ctx, query, subscriptionID, except).Scan(&open); err != nil {
+		return false, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return open, nil
+}
+
+func (bs *billingStore) ClaimDueInvoices(ctx context.Context, limit int, lease time.Duration) ([]billing.Invoice, error) {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		UPDATE invoices
+		SET next_attempt_at = now() + $2::interval
+		WHERE id IN (
+			SELECT id FROM invoices
+			WHERE status = 'open' AND next_attempt_at <= now()
+			ORDER BY next_attempt_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + invoiceColumns
+	rows, err := conn.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	invoices, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (billing.Invoice, error) {
+		return scanInvoice(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	return invoices, nil
+}
+
+func (bs *billingStore) SetTenantPlan(ctx context.Context, tenantID uuid.UUID, plan tenant.PlanType) error {
+	conn := database.GetTXFromContext(ctx, bs.conn)
+
+	const query = `
+		UPDATE tenants
+		SET plan = $2, trial_end_at = NULL, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, tenantID, plan)
+	if err != nil {
+		return fmt.Errorf("%w: %w", billing.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrTenantNotFound
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Package billingfake is an in-memory billing.Provider for tests and local
+// runs. It takes every payment unless told to decline a store's.
+package billingfake
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+)
+
+type Provider struct {
+	mu       sync.Mutex
+	charges  map[uuid.UUID]billing.Charge
+	refs     map[uuid.UUID]string
+	declines map[uuid.UUID]string
+	down     error
+}
+
+var _ billing.Provider = (*Provider)(nil)
+
+func NewProvider() *Provider {
+	return &Provider{
+		charges:  make(map[uuid.UUID]billing.Charge),
+		refs:     make(map[uuid.UUID]string),
+		declines: make(map[uuid.UUID]string),
+	}
+}
+
+// Decline refuses the store's payments with reason until Accept.
+func (p *Provider) Decline(tenantID uuid.UUID, reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.declines[tenantID] = reason
+}
+
+func (p *Provider) Accept(tenantID uuid.UUID) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.declines, tenantID)
+}
+
+// Down makes every charge fail with err, as an unreachable provider would.
+// A nil err brings it back.
+func (p *Provider) Down(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.down = err
+}
+
+func (p *Provider) Charge(_ context.Context, c billing.Charge) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.down != nil {
+		return "", p.down
+	}
+	if ref, ok := p.refs[c.InvoiceID]; ok {
+		return ref, nil
+	}
+	if reason, ok := p.declines[c.TenantID]; ok {
+		return "", fmt.Errorf("%w: %s", billing.ErrPaymentDeclined, reason)
+	}
+	ref := "fake_" + uuid.NewString()
+	p.charges[c.InvoiceID] = c
+	p.refs[c.InvoiceID] = ref
+	return ref, nil
+}
+
+// Charges returns the payments taken, by invoice.
+func (p *Provider) Charges() map[uuid.UUID]billing.Charge {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make(map[uuid.UUID]billing.Charge, len(p.charges))
+	for id, c := range p.charges {
+		out[id] = c
+	}
+	return out
+}
@@ -0,0 +1,596 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"github.com/shopspring/decimal"
+)
+
+var ErrProviderUnavailable = errors.New("payment could not be taken, try again later")
+
+const (
+	// paymentLease is how long an invoice being charged is left to whoever
+	// charges it before the worker collects it itself.
+	paymentLease = time.Hour
+	// providerRetry is how soon a charge the provider could not be reached
+	// for is tried again.
+	providerRetry = time.Hour
+)
+
+// overdueReason marks the suspensions billing imposes, the only ones it
+// lifts when the arrears are paid.
+const overdueReason = "subscription payment overdue"
+
+// retrySchedule is how long after each declined renewal it is charged
+// again. Past the last retry the owner pays from the dashboard.
+var retrySchedule = []time.Duration{24 * time.Hour, 3 * 24 * time.Hour, 5 * 24 * time.Hour}
+
+type BillingBusiness struct {
+	storer   Repository
+	trx      database.TransactorTX
+	provider Provider
+	stores   StoreStatus
+	auditor  audit.Recorder
+	config   *config.Config
+}
+
+type BillingBusinessCfg func(bb *BillingBusiness) error
+
+func NewBillingBusiness(cfgs ...BillingBusinessCfg) (*BillingBusiness, error) {
+	bb := &BillingBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(bb); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case bb.storer == nil:
+		return nil, errors.New("billing repository is required")
+	case bb.trx == nil:
+		return nil, errors.New("transaction manager is required")
+	case bb.stores == nil:
+		return nil, errors.New("store status is required")
+	case bb.config == nil:
+		return nil, errors.New("config is required")
+	}
+	return bb, nil
+}
+
+func WithBillingRepository(st Repository) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.storer = st
+		return nil
+	}
+}
+
+func WithTransactor(trx database.TransactorTX) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.trx = trx
+		return nil
+	}
+}
+
+// WithProvider sets who collects payments. Without one nothing is charged:
+// moves to a paid plan are refused and renewals wait.
+func WithProvider(p Provider) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.provider = p
+		return nil
+	}
+}
+
+// WithStoreStatus is how overdue stores are suspended and reinstated.
+func WithStoreStatus(s StoreStatus) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.stores = s
+		return nil
+	}
+}
+
+func WithAuditor(auditor audit.Recorder) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.auditor = auditor
+		return nil
+	}
+}
+
+func WithConfigs(cfg *config.Config) BillingBusinessCfg {
+	return func(bb *BillingBusiness) error {
+		bb.config = cfg
+		return nil
+	}
+}
+
+// recordAudit writes e within the transaction on ctx. Without an auditor
+// configured nothing is recorded.
+func (bb *BillingBusiness) recordAudit(ctx context.Context, e audit.Entry) error {
+	if bb.auditor == nil {
+		return nil
+	}
+	return bb.auditor.Record(ctx, e)
+}
+
+func (bb *BillingBusiness) GetSubscription(ctx context.Context, tenantID uuid.UUID) (*Subscription, error) {
+	sub, err := bb.storer.GetSubscription(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrSubscriptionNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("getsubscription: %w", err)
+	}
+	return sub, nil
+}
+
+func (bb *BillingBusiness) ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]Invoice, error) {
+	invoices, err := bb.storer.ListInvoices(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listinvoices: %w", err)
+	}
+	return invoices, nil
+}
+
+// PlanRequest asks for the store to be moved to Plan, billed every
+// Interval.
+type PlanRequest struct {
+	Plan     tenant.PlanType
+	Interval Interval
+	ActorID  uuid.UUID
+}
+
+// ChangePlan moves the store to another plan or interval. A move that costs
+// more is charged at once, prorated over what is left of the period, and
+// applied once paid. One that costs less is applied at once and leaves
+// credit for the next invoices. A move to the free plan waits for the end
+// of the period paid for. The invoice is nil when nothing was billed.
+func (bb *BillingBusiness) ChangePlan(ctx context.Context, tenantID uuid.UUID, req PlanRequest) (*Subscription, *Invoice, error) {
+	if _, ok := catalog[req.Plan]; !ok {
+		return nil, nil, errs.NewDomainError(errs.InvalidArgument, fmt.Errorf("%w: %s", ErrUnknownPlan, req.Plan))
+	}
+	if _, ok := intervals[string(req.Interval)]; !ok {
+		return nil, nil, errs.NewDomainError(errs.InvalidArgument, fmt.Errorf("invalid billing interval: %v", req.Interval))
+	}
+
+	var (
+		sub *Subscription
+		inv *Invoice
+	)
+	err := bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := bb.storer.LockSubscription(ctx, tenantID)
+		if err != nil && !errors.Is(err, ErrSubscriptionNotFound) {
+			return err
+		}
+		sub, inv, err = bb.planChange(ctx, tenantID, cur, req, time.Now())
+		return err
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOverdue), errors.Is(err, ErrSamePlan), errors.Is(err, ErrNotSubscribed):
+			return nil, nil, errs.NewDomainError(errs.FailedPrecondition, err)
+		case errors.Is(err, ErrChangePending):
+			return nil, nil, errs.NewDomainError(errs.Aborted, err)
+		case errors.Is(err, ErrNoProvider):
+			return nil, nil, errs.NewDomainError(errs.Unavailable, err)
+		}
+		return nil, nil, fmt.Errorf("changeplan-trx: %w", err)
+	}
+	if inv == nil || inv.Status != InvoiceOpen {
+		return sub, inv, nil
+	}
+
+	if err := bb.collect(ctx, inv); err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentDeclined):
+			return nil, nil, errs.NewDomainError(errs.FailedPrecondition, err)
+		case errors.Is(err, ErrProviderUnavailable):
+			return nil, nil, errs.NewDomainError(errs.Unavailable, err)
+		}
+		return nil, nil, fmt.Errorf("collect: %w", err)
+	}
+	sub, err = bb.storer.GetSubscription(ctx, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getsubscription: %w", err)
+	}
+	return sub, inv, nil
+}
+
+// planChange records the move asked for in req within the transaction
+// holding cur, the store's subscription if it has one.
+func (bb *BillingBusiness) planChange(ctx context.Context, tenantID uuid.UUID, cur *Subscription, req PlanRequest, now time.Time) (*Subscription, *Invoice, error) {
+	live := cur != nil && cur.IsLive()
+	switch {
+	case live && cur.Status != StatusActive:
+		return nil, nil, ErrOverdue
+	case !live && req.Plan == tenant.FreePlan:
+		return nil, nil, ErrNotSubscribed
+	case live && req.Plan == tenant.FreePlan:
+		return bb.scheduleFree(ctx, cur, req.ActorID, now)
+	case live && req.Plan == cur.Plan && req.Interval == cur.Interval:
+		if cur.PendingPlan == "" {
+			return nil, nil, ErrSamePlan
+		}
+		// staying on the plan calls off the move to free
+		cur.PendingPlan = ""
+		cur.UpdatedAt = now
+		if err := bb.storer.UpdateSubscription(ctx, cur); err != nil {
+			return nil, nil, err
+		}
+		return cur, nil, bb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			ActorID:    &req.ActorID,
+			Action:     "billing.plan_change",
+			TargetType: "subscription",
+			TargetID:   cur.ID.String(),
+			Before:     map[string]any{"pending_plan": tenant.FreePlan},
+			After:      map[string]any{"plan": cur.Plan, "interval": cur.Interval},
+		})
+	}
+
+	q, err := quote(cur, req.Plan, req.Interval, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if q.Amount.Amount.IsPositive() && bb.provider == nil {
+		return nil, nil, ErrNoProvider
+	}
+
+	sub := cur
+	if sub == nil {
+		sub = &Subscription{
+			ID:        uuid.New(),
+			TenantID:  tenantID,
+			Credit:    money.New(decimal.Zero, Currency),
+			CreatedAt: now,
+		}
+	}
+	before := map[string]any{"plan": sub.Plan, "interval": sub.Interval, "status": sub.Status}
+
+	kind := KindProration
+	if !live {
+		kind = KindSubscription
+		sub.Status = StatusIncomplete
+		sub.Plan, sub.Interval = q.Plan, q.Interval
+		sub.CurrentPeriodStart, sub.CurrentPeriodEnd = q.PeriodStart, q.PeriodEnd
+		sub.PastDueSince, sub.SuspendedAt = nil, nil
+	}
+	sub.PendingPlan = ""
+	sub.UpdatedAt = now
+
+	inv := newInvoice(sub, kind, q, now)
+	if inv.Status == InvoicePaid {
+		applyChange(sub, inv)
+		sub.Credit = q.Credit
+	} else {
+		// collected right after, the worker takes over if that never ends
+		lease := now.Add(paymentLease)
+		inv.NextAttemptAt = &lease
+	}
+
+	if cur == nil {
+		err = bb.storer.CreateSubscription(ctx, sub)
+	} else {
+		err = bb.storer.UpdateSubscription(ctx, sub)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := bb.storer.CreateInvoice(ctx, inv); err != nil {
+		return nil, nil, err
+	}
+	if inv.Status == InvoicePaid {
+		if err := bb.storer.SetTenantPlan(ctx, tenantID, sub.Plan); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return sub, inv, bb.recordAudit(ctx, audit.Entry{
+		TenantID:   &tenantID,
+		ActorID:    &req.ActorID,
+		Action:     "billing.plan_change",
+		TargetType: "subscription",
+		TargetID:   sub.ID.String(),
+		Before:     before,
+		After: map[string]any{
+			"plan":     req.Plan,
+			"interval": req.Interval,
+			"invoice":  inv.ID,
+			"amount":   inv.Amount.String(),
+			"credit":   inv.CreditApplied.String(),
+		},
+	})
+}
+
+// scheduleFree moves the store to the free plan when its period ends.
+func (bb *BillingBusiness) scheduleFree(ctx context.Context, sub *Subscription, actorID uuid.UUID, now time.Time) (*Subscription, *Invoice, error) {
+	if sub.PendingPlan == tenant.FreePlan {
+		return sub, nil, nil
+	}
+	sub.PendingPlan = tenant.FreePlan
+	sub.UpdatedAt = now
+	if err := bb.storer.UpdateSubscription(ctx, sub); err != nil {
+		return nil, nil, err
+	}
+	return sub, nil, bb.recordAudit(ctx, audit.Entry{
+		TenantID:   &sub.TenantID,
+		ActorID:    &actorID,
+		Action:     "billing.plan_change",
+		TargetType: "subscription",
+		TargetID:   sub.ID.String(),
+		Before:     map[string]any{"plan": sub.Plan, "interval": sub.Interval},
+		After:      map[string]any{"pending_plan": tenant.FreePlan, "at": sub.CurrentPeriodEnd},
+	})
+}
+
+// applyChange puts sub on the plan and period inv paid for.
+func applyChange(sub *Subscription, inv *Invoice) {
+	sub.Plan, sub.Interval = inv.Plan, inv.Interval
+	sub.CurrentPeriodStart, sub.CurrentPeriodEnd = inv.PeriodStart, inv.PeriodEnd
+	sub.Status = StatusActive
+	sub.PendingPlan = ""
+}
+
+// PayInvoice charges an open invoice now, the way an owner settles a
+// renewal that failed. Once nothing is overdue the subscription is active
+// again and a store billing suspended is reinstated.
+func (bb *BillingBusiness) PayInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*Invoice, error) {
+	inv, err := bb.storer.GetInvoice(ctx, tenantID, invoiceID)
+	if err != nil {
+		if errors.Is(err, ErrInvoiceNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("getinvoice: %w", err)
+	}
+	if inv.Status != InvoiceOpen {
+		return nil, errs.NewDomainError(errs.FailedPrecondition, ErrInvoiceClosed)
+	}
+	if bb.provider == nil {
+		return nil, errs.NewDomainError(errs.Unavailable, ErrNoProvider)
+	}
+
+	if err := bb.collect(ctx, inv); err != nil {
+		switch {
+		case errors.Is(err, ErrPaymentDeclined):
+			return nil, errs.NewDomainError(errs.FailedPrecondition, err)
+		case errors.Is(err, ErrProviderUnavailable):
+			return nil, errs.NewDomainError(errs.Unavailable, err)
+		}
+		return nil, fmt.Errorf("collect: %w", err)
+	}
+	return inv, nil
+}
+
+// collect charges inv and records how that went. It returns the charge's
+// error, ErrPaymentDeclined or ErrProviderUnavailable, when it failed.
+func (bb *BillingBusiness) collect(ctx context.Context, inv *Invoice) error {
+	if bb.provider == nil {
+		return bb.chargeFailed(ctx, inv, fmt.Errorf("%w: %w", ErrProviderUnavailable, ErrNoProvider))
+	}
+	ref, err := bb.provider.Charge(ctx, Charge{
+		InvoiceID: inv.ID,
+		TenantID:  inv.TenantID,
+		Amount:    inv.Amount,
+		Description: fmt.Sprintf("%s plan, %s, %s to %s", inv.Plan, inv.Interval,
+			inv.PeriodStart.Format(time.DateOnly), inv.PeriodEnd.Format(time.DateOnly)),
+	})
+	if err != nil {
+		if !errors.Is(err, ErrPaymentDeclined) {
+			err = fmt.Errorf("%w: %w", ErrProviderUnavailable, err)
+		}
+		return bb.chargeFailed(ctx, inv, err)
+	}
+	return bb.invoicePaid(ctx, inv, ref)
+}
+
+// chargeFailed records cause against inv and returns it. A plan change
+// that was declined is voided. A declined renewal is retried on schedule
+// and puts the subscription past due, which starts its grace period.
+func (bb *BillingBusiness) chargeFailed(ctx context.Context, inv *Invoice, cause error) error {
+	now := time.Now()
+	inv.LastError = cause.Error()
+	inv.UpdatedAt = now
+
+	err := bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		switch {
+		case !errors.Is(cause, ErrPaymentDeclined):
+			next := now.Add(providerRetry)
+			inv.NextAttemptAt = &next
+			return bb.storer.UpdateInvoice(ctx, inv)
+		case inv.Kind != KindRenewal:
+			inv.Attempts++
+			inv.Status = InvoiceVoid
+			inv.NextAttemptAt = nil
+			return bb.storer.UpdateInvoice(ctx, inv)
+		}
+
+		inv.Attempts++
+		inv.NextAttemptAt = nextRetry(inv.Attempts, now)
+		if err := bb.storer.UpdateInvoice(ctx, inv); err != nil {
+			return err
+		}
+		sub, err := bb.storer.LockSubscription(ctx, inv.TenantID)
+		if err != nil {
+			return err
+		}
+		if sub.Status != StatusActive {
+			return nil
+		}
+		sub.Status = StatusPastDue
+		sub.PastDueSince = &now
+		sub.UpdatedAt = now
+		if err := bb.storer.UpdateSubscription(ctx, sub); err != nil {
+			return err
+		}
+		return bb.recordAudit(ctx, audit.Entry{
+			TenantID:   &inv.TenantID,
+			Action:     "billing.past_due",
+			TargetType: "invoice",
+			TargetID:   inv.ID.String(),
+			After:      map[string]any{"amount": inv.Amount.String(), "attempts": inv.Attempts, "error": inv.LastError},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvoiceClosed) {
+			// settled meanwhile by another attempt
+			return cause
+		}
+		return errors.Join(cause, fmt.Errorf("chargefailed-trx: %w", err))
+	}
+	return cause
+}
+
+// nextRetry is when a renewal declined attempts times is charged again,
+// nil once the schedule is used up.
+func nextRetry(attempts int, now time.Time) *time.Time {
+	if attempts < 1 || attempts > len(retrySchedule) {
+		return nil
+	}
+	next := now.Add(retrySchedule[attempts-1])
+	return &next
+}
+
+// invoicePaid records inv paid. A plan change takes effect, a renewal
+// clears the subscription's arrears once it was the last open one.
+func (bb *BillingBusiness) invoicePaid(ctx context.Context, inv *Invoice, ref string) error {
+	now := time.Now()
+	var sub *Subscription
+	err := bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if sub, err = bb.storer.LockSubscription(ctx, inv.TenantID); err != nil {
+			return err
+		}
+
+		inv.Status = InvoicePaid
+		inv.ProviderRef = ref
+		inv.PaidAt = &now
+		inv.NextAttemptAt = nil
+		inv.LastError = ""
+		inv.UpdatedAt = now
+		if err := bb.storer.UpdateInvoice(ctx, inv); err != nil {
+			return err
+		}
+
+		if inv.Kind == KindRenewal {
+			if sub.Status == StatusPastDue || sub.Status == StatusUnpaid {
+				open, err := bb.storer.HasOpenRenewals(ctx, sub.ID, inv.ID)
+				if err != nil {
+					return err
+				}
+				if !open {
+					sub.Status = StatusActive
+					sub.PastDueSince = nil
+				}
+			}
+		} else {
+			applyChange(sub, inv)
+			sub.Credit = money.New(decimal.Max(sub.Credit.Amount.Sub(inv.CreditApplied.Amount), decimal.Zero), Currency)
+			if err := bb.storer.SetTenantPlan(ctx, sub.TenantID, sub.Plan); err != nil {
+				return err
+			}
+		}
+		sub.UpdatedAt = now
+		if err := bb.storer.UpdateSubscription(ctx, sub); err != nil {
+			return err
+		}
+		return bb.recordAudit(ctx, audit.Entry{
+			TenantID:   &inv.TenantID,
+			Action:     "billing.invoice_paid",
+			TargetType: "invoice",
+			TargetID:   inv.ID.String(),
+			After:      map[string]any{"kind": inv.Kind, "amount": inv.Amount.String(), "provider_ref": ref},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrInvoiceClosed) {
+			return nil
+		}
+		return fmt.Errorf("invoicepaid-trx: %w", err)
+	}
+
+	switch {
+	case sub.Status != StatusActive:
+	case sub.SuspendedAt != nil:
+		// one that fails is lifted by the next billing run
+		_ = bb.liftSuspension(ctx, sub.TenantID, *sub.SuspendedAt)
+	case inv.Kind != KindRenewal && sub.Plan != tenant.FreePlan:
+		// the owner retries reinstating by paying again, or asks support
+		_ = bb.reinstatePaid(ctx, sub.TenantID)
+	}
+	return nil
+}
+
+// liftSuspension reinstates the store billing suspended at suspendedAt,
+// now paid up. A suspension imposed since, by the platform say, stays.
+func (bb *BillingBusiness) liftSuspension(ctx context.Context, tenantID uuid.UUID, suspendedAt time.Time) error {
+	cur, err := bb.currentSuspension(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	// a store that left billing's suspension some other way has nothing
+	// left for billing to lift. The slack covers the database rounding
+	// suspendedAt to microseconds.
+	if cur != nil && cur.Trigger == tenant.TriggerSystem && cur.Reason == overdueReason &&
+		!cur.CreatedAt.Before(suspendedAt.Add(-time.Millisecond)) {
+		if err := bb.reinstate(ctx, tenantID, "overdue invoices paid"); err != nil {
+			return err
+		}
+	}
+
+	err = bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		sub, err := bb.storer.LockSubscription(ctx, tenantID)
+		if err != nil {
+			return err
+		}
+		sub.SuspendedAt = nil
+		sub.UpdatedAt = time.Now()
+		return bb.storer.UpdateSubscription(ctx, sub)
+	})
+	if err != nil {
+		return fmt.Errorf("liftsuspension-trx: %w", err)
+	}
+	return nil
+}
+
+// reinstatePaid reinstates a store suspended for not paying, its trial
+// over or its subscription overdue, once a paid plan is paid for.
+func (bb *BillingBusiness) reinstatePaid(ctx context.Context, tenantID uuid.UUID) error {
+	cur, err := bb.currentSuspension(ctx, tenantID)
+	if err != nil || cur == nil || cur.Trigger != tenant.TriggerSystem {
+		return err
+	}
+	if cur.Reason != tenant.TrialEndedReason && cur.Reason != overdueReason {
+		return nil
+	}
+	return bb.reinstate(ctx, tenantID, "paid plan started")
+}
+
+// currentSuspension returns the status change that suspended the store,
+// nil when the store is not suspended.
+func (bb *BillingBusiness) currentSuspension(ctx context.Context, tenantID uuid.UUID) (*tenant.StatusChange, error) {
+	history, err := bb.stores.StatusHistory(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("statushistory: %w", err)
+	}
+	if len(history) == 0 || history[0].To != tenant.TenantStatusSuspended {
+		return nil, nil
+	}
+	return &history[0], nil
+}
+
+func (bb *BillingBusiness) reinstate(ctx context.Context, tenantID uuid.UUID, reason string) error {
+	if _, err := bb.stores.Reinstate(ctx, tenantID, tenant.TriggerSystem, nil, reason); err != nil {
+		// a domain error means the store left the suspension meanwhile
+		if _, ok := errs.IsDomainError(err); !ok {
+			return fmt.Errorf("reinstate: %w", err)
+		}
+	}
+	return nil
+}
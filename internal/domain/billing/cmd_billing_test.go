@@ -0,0 +1,452 @@
+package billing_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/billing/billingfake"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"github.com/shopspring/decimal"
+)
+
+type fakeTrx struct{}
+
+func (fakeTrx) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+type fakeStore struct {
+	subs     map[uuid.UUID]*billing.Subscription
+	invoices map[uuid.UUID]*billing.Invoice
+	plans    map[uuid.UUID]tenant.PlanType
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		subs:     map[uuid.UUID]*billing.Subscription{},
+		invoices: map[uuid.UUID]*billing.Invoice{},
+		plans:    map[uuid.UUID]tenant.PlanType{},
+	}
+}
+
+func (f *fakeStore) CreateSubscription(_ context.Context, s *billing.Subscription) error {
+	cp := *s
+	f.subs[s.TenantID] = &cp
+	return nil
+}
+
+func (f *fakeStore) GetSubscription(_ context.Context, tenantID uuid.UUID) (*billing.Subscription, error) {
+	s, ok := f.subs[tenantID]
+	if !ok {
+		return nil, billing.ErrSubscriptionNotFound
+	}
+	cp := *s
+	return &cp, nil
+}
+
+func (f *fakeStore) LockSubscription(ctx context.Context, tenantID uuid.UUID) (*billing.Subscription, error) {
+	return f.GetSubscription(ctx, tenantID)
+}
+
+func (f *fakeStore) UpdateSubscription(_ context.Context, s *billing.Subscription) error {
+	cp := *s
+	f.subs[s.TenantID] = &cp
+	return nil
+}
+
+func (f *fakeStore) CreateInvoice(_ context.Context, inv *billing.Invoice) error {
+	for _, other := range f.invoices {
+		if other.SubscriptionID == inv.SubscriptionID && other.Status == billing.InvoiceOpen && other.Kind != billing.KindRenewal &&
+			inv.Kind != billing.KindRenewal && inv.Status == billing.InvoiceOpen {
+			return billing.ErrChangePending
+		}
+	}
+	cp := *inv
+	f.invoices[inv.ID] = &cp
+	return nil
+}
+
+func (f *fakeStore) GetInvoice(_ context.Context, tenantID, invoiceID uuid.UUID) (*billing.Invoice, error) {
+	inv, ok := f.invoices[invoiceID]
+	if !ok || inv.TenantID != tenantID {
+		return nil, billing.ErrInvoiceNotFound
+	}
+	cp := *inv
+	return &cp, nil
+}
+
+func (f *fakeStore) UpdateInvoice(_ context.Context, inv *billing.Invoice) error {
+	if f.invoices[inv.ID].Status != billing.InvoiceOpen {
+		return billing.ErrInvoiceClosed
+	}
+	cp := *inv
+	f.invoices[inv.ID] = &cp
+	return nil
+}
+
+func (f *fakeStore) ListInvoices(_ context.Context, tenantID uuid.UUID) ([]billing.Invoice, error) {
+	var out []billing.Invoice
+	for _, inv := range f.invoices {
+		if inv.TenantID == tenantID {
+			out = append(out, *inv)
+		}
+	}
+	slices.SortFunc(out, func(a, b billing.Invoice) int { return b.CreatedAt.Compare(a.CreatedAt) })
+	return out, nil
+}
+
+func (f *fakeStore) HasOpenRenewals(_ context.Context, subscriptionID, except uuid.UUID) (bool, error) {
+	for _, inv := range f.invoices {
+		if inv.SubscriptionID == subscriptionID && inv.ID != except && inv.Kind == billing.KindRenewal && inv.Status == billing.InvoiceOpen {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (f *fakeStore) listSubs(match func(s *billing.Subscription) bool, limit int) []billing.Subscription {
+	var out []billing.Subscription
+	for _, s := range f.subs {
+		if match(s) && len(out) < limit {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
+
+func (f *fakeStore) ListDueRenewals(_ context.Context, now time.Time, limit int) ([]billing.Subscription, error) {
+	return f.listSubs(func(s *billing.Subscription) bool {
+		return s.Status == billing.StatusActive && !s.CurrentPeriodEnd.After(now)
+	}, limit), nil
+}
+
+func (f *fakeStore) ClaimDueInvoices(_ context.Context, limit int, lease time.Duration) ([]billing.Invoice, error) {
+	now := time.Now()
+	var out []billing.Invoice
+	for _, inv := range f.invoices {
+		if inv.Status == billing.InvoiceOpen && inv.NextAttemptAt != nil && !inv.NextAttemptAt.After(now) && len(out) < limit {
+			next := now.Add(lease)
+			inv.NextAttemptAt = &next
+			out = append(out, *inv)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeStore) ListGraceEnded(_ context.Context, pastDueBefore time.Time, limit int) ([]billing.Subscription, error) {
+	return f.listSubs(func(s *billing.Subscription) bool {
+		return s.Status == billing.StatusPastDue && s.PastDueSince.Before(pastDueBefore)
+	}, limit), nil
+}
+
+func (f *fakeStore) ListPaidSuspended(_ context.Context, limit int) ([]billing.Subscription, error) {
+	return f.listSubs(func(s *billing.Subscription) bool {
+		return s.Status == billing.StatusActive && s.SuspendedAt != nil
+	}, limit), nil
+}
+
+func (f *fakeStore) SetTenantPlan(_ context.Context, tenantID uuid.UUID, plan tenant.PlanType) error {
+	f.plans[tenantID] = plan
+	return nil
+}
+
+// fakeStores records the status changes billing asks for, and keeps each
+// store's history newest first.
+type fakeStores struct {
+	suspended  []uuid.UUID
+	reinstated []uuid.UUID
+	history    map[uuid.UUID][]tenant.StatusChange
+}
+
+func (f *fakeStores) record(tenantID uuid.UUID, req tenant.StatusRequest) tenant.StatusChange {
+	c := tenant.StatusChange{ID: uuid.New(), TenantID: tenantID, To: req.To, Reason: req.Reason, Trigger: req.Trigger, CreatedAt: time.Now()}
+	f.history[tenantID] = append([]tenant.StatusChange{c}, f.history[tenantID]...)
+	return c
+}
+
+func (f *fakeStores) ChangeStatus(_ context.Context, tenantID uuid.UUID, req tenant.StatusRequest) (*tenant.TenantProfile, error) {
+	f.suspended = append(f.suspended, tenantID)
+	c := f.record(tenantID, req)
+	return &tenant.TenantProfile{ID: tenantID, Status: req.To, UpdatedAt: c.CreatedAt}, nil
+}
+
+func (f *fakeStores) Reinstate(_ context.Context, tenantID uuid.UUID, trigger tenant.Trigger, _ *uuid.UUID, reason string) (*tenant.TenantProfile, error) {
+	f.reinstated = append(f.reinstated, tenantID)
+	c := f.record(tenantID, tenant.StatusRequest{To: tenant.TenantStatusActive, Reason: reason, Trigger: trigger})
+	return &tenant.TenantProfile{ID: tenantID, Status: c.To, UpdatedAt: c.CreatedAt}, nil
+}
+
+func (f *fakeStores) StatusHistory(_ context.Context, tenantID uuid.UUID) ([]tenant.StatusChange, error) {
+	return f.history[tenantID], nil
+}
+
+type fixture struct {
+	bb       *billing.BillingBusiness
+	store    *fakeStore
+	stores   *fakeStores
+	provider *billingfake.Provider
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	f := fixture{store: newFakeStore(), stores: &fakeStores{history: map[uuid.UUID][]tenant.StatusChange{}}, provider: billingfake.NewProvider()}
+	cfg := &config.Config{Billing: config.BillingConfig{GracePeriod: 7 * 24 * time.Hour, RunInterval: time.Hour}}
+	bb, err := billing.NewBillingBusiness(
+		billing.WithBillingRepository(f.store),
+		billing.WithTransactor(fakeTrx{}),
+		billing.WithProvider(f.provider),
+		billing.WithStoreStatus(f.stores),
+		billing.WithConfigs(cfg),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.bb = bb
+	return f
+}
+
+// subscribed puts a store on plan monthly, halfway through a 30 day period.
+func (f fixture) subscribed(plan tenant.PlanType) uuid.UUID {
+	tenantID := uuid.New()
+	now := time.Now()
+	f.store.subs[tenantID] = &billing.Subscription{
+		ID:                 uuid.New(),
+		TenantID:           tenantID,
+		Plan:               plan,
+		Interval:           billing.Monthly,
+		Status:             billing.StatusActive,
+		CurrentPeriodStart: now.Add(-15 * 24 * time.Hour),
+		CurrentPeriodEnd:   now.Add(15 * 24 * time.Hour),
+		Credit:             money.New(decimal.Zero, billing.Currency),
+	}
+	f.store.plans[tenantID] = plan
+	return tenantID
+}
+
+// periodEnded moves the store's period into the past.
+func (f fixture) periodEnded(tenantID uuid.UUID) {
+	s := f.store.subs[tenantID]
+	s.CurrentPeriodStart = s.CurrentPeriodStart.Add(-15 * 24 * time.Hour)
+	s.CurrentPeriodEnd = s.CurrentPeriodEnd.Add(-15*24*time.Hour - time.Minute)
+}
+
+func near(t *testing.T, got money.Money, want float64) {
+	t.Helper()
+	if diff := got.Amount.Sub(decimal.NewFromFloat(want)).Abs(); diff.GreaterThan(decimal.NewFromFloat(0.02)) {
+		t.Fatalf("amount = %s, want about %.2f", got, want)
+	}
+}
+
+func TestChangePlanUpgradeProrates(t *testing.T) {
+	f := newFixture(t)
+	tenantID := f.subscribed(tenant.CorePlan)
+
+	sub, inv, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+		Plan: tenant.ProPlan, Interval: billing.Monthly, ActorID: uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// half a month of pro less half a month of core
+	near(t, inv.Amount, 25)
+	if inv.Status != billing.InvoicePaid || sub.Plan != tenant.ProPlan || f.store.plans[tenantID] != tenant.ProPlan {
+		t.Fatalf("invoice = %s, plan = %s, store plan = %s", inv.Status, sub.Plan, f.store.plans[tenantID])
+	}
+	if _, ok := f.provider.Charges()[inv.ID]; !ok {
+		t.Fatal("upgrade not charged")
+	}
+}
+
+func TestChangePlanDowngradeCredits(t *testing.T) {
+	f := newFixture(t)
+	tenantID := f.subscribed(tenant.ProPlan)
+
+	sub, inv, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+		Plan: tenant.CorePlan, Interval: billing.Monthly, ActorID: uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !inv.Amount.Amount.IsZero() || sub.Plan != tenant.CorePlan {
+		t.Fatalf("amount = %s, plan = %s", inv.Amount, sub.Plan)
+	}
+	near(t, sub.Credit, 25)
+
+	// the credit comes off the renewal
+	f.periodEnded(tenantID)
+	if _, err := f.bb.RunBilling(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	invoices, _ := f.store.ListInvoices(context.Background(), tenantID)
+	near(t, invoices[0].Amount, 4)
+	near(t, invoices[0].CreditApplied, 25)
+}
+
+func TestChangePlanDeclined(t *testing.T) {
+	f := newFixture(t)
+	tenantID := uuid.New()
+	f.provider.Decline(tenantID, "card expired")
+
+	_, _, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+		Plan: tenant.CorePlan, Interval: billing.Annual, ActorID: uuid.New(),
+	})
+	derr, ok := errs.IsDomainError(err)
+	if !ok || derr.Code != errs.FailedPrecondition || !errors.Is(err, billing.ErrPaymentDeclined) {
+		t.Fatalf("err = %v", err)
+	}
+	if sub := f.store.subs[tenantID]; sub.Status != billing.StatusIncomplete {
+		t.Fatalf("status = %s", sub.Status)
+	}
+	if _, ok := f.store.plans[tenantID]; ok {
+		t.Fatal("store moved to a plan it did not pay for")
+	}
+
+	// a declined attempt does not block the next one
+	f.provider.Accept(tenantID)
+	sub, inv, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+		Plan: tenant.CorePlan, Interval: billing.Annual, ActorID: uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	near(t, inv.Amount, 290)
+	if sub.Status != billing.StatusActive || f.store.plans[tenantID] != tenant.CorePlan {
+		t.Fatalf("status = %s, store plan = %s", sub.Status, f.store.plans[tenantID])
+	}
+}
+
+func TestRenewalGraceAndSuspension(t *testing.T) {
+	f := newFixture(t)
+	tenantID := f.subscribed(tenant.CorePlan)
+	f.periodEnded(tenantID)
+	f.provider.Decline(tenantID, "insufficient funds")
+
+	report, err := f.bb.RunBilling(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub := f.store.subs[tenantID]
+	if report.Renewed != 1 || report.Failed != 1 || sub.Status != billing.StatusPastDue {
+		t.Fatalf("report = %+v, status = %s", report, sub.Status)
+	}
+
+	// still within the grace period
+	if _, err := f.bb.RunBilling(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(f.stores.suspended) != 0 {
+		t.Fatal("suspended within the grace period")
+	}
+
+	past := time.Now().Add(-8 * 24 * time.Hour)
+	f.store.subs[tenantID].PastDueSince = &past
+	report, err = f.bb.RunBilling(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub = f.store.subs[tenantID]
+	if report.Suspended != 1 || sub.Status != billing.StatusUnpaid || sub.SuspendedAt == nil {
+		t.Fatalf("report = %+v, status = %s", report, sub.Status)
+	}
+
+	f.provider.Accept(tenantID)
+	invoices, _ := f.store.ListInvoices(context.Background(), tenantID)
+	if _, err := f.bb.PayInvoice(context.Background(), tenantID, invoices[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	sub = f.store.subs[tenantID]
+	if sub.Status != billing.StatusActive || sub.SuspendedAt != nil || len(f.stores.reinstated) != 1 {
+		t.Fatalf("status = %s, suspended_at = %v, reinstated = %d", sub.Status, sub.SuspendedAt, len(f.stores.reinstated))
+	}
+}
+
+func TestMoveToFreeAtPeriodEnd(t *testing.T) {
+	f := newFixture(t)
+	tenantID := f.subscribed(tenant.ProPlan)
+
+	sub, inv, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+		Plan: tenant.FreePlan, Interval: billing.Monthly, ActorID: uuid.New(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inv != nil || sub.PendingPlan != tenant.FreePlan || f.store.plans[tenantID] != tenant.ProPlan {
+		t.Fatalf("invoice = %v, pending = %s, store plan = %s", inv, sub.PendingPlan, f.store.plans[tenantID])
+	}
+
+	f.periodEnded(tenantID)
+	report, err := f.bb.RunBilling(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Canceled != 1 || f.store.subs[tenantID].Status != billing.StatusCanceled || f.store.plans[tenantID] != tenant.FreePlan {
+		t.Fatalf("report = %+v, store plan = %s", report, f.store.plans[tenantID])
+	}
+	if len(f.provider.Charges()) != 0 {
+		t.Fatal("charged for a canceled subscription")
+	}
+}
+
+func TestPaidArrearsLeaveOtherSuspensions(t *testing.T) {
+	f := newFixture(t)
+	tenantID := f.subscribed(tenant.CorePlan)
+	f.periodEnded(tenantID)
+	f.provider.Decline(tenantID, "insufficient funds")
+	if _, err := f.bb.RunBilling(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	past := time.Now().Add(-8 * 24 * time.Hour)
+	f.store.subs[tenantID].PastDueSince = &past
+	if _, err := f.bb.RunBilling(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// the platform suspends the store on top of billing
+	f.stores.record(tenantID, tenant.StatusRequest{To: tenant.TenantStatusSuspended, Reason: "fraud review", Trigger: tenant.TriggerPlatform})
+
+	f.provider.Accept(tenantID)
+	invoices, _ := f.store.ListInvoices(context.Background(), tenantID)
+	if _, err := f.bb.PayInvoice(context.Background(), tenantID, invoices[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	sub := f.store.subs[tenantID]
+	if sub.Status != billing.StatusActive || sub.SuspendedAt != nil || len(f.stores.reinstated) != 0 {
+		t.Fatalf("status = %s, suspended_at = %v, reinstated = %d", sub.Status, sub.SuspendedAt, len(f.stores.reinstated))
+	}
+}
+
+func TestPaidPlanReinstatesTrialSuspension(t *testing.T) {
+	tests := []struct {
+		name      string
+		suspended tenant.StatusRequest
+		want      int
+	}{
+		{"trial ended", tenant.StatusRequest{To: tenant.TenantStatusSuspended, Reason: tenant.TrialEndedReason, Trigger: tenant.TriggerSystem}, 1},
+		{"platform suspension", tenant.StatusRequest{To: tenant.TenantStatusSuspended, Reason: "fraud review", Trigger: tenant.TriggerPlatform}, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFixture(t)
+			tenantID := uuid.New()
+			f.stores.record(tenantID, tt.suspended)
+
+			_, _, err := f.bb.ChangePlan(context.Background(), tenantID, billing.PlanRequest{
+				Plan: tenant.CorePlan, Interval: billing.Monthly, ActorID: uuid.New(),
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := len(f.stores.reinstated); got != tt.want {
+				t.Fatalf("reinstated = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const runBatch = 100
+
+// RunBilling is run by the worker. It renews the subscriptions whose period
+// ended, retries the payments that are due, suspends the stores still
+// unpaid once their grace period is over and reinstates those paid up
+// since. Each subscription is handled on its own, the error returned joins
+// those that failed.
+func (bb *BillingBusiness) RunBilling(ctx context.Context) (RunReport, error) {
+	var (
+		report  RunReport
+		errList []error
+	)
+	now := time.Now()
+	errList = append(errList, bb.renewDue(ctx, now, &report)...)
+	errList = append(errList, bb.collectDue(ctx, &report)...)
+	errList = append(errList, bb.suspendOverdue(ctx, now, &report)...)
+	errList = append(errList, bb.liftPaidSuspensions(ctx, &report)...)
+	return report, errors.Join(errList...)
+}
+
+// renewDue renews a batch at a time. A batch with failures is the last of
+// the run, its failures would only be listed again.
+func (bb *BillingBusiness) renewDue(ctx context.Context, now time.Time, report *RunReport) []error {
+	var errList []error
+	for {
+		due, err := bb.storer.ListDueRenewals(ctx, now, runBatch)
+		if err != nil {
+			return append(errList, fmt.Errorf("listduerenewals: %w", err))
+		}
+		failed := false
+		for i := range due {
+			if err := bb.renew(ctx, &due[i], now, report); err != nil {
+				errList = append(errList, fmt.Errorf("subscription[%s]: %w", due[i].ID, err))
+				failed = true
+			}
+		}
+		if len(due) < runBatch || failed || ctx.Err() != nil {
+			return errList
+		}
+	}
+}
+
+// renew starts the subscription's next period and bills it, or moves the
+// store to the plan it asked for at the end of this one.
+func (bb *BillingBusiness) renew(ctx context.Context, sub *Subscription, now time.Time, report *RunReport) error {
+	var (
+		inv      *Invoice
+		canceled bool
+	)
+	err := bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := bb.storer.LockSubscription(ctx, sub.TenantID)
+		if err != nil {
+			return err
+		}
+		if cur.Status != StatusActive || cur.CurrentPeriodEnd.After(now) {
+			// renewed meanwhile
+			return nil
+		}
+		cur.UpdatedAt = now
+
+		if cur.PendingPlan != "" {
+			from := cur.Plan
+			cur.Plan, cur.PendingPlan = cur.PendingPlan, ""
+			cur.Status = StatusCanceled
+			if err := bb.storer.UpdateSubscription(ctx, cur); err != nil {
+				return err
+			}
+			if err := bb.storer.SetTenantPlan(ctx, cur.TenantID, cur.Plan); err != nil {
+				return err
+			}
+			canceled = true
+			return bb.recordAudit(ctx, audit.Entry{
+				TenantID:   &cur.TenantID,
+				Action:     "billing.cancel",
+				TargetType: "subscription",
+				TargetID:   cur.ID.String(),
+				Before:     map[string]any{"plan": from},
+				After:      map[string]any{"plan": cur.Plan},
+			})
+		}
+
+		q, err := renewalQuote(cur)
+		if err != nil {
+			return err
+		}
+		inv = newInvoice(cur, KindRenewal, q, now)
+		if inv.Status == InvoiceOpen {
+			lease := now.Add(paymentLease)
+			inv.NextAttemptAt = &lease
+		}
+		cur.CurrentPeriodStart, cur.CurrentPeriodEnd = q.PeriodStart, q.PeriodEnd
+		cur.Credit = q.Credit
+		if err := bb.storer.CreateInvoice(ctx, inv); err != nil {
+			return err
+		}
+		return bb.storer.UpdateSubscription(ctx, cur)
+	})
+	if err != nil {
+		return fmt.Errorf("renew-trx: %w", err)
+	}
+
+	switch {
+	case canceled:
+		report.Canceled++
+		return nil
+	case inv == nil:
+		return nil
+	}
+	report.Renewed++
+	if inv.Status != InvoiceOpen {
+		return nil
+	}
+	return bb.collectReported(ctx, inv, report)
+}
+
+// collectDue charges the open invoices whose next attempt is due.
+func (bb *BillingBusiness) collectDue(ctx context.Context, report *RunReport) []error {
+	var errList []error
+	for {
+		due, err := bb.storer.ClaimDueInvoices(ctx, runBatch, paymentLease)
+		if err != nil {
+			return append(errList, fmt.Errorf("claimdueinvoices: %w", err))
+		}
+		for i := range due {
+			if err := bb.collectReported(ctx, &due[i], report); err != nil {
+				errList = append(errList, fmt.Errorf("invoice[%s]: %w", due[i].ID, err))
+			}
+		}
+		if len(due) < runBatch || ctx.Err() != nil {
+			return errList
+		}
+	}
+}
+
+// collectReported collects inv for a billing run. A declined payment is
+// counted, not an error: it is retried on schedule.
+func (bb *BillingBusiness) collectReported(ctx context.Context, inv *Invoice, report *RunReport) error {
+	err := bb.collect(ctx, inv)
+	switch {
+	case err == nil:
+		report.Collected++
+		return nil
+	case errors.Is(err, ErrPaymentDeclined):
+		report.Failed++
+		return nil
+	}
+	return err
+}
+
+// suspendOverdue suspends the stores past due for longer than the grace
+// period.
+func (bb *BillingBusiness) suspendOverdue(ctx context.Context, now time.Time, report *RunReport) []error {
+	var errList []error
+	before := now.Add(-bb.config.Billing.GracePeriod)
+	for {
+		subs, err := bb.storer.ListGraceEnded(ctx, before, runBatch)
+		if err != nil {
+			return append(errList, fmt.Errorf("listgraceended: %w", err))
+		}
+		failed := false
+		for i := range subs {
+			if err := bb.suspend(ctx, &subs[i], now, report); err != nil {
+				errList = append(errList, fmt.Errorf("subscription[%s]: %w", subs[i].ID, err))
+				failed = true
+			}
+		}
+		if len(subs) < runBatch || failed || ctx.Err() != nil {
+			return errList
+		}
+	}
+}
+
+// suspend marks the subscription unpaid and suspends its store. A store
+// suspended or archived already is left as it is, and paying does not
+// reinstate it later. SuspendedAt is the time of the status change, which
+// is how liftSuspension knows it.
+func (bb *BillingBusiness) suspend(ctx context.Context, sub *Subscription, now time.Time, report *RunReport) error {
+	te, err := bb.stores.ChangeStatus(ctx, sub.TenantID, tenant.StatusRequest{
+		To:      tenant.TenantStatusSuspended,
+		Reason:  overdueReason,
+		Trigger: tenant.TriggerSystem,
+	})
+	suspended := err == nil
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); !ok || derr.Code == errs.Aborted {
+			return fmt.Errorf("changestatus: %w", err)
+		}
+	}
+
+	err = bb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		cur, err := bb.storer.LockSubscription(ctx, sub.TenantID)
+		if err != nil {
+			return err
+		}
+		if cur.Status != StatusPastDue {
+			return nil
+		}
+		cur.Status = StatusUnpaid
+		if suspended {
+			cur.SuspendedAt = &te.UpdatedAt
+		}
+		cur.UpdatedAt = now
+		if err := bb.storer.UpdateSubscription(ctx, cur); err != nil {
+			return err
+		}
+		return bb.recordAudit(ctx, audit.Entry{
+			TenantID:   &cur.TenantID,
+			Action:     "billing.unpaid",
+			TargetType: "subscription",
+			TargetID:   cur.ID.String(),
+			Before:     map[string]any{"status": StatusPastDue, "past_due_since": cur.PastDueSince},
+			After:      map[string]any{"status": StatusUnpaid, "store_suspended": suspended},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("unpaid-trx: %w", err)
+	}
+	if suspended {
+		report.Suspended++
+	}
+	return nil
+}
+
+// liftPaidSuspensions reinstates the stores whose reinstatement did not go
+// through when they paid.
+func (bb *BillingBusiness) liftPaidSuspensions(ctx context.Context, report *RunReport) []error {
+	var errList []error
+	for {
+		subs, err := bb.storer.ListPaidSuspended(ctx, runBatch)
+		if err != nil {
+			return append(errList, fmt.Errorf("listpaidsuspended: %w", err))
+		}
+		failed := false
+		for i := range subs {
+			if err := bb.liftSuspension(ctx, subs[i].TenantID, *subs[i].SuspendedAt); err != nil {
+				errList = append(errList, fmt.Errorf("subscription[%s]: %w", subs[i].ID, err))
+				failed = true
+				continue
+			}
+			report.Reinstated++
+		}
+		if len(subs) < runBatch || failed || ctx.Err() != nil {
+			return errList
+		}
+	}
+}
@@ -0,0 +1,85 @@
+package billing
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+)
+
+var (
+	ErrDatabase             = errors.New("database error")
+	ErrUnknownPlan          = errors.New("unknown plan")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrInvoiceNotFound      = errors.New("invoice not found")
+	ErrChangePending        = errors.New("a plan change is already awaiting payment")
+	ErrPaymentDeclined      = errors.New("payment declined")
+	ErrNoProvider           = errors.New("payments are not set up")
+	ErrOverdue              = errors.New("subscription has overdue invoices, pay them first")
+	ErrSamePlan             = errors.New("store is already on this plan")
+	ErrNotSubscribed        = errors.New("store has no paid subscription")
+	ErrInvoiceClosed        = errors.New("invoice is not open")
+)
+
+type Repository interface {
+	CreateSubscription(ctx context.Context, s *Subscription) error
+	GetSubscription(ctx context.Context, tenantID uuid.UUID) (*Subscription, error)
+	// LockSubscription returns the store's subscription locked until the
+	// transaction on ctx ends.
+	LockSubscription(ctx context.Context, tenantID uuid.UUID) (*Subscription, error)
+	UpdateSubscription(ctx context.Context, s *Subscription) error
+	// CreateInvoice reports ErrChangePending when the subscription has a
+	// plan change open already.
+	CreateInvoice(ctx context.Context, inv *Invoice) error
+	GetInvoice(ctx context.Context, tenantID, invoiceID uuid.UUID) (*Invoice, error)
+	UpdateInvoice(ctx context.Context, inv *Invoice) error
+	// ListInvoices returns the store's invoices, newest first.
+	ListInvoices(ctx context.Context, tenantID uuid.UUID) ([]Invoice, error)
+	// HasOpenRenewals reports whether the subscription has open renewal
+	// invoices other than except.
+	HasOpenRenewals(ctx context.Context, subscriptionID, except uuid.UUID) (bool, error)
+	// ListDueRenewals returns up to limit active subscriptions whose period
+	// ended by now, oldest first.
+	ListDueRenewals(ctx context.Context, now time.Time, limit int) ([]Subscription, error)
+	// ClaimDueInvoices returns up to limit open invoices whose next attempt
+	// is due and pushes that attempt lease into the future, so concurrent
+	// workers never charge the same invoice.
+	ClaimDueInvoices(ctx context.Context, limit int, lease time.Duration) ([]Invoice, error)
+	// ListGraceEnded returns up to limit subscriptions past due since before
+	// the given time.
+	ListGraceEnded(ctx context.Context, pastDueBefore time.Time, limit int) ([]Subscription, error)
+	// ListPaidSuspended returns up to limit subscriptions paid up again
+	// whose store billing suspended.
+	ListPaidSuspended(ctx context.Context, limit int) ([]Subscription, error)
+	// SetTenantPlan moves the store to plan, which ends any trial it is on.
+	SetTenantPlan(ctx context.Context, tenantID uuid.UUID, plan tenant.PlanType) error
+}
+
+// Charge asks a Provider for the amount of an invoice.
+type Charge struct {
+	InvoiceID   uuid.UUID
+	TenantID    uuid.UUID
+	Amount      money.Money
+	Description string
+}
+
+// Provider collects payments with whatever the store's owner has on file.
+// Charges are keyed by invoice: charging an invoice again returns the first
+// charge's reference instead of taking the money twice. A refused payment
+// is reported as ErrPaymentDeclined, any other error is taken as the
+// provider being unreachable.
+type Provider interface {
+	Charge(ctx context.Context, c Charge) (string, error)
+}
+
+// StoreStatus moves stores along their lifecycle, *tenant.TenantBusiness
+// satisfies it. The history tells billing whether the suspension in force
+// is one it may lift.
+type StoreStatus interface {
+	ChangeStatus(ctx context.Context, tenantID uuid.UUID, req tenant.StatusRequest) (*tenant.TenantProfile, error)
+	Reinstate(ctx context.Context, tenantID uuid.UUID, trigger tenant.Trigger, actorID *uuid.UUID, reason string) (*tenant.TenantProfile, error)
+	StatusHistory(ctx context.Context, tenantID uuid.UUID) ([]tenant.StatusChange, error)
+}
@@ -0,0 +1,34 @@
+package cleanup
+
+import "time"
+
+// Target names one kind of leftover the sweep removes. It labels the counts
+// in Report and the job's metrics.
+type Target string
+
+const (
+	TargetTokens             Target = "tokens"
+	TargetSessions           Target = "sessions"
+	TargetUnverifiedUsers    Target = "unverified_users"
+	TargetSignInReports      Target = "signin_reports"
+	TargetOAuthCodes         Target = "oauth_codes"
+	TargetOAuthRefreshTokens Target = "oauth_refresh_tokens"
+	TargetCacheKeys          Target = "cache_keys"
+	TargetOverduePurges      Target = "overdue_purges"
+)
+
+// Report is what one sweep removed. A target that failed keeps the count it
+// reached before the error.
+type Report struct {
+	Deleted  map[Target]int64
+	Batches  int
+	Duration time.Duration
+}
+
+func (r Report) Total() int64 {
+	var n int64
+	for _, d := range r.Deleted {
+		n += d
+	}
+	return n
+}
@@ -0,0 +1,120 @@
+package cleanupdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/domain/cleanup"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Every delete picks its batch with FOR UPDATE SKIP LOCKED, so workers
+// sweeping at the same time split the rows instead of queueing on them.
+
+type cleanupStore struct {
+	conn database.DBTX
+}
+
+func NewCleanupStore(conn *pgxpool.Pool) *cleanupStore {
+	return &cleanupStore{conn: conn}
+}
+
+func (cs *cleanupStore) deleteBatch(ctx context.Context, query string, args ...any) (int64, error) {
+	conn := database.GetTXFromContext(ctx, cs.conn)
+
+	res, err := conn.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", cleanup.ErrDatabase, err)
+	}
+	return res.RowsAffected(), nil
+}
+
+func (cs *cleanupStore) DeleteExpiredTokens(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		DELETE FROM tokens
+		WHERE hash IN (
+			SELECT hash FROM tokens
+			WHERE expiry < now()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, limit)
+}
+
+// DeleteDeadSessions removes blocked sessions and expired ones. A consumed
+// session is kept until it expires, it is how refresh token reuse is
+// caught.
+func (cs *cleanupStore) DeleteDeadSessions(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		DELETE FROM sessions
+		WHERE id IN (
+			SELECT id FROM sessions
+			WHERE is_blocked OR expires_at < now()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, limit)
+}
+
+// DeleteUnverifiedUsers removes accounts that never verified their email.
+// Ones that were deleted are left to the purge job, and the checks on
+// tenants and addresses keep anything they own from blocking the delete.
+func (cs *cleanupStore) DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time, limit int) (int64, error) {
+	const query = `
+		DELETE FROM users
+		WHERE id IN (
+			SELECT u.id FROM users u
+			WHERE NOT u.is_verified
+			  AND NOT u.is_deleted
+			  AND u.created_at < $1
+			  AND NOT EXISTS (SELECT 1 FROM tenants t WHERE t.user_id = u.id)
+			  AND NOT EXISTS (SELECT 1 FROM addresses a WHERE a.user_id = u.id)
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, createdBefore, limit)
+}
+
+func (cs *cleanupStore) DeleteExpiredSignInReports(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		DELETE FROM signin_reports
+		WHERE hash IN (
+			SELECT hash FROM signin_reports
+			WHERE expiry < now()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, limit)
+}
+
+func (cs *cleanupStore) DeleteExpiredOAuthCodes(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		DELETE FROM oauth_codes
+		WHERE code_hash IN (
+			SELECT code_hash FROM oauth_codes
+			WHERE expiry < now()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, limit)
+}
+
+func (cs *cleanupStore) DeleteExpiredOAuthRefreshTokens(ctx context.Context, limit int) (int64, error) {
+	const query = `
+		DELETE FROM oauth_refresh_tokens
+		WHERE token_hash IN (
+			SELECT token_hash FROM oauth_refresh_tokens
+			WHERE expiry < now()
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+	`
+	return cs.deleteBatch(ctx, query, limit)
+}
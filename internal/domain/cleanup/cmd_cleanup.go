@@ -0,0 +1,147 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/users"
+)
+
+type CleanupBusiness struct {
+	storer Repository
+	keys   KeySweeper
+	purger AccountPurger
+	config *config.Config
+}
+
+type CleanupBusinessCfg func(cb *CleanupBusiness) error
+
+func NewCleanupBusiness(cfgs ...CleanupBusinessCfg) (*CleanupBusiness, error) {
+	cb := &CleanupBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(cb); err != nil {
+			return nil, err
+		}
+	}
+	if cb.storer == nil {
+		return nil, errors.New("cleanup repository is required")
+	}
+	if cb.keys == nil {
+		return nil, errors.New("cache is required")
+	}
+	if cb.config == nil {
+		return nil, errors.New("config is required")
+	}
+	return cb, nil
+}
+
+func WithCleanupRepository(st Repository) CleanupBusinessCfg {
+	return func(cb *CleanupBusiness) error {
+		cb.storer = st
+		return nil
+	}
+}
+
+func WithCache(keys KeySweeper) CleanupBusinessCfg {
+	return func(cb *CleanupBusiness) error {
+		cb.keys = keys
+		return nil
+	}
+}
+
+// WithAccountPurger has the sweep purge deleted accounts whose purge job
+// never ran. Without one they wait for their job.
+func WithAccountPurger(p AccountPurger) CleanupBusinessCfg {
+	return func(cb *CleanupBusiness) error {
+		cb.purger = p
+		return nil
+	}
+}
+
+func WithConfigs(cfg *config.Config) CleanupBusinessCfg {
+	return func(cb *CleanupBusiness) error {
+		cb.config = cfg
+		return nil
+	}
+}
+
+// sweepStep removes one target a batch at a time.
+type sweepStep struct {
+	target Target
+	delete func(ctx context.Context) (int64, error)
+}
+
+// Sweep deletes everything that has outlived its use, one bounded batch at
+// a time until a target runs dry. Sessions are kept until blocked or
+// expired and OAuth refresh tokens until expired, consumed ones are still
+// needed to spot reuse. Several sweeps may run at once, each skips the rows
+// another holds. A failing target does not stop the others, their errors
+// are joined.
+func (cb *CleanupBusiness) Sweep(ctx context.Context) (Report, error) {
+	start := time.Now()
+	report := Report{Deleted: make(map[Target]int64)}
+	limit := cb.config.Cleanup.BatchSize
+	unverifiedBefore := start.Add(-cb.config.Cleanup.UnverifiedRetention)
+
+	steps := []sweepStep{
+		{TargetTokens, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteExpiredTokens(ctx, limit)
+		}},
+		{TargetSessions, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteDeadSessions(ctx, limit)
+		}},
+		{TargetSignInReports, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteExpiredSignInReports(ctx, limit)
+		}},
+		{TargetOAuthCodes, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteExpiredOAuthCodes(ctx, limit)
+		}},
+		{TargetOAuthRefreshTokens, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteExpiredOAuthRefreshTokens(ctx, limit)
+		}},
+		{TargetUnverifiedUsers, func(ctx context.Context) (int64, error) {
+			return cb.storer.DeleteUnverifiedUsers(ctx, unverifiedBefore, limit)
+		}},
+	}
+	if cb.purger != nil {
+		steps = append(steps, sweepStep{TargetOverduePurges, func(ctx context.Context) (int64, error) {
+			return cb.purger.PurgeOverdueAccounts(ctx, limit)
+		}})
+	}
+
+	var errList []error
+	for _, step := range steps {
+		for {
+			if err := ctx.Err(); err != nil {
+				report.Duration = time.Since(start)
+				return report, errors.Join(append(errList, err)...)
+			}
+			n, err := step.delete(ctx)
+			report.Deleted[step.target] += n
+			report.Batches++
+			if err != nil {
+				errList = append(errList, fmt.Errorf("delete %s: %w", step.target, err))
+				break
+			}
+			if n < int64(limit) {
+				break
+			}
+		}
+	}
+
+	for _, pattern := range slices.Concat(users.KeyPatterns, tenant.KeyPatterns) {
+		n, err := cb.keys.DeleteStale(ctx, pattern, int64(limit))
+		report.Deleted[TargetCacheKeys] += n
+		if err != nil {
+			errList = append(errList, fmt.Errorf("delete stale keys %q: %w", pattern, err))
+		}
+	}
+
+	report.Duration = time.Since(start)
+	return report, errors.Join(errList...)
+}
@@ -0,0 +1,31 @@
+package cleanup
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var ErrDatabase = errors.New("database error")
+
+// Repository deletes at most limit rows per call and reports how many went.
+// Rows locked by a concurrent sweep are skipped, not waited on.
+type Repository interface {
+	DeleteExpiredTokens(ctx context.Context, limit int) (int64, error)
+	DeleteDeadSessions(ctx context.Context, limit int) (int64, error)
+	DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time, limit int) (int64, error)
+	DeleteExpiredSignInReports(ctx context.Context, limit int) (int64, error)
+	DeleteExpiredOAuthCodes(ctx context.Context, limit int) (int64, error)
+	DeleteExpiredOAuthRefreshTokens(ctx context.Context, limit int) (int64, error)
+}
+
+// KeySweeper drops cache keys that were left without a TTL.
+type KeySweeper interface {
+	DeleteStale(ctx context.Context, pattern string, count int64) (int64, error)
+}
+
+// AccountPurger anonymizes deleted accounts whose purge is overdue,
+// *users.UserBusiness satisfies it.
+type AccountPurger interface {
+	PurgeOverdueAccounts(ctx context.Context, limit int) (int64, error)
+}
@@ -0,0 +1,464 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/infra/cache"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	checkBatch = 50
+	// maxCheckBackoff caps the wait between DNS polls of one domain.
+	maxCheckBackoff = time.Hour
+	// certRetry is how soon a verified domain is probed again while no
+	// certificate is served, certRecheck how often an issued one is, to
+	// follow its renewals.
+	certRetry   = 10 * time.Minute
+	certRecheck = 24 * time.Hour
+)
+
+type DomainBusiness struct {
+	storer   Repository
+	trx      database.TransactorTX
+	resolver Resolver
+	prober   CertProber
+	cache    cache.Cache
+	auditor  audit.Recorder
+	plans    tenant.EntitlementChecker
+	config   *config.Config
+}
+
+type DomainBusinessCfg func(db *DomainBusiness) error
+
+func NewDomainBusiness(cfgs ...DomainBusinessCfg) (*DomainBusiness, error) {
+	db := &DomainBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(db); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case db.storer == nil:
+		return nil, errors.New("custom domain repository is required")
+	case db.trx == nil:
+		return nil, errors.New("transaction manager is required")
+	case db.resolver == nil:
+		return nil, errors.New("dns resolver is required")
+	case db.config == nil:
+		return nil, errors.New("config is required")
+	}
+	return db, nil
+}
+
+func WithDomainRepository(st Repository) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.storer = st
+		return nil
+	}
+}
+
+func WithTransactor(trx database.TransactorTX) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.trx = trx
+		return nil
+	}
+}
+
+func WithResolver(r Resolver) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.resolver = r
+		return nil
+	}
+}
+
+// WithCertProber enables certificate tracking. Without one verified is as
+// far as a domain goes.
+func WithCertProber(p CertProber) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.prober = p
+		return nil
+	}
+}
+
+// WithCache lets a newly served or removed domain take effect before the
+// storefront's cached host lookup expires.
+func WithCache(c cache.Cache) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.cache = c
+		return nil
+	}
+}
+
+func WithAuditor(auditor audit.Recorder) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.auditor = auditor
+		return nil
+	}
+}
+
+// WithEntitlements refuses domains to stores whose plan does not include
+// them. Without it any store can add one.
+func WithEntitlements(ec tenant.EntitlementChecker) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.plans = ec
+		return nil
+	}
+}
+
+func WithConfigs(cfg *config.Config) DomainBusinessCfg {
+	return func(db *DomainBusiness) error {
+		db.config = cfg
+		return nil
+	}
+}
+
+// EdgeHost is the name custom domains point their CNAME at.
+func (db *DomainBusiness) EdgeHost() string {
+	return db.config.CustomDomain.EdgeHost
+}
+
+// recordAudit writes e within the transaction on ctx. Without an auditor
+// configured nothing is recorded.
+func (db *DomainBusiness) recordAudit(ctx context.Context, e audit.Entry) error {
+	if db.auditor == nil {
+		return nil
+	}
+	return db.auditor.Record(ctx, e)
+}
+
+// AddDomain records the domain a store wants to be served on. Nothing is
+// served on it until its TXT challenge is verified. A name another store
+// serves is refused, one only claimed by others is not: whoever verifies
+// it first gets it.
+func (db *DomainBusiness) AddDomain(ctx context.Context, tenantID uuid.UUID, name string) (*CustomDomain, error) {
+	if db.plans != nil {
+		if err := db.plans.CheckFeature(ctx, tenantID, tenant.FeatureCustomDomain); err != nil {
+			return nil, err
+		}
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+	d, err := newCustomDomain(tenantID, name, token)
+	if err != nil {
+		return nil, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+
+	taken, err := db.storer.IsHostTaken(ctx, d.Domain)
+	if err != nil {
+		return nil, fmt.Errorf("ishosttaken: %w", err)
+	}
+	if taken {
+		return nil, errs.NewDomainError(errs.AlreadyExists, ErrDomainTaken)
+	}
+
+	err = db.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := db.storer.CreateDomain(ctx, d); err != nil {
+			return err
+		}
+		return db.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "domain.add",
+			TargetType: "custom_domain",
+			TargetID:   d.ID.String(),
+			After:      map[string]any{"domain": d.Domain},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrDomainExists) {
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		}
+		return nil, fmt.Errorf("adddomain-trx: %w", err)
+	}
+	return d, nil
+}
+
+func (db *DomainBusiness) GetDomain(ctx context.Context, tenantID uuid.UUID) (*CustomDomain, error) {
+	d, err := db.storer.GetDomain(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrDomainNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("getdomain: %w", err)
+	}
+	return d, nil
+}
+
+// StartVerification has the worker look for the TXT challenge from now on,
+// for up to the configured window. A failed domain can be started again.
+func (db *DomainBusiness) StartVerification(ctx context.Context, tenantID uuid.UUID) (*CustomDomain, error) {
+	d, err := db.GetDomain(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	switch d.Status {
+	case StatusVerifying:
+		return d, nil
+	case StatusVerified, StatusCertIssued:
+		return nil, errs.NewDomainError(errs.FailedPrecondition, ErrDomainVerified)
+	}
+
+	now := time.Now()
+	d.Status = StatusVerifying
+	d.Attempts = 0
+	d.LastError = ""
+	d.VerifyStartedAt = &now
+	d.NextCheckAt = &now
+	d.UpdatedAt = now
+	if err := db.storer.UpdateDomain(ctx, d); err != nil {
+		return nil, fmt.Errorf("updatedomain: %w", err)
+	}
+	return d, nil
+}
+
+// RemoveDomain drops the store's custom domain, a served one hands back to
+// the store's subdomain.
+func (db *DomainBusiness) RemoveDomain(ctx context.Context, tenantID uuid.UUID) error {
+	var removed *CustomDomain
+	err := db.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if removed, err = db.storer.DeleteDomain(ctx, tenantID); err != nil {
+			return err
+		}
+		if removed.IsServed() {
+			if err := db.storer.SetServedDomain(ctx, tenantID, ""); err != nil {
+				return err
+			}
+		}
+		return db.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "domain.remove",
+			TargetType: "custom_domain",
+			TargetID:   removed.ID.String(),
+			Before:     map[string]any{"domain": removed.Domain, "status": removed.Status},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrDomainNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("removedomain-trx: %w", err)
+	}
+	db.forgetHost(ctx, removed.Domain)
+	return nil
+}
+
+// AllowCertificate tells the edge whether it may order a certificate for
+// host, only verified custom domains qualify.
+func (db *DomainBusiness) AllowCertificate(ctx context.Context, host string) (bool, error) {
+	d, err := db.storer.GetDomainByName(ctx, tenant.NormalizeHost(host))
+	if err != nil {
+		if errors.Is(err, ErrDomainNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getdomainbyname: %w", err)
+	}
+	return d.IsServed(), nil
+}
+
+// CheckDomains is run by the worker. It drops stale claims, polls DNS for
+// domains being verified and probes the certificates of verified ones, a
+// batch at a time. DNS and TLS failures only count against their domain,
+// the error returned is for the ones that could not be saved.
+func (db *DomainBusiness) CheckDomains(ctx context.Context) (CheckReport, error) {
+	var (
+		report  CheckReport
+		errList []error
+	)
+	expired, err := db.storer.DeleteStaleClaims(ctx, time.Now().Add(-db.config.CustomDomain.VerifyWindow))
+	if err != nil {
+		errList = append(errList, fmt.Errorf("deletestaleclaims: %w", err))
+	}
+	report.Expired = expired
+
+	for {
+		due, err := db.storer.ClaimDueDomains(ctx, checkBatch, db.config.CustomDomain.CheckInterval)
+		if err != nil {
+			return report, errors.Join(append(errList, fmt.Errorf("claimduedomains: %w", err))...)
+		}
+		for i := range due {
+			d := &due[i]
+			var err error
+			switch d.Status {
+			case StatusVerifying:
+				err = db.checkChallenge(ctx, d, &report)
+			case StatusVerified, StatusCertIssued:
+				err = db.checkCertificate(ctx, d, &report)
+			}
+			report.Checked++
+			if err != nil {
+				errList = append(errList, fmt.Errorf("domain[%s]: %w", d.Domain, err))
+			}
+		}
+		if len(due) < checkBatch || ctx.Err() != nil {
+			return report, errors.Join(errList...)
+		}
+	}
+}
+
+func (db *DomainBusiness) checkChallenge(ctx context.Context, d *CustomDomain, report *CheckReport) error {
+	now := time.Now()
+	d.UpdatedAt = now
+
+	found, err := db.challengePublished(ctx, d)
+	if found {
+		d.Status = StatusVerified
+		d.VerifiedAt = &now
+		d.LastError = ""
+		d.NextCheckAt = &now
+		err := db.trx.WithTransaction(ctx, func(ctx context.Context) error {
+			if err := db.storer.UpdateDomain(ctx, d); err != nil {
+				return err
+			}
+			if err := db.storer.SetServedDomain(ctx, d.TenantID, d.Domain); err != nil {
+				return err
+			}
+			return db.recordAudit(ctx, audit.Entry{
+				TenantID:   &d.TenantID,
+				Action:     "domain.verify",
+				TargetType: "custom_domain",
+				TargetID:   d.ID.String(),
+				After:      map[string]any{"domain": d.Domain, "attempts": d.Attempts},
+			})
+		})
+		switch {
+		case errors.Is(err, ErrDomainTaken):
+			// another store verified the name first
+			d.VerifiedAt = nil
+			d.LastError = ErrDomainTaken.Error()
+			return db.failDomain(ctx, d, report)
+		case err != nil:
+			return fmt.Errorf("verify-trx: %w", err)
+		}
+		db.forgetHost(ctx, d.Domain)
+		report.Verified++
+		return nil
+	}
+
+	d.Attempts++
+	d.LastError = fmt.Sprintf("TXT record %s not found", d.ChallengeName())
+	if err != nil {
+		d.LastError = fmt.Sprintf("TXT lookup %s: %s", d.ChallengeName(), err)
+	}
+
+	if d.VerifyStartedAt == nil || now.After(d.VerifyStartedAt.Add(db.config.CustomDomain.VerifyWindow)) {
+		return db.failDomain(ctx, d, report)
+	}
+
+	next := now.Add(db.backoff(d.Attempts))
+	d.NextCheckAt = &next
+	if err := db.storer.UpdateDomain(ctx, d); err != nil {
+		return fmt.Errorf("updatedomain: %w", err)
+	}
+	return nil
+}
+
+// failDomain stops verifying d, LastError tells the store why.
+func (db *DomainBusiness) failDomain(ctx context.Context, d *CustomDomain, report *CheckReport) error {
+	d.Status = StatusFailed
+	d.NextCheckAt = nil
+	err := db.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := db.storer.UpdateDomain(ctx, d); err != nil {
+			return err
+		}
+		return db.recordAudit(ctx, audit.Entry{
+			TenantID:   &d.TenantID,
+			Action:     "domain.verify_failed",
+			TargetType: "custom_domain",
+			TargetID:   d.ID.String(),
+			After:      map[string]any{"domain": d.Domain, "attempts": d.Attempts, "error": d.LastError},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("fail-trx: %w", err)
+	}
+	report.Failed++
+	return nil
+}
+
+// challengePublished looks for the TXT challenge. A name that does not
+// exist yet is not an error, it is the usual answer until the merchant
+// publishes the record.
+func (db *DomainBusiness) challengePublished(ctx context.Context, d *CustomDomain) (bool, error) {
+	records, err := db.resolver.LookupTXT(ctx, d.ChallengeName())
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+	for _, r := range records {
+		if strings.TrimSpace(r) == d.ChallengeValue() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// checkCertificate follows the certificate the edge obtains over ACME once
+// a domain is verified. One that has expired unrenewed sends the domain
+// back to verified.
+func (db *DomainBusiness) checkCertificate(ctx context.Context, d *CustomDomain, report *CheckReport) error {
+	now := time.Now()
+	d.UpdatedAt = now
+
+	if db.prober == nil {
+		d.NextCheckAt = nil
+		if err := db.storer.UpdateDomain(ctx, d); err != nil {
+			return fmt.Errorf("updatedomain: %w", err)
+		}
+		return nil
+	}
+
+	expiresAt, err := db.prober.ProbeCertificate(ctx, d.Domain)
+	if err != nil {
+		d.LastError = fmt.Sprintf("certificate: %s", err)
+		if d.CertExpiresAt != nil && now.After(*d.CertExpiresAt) {
+			d.Status = StatusVerified
+		}
+		next := now.Add(certRetry)
+		d.NextCheckAt = &next
+	} else {
+		if d.Status != StatusCertIssued {
+			report.CertsIssued++
+		}
+		d.Status = StatusCertIssued
+		d.CertExpiresAt = &expiresAt
+		d.LastError = ""
+		next := now.Add(certRecheck)
+		d.NextCheckAt = &next
+	}
+
+	if err := db.storer.UpdateDomain(ctx, d); err != nil {
+		return fmt.Errorf("updatedomain: %w", err)
+	}
+	return nil
+}
+
+// backoff doubles the wait between polls with each miss, up to
+// maxCheckBackoff.
+func (db *DomainBusiness) backoff(attempts int) time.Duration {
+	return min(db.config.CustomDomain.CheckInterval<<min(attempts, 8), maxCheckBackoff)
+}
+
+// forgetHost drops the storefront's cached lookup of domain, best effort.
+func (db *DomainBusiness) forgetHost(ctx context.Context, domain string) {
+	if db.cache == nil {
+		return
+	}
+	_ = db.cache.Delete(ctx, tenant.TenantHost(domain))
+}
@@ -0,0 +1,294 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+)
+
+type fakeResolver map[string][]string
+
+func (f fakeResolver) LookupTXT(_ context.Context, name string) ([]string, error) {
+	records, ok := f[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+type fakeProber struct {
+	expiresAt time.Time
+	err       error
+}
+
+func (f fakeProber) ProbeCertificate(context.Context, string) (time.Time, error) {
+	return f.expiresAt, f.err
+}
+
+type fakeTrx struct{}
+
+func (fakeTrx) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+// fakeStore hands out every stored domain as due.
+type fakeStore struct {
+	Repository
+	domains map[uuid.UUID]*CustomDomain
+	served  map[uuid.UUID]string
+}
+
+func (f *fakeStore) GetDomain(_ context.Context, tenantID uuid.UUID) (*CustomDomain, error) {
+	d, ok := f.domains[tenantID]
+	if !ok {
+		return nil, ErrDomainNotFound
+	}
+	cp := *d
+	return &cp, nil
+}
+
+func (f *fakeStore) UpdateDomain(_ context.Context, d *CustomDomain) error {
+	for _, o := range f.domains {
+		if d.IsServed() && o.IsServed() && o.Domain == d.Domain && o.TenantID != d.TenantID {
+			return ErrDomainTaken
+		}
+	}
+	cp := *d
+	f.domains[d.TenantID] = &cp
+	return nil
+}
+
+func (f *fakeStore) DeleteStaleClaims(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	for tenantID, d := range f.domains {
+		if (d.Status == StatusPending || d.Status == StatusFailed) && d.UpdatedAt.Before(before) {
+			delete(f.domains, tenantID)
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (f *fakeStore) ClaimDueDomains(_ context.Context, limit int, _ time.Duration) ([]CustomDomain, error) {
+	var due []CustomDomain
+	for _, d := range f.domains {
+		if d.NextCheckAt != nil && len(due) < limit {
+			due = append(due, *d)
+		}
+	}
+	return due, nil
+}
+
+func (f *fakeStore) SetServedDomain(_ context.Context, tenantID uuid.UUID, domain string) error {
+	f.served[tenantID] = domain
+	return nil
+}
+
+func newTestBusiness(t *testing.T, resolver Resolver, prober CertProber) (*DomainBusiness, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{domains: map[uuid.UUID]*CustomDomain{}, served: map[uuid.UUID]string{}}
+	cfg := &config.Config{CustomDomain: config.CustomDomainConfig{
+		EdgeHost:      "edge.merchcore.com",
+		VerifyWindow:  time.Hour,
+		CheckInterval: time.Minute,
+	}}
+	db, err := NewDomainBusiness(
+		WithDomainRepository(store),
+		WithTransactor(fakeTrx{}),
+		WithResolver(resolver),
+		WithCertProber(prober),
+		WithConfigs(cfg),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, store
+}
+
+// verifying adds a domain that has been verifying since startedAt.
+func verifying(t *testing.T, store *fakeStore, name string, startedAt time.Time) *CustomDomain {
+	t.Helper()
+	d, err := newCustomDomain(uuid.New(), name, "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Status = StatusVerifying
+	d.VerifyStartedAt = &startedAt
+	d.NextCheckAt = &startedAt
+	store.domains[d.TenantID] = d
+	return d
+}
+
+func TestCheckDomainsVerifies(t *testing.T) {
+	resolver := fakeResolver{}
+	db, store := newTestBusiness(t, resolver, fakeProber{err: errors.New("no certificate")})
+	d := verifying(t, store, "Shop.Example.com", time.Now())
+	resolver[d.ChallengeName()] = []string{"unrelated", d.ChallengeValue()}
+
+	report, err := db.CheckDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Verified != 1 {
+		t.Fatalf("verified = %d, want 1", report.Verified)
+	}
+	got := store.domains[d.TenantID]
+	if got.Status != StatusVerified || got.VerifiedAt == nil {
+		t.Fatalf("status = %s, verified_at = %v", got.Status, got.VerifiedAt)
+	}
+	if store.served[d.TenantID] != "shop.example.com" {
+		t.Fatalf("served = %q", store.served[d.TenantID])
+	}
+
+	// the next run follows the certificate, not served yet
+	if _, err := db.CheckDomains(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := store.domains[d.TenantID]; got.Status != StatusVerified || got.LastError == "" {
+		t.Fatalf("status = %s, last_error = %q", got.Status, got.LastError)
+	}
+}
+
+func TestCheckDomainsBacksOff(t *testing.T) {
+	db, store := newTestBusiness(t, fakeResolver{}, nil)
+	d := verifying(t, store, "shop.example.com", time.Now())
+
+	if _, err := db.CheckDomains(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := store.domains[d.TenantID]
+	if got.Status != StatusVerifying || got.Attempts != 1 {
+		t.Fatalf("status = %s, attempts = %d", got.Status, got.Attempts)
+	}
+	if wait := time.Until(*got.NextCheckAt); wait < time.Minute || wait > 2*time.Minute {
+		t.Fatalf("next check in %s, want about 2m", wait)
+	}
+	if _, ok := store.served[d.TenantID]; ok {
+		t.Fatal("unverified domain served")
+	}
+}
+
+func TestCheckDomainsFailsAfterWindow(t *testing.T) {
+	resolver := fakeResolver{}
+	db, store := newTestBusiness(t, resolver, nil)
+	d := verifying(t, store, "shop.example.com", time.Now().Add(-2*time.Hour))
+	resolver[d.ChallengeName()] = []string{"merchcore-verification=wrong"}
+
+	report, err := db.CheckDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Failed != 1 {
+		t.Fatalf("failed = %d, want 1", report.Failed)
+	}
+	got := store.domains[d.TenantID]
+	if got.Status != StatusFailed || got.NextCheckAt != nil {
+		t.Fatalf("status = %s, next_check_at = %v", got.Status, got.NextCheckAt)
+	}
+
+	// a failed domain can be tried again
+	again, err := db.StartVerification(context.Background(), d.TenantID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Status != StatusVerifying || again.Attempts != 0 {
+		t.Fatalf("status = %s, attempts = %d", again.Status, again.Attempts)
+	}
+}
+
+func TestCheckDomainsIssuesCertificate(t *testing.T) {
+	expiresAt := time.Now().Add(90 * 24 * time.Hour)
+	db, store := newTestBusiness(t, fakeResolver{}, fakeProber{expiresAt: expiresAt})
+	d := verifying(t, store, "shop.example.com", time.Now())
+	d.Status = StatusVerified
+
+	report, err := db.CheckDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := store.domains[d.TenantID]
+	if report.CertsIssued != 1 || got.Status != StatusCertIssued || !got.CertExpiresAt.Equal(expiresAt) {
+		t.Fatalf("certs = %d, status = %s, expires = %v", report.CertsIssued, got.Status, got.CertExpiresAt)
+	}
+}
+
+func TestCheckDomain(t *testing.T) {
+	for name, ok := range map[string]bool{
+		"shop.example.com":      true,
+		"example.co.uk":         true,
+		"localhost":             false,
+		"shop.merchcore.com":    false,
+		"10.0.0.1":              false,
+		"-bad.example.com":      false,
+		"bad_label.example.com": false,
+	} {
+		if err := checkDomain(name); (err == nil) != ok {
+			t.Errorf("checkDomain(%q) = %v, want ok %v", name, err, ok)
+		}
+	}
+}
+
+func TestCheckDomainsFirstVerifiedWins(t *testing.T) {
+	resolver := fakeResolver{}
+	db, store := newTestBusiness(t, resolver, nil)
+	first := verifying(t, store, "shop.example.com", time.Now())
+	first.Status = StatusVerified
+	first.NextCheckAt = nil
+	squatter := verifying(t, store, "shop.example.com", time.Now())
+	resolver[squatter.ChallengeName()] = []string{squatter.ChallengeValue()}
+
+	report, err := db.CheckDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := store.domains[squatter.TenantID]
+	if report.Failed != 1 || got.Status != StatusFailed || got.LastError != ErrDomainTaken.Error() {
+		t.Fatalf("failed = %d, status = %s, last_error = %q", report.Failed, got.Status, got.LastError)
+	}
+	if _, ok := store.served[squatter.TenantID]; ok {
+		t.Fatal("domain served to a second store")
+	}
+}
+
+func TestCheckDomainsExpiresStaleClaims(t *testing.T) {
+	db, store := newTestBusiness(t, fakeResolver{}, nil)
+	stale := time.Now().Add(-2 * time.Hour)
+	tests := []struct {
+		status    Status
+		updatedAt time.Time
+		kept      bool
+	}{
+		{StatusPending, stale, false},
+		{StatusFailed, stale, false},
+		{StatusPending, time.Now(), true},
+		{StatusVerified, stale, true},
+	}
+	ids := make([]uuid.UUID, len(tests))
+	for i, tt := range tests {
+		d, err := newCustomDomain(uuid.New(), "shop.example.com", "tok")
+		if err != nil {
+			t.Fatal(err)
+		}
+		d.Status, d.UpdatedAt = tt.status, tt.updatedAt
+		store.domains[d.TenantID] = d
+		ids[i] = d.TenantID
+	}
+
+	report, err := db.CheckDomains(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Expired != 2 {
+		t.Fatalf("expired = %d, want 2", report.Expired)
+	}
+	for i, tt := range tests {
+		if _, ok := store.domains[ids[i]]; ok != tt.kept {
+			t.Errorf("%s claim kept = %v, want %v", tt.status, ok, tt.kept)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package customdomain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// ChallengePrefix is the label the TXT challenge is published under, at
+// _merchcore-challenge.<domain>.
+const (
+	ChallengePrefix = "_merchcore-challenge"
+	challengeTag    = "merchcore-verification="
+)
+
+type Status string
+
+var statuses = make(map[string]Status)
+
+func newStatus(v string) Status {
+	s := Status(v)
+	statuses[v] = s
+	return s
+}
+
+// A domain starts pending until the merchant asks for it to be checked.
+// While verifying the worker polls for the TXT challenge. Once verified the
+// store is served on it and the edge may get it a certificate, cert_issued
+// once one is seen served. failed means the challenge never showed up in
+// time, verification can be started again.
+var (
+	StatusPending    = newStatus("pending")
+	StatusVerifying  = newStatus("verifying")
+	StatusVerified   = newStatus("verified")
+	StatusCertIssued = newStatus("cert_issued")
+	StatusFailed     = newStatus("failed")
+)
+
+func ParseStatus(v string) (Status, error) {
+	s, ok := statuses[strings.ToLower(v)]
+	if !ok {
+		return "", fmt.Errorf("invalid domain status: %v", v)
+	}
+	return s, nil
+}
+
+type CustomDomain struct {
+	ID              uuid.UUID
+	TenantID        uuid.UUID
+	Domain          string
+	Status          Status
+	Token           string
+	Attempts        int
+	LastError       string
+	VerifyStartedAt *time.Time
+	NextCheckAt     *time.Time
+	VerifiedAt      *time.Time
+	CertExpiresAt   *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// IsServed reports whether the store answers on the domain.
+func (d CustomDomain) IsServed() bool {
+	return d.Status == StatusVerified || d.Status == StatusCertIssued
+}
+
+// ChallengeName is where the merchant publishes the TXT record.
+func (d CustomDomain) ChallengeName() string {
+	return ChallengePrefix + "." + d.Domain
+}
+
+// ChallengeValue is the TXT record's expected content.
+func (d CustomDomain) ChallengeValue() string {
+	return challengeTag + d.Token
+}
+
+func newCustomDomain(tenantID uuid.UUID, name, token string) (*CustomDomain, error) {
+	domain := tenant.NormalizeHost(name)
+	if err := checkDomain(domain); err != nil {
+		fieldErrs := errs.NewFieldErrors()
+		fieldErrs.AddFieldError("domain", err)
+		return nil, fieldErrs
+	}
+
+	now := time.Now()
+	return &CustomDomain{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Domain:    domain,
+		Status:    StatusPending,
+		Token:     token,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}, nil
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", fmt.Errorf("randread: %w", err)
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// checkDomain accepts a public DNS name the merchant can own, not an IP
+// address and not one of the platform's own names.
+func checkDomain(domain string) error {
+	if domain == "" {
+		return errors.New("domain required")
+	}
+	if len(domain) > 253 {
+		return errors.New("cannot be more than 253 characters")
+	}
+	if domain == tenant.PlatformDomain || strings.HasSuffix(domain, "."+tenant.PlatformDomain) {
+		return fmt.Errorf("cannot be a %s domain, use the store subdomain", tenant.PlatformDomain)
+	}
+
+	labels := strings.Split(domain, ".")
+	if len(labels) < 2 {
+		return errors.New("must be a fully qualified domain name")
+	}
+	for _, label := range labels {
+		if !validLabel(label) {
+			return fmt.Errorf("invalid label %q", label)
+		}
+	}
+	if strings.Trim(labels[len(labels)-1], "0123456789") == "" {
+		return errors.New("must be a domain name, not an address")
+	}
+	return nil
+}
+
+func validLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// CheckReport is what one polling run did.
+type CheckReport struct {
+	Checked     int
+	Verified    int
+	Failed      int
+	CertsIssued int
+	// Expired counts pending and failed claims dropped, VerifyWindow after
+	// they were last touched.
+	Expired int64
+}
@@ -0,0 +1,222 @@
+package customdomaindb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type domainStore struct {
+	conn database.DBTX
+}
+
+var _ customdomain.Repository = (*domainStore)(nil)
+
+func NewDomainStore(conn *pgxpool.Pool) *domainStore {
+	return &domainStore{conn: conn}
+}
+
+const domainColumns = `id, tenant_id, domain, status, token, attempts, COALESCE(last_error, ''),
+		       verify_started_at, next_check_at, verified_at, cert_expires_at, created_at, updated_at`
+
+func scanDomain(row pgx.Row) (customdomain.CustomDomain, error) {
+	var (
+		d      customdomain.CustomDomain
+		status string
+	)
+	err := row.Scan(
+		&d.ID,
+		&d.TenantID,
+		&d.Domain,
+		&status,
+		&d.Token,
+		&d.Attempts,
+		&d.LastError,
+		&d.VerifyStartedAt,
+		&d.NextCheckAt,
+		&d.VerifiedAt,
+		&d.CertExpiresAt,
+		&d.CreatedAt,
+		&d.UpdatedAt,
+	)
+	if err != nil {
+		return d, err
+	}
+	d.Status = customdomain.Status(status)
+	return d, nil
+}
+
+func (ds *domainStore) CreateDomain(ctx context.Context, d *customdomain.CustomDomain) error {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `
+		INSERT INTO tenant_domains (id, tenant_id, domain, status, token, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	_, err := conn.Exec(ctx, query, d.ID, d.TenantID, d.Domain, d.Status, d.Token, d.CreatedAt, d.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "tenant_domains_tenant_uq" {
+			return customdomain.ErrDomainExists
+		}
+		return fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (ds *domainStore) GetDomain(ctx context.Context, tenantID uuid.UUID) (*customdomain.CustomDomain, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `SELECT ` + domainColumns + ` FROM tenant_domains WHERE tenant_id = $1`
+	d, err := scanDomain(conn.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customdomain.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return &d, nil
+}
+
+func (ds *domainStore) GetDomainByName(ctx context.Context, domain string) (*customdomain.CustomDomain, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `
+		SELECT ` + domainColumns + ` FROM tenant_domains
+		WHERE domain = $1
+		ORDER BY status IN ('verified', 'cert_issued') DESC, created_at
+		LIMIT 1
+	`
+	d, err := scanDomain(conn.QueryRow(ctx, query, domain))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customdomain.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return &d, nil
+}
+
+func (ds *domainStore) UpdateDomain(ctx context.Context, d *customdomain.CustomDomain) error {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `
+		UPDATE tenant_domains
+		SET status = $2, attempts = $3, last_error = NULLIF($4, ''), verify_started_at = $5,
+		    next_check_at = $6, verified_at = $7, cert_expires_at = $8, updated_at = $9
+		WHERE id = $1
+	`
+	res, err := conn.Exec(ctx, query,
+		d.ID,
+		d.Status,
+		d.Attempts,
+		d.LastError,
+		d.VerifyStartedAt,
+		d.NextCheckAt,
+		d.VerifiedAt,
+		d.CertExpiresAt,
+		d.UpdatedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == "tenant_domains_verified_uq" {
+			return customdomain.ErrDomainTaken
+		}
+		return fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return customdomain.ErrDomainNotFound
+	}
+	return nil
+}
+
+func (ds *domainStore) DeleteDomain(ctx context.Context, tenantID uuid.UUID) (*customdomain.CustomDomain, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `DELETE FROM tenant_domains WHERE tenant_id = $1 RETURNING ` + domainColumns
+	d, err := scanDomain(conn.QueryRow(ctx, query, tenantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, customdomain.ErrDomainNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return &d, nil
+}
+
+func (ds *domainStore) DeleteStaleClaims(ctx context.Context, before time.Time) (int64, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `DELETE FROM tenant_domains WHERE status IN ('pending', 'failed') AND updated_at < $1`
+	res, err := conn.Exec(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return res.RowsAffected(), nil
+}
+
+func (ds *domainStore) ClaimDueDomains(ctx context.Context, limit int, lease time.Duration) ([]customdomain.CustomDomain, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `
+		UPDATE tenant_domains
+		SET next_check_at = now() + $2::interval
+		WHERE id IN (
+			SELECT id FROM tenant_domains
+			WHERE next_check_at <= now()
+			  AND status IN ('verifying', 'verified', 'cert_issued')
+			ORDER BY next_check_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + domainColumns
+	rows, err := conn.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+
+	domains, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (customdomain.CustomDomain, error) {
+		return scanDomain(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return domains, nil
+}
+
+func (ds *domainStore) IsHostTaken(ctx context.Context, host string) (bool, error) {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `SELECT EXISTS (SELECT 1 FROM tenants WHERE domain = $1 OR subdomain = $1)`
+	var taken bool
+	if err := conn.QueryRow(ctx, query, host).Scan(&taken); err != nil {
+		return false, fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	return taken, nil
+}
+
+func (ds *domainStore) SetServedDomain(ctx context.Context, tenantID uuid.UUID, domain string) error {
+	conn := database.GetTXFromContext(ctx, ds.conn)
+
+	const query = `
+		UPDATE tenants
+		SET domain = COALESCE(NULLIF($2, ''), subdomain), updated_at = now()
+		WHERE id = $1
+	`
+	res, err := conn.Exec(ctx, query, tenantID, domain)
+	if err != nil {
+		return fmt.Errorf("%w: %w", customdomain.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return customdomain.ErrDomainNotFound
+	}
+	return nil
+}
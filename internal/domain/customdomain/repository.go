@@ -0,0 +1,55 @@
+package customdomain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDatabase       = errors.New("database error")
+	ErrDomainNotFound = errors.New("custom domain not found")
+	ErrDomainExists   = errors.New("store already has a custom domain")
+	ErrDomainTaken    = errors.New("domain is already in use")
+	ErrDomainVerified = errors.New("domain is already verified")
+)
+
+type Repository interface {
+	// CreateDomain reports ErrDomainExists when the store has one already.
+	// Several stores may claim a name, the first to verify it gets it.
+	CreateDomain(ctx context.Context, d *CustomDomain) error
+	GetDomain(ctx context.Context, tenantID uuid.UUID) (*CustomDomain, error)
+	// GetDomainByName returns the verified claim on domain, or the oldest
+	// one while none is.
+	GetDomainByName(ctx context.Context, domain string) (*CustomDomain, error)
+	// UpdateDomain reports ErrDomainTaken when d is verified for a name
+	// another store verified first.
+	UpdateDomain(ctx context.Context, d *CustomDomain) error
+	DeleteDomain(ctx context.Context, tenantID uuid.UUID) (*CustomDomain, error)
+	// DeleteStaleClaims drops pending and failed domains not touched since
+	// before and returns how many.
+	DeleteStaleClaims(ctx context.Context, before time.Time) (int64, error)
+	// ClaimDueDomains returns up to limit domains whose next check is due and
+	// pushes that check lease into the future, so concurrent workers never
+	// get the same domain.
+	ClaimDueDomains(ctx context.Context, limit int, lease time.Duration) ([]CustomDomain, error)
+	// IsHostTaken reports whether a store uses host as its domain or
+	// subdomain.
+	IsHostTaken(ctx context.Context, host string) (bool, error)
+	// SetServedDomain points the store's primary domain at domain, or back
+	// at its subdomain when domain is empty.
+	SetServedDomain(ctx context.Context, tenantID uuid.UUID, domain string) error
+}
+
+// Resolver looks up DNS TXT records, *net.Resolver satisfies it.
+type Resolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// CertProber reports when the certificate served for a domain expires. It
+// fails while no valid certificate for it is served.
+type CertProber interface {
+	ProbeCertificate(ctx context.Context, domain string) (time.Time, error)
+}
@@ -0,0 +1,34 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// ListInstalledApps returns the apps currently installed in the store.
+func (ob *OAuthBusiness) ListInstalledApps(ctx context.Context, tenantID uuid.UUID) ([]Grant, error) {
+	grants, err := ob.storer.ListGrants(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listgrants: %w", err)
+	}
+	return grants, nil
+}
+
+// UninstallApp lets the merchant cut an app off, whoever installed it.
+func (ob *OAuthBusiness) UninstallApp(ctx context.Context, tenantID, grantID uuid.UUID) error {
+	grant, err := ob.storer.GetGrant(ctx, grantID)
+	if err != nil {
+		if errors.Is(err, ErrGrantNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("getgrant: %w", err)
+	}
+	if grant.TenantID != tenantID || !grant.IsActive() {
+		return errs.NewDomainError(errs.NotFound, ErrGrantNotFound)
+	}
+	return ob.revokeGrant(ctx, grant, "app.uninstall")
+}
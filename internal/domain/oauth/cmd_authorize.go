@@ -0,0 +1,199 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// PKCE S256 is the only method accepted, plain would let a stolen code be
+// redeemed by whoever saw the authorization request.
+const challengeMethodS256 = "S256"
+
+// Consent checks an authorization request and returns what the user is
+// asked to approve. Without a store picked yet only the request itself is
+// checked, with one the user must be allowed to install apps there and hold
+// every scope requested.
+func (ob *OAuthBusiness) Consent(ctx context.Context, userID uuid.UUID, platformRole string, req AuthorizeRequest) (*Consent, error) {
+	clientID, err := uuid.Parse(req.ClientID)
+	if err != nil {
+		return nil, invalidRequest("unknown client_id")
+	}
+	client, err := ob.storer.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, invalidRequest("unknown client_id")
+		}
+		return nil, fmt.Errorf("getclient: %w", err)
+	}
+	if !client.IsActive() {
+		return nil, invalidRequest("unknown client_id")
+	}
+
+	redirectURI := req.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, invalidRequest("redirect_uri is not registered for this client")
+	}
+
+	if req.ResponseType != "code" {
+		return nil, protocolError(errs.InvalidArgument, CodeUnsupportedResponseType, "response_type must be code")
+	}
+	if req.CodeChallengeMethod != challengeMethodS256 {
+		return nil, invalidRequest("code_challenge_method must be S256")
+	}
+	if !validChallenge(req.CodeChallenge) {
+		return nil, invalidRequest("code_challenge must be the base64url encoded SHA-256 of the code verifier")
+	}
+
+	scopes := permission.NewSet().Union(client.Scopes)
+	if fields := strings.Fields(req.Scope); len(fields) > 0 {
+		scopes, err = parseScopes(fields)
+		if err != nil {
+			return nil, protocolError(errs.InvalidArgument, CodeInvalidScope, err.Error())
+		}
+		for p := range scopes {
+			if !client.Scopes.Has(p) {
+				return nil, protocolError(errs.InvalidArgument, CodeInvalidScope, fmt.Sprintf("client is not registered for %s", p))
+			}
+		}
+	}
+
+	if req.TenantID != uuid.Nil {
+		held, err := ob.perms.EffectivePermissions(ctx, req.TenantID, userID, platformRole)
+		if err != nil {
+			return nil, err
+		}
+		if !held.Has(permission.AppsManage) {
+			return nil, errs.NewDomainError(errs.PermissionDenied, errors.New("not allowed to install apps in this store"))
+		}
+		for p := range scopes {
+			if !held.Has(p) {
+				return nil, errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("cannot grant %s", p))
+			}
+		}
+	}
+
+	return &Consent{
+		Client:      client,
+		TenantID:    req.TenantID,
+		Scopes:      scopes,
+		RedirectURI: redirectURI,
+		State:       req.State,
+	}, nil
+}
+
+// Authorize records the user's decision on the consent screen and returns
+// where to send the browser: back to the app with a code, or with
+// access_denied when the user declined.
+func (ob *OAuthBusiness) Authorize(ctx context.Context, userID uuid.UUID, platformRole string, req AuthorizeRequest, approved bool) (string, error) {
+	if req.TenantID == uuid.Nil {
+		return "", invalidRequest("tenant_id is required")
+	}
+	consent, err := ob.Consent(ctx, userID, platformRole, req)
+	if err != nil {
+		return "", err
+	}
+
+	if !approved {
+		return redirectWith(consent.RedirectURI, url.Values{
+			"error": {CodeAccessDenied},
+			"state": {consent.State},
+		})
+	}
+
+	code, hash, err := newCode()
+	if err != nil {
+		return "", err
+	}
+	err = ob.storer.CreateAuthCode(ctx, &AuthCode{
+		CodeHash:      hash,
+		ClientID:      consent.Client.ID,
+		UserID:        userID,
+		TenantID:      consent.TenantID,
+		RedirectURI:   consent.RedirectURI,
+		Scopes:        consent.Scopes,
+		CodeChallenge: req.CodeChallenge,
+		Expiry:        time.Now().Add(ob.config.OAuth.CodeLifeTime),
+	})
+	if err != nil {
+		return "", fmt.Errorf("createauthcode: %w", err)
+	}
+
+	return redirectWith(consent.RedirectURI, url.Values{
+		"code":  {code},
+		"state": {consent.State},
+	})
+}
+
+// redirectWith adds params to the query of a registered redirect URI,
+// keeping the query it was registered with.
+func redirectWith(redirectURI string, params url.Values) (string, error) {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return "", fmt.Errorf("parseredirecturi: %w", err)
+	}
+	q := u.Query()
+	for k, v := range params {
+		if len(v) > 0 && v[0] != "" {
+			q[k] = v
+		}
+	}
+	u.RawQuery = q.Encode()
+	return u.String(), nil
+}
+
+func newCode() (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("newcode: %w", err)
+	}
+	code := base64.RawURLEncoding.EncodeToString(b)
+	hash := sha256.Sum256([]byte(code))
+	return code, hash[:], nil
+}
+
+// validChallenge accepts what S256 produces, 32 bytes in unpadded base64url.
+func validChallenge(challenge string) bool {
+	b, err := base64.RawURLEncoding.DecodeString(challenge)
+	return err == nil && len(b) == sha256.Size
+}
+
+// verifyPKCE checks verifier against the challenge the flow was opened with
+// (RFC 7636 section 4.6).
+func verifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+	for _, r := range verifier {
+		if !isUnreserved(r) {
+			return false
+		}
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	computed := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(computed), []byte(challenge)) == 1
+}
+
+func isUnreserved(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return true
+	case r == '-', r == '.', r == '_', r == '~':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+package oauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/cache"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// secrets handed out start with a tag so leaks are easy to spot
+const (
+	clientSecretTag = "mcs"
+	refreshTokenTag = "mcr"
+)
+
+var secretEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// PermissionResolver tells what a user may do in a store, implemented by
+// the tenant business.
+type PermissionResolver interface {
+	EffectivePermissions(ctx context.Context, tenantID, userID uuid.UUID, platformRole string) (permission.Set, error)
+}
+
+type OAuthBusiness struct {
+	storer  Repository
+	trx     database.TransactorTX
+	tokens  authz.TokenMaker
+	perms   PermissionResolver
+	cache   cache.Cache
+	auditor audit.Recorder
+	config  *config.Config
+}
+
+type OAuthBusinessCfg func(ob *OAuthBusiness) error
+
+func NewOAuthBusiness(cfgs ...OAuthBusinessCfg) (*OAuthBusiness, error) {
+	ob := &OAuthBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(ob); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case ob.storer == nil:
+		return nil, errors.New("oauth repository is required")
+	case ob.trx == nil:
+		return nil, errors.New("transaction manager is required")
+	case ob.tokens == nil:
+		return nil, errors.New("token maker is required")
+	case ob.perms == nil:
+		return nil, errors.New("permission resolver is required")
+	case ob.cache == nil:
+		return nil, errors.New("cache is required")
+	case ob.config == nil:
+		return nil, errors.New("config is required")
+	}
+	return ob, nil
+}
+
+func WithOAuthRepository(st Repository) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.storer = st
+		return nil
+	}
+}
+
+func WithTransactor(trx database.TransactorTX) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.trx = trx
+		return nil
+	}
+}
+
+func WithTokenMaker(maker authz.TokenMaker) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.tokens = maker
+		return nil
+	}
+}
+
+func WithPermissionResolver(perms PermissionResolver) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.perms = perms
+		return nil
+	}
+}
+
+func WithCache(c cache.Cache) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.cache = c
+		return nil
+	}
+}
+
+func WithAuditor(auditor audit.Recorder) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.auditor = auditor
+		return nil
+	}
+}
+
+func WithConfigs(cfg *config.Config) OAuthBusinessCfg {
+	return func(ob *OAuthBusiness) error {
+		ob.config = cfg
+		return nil
+	}
+}
+
+// recordAudit writes e within the transaction on ctx. Without an auditor
+// configured nothing is recorded.
+func (ob *OAuthBusiness) recordAudit(ctx context.Context, e audit.Entry) error {
+	if ob.auditor == nil {
+		return nil
+	}
+	return ob.auditor.Record(ctx, e)
+}
+
+// RegisterClient registers an app for ownerID. Confidential clients get a
+// secret that is only returned here.
+func (ob *OAuthBusiness) RegisterClient(ctx context.Context, ownerID uuid.UUID, cc ClientCreate) (*Client, error) {
+	client, err := newClient(ownerID, cc)
+	if err != nil {
+		return nil, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+	if !cc.Public {
+		client.Secret, client.SecretHash, err = newSecret(clientSecretTag)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = ob.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ob.storer.CreateClient(ctx, client); err != nil {
+			return err
+		}
+		return ob.recordAudit(ctx, audit.Entry{
+			ActorID:    &ownerID,
+			Action:     "oauth_client.create",
+			TargetType: "oauth_client",
+			TargetID:   client.ID.String(),
+			After: map[string]any{
+				"name":          client.Name,
+				"redirect_uris": client.RedirectURIs,
+				"scopes":        client.Scopes.Strings(),
+				"confidential":  client.IsConfidential(),
+			},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("registerclient-trx: %w", err)
+	}
+	return client, nil
+}
+
+func (ob *OAuthBusiness) ListClients(ctx context.Context, ownerID uuid.UUID) ([]Client, error) {
+	clients, err := ob.storer.ListClients(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("listclients: %w", err)
+	}
+	return clients, nil
+}
+
+// DeleteClient retires the app and uninstalls it from every store.
+func (ob *OAuthBusiness) DeleteClient(ctx context.Context, ownerID, clientID uuid.UUID) error {
+	var grants []uuid.UUID
+	err := ob.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ob.storer.RevokeClient(ctx, ownerID, clientID); err != nil {
+			return err
+		}
+		var err error
+		if grants, err = ob.storer.RevokeClientGrants(ctx, clientID); err != nil {
+			return err
+		}
+		return ob.recordAudit(ctx, audit.Entry{
+			ActorID:    &ownerID,
+			Action:     "oauth_client.delete",
+			TargetType: "oauth_client",
+			TargetID:   clientID.String(),
+			After:      map[string]any{"uninstalled": len(grants)},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("deleteclient-trx: %w", err)
+	}
+	return ob.endSessions(ctx, grants...)
+}
+
+// authenticateClient checks the credentials of the client calling a token
+// endpoint. Public clients have nothing to check beyond their ID.
+func (ob *OAuthBusiness) authenticateClient(ctx context.Context, creds ClientCredentials) (*Client, error) {
+	clientID, err := uuid.Parse(creds.ClientID)
+	if err != nil {
+		return nil, errInvalidClient
+	}
+	client, err := ob.storer.GetClient(ctx, clientID)
+	if err != nil {
+		if errors.Is(err, ErrClientNotFound) {
+			return nil, errInvalidClient
+		}
+		return nil, fmt.Errorf("getclient: %w", err)
+	}
+	if !client.IsActive() {
+		return nil, errInvalidClient
+	}
+	if client.IsConfidential() && !matchSecret(creds.Secret, client.SecretHash) {
+		return nil, errInvalidClient
+	}
+	return client, nil
+}
+
+// endSessions makes the auth middleware refuse access tokens already issued
+// under grants, they are otherwise valid until they expire.
+func (ob *OAuthBusiness) endSessions(ctx context.Context, grants ...uuid.UUID) error {
+	for _, id := range grants {
+		err := ob.cache.Set(ctx, users.RevokedSession(id), true, ob.config.OAuth.AccessTokenLifeTime)
+		if err != nil {
+			return fmt.Errorf("setcache: %w", err)
+		}
+	}
+	return nil
+}
+
+// newSecret returns a tagged random secret and the hash kept of it.
+func newSecret(tag string) (string, []byte, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("newsecret: %w", err)
+	}
+	secret := tag + "_" + strings.ToLower(secretEncoding.EncodeToString(b))
+	hash := sha256.Sum256([]byte(secret))
+	return secret, hash[:], nil
+}
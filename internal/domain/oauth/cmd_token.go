@@ -0,0 +1,372 @@
+package oauth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	GrantAuthorizationCode = "authorization_code"
+	GrantRefreshToken      = "refresh_token"
+
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// Token serves the token endpoint: a code is exchanged once for the first
+// pair, a refresh token is rotated for the next.
+func (ob *OAuthBusiness) Token(ctx context.Context, req TokenRequest) (*Tokens, error) {
+	client, err := ob.authenticateClient(ctx, req.Client)
+	if err != nil {
+		return nil, err
+	}
+
+	switch req.GrantType {
+	case GrantAuthorizationCode:
+		return ob.exchangeCode(ctx, client, req)
+	case GrantRefreshToken:
+		return ob.refresh(ctx, client, req.RefreshToken)
+	case "":
+		return nil, invalidRequest("grant_type is required")
+	default:
+		return nil, protocolError(errs.InvalidArgument, CodeUnsupportedGrantType, fmt.Sprintf("grant_type %q is not supported", req.GrantType))
+	}
+}
+
+// exchangeCode installs the app in the store the code was issued for. The
+// code is spent before anything else is checked so it cannot be retried.
+func (ob *OAuthBusiness) exchangeCode(ctx context.Context, client *Client, req TokenRequest) (*Tokens, error) {
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, invalidRequest("code and code_verifier are required")
+	}
+
+	code, err := ob.storer.ConsumeAuthCode(ctx, hashToken(req.Code))
+	if err != nil {
+		if errors.Is(err, ErrAuthCodeNotFound) {
+			return nil, invalidGrant("invalid or expired authorization code")
+		}
+		return nil, fmt.Errorf("consumeauthcode: %w", err)
+	}
+	switch {
+	case code.ClientID != client.ID, time.Now().After(code.Expiry):
+		return nil, invalidGrant("invalid or expired authorization code")
+	case code.RedirectURI != req.RedirectURI:
+		return nil, invalidGrant("redirect_uri does not match the authorization request")
+	case !verifyPKCE(req.CodeVerifier, code.CodeChallenge):
+		return nil, invalidGrant("code_verifier does not match the code_challenge")
+	}
+
+	grant := &Grant{
+		ID:         uuid.New(),
+		ClientID:   client.ID,
+		ClientName: client.Name,
+		TenantID:   code.TenantID,
+		UserID:     code.UserID,
+		Scopes:     code.Scopes,
+	}
+	var tokens *Tokens
+	err = ob.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ob.storer.CreateGrant(ctx, grant); err != nil {
+			return err
+		}
+		if err := ob.recordAudit(ctx, audit.Entry{
+			TenantID:   &grant.TenantID,
+			ActorID:    &grant.UserID,
+			Action:     "app.install",
+			TargetType: "app",
+			TargetID:   grant.ID.String(),
+			After: map[string]any{
+				"client_id": client.ID,
+				"name":      client.Name,
+				"scopes":    grant.Scopes.Strings(),
+			},
+		}); err != nil {
+			return err
+		}
+
+		var err error
+		tokens, err = ob.issue(ctx, grant, grant.Scopes)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("exchangecode-trx: %w", err)
+	}
+	return tokens, nil
+}
+
+// refresh rotates a refresh token. The new access token carries the granted
+// scopes the installing user still holds, an installer who left the store
+// takes the app's access with them.
+func (ob *OAuthBusiness) refresh(ctx context.Context, client *Client, raw string) (*Tokens, error) {
+	if raw == "" {
+		return nil, invalidRequest("refresh_token is required")
+	}
+	invalid := invalidGrant("invalid or expired refresh token")
+
+	hash := hashToken(raw)
+	rt, err := ob.storer.GetRefreshToken(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("getrefreshtoken: %w", err)
+	}
+	grant, err := ob.storer.GetGrant(ctx, rt.GrantID)
+	if err != nil {
+		if errors.Is(err, ErrGrantNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("getgrant: %w", err)
+	}
+	if grant.ClientID != client.ID || !grant.IsActive() || time.Now().After(rt.Expiry) {
+		return nil, invalid
+	}
+	if rt.UsedAt != nil {
+		return nil, ob.revokeReused(ctx, grant)
+	}
+
+	held, err := ob.perms.EffectivePermissions(ctx, grant.TenantID, grant.UserID, "")
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok && derr.Code == errs.NotFound {
+			if err := ob.revokeGrant(ctx, grant, "app.installer_gone"); err != nil {
+				return nil, err
+			}
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("effectivepermissions: %w", err)
+	}
+	scopes := permission.NewSet()
+	for p := range grant.Scopes {
+		if held.Has(p) {
+			scopes.Add(p)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, invalidGrant("the installing user no longer holds any of the granted scopes")
+	}
+
+	var tokens *Tokens
+	err = ob.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ob.storer.MarkRefreshTokenUsed(ctx, hash); err != nil {
+			return err
+		}
+		var err error
+		tokens, err = ob.issue(ctx, grant, scopes)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenUsed) {
+			return nil, ob.revokeReused(ctx, grant)
+		}
+		return nil, fmt.Errorf("refresh-trx: %w", err)
+	}
+	return tokens, nil
+}
+
+// issue signs an access token for the grant and stores a new refresh token.
+// The grant ID is the session of the access token so revoking the grant
+// ends it early.
+func (ob *OAuthBusiness) issue(ctx context.Context, grant *Grant, scopes permission.Set) (*Tokens, error) {
+	access, payload, err := ob.tokens.GenerateToken(authz.JWTData{
+		UserID:      grant.UserID,
+		Duration:    ob.config.OAuth.AccessTokenLifeTime,
+		ServiceName: ob.config.Observability.ServiceName,
+		SessionID:   grant.ID,
+		TenantID:    grant.TenantID,
+		ClientID:    grant.ClientID,
+		Scopes:      scopes.Strings(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	refresh, hash, err := newSecret(refreshTokenTag)
+	if err != nil {
+		return nil, err
+	}
+	err = ob.storer.CreateRefreshToken(ctx, &RefreshToken{
+		TokenHash: hash,
+		GrantID:   grant.ID,
+		Expiry:    time.Now().Add(ob.config.OAuth.RefreshTokenLifeTime),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("createrefreshtoken: %w", err)
+	}
+
+	return &Tokens{
+		AccessToken:  access,
+		ExpiresAt:    payload.ExpiresAt.Time,
+		RefreshToken: refresh,
+		Scopes:       scopes,
+		TenantID:     grant.TenantID,
+	}, nil
+}
+
+// revokeReused ends a grant whose refresh token was presented twice, one of
+// the two callers is not the app.
+func (ob *OAuthBusiness) revokeReused(ctx context.Context, grant *Grant) error {
+	if err := ob.revokeGrant(ctx, grant, "app.token_reuse"); err != nil {
+		return err
+	}
+	return invalidGrant("refresh token was already used, the installation has been revoked")
+}
+
+func (ob *OAuthBusiness) revokeGrant(ctx context.Context, grant *Grant, action string) error {
+	err := ob.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := ob.storer.RevokeGrant(ctx, grant.ID); err != nil {
+			return err
+		}
+		return ob.recordAudit(ctx, audit.Entry{
+			TenantID:   &grant.TenantID,
+			Action:     action,
+			TargetType: "app",
+			TargetID:   grant.ID.String(),
+			Before: map[string]any{
+				"client_id": grant.ClientID,
+				"scopes":    grant.Scopes.Strings(),
+			},
+		})
+	})
+	if err != nil && !errors.Is(err, ErrGrantNotFound) {
+		return fmt.Errorf("revokegrant-trx: %w", err)
+	}
+	return ob.endSessions(ctx, grant.ID)
+}
+
+// Introspect describes a token issued to the calling client (RFC 7662).
+// Tokens of other clients, unknown, expired or revoked ones are inactive.
+func (ob *OAuthBusiness) Introspect(ctx context.Context, creds ClientCredentials, token string) (*Introspection, error) {
+	client, err := ob.authenticateClient(ctx, creds)
+	if err != nil {
+		return nil, err
+	}
+	inactive := &Introspection{}
+
+	if strings.HasPrefix(token, refreshTokenTag+"_") {
+		rt, grant, err := ob.refreshGrant(ctx, client, token)
+		if err != nil {
+			return nil, err
+		}
+		if grant == nil || !grant.IsActive() || rt.UsedAt != nil || time.Now().After(rt.Expiry) {
+			return inactive, nil
+		}
+		return &Introspection{
+			Active:    true,
+			TokenType: TokenTypeRefresh,
+			Scopes:    grant.Scopes,
+			ClientID:  grant.ClientID,
+			UserID:    grant.UserID,
+			TenantID:  grant.TenantID,
+			ExpiresAt: rt.Expiry,
+		}, nil
+	}
+
+	payload, grant, err := ob.accessGrant(ctx, client, token)
+	if err != nil {
+		return nil, err
+	}
+	if grant == nil || !grant.IsActive() {
+		return inactive, nil
+	}
+	scopes := permission.NewSet()
+	for _, v := range strings.Fields(payload.Scope) {
+		if p, err := permission.Parse(v); err == nil {
+			scopes.Add(p)
+		}
+	}
+	return &Introspection{
+		Active:    true,
+		TokenType: TokenTypeAccess,
+		Scopes:    scopes,
+		ClientID:  payload.ClientID,
+		UserID:    payload.UserID,
+		TenantID:  payload.TenantID,
+		IssuedAt:  payload.IssuedAt.Time,
+		ExpiresAt: payload.ExpiresAt.Time,
+	}, nil
+}
+
+// Revoke uninstalls the app from the store a token of the calling client
+// was issued for (RFC 7009). Unknown tokens are not an error.
+func (ob *OAuthBusiness) Revoke(ctx context.Context, creds ClientCredentials, token string) error {
+	client, err := ob.authenticateClient(ctx, creds)
+	if err != nil {
+		return err
+	}
+
+	var grant *Grant
+	if strings.HasPrefix(token, refreshTokenTag+"_") {
+		_, grant, err = ob.refreshGrant(ctx, client, token)
+	} else {
+		_, grant, err = ob.accessGrant(ctx, client, token)
+	}
+	if err != nil {
+		return err
+	}
+	if grant == nil || !grant.IsActive() {
+		return nil
+	}
+	return ob.revokeGrant(ctx, grant, "app.revoke")
+}
+
+// refreshGrant finds the grant of a refresh token issued to client, a nil
+// grant when there is none.
+func (ob *OAuthBusiness) refreshGrant(ctx context.Context, client *Client, token string) (*RefreshToken, *Grant, error) {
+	rt, err := ob.storer.GetRefreshToken(ctx, hashToken(token))
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("getrefreshtoken: %w", err)
+	}
+	grant, err := ob.clientGrant(ctx, client, rt.GrantID)
+	return rt, grant, err
+}
+
+// accessGrant finds the grant of a live access token issued to client, a
+// nil grant when there is none.
+func (ob *OAuthBusiness) accessGrant(ctx context.Context, client *Client, token string) (*authz.Payload, *Grant, error) {
+	payload, err := ob.tokens.VerifyToken(token)
+	if err != nil || !payload.IsApp() || payload.ClientID != client.ID {
+		return nil, nil, nil
+	}
+	grant, err := ob.clientGrant(ctx, client, payload.SessionID)
+	return payload, grant, err
+}
+
+func (ob *OAuthBusiness) clientGrant(ctx context.Context, client *Client, grantID uuid.UUID) (*Grant, error) {
+	grant, err := ob.storer.GetGrant(ctx, grantID)
+	if err != nil {
+		if errors.Is(err, ErrGrantNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getgrant: %w", err)
+	}
+	if grant.ClientID != client.ID {
+		return nil, nil
+	}
+	return grant, nil
+}
+
+func hashToken(token string) []byte {
+	sum := sha256.Sum256([]byte(token))
+	return sum[:]
+}
+
+func matchSecret(secret string, hash []byte) bool {
+	if secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare(hashToken(secret), hash) == 1
+}
@@ -0,0 +1,37 @@
+package oauth
+
+import "github.com/iamonah/merchcore/internal/sdk/errs"
+
+// OAuth2 error codes, RFC 6749 sections 4.1.2.1 and 5.2.
+const (
+	CodeInvalidRequest          = "invalid_request"
+	CodeInvalidClient           = "invalid_client"
+	CodeInvalidGrant            = "invalid_grant"
+	CodeInvalidScope            = "invalid_scope"
+	CodeUnsupportedGrantType    = "unsupported_grant_type"
+	CodeUnsupportedResponseType = "unsupported_response_type"
+	CodeAccessDenied            = "access_denied"
+)
+
+// Error is an OAuth2 error response. The business returns it inside an
+// errs.DomainError, the token endpoints answer with it as is.
+type Error struct {
+	Code        string
+	Description string
+}
+
+func (e *Error) Error() string { return e.Code + ": " + e.Description }
+
+func protocolError(code errs.ErrCode, oauthCode, description string) error {
+	return errs.NewDomainError(code, &Error{Code: oauthCode, Description: description})
+}
+
+func invalidRequest(description string) error {
+	return protocolError(errs.InvalidArgument, CodeInvalidRequest, description)
+}
+
+func invalidGrant(description string) error {
+	return protocolError(errs.InvalidArgument, CodeInvalidGrant, description)
+}
+
+var errInvalidClient = protocolError(errs.Unauthenticated, CodeInvalidClient, "client authentication failed")
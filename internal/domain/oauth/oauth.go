@@ -0,0 +1,240 @@
+package oauth
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// Client is an app registered by an outside developer. Public clients (SPAs,
+// mobile apps) have no secret and rely on PKCE alone. Secret is only set when
+// the client is created.
+type Client struct {
+	ID           uuid.UUID
+	OwnerID      uuid.UUID
+	Name         string
+	RedirectURIs []string
+	Scopes       permission.Set
+	SecretHash   []byte
+	Secret       string
+	CreatedAt    time.Time
+	RevokedAt    *time.Time
+}
+
+func (c *Client) IsConfidential() bool { return len(c.SecretHash) > 0 }
+
+func (c *Client) IsActive() bool { return c.RevokedAt == nil }
+
+// HasRedirectURI matches uri exactly against the registered ones, no prefix
+// or wildcard matching.
+func (c *Client) HasRedirectURI(uri string) bool {
+	for _, u := range c.RedirectURIs {
+		if u == uri {
+			return true
+		}
+	}
+	return false
+}
+
+type ClientCreate struct {
+	Name         string
+	RedirectURIs []string
+	Scopes       []string
+	Public       bool
+}
+
+const (
+	maxClientName   = 100
+	maxRedirectURIs = 10
+)
+
+// newClient checks what the developer asked for. Scopes are the most the app
+// may ever request, merchants can grant it less.
+func newClient(ownerID uuid.UUID, cc ClientCreate) (*Client, error) {
+	fe := errs.NewFieldErrors()
+
+	name := strings.TrimSpace(cc.Name)
+	switch {
+	case name == "":
+		fe.AddFieldError("name", errors.New("name required"))
+	case utf8.RuneCountInString(name) > maxClientName:
+		fe.AddFieldError("name", fmt.Errorf("cannot be more than %d characters", maxClientName))
+	}
+
+	switch {
+	case len(cc.RedirectURIs) == 0:
+		fe.AddFieldError("redirect_uris", errors.New("at least one redirect uri required"))
+	case len(cc.RedirectURIs) > maxRedirectURIs:
+		fe.AddFieldError("redirect_uris", fmt.Errorf("cannot be more than %d", maxRedirectURIs))
+	}
+	for _, uri := range cc.RedirectURIs {
+		if err := checkRedirectURI(uri); err != nil {
+			fe.AddFieldError("redirect_uris", err)
+		}
+	}
+
+	scopes, err := parseScopes(cc.Scopes)
+	if err != nil {
+		fe.AddFieldError("scopes", err)
+	} else if len(scopes) == 0 {
+		fe.AddFieldError("scopes", errors.New("at least one scope required"))
+	}
+
+	if err := fe.ToError(); err != nil {
+		return nil, err
+	}
+	return &Client{
+		ID:           uuid.New(),
+		OwnerID:      ownerID,
+		Name:         name,
+		RedirectURIs: cc.RedirectURIs,
+		Scopes:       scopes,
+	}, nil
+}
+
+// checkRedirectURI wants an absolute https URI without a fragment. Plain
+// http is only allowed on loopback for local development (RFC 8252).
+func checkRedirectURI(uri string) error {
+	u, err := url.Parse(uri)
+	if err != nil || !u.IsAbs() || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute uri", uri)
+	}
+	if u.Fragment != "" {
+		return fmt.Errorf("%q cannot have a fragment", uri)
+	}
+	switch u.Scheme {
+	case "https":
+	case "http":
+		host := u.Hostname()
+		if host != "localhost" && host != "127.0.0.1" && host != "::1" {
+			return fmt.Errorf("%q must use https", uri)
+		}
+	default:
+		return fmt.Errorf("%q must use https", uri)
+	}
+	return nil
+}
+
+// parseScopes reads store permissions an app may be granted. Handing out
+// access (api keys, roles, other apps) stays with the merchant.
+func parseScopes(values []string) (permission.Set, error) {
+	scopes := permission.NewSet()
+	for _, v := range values {
+		p, err := permission.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		if !grantable(p) {
+			return nil, fmt.Errorf("%s cannot be granted to an app", p)
+		}
+		scopes.Add(p)
+	}
+	return scopes, nil
+}
+
+func grantable(p permission.Permission) bool {
+	return p.IsStoreScoped() && p != permission.APIKeysManage && p != permission.AppsManage && p != permission.RolesManage
+}
+
+// AuthCode is a one-time code handed to the app through the redirect,
+// bound to the PKCE challenge the app opened the flow with.
+type AuthCode struct {
+	CodeHash      []byte
+	ClientID      uuid.UUID
+	UserID        uuid.UUID
+	TenantID      uuid.UUID
+	RedirectURI   string
+	Scopes        permission.Set
+	CodeChallenge string
+	Expiry        time.Time
+}
+
+// Grant is an app installed into a store by one of its members. Its ID is
+// the session of every token issued under it, revoking it ends them all.
+type Grant struct {
+	ID         uuid.UUID
+	ClientID   uuid.UUID
+	ClientName string
+	TenantID   uuid.UUID
+	UserID     uuid.UUID
+	Scopes     permission.Set
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+}
+
+func (g *Grant) IsActive() bool { return g.RevokedAt == nil }
+
+// RefreshToken is rotated on every use. Presenting one that was already used
+// means it leaked, the whole grant is revoked.
+type RefreshToken struct {
+	TokenHash []byte
+	GrantID   uuid.UUID
+	Expiry    time.Time
+	UsedAt    *time.Time
+}
+
+// AuthorizeRequest is the query of /oauth/authorize plus the store the user
+// picked on the consent screen.
+type AuthorizeRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	TenantID            uuid.UUID
+}
+
+// Consent is what the consent screen shows before the user approves.
+type Consent struct {
+	Client      *Client
+	TenantID    uuid.UUID
+	Scopes      permission.Set
+	RedirectURI string
+	State       string
+}
+
+// ClientCredentials come from HTTP Basic auth or the form body.
+type ClientCredentials struct {
+	ClientID string
+	Secret   string
+}
+
+type TokenRequest struct {
+	GrantType    string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	Client       ClientCredentials
+}
+
+// Tokens is a successful token response.
+type Tokens struct {
+	AccessToken  string
+	ExpiresAt    time.Time
+	RefreshToken string
+	Scopes       permission.Set
+	TenantID     uuid.UUID
+}
+
+// Introspection is the RFC 7662 view of a token. Inactive tokens say
+// nothing else.
+type Introspection struct {
+	Active    bool
+	TokenType string
+	Scopes    permission.Set
+	ClientID  uuid.UUID
+	UserID    uuid.UUID
+	TenantID  uuid.UUID
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
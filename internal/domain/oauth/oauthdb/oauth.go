@@ -0,0 +1,365 @@
+package oauthdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/oauth"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type oauthStore struct {
+	conn database.DBTX
+}
+
+func NewOAuthStore(conn *pgxpool.Pool) *oauthStore {
+	return &oauthStore{conn: conn}
+}
+
+func parseScopes(values []string) permission.Set {
+	scopes := permission.NewSet()
+	for _, v := range values {
+		if p, err := permission.Parse(v); err == nil {
+			scopes.Add(p)
+		}
+	}
+	return scopes
+}
+
+const clientColumns = `id, owner_id, name, redirect_uris, scopes, secret_hash, created_at, revoked_at`
+
+func scanClient(row pgx.Row) (oauth.Client, error) {
+	var (
+		c      oauth.Client
+		scopes []string
+	)
+	err := row.Scan(
+		&c.ID,
+		&c.OwnerID,
+		&c.Name,
+		&c.RedirectURIs,
+		&scopes,
+		&c.SecretHash,
+		&c.CreatedAt,
+		&c.RevokedAt,
+	)
+	if err != nil {
+		return oauth.Client{}, err
+	}
+	c.Scopes = parseScopes(scopes)
+	return c, nil
+}
+
+func (o *oauthStore) CreateClient(ctx context.Context, c *oauth.Client) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		INSERT INTO oauth_clients (id, owner_id, name, redirect_uris, scopes, secret_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query,
+		c.ID,
+		c.OwnerID,
+		c.Name,
+		c.RedirectURIs,
+		c.Scopes.Strings(),
+		c.SecretHash,
+	).Scan(&c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (o *oauthStore) GetClient(ctx context.Context, clientID uuid.UUID) (*oauth.Client, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `SELECT ` + clientColumns + ` FROM oauth_clients WHERE id = $1`
+	c, err := scanClient(conn.QueryRow(ctx, query, clientID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, oauth.ErrClientNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return &c, nil
+}
+
+func (o *oauthStore) ListClients(ctx context.Context, ownerID uuid.UUID) ([]oauth.Client, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		SELECT ` + clientColumns + `
+		FROM oauth_clients
+		WHERE owner_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+
+	clients, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (oauth.Client, error) {
+		return scanClient(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return clients, nil
+}
+
+func (o *oauthStore) RevokeClient(ctx context.Context, ownerID, clientID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		UPDATE oauth_clients
+		SET revoked_at = now()
+		WHERE owner_id = $1 AND id = $2 AND revoked_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, ownerID, clientID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return oauth.ErrClientNotFound
+	}
+	return nil
+}
+
+func (o *oauthStore) CreateAuthCode(ctx context.Context, code *oauth.AuthCode) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		INSERT INTO oauth_codes (code_hash, client_id, user_id, tenant_id, redirect_uri, scopes, code_challenge, expiry)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := conn.Exec(ctx, query,
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.TenantID,
+		code.RedirectURI,
+		code.Scopes.Strings(),
+		code.CodeChallenge,
+		code.Expiry,
+	)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (o *oauthStore) ConsumeAuthCode(ctx context.Context, hash []byte) (*oauth.AuthCode, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		DELETE FROM oauth_codes
+		WHERE code_hash = $1
+		RETURNING code_hash, client_id, user_id, tenant_id, redirect_uri, scopes, code_challenge, expiry
+	`
+	var (
+		code   oauth.AuthCode
+		scopes []string
+	)
+	err := conn.QueryRow(ctx, query, hash).Scan(
+		&code.CodeHash,
+		&code.ClientID,
+		&code.UserID,
+		&code.TenantID,
+		&code.RedirectURI,
+		&scopes,
+		&code.CodeChallenge,
+		&code.Expiry,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, oauth.ErrAuthCodeNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	code.Scopes = parseScopes(scopes)
+	return &code, nil
+}
+
+const grantColumns = `g.id, g.client_id, c.name, g.tenant_id, g.user_id, g.scopes, g.created_at, g.revoked_at`
+
+func scanGrant(row pgx.Row) (oauth.Grant, error) {
+	var (
+		g      oauth.Grant
+		scopes []string
+	)
+	err := row.Scan(
+		&g.ID,
+		&g.ClientID,
+		&g.ClientName,
+		&g.TenantID,
+		&g.UserID,
+		&scopes,
+		&g.CreatedAt,
+		&g.RevokedAt,
+	)
+	if err != nil {
+		return oauth.Grant{}, err
+	}
+	g.Scopes = parseScopes(scopes)
+	return g, nil
+}
+
+func (o *oauthStore) CreateGrant(ctx context.Context, g *oauth.Grant) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		INSERT INTO oauth_grants (id, client_id, tenant_id, user_id, scopes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query,
+		g.ID,
+		g.ClientID,
+		g.TenantID,
+		g.UserID,
+		g.Scopes.Strings(),
+	).Scan(&g.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (o *oauthStore) GetGrant(ctx context.Context, grantID uuid.UUID) (*oauth.Grant, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		SELECT ` + grantColumns + `
+		FROM oauth_grants g
+		JOIN oauth_clients c ON c.id = g.client_id
+		WHERE g.id = $1
+	`
+	g, err := scanGrant(conn.QueryRow(ctx, query, grantID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, oauth.ErrGrantNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return &g, nil
+}
+
+func (o *oauthStore) ListGrants(ctx context.Context, tenantID uuid.UUID) ([]oauth.Grant, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		SELECT ` + grantColumns + `
+		FROM oauth_grants g
+		JOIN oauth_clients c ON c.id = g.client_id
+		WHERE g.tenant_id = $1 AND g.revoked_at IS NULL
+		ORDER BY g.created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+
+	grants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (oauth.Grant, error) {
+		return scanGrant(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return grants, nil
+}
+
+func (o *oauthStore) RevokeGrant(ctx context.Context, grantID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		UPDATE oauth_grants
+		SET revoked_at = now()
+		WHERE id = $1 AND revoked_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, grantID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return oauth.ErrGrantNotFound
+	}
+	return nil
+}
+
+func (o *oauthStore) RevokeClientGrants(ctx context.Context, clientID uuid.UUID) ([]uuid.UUID, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		UPDATE oauth_grants
+		SET revoked_at = now()
+		WHERE client_id = $1 AND revoked_at IS NULL
+		RETURNING id
+	`
+	rows, err := conn.Query(ctx, query, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return ids, nil
+}
+
+func (o *oauthStore) CreateRefreshToken(ctx context.Context, rt *oauth.RefreshToken) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		INSERT INTO oauth_refresh_tokens (token_hash, grant_id, expiry)
+		VALUES ($1, $2, $3)
+	`
+	if _, err := conn.Exec(ctx, query, rt.TokenHash, rt.GrantID, rt.Expiry); err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (o *oauthStore) GetRefreshToken(ctx context.Context, hash []byte) (*oauth.RefreshToken, error) {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		SELECT token_hash, grant_id, expiry, used_at
+		FROM oauth_refresh_tokens
+		WHERE token_hash = $1
+	`
+	var rt oauth.RefreshToken
+	err := conn.QueryRow(ctx, query, hash).Scan(&rt.TokenHash, &rt.GrantID, &rt.Expiry, &rt.UsedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, oauth.ErrRefreshTokenNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	return &rt, nil
+}
+
+func (o *oauthStore) MarkRefreshTokenUsed(ctx context.Context, hash []byte) error {
+	conn := database.GetTXFromContext(ctx, o.conn)
+
+	const query = `
+		UPDATE oauth_refresh_tokens
+		SET used_at = now()
+		WHERE token_hash = $1 AND used_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, hash)
+	if err != nil {
+		return fmt.Errorf("%w: %w", oauth.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return oauth.ErrRefreshTokenUsed
+	}
+	return nil
+}
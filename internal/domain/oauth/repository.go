@@ -0,0 +1,39 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrDatabase             = errors.New("database error")
+	ErrClientNotFound       = errors.New("client not found")
+	ErrAuthCodeNotFound     = errors.New("authorization code not found")
+	ErrGrantNotFound        = errors.New("app installation not found")
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	ErrRefreshTokenUsed     = errors.New("refresh token already used")
+)
+
+type Repository interface {
+	CreateClient(ctx context.Context, c *Client) error
+	GetClient(ctx context.Context, clientID uuid.UUID) (*Client, error)
+	ListClients(ctx context.Context, ownerID uuid.UUID) ([]Client, error)
+	RevokeClient(ctx context.Context, ownerID, clientID uuid.UUID) error
+	CreateAuthCode(ctx context.Context, code *AuthCode) error
+	// ConsumeAuthCode deletes the code and returns it, a code only works once.
+	ConsumeAuthCode(ctx context.Context, hash []byte) (*AuthCode, error)
+	CreateGrant(ctx context.Context, g *Grant) error
+	GetGrant(ctx context.Context, grantID uuid.UUID) (*Grant, error)
+	ListGrants(ctx context.Context, tenantID uuid.UUID) ([]Grant, error)
+	RevokeGrant(ctx context.Context, grantID uuid.UUID) error
+	// RevokeClientGrants revokes every active grant of the client and returns
+	// their IDs.
+	RevokeClientGrants(ctx context.Context, clientID uuid.UUID) ([]uuid.UUID, error)
+	CreateRefreshToken(ctx context.Context, rt *RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash []byte) (*RefreshToken, error)
+	// MarkRefreshTokenUsed fails with ErrRefreshTokenUsed when another
+	// request got there first.
+	MarkRefreshTokenUsed(ctx context.Context, hash []byte) error
+}
@@ -18,6 +18,8 @@ type CatalogDB struct {
 	conn database.DBTX
 }
 
+var _ catalog.Repository = (*CatalogDB)(nil)
+
 func NewCatalogDB(conn *pgxpool.Pool) *CatalogDB {
 	return &CatalogDB{
 		conn: conn,
@@ -55,7 +57,7 @@ func (db *CatalogDB) CreateProduct(ctx context.Context, p catalog.Product) error
 	return nil
 }
 
-func (db *CatalogDB) GetProduct(ctx context.Context, tenantID, productID catalog.ProductID) (*catalog.Product, error) {
+func (db *CatalogDB) GetProduct(ctx context.Context, tenantID uuid.UUID, productID catalog.ProductID) (*catalog.Product, error) {
 	conn := database.GetTXFromContext(ctx, db.conn)
 
 	query := `
@@ -1 +1,66 @@
 package catalog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	storecatalog "github.com/iamonah/merchcore/internal/domain/store/catalog/storecatalog"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/money"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+type CatalogBusiness struct {
+	storer storecatalog.Repository
+	plans  tenant.EntitlementChecker
+}
+
+type CatalogBusinessCfg func(cb *CatalogBusiness) error
+
+func NewCatalogBusiness(cfgs ...CatalogBusinessCfg) (*CatalogBusiness, error) {
+	cb := &CatalogBusiness{}
+	for _, cfg := range cfgs {
+		if err := cfg(cb); err != nil {
+			return nil, err
+		}
+	}
+	switch {
+	case cb.storer == nil:
+		return nil, errors.New("catalog repository is required")
+	case cb.plans == nil:
+		return nil, errors.New("entitlement checker is required")
+	}
+	return cb, nil
+}
+
+func WithCatalogRepository(st storecatalog.Repository) CatalogBusinessCfg {
+	return func(cb *CatalogBusiness) error {
+		cb.storer = st
+		return nil
+	}
+}
+
+func WithEntitlements(ec tenant.EntitlementChecker) CatalogBusinessCfg {
+	return func(cb *CatalogBusiness) error {
+		cb.plans = ec
+		return nil
+	}
+}
+
+// CreateProduct adds a product to the store's catalog, within the number
+// of products its plan allows.
+func (cb *CatalogBusiness) CreateProduct(ctx context.Context, tenantID uuid.UUID, name, description string, price money.Money) (*storecatalog.Product, error) {
+	p, err := storecatalog.NewProduct(tenantID, name, description, price)
+	if err != nil {
+		return nil, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+	if err := cb.plans.CheckQuota(ctx, tenantID, tenant.QuotaProducts, 1); err != nil {
+		return nil, err
+	}
+	if err := cb.storer.CreateProduct(ctx, *p); err != nil {
+		return nil, fmt.Errorf("createproduct: %w", err)
+	}
+	return p, nil
+}
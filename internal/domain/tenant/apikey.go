@@ -0,0 +1,32 @@
+package tenant
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+)
+
+// APIKey lets a store's own systems call the API. Secret is only set when
+// the key is created, the store keeps its hash and Prefix to find it by.
+type APIKey struct {
+	ID         uuid.UUID
+	TenantID   uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    []byte
+	Secret     string
+	Scopes     permission.Set
+	CreatedBy  uuid.UUID
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+	CreatedAt  time.Time
+}
+
+func (k *APIKey) IsActive() bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || time.Now().Before(*k.ExpiresAt)
+}
@@ -0,0 +1,14 @@
+package tenant
+
+import "fmt"
+
+// TenantHost caches which store a storefront host name belongs to.
+func TenantHost(host string) string {
+	return fmt.Sprintf("tenant:host:%s", host)
+}
+
+// KeyPatterns matches every key above. All of them are written with a TTL,
+// so one found without is left over and safe to drop.
+var KeyPatterns = []string{
+	"tenant:host:*",
+}
@@ -0,0 +1,191 @@
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// apiKeyTag starts every key so leaked keys are easy to spot in logs and
+// secret scanners.
+const apiKeyTag = "mk"
+
+var (
+	errInvalidAPIKey = errors.New("invalid or revoked api key")
+	keyEncoding      = base32.StdEncoding.WithPadding(base32.NoPadding)
+)
+
+// CreateAPIKey issues a key for the store. The caller needs api_keys:manage
+// and can only hand the key scopes they hold themselves. The returned key
+// carries the secret, it cannot be read back later.
+func (tb *TenantBusiness) CreateAPIKey(ctx context.Context, tenantID, callerID uuid.UUID, name string, scopes []permission.Permission, expiresAt *time.Time) (*APIKey, error) {
+	held, err := tb.storePermissions(ctx, tenantID, callerID)
+	if err != nil {
+		return nil, err
+	}
+	if !held.Has(permission.APIKeysManage) {
+		return nil, errs.NewDomainError(errs.PermissionDenied, errors.New("not allowed to manage api keys"))
+	}
+	for _, p := range scopes {
+		if !p.IsStoreScoped() || p == permission.APIKeysManage || p == permission.AppsManage || p == permission.RolesManage {
+			return nil, errs.NewDomainError(errs.InvalidArgument, fmt.Errorf("%s cannot be granted to an api key", p))
+		}
+		if !held.Has(p) {
+			return nil, errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("cannot grant %s", p))
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, errs.NewDomainError(errs.InvalidArgument, errors.New("expires_at must be in the future"))
+	}
+	if err := tb.CheckQuota(ctx, tenantID, QuotaAPIKeys, 1); err != nil {
+		return nil, err
+	}
+
+	prefix, secret, hash, err := newAPIKey()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &APIKey{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		Name:      strings.TrimSpace(name),
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Secret:    secret,
+		Scopes:    permission.NewSet(scopes...),
+		CreatedBy: callerID,
+		ExpiresAt: expiresAt,
+	}
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.CreateAPIKey(ctx, key); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "api_key.create",
+			TargetType: "api_key",
+			TargetID:   key.ID.String(),
+			After: map[string]any{
+				"name":       key.Name,
+				"prefix":     key.Prefix,
+				"scopes":     key.Scopes.Strings(),
+				"expires_at": key.ExpiresAt,
+			},
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("createapikey-trx: %w", err)
+	}
+	return key, nil
+}
+
+func (tb *TenantBusiness) ListAPIKeys(ctx context.Context, tenantID, callerID uuid.UUID) ([]APIKey, error) {
+	if err := tb.requireStorePermission(ctx, tenantID, callerID, permission.APIKeysManage); err != nil {
+		return nil, err
+	}
+
+	keys, err := tb.storer.ListAPIKeys(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listapikeys: %w", err)
+	}
+	return keys, nil
+}
+
+func (tb *TenantBusiness) RevokeAPIKey(ctx context.Context, tenantID, callerID, keyID uuid.UUID) error {
+	if err := tb.requireStorePermission(ctx, tenantID, callerID, permission.APIKeysManage); err != nil {
+		return err
+	}
+
+	err := tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.RevokeAPIKey(ctx, tenantID, keyID); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "api_key.revoke",
+			TargetType: "api_key",
+			TargetID:   keyID.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("revokeapikey-trx: %w", err)
+	}
+	return nil
+}
+
+// AuthenticateAPIKey resolves a raw key into the principal it acts as.
+// Every failure looks the same to the caller.
+func (tb *TenantBusiness) AuthenticateAPIKey(ctx context.Context, raw string) (*authz.Principal, error) {
+	invalid := errs.NewDomainError(errs.Unauthenticated, errInvalidAPIKey)
+
+	parts := strings.Split(raw, "_")
+	if len(parts) != 3 || parts[0] != apiKeyTag {
+		return nil, invalid
+	}
+
+	key, err := tb.storer.GetAPIKeyByPrefix(ctx, parts[1])
+	if err != nil {
+		if errors.Is(err, ErrAPIKeyNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("getapikeybyprefix: %w", err)
+	}
+	sha := sha256.Sum256([]byte(raw))
+	if subtle.ConstantTimeCompare(sha[:], key.KeyHash) != 1 || !key.IsActive() {
+		return nil, invalid
+	}
+
+	if err := tb.storer.TouchAPIKey(ctx, key.ID); err != nil {
+		return nil, fmt.Errorf("touchapikey: %w", err)
+	}
+
+	return &authz.Principal{
+		Kind:     authz.PrincipalAPIKey,
+		UserID:   key.CreatedBy,
+		TenantID: key.TenantID,
+		APIKeyID: key.ID,
+		Scopes:   key.Scopes,
+	}, nil
+}
+
+func (tb *TenantBusiness) requireStorePermission(ctx context.Context, tenantID, userID uuid.UUID, p permission.Permission) error {
+	held, err := tb.storePermissions(ctx, tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if !held.Has(p) {
+		return errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("missing permission %s", p))
+	}
+	return nil
+}
+
+// newAPIKey returns the lookup prefix, the full key handed to the merchant
+// and the hash kept of it.
+func newAPIKey() (string, string, []byte, error) {
+	b := make([]byte, 25)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", nil, fmt.Errorf("newapikey: %w", err)
+	}
+	prefix := strings.ToLower(keyEncoding.EncodeToString(b[:5]))
+	secret := strings.ToLower(keyEncoding.EncodeToString(b[5:]))
+
+	key := apiKeyTag + "_" + prefix + "_" + secret
+	hash := sha256.Sum256([]byte(key))
+	return prefix, key, hash[:], nil
+}
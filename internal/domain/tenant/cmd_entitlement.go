@@ -0,0 +1,125 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// EntitlementChecker checks a store's plan before it gets more of
+// something, *TenantBusiness satisfies it.
+type EntitlementChecker interface {
+	CheckQuota(ctx context.Context, tenantID uuid.UUID, q Quota, n int64) error
+	CheckFeature(ctx context.Context, tenantID uuid.UUID, f Feature) error
+}
+
+var _ EntitlementChecker = (*TenantBusiness)(nil)
+
+func (tb *TenantBusiness) tenantForPlan(ctx context.Context, tenantID uuid.UUID) (*TenantProfile, error) {
+	t, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	return t, nil
+}
+
+// Usage reports what the store consumes of each quota next to what its
+// plan allows.
+func (tb *TenantBusiness) Usage(ctx context.Context, tenantID uuid.UUID) (*Usage, error) {
+	t, err := tb.tenantForPlan(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	u := &Usage{
+		Plan:         t.Plan,
+		Entitlements: EntitlementsFor(t.Plan),
+		Used:         make(map[Quota]int64, len(Quotas)),
+	}
+	for _, q := range Quotas {
+		n, err := tb.storer.CountUsage(ctx, t, q)
+		if err != nil {
+			return nil, fmt.Errorf("countusage[%s]: %w", q, err)
+		}
+		u.Used[q] = n
+	}
+	return u, nil
+}
+
+// CallerUsage is Usage for the one store userID owns or works in, for
+// callers that do not name a store. Someone in several stores must name
+// one. Reading it takes settings:read in that store like the per store
+// route.
+func (tb *TenantBusiness) CallerUsage(ctx context.Context, userID uuid.UUID) (uuid.UUID, *Usage, error) {
+	ids, err := tb.storer.ListUserTenantIDs(ctx, userID)
+	if err != nil {
+		return uuid.Nil, nil, fmt.Errorf("listusertenantids: %w", err)
+	}
+	switch len(ids) {
+	case 0:
+		return uuid.Nil, nil, errs.NewDomainError(errs.NotFound, ErrTenantNotFound)
+	case 1:
+	default:
+		return uuid.Nil, nil, errs.NewDomainError(errs.FailedPrecondition, ErrSeveralStores)
+	}
+
+	tenantID := ids[0]
+	held, err := tb.storePermissions(ctx, tenantID, userID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if !held.Has(permission.SettingsRead) {
+		return uuid.Nil, nil, errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("missing permission %s", permission.SettingsRead))
+	}
+
+	u, err := tb.Usage(ctx, tenantID)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	return tenantID, u, nil
+}
+
+// CheckQuota fails with ResourceExhausted when the store's plan has no
+// room for n more of q. Limits are checked, not reserved: requests racing
+// for the last one can both get it.
+func (tb *TenantBusiness) CheckQuota(ctx context.Context, tenantID uuid.UUID, q Quota, n int64) error {
+	t, err := tb.tenantForPlan(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	return tb.checkQuota(ctx, t, q, n)
+}
+
+func (tb *TenantBusiness) checkQuota(ctx context.Context, t *TenantProfile, q Quota, n int64) error {
+	ent := EntitlementsFor(t.Plan)
+	if ent.Limit(q) == Unlimited {
+		return nil
+	}
+	used, err := tb.storer.CountUsage(ctx, t, q)
+	if err != nil {
+		return fmt.Errorf("countusage[%s]: %w", q, err)
+	}
+	if !ent.Allows(q, used, n) {
+		return errs.NewDomainError(errs.ResourceExhausted, quotaError(t.Plan, q, used, n))
+	}
+	return nil
+}
+
+// CheckFeature fails with ResourceExhausted when the store's plan does not
+// include f.
+func (tb *TenantBusiness) CheckFeature(ctx context.Context, tenantID uuid.UUID, f Feature) error {
+	t, err := tb.tenantForPlan(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	if !EntitlementsFor(t.Plan).Has(f) {
+		return errs.NewDomainError(errs.ResourceExhausted, featureError(t.Plan, f))
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// StatusHook is told of every status change once it is committed. A hook
+// cannot undo the change, it deals with its own failures.
+type StatusHook interface {
+	TenantStatusChanged(ctx context.Context, te TenantProfile, change StatusChange)
+}
+
+// StatusHookFunc lets a plain function be a StatusHook.
+type StatusHookFunc func(ctx context.Context, te TenantProfile, change StatusChange)
+
+func (f StatusHookFunc) TenantStatusChanged(ctx context.Context, te TenantProfile, change StatusChange) {
+	f(ctx, te, change)
+}
+
+// WithStatusHooks adds hooks run after each status change, in order.
+func WithStatusHooks(hooks ...StatusHook) TenantBusinessCfg {
+	return func(tb *TenantBusiness) error {
+		tb.hooks = append(tb.hooks, hooks...)
+		return nil
+	}
+}
+
+// ChangeStatus moves the store along its lifecycle, see transitions for
+// who may go where. The change is recorded in the store's status history
+// and the audit log. The storefront follows it at once: a suspended or
+// archived store stops being served.
+func (tb *TenantBusiness) ChangeStatus(ctx context.Context, tenantID uuid.UUID, req StatusRequest) (*TenantProfile, error) {
+	return tb.changeStatus(ctx, tenantID, req, nil)
+}
+
+// changeStatus is ChangeStatus running also, when not nil, inside the
+// transaction that records the change.
+func (tb *TenantBusiness) changeStatus(ctx context.Context, tenantID uuid.UUID, req StatusRequest, also func(ctx context.Context) error) (*TenantProfile, error) {
+	te, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if req.Trigger == TriggerOwner && (req.ActorID == nil || *req.ActorID != te.UserID) {
+		return nil, errs.NewDomainError(errs.PermissionDenied, errors.New("only the store owner can do this"))
+	}
+
+	change, err := newStatusChange(te, req)
+	if err != nil {
+		if errors.Is(err, ErrInvalidTransition) {
+			return nil, errs.NewDomainError(errs.FailedPrecondition, err)
+		}
+		return nil, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.UpdateTenantStatus(ctx, tenantID, change.From, change.To); err != nil {
+			return err
+		}
+		if err := tb.storer.CreateStatusChange(ctx, change); err != nil {
+			return err
+		}
+		if also != nil {
+			if err := also(ctx); err != nil {
+				return err
+			}
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			ActorID:    change.ActorID,
+			Action:     "tenant.status_change",
+			TargetType: "tenant",
+			TargetID:   tenantID.String(),
+			Before:     map[string]any{"status": change.From},
+			After:      map[string]any{"status": change.To, "reason": change.Reason, "trigger": change.Trigger},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrStatusConflict) {
+			return nil, errs.NewDomainError(errs.Aborted, err)
+		}
+		return nil, fmt.Errorf("changestatus-trx: %w", err)
+	}
+
+	te.Status = change.To
+	te.UpdatedAt = change.CreatedAt
+	tb.forgetHosts(ctx, te)
+	for _, hook := range tb.hooks {
+		hook.TenantStatusChanged(ctx, *te, *change)
+	}
+	return te, nil
+}
+
+// Reinstate lifts a suspension, putting the store back in the status it
+// had before it was suspended. A store that is not suspended is refused.
+func (tb *TenantBusiness) Reinstate(ctx context.Context, tenantID uuid.UUID, trigger Trigger, actorID *uuid.UUID, reason string) (*TenantProfile, error) {
+	te, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if te.Status != TenantStatusSuspended {
+		return nil, errs.NewDomainError(errs.FailedPrecondition, fmt.Errorf("%w: it is %s", ErrNotSuspended, te.Status))
+	}
+
+	history, err := tb.storer.ListStatusChanges(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("liststatuschanges: %w", err)
+	}
+
+	to := TenantStatusMaintenance
+	for _, c := range history {
+		if c.To == TenantStatusSuspended {
+			to = c.From
+			break
+		}
+	}
+	return tb.ChangeStatus(ctx, tenantID, StatusRequest{To: to, Reason: reason, Trigger: trigger, ActorID: actorID})
+}
+
+// StatusHistory lists the store's status changes, newest first.
+func (tb *TenantBusiness) StatusHistory(ctx context.Context, tenantID uuid.UUID) ([]StatusChange, error) {
+	if _, err := tb.storer.GetTenantByID(ctx, tenantID); err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+
+	history, err := tb.storer.ListStatusChanges(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("liststatuschanges: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,232 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// EffectivePermissions resolves what userID may do in tenantID: the
+// permissions of their platform role plus whatever they hold in the store.
+// With uuid.Nil only the platform permissions apply. Platform staff keep
+// their platform permissions in stores they are not part of, anyone else
+// outside the store gets NotFound.
+func (tb *TenantBusiness) EffectivePermissions(ctx context.Context, tenantID, userID uuid.UUID, platformRole string) (permission.Set, error) {
+	perms := permission.NewSet()
+	if r, err := role.Parse(platformRole); err == nil {
+		perms.Union(permission.ForPlatformRole(r))
+	}
+	if tenantID == uuid.Nil {
+		return perms, nil
+	}
+
+	inStore, err := tb.storePermissions(ctx, tenantID, userID)
+	if err != nil {
+		if derr, ok := errs.IsDomainError(err); ok && derr.Code == errs.NotFound && len(perms) > 0 {
+			return perms, nil
+		}
+		return nil, err
+	}
+	return perms.Union(inStore), nil
+}
+
+// storePermissions returns what userID holds in the store alone. A custom
+// role replaces the permissions of the member's built-in role.
+func (tb *TenantBusiness) storePermissions(ctx context.Context, tenantID, userID uuid.UUID) (permission.Set, error) {
+	t, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if t.UserID == userID {
+		return permission.ForStoreRole(role.StoreOwner), nil
+	}
+
+	m, err := tb.storer.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, ErrTenantNotFound)
+		}
+		return nil, fmt.Errorf("getmember: %w", err)
+	}
+	if m.CustomRoleID == nil {
+		return permission.ForStoreRole(m.Role), nil
+	}
+
+	cr, err := tb.storer.GetRole(ctx, tenantID, *m.CustomRoleID)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return permission.ForStoreRole(m.Role), nil
+		}
+		return nil, fmt.Errorf("getrole: %w", err)
+	}
+	return cr.Permissions, nil
+}
+
+// ListRoles returns the custom roles of the store. Any member may look.
+func (tb *TenantBusiness) ListRoles(ctx context.Context, tenantID, callerID uuid.UUID) ([]CustomRole, error) {
+	if _, err := tb.MemberRole(ctx, tenantID, callerID); err != nil {
+		return nil, err
+	}
+
+	roles, err := tb.storer.ListRoles(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("listroles: %w", err)
+	}
+	return roles, nil
+}
+
+func (tb *TenantBusiness) CreateRole(ctx context.Context, tenantID, callerID uuid.UUID, name, description string, perms []permission.Permission) (*CustomRole, error) {
+	if err := tb.checkRoleGrant(ctx, tenantID, callerID, perms); err != nil {
+		return nil, err
+	}
+
+	cr := &CustomRole{
+		ID:          uuid.New(),
+		TenantID:    tenantID,
+		Name:        strings.TrimSpace(name),
+		Description: strings.TrimSpace(description),
+		Permissions: permission.NewSet(perms...),
+	}
+	err := tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.CreateRole(ctx, cr); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "role.create",
+			TargetType: "role",
+			TargetID:   cr.ID.String(),
+			After:      roleFields(cr),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrRoleExists) {
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		}
+		return nil, fmt.Errorf("createrole: %w", err)
+	}
+	return cr, nil
+}
+
+func (tb *TenantBusiness) UpdateRole(ctx context.Context, tenantID, callerID, roleID uuid.UUID, name, description string, perms []permission.Permission) (*CustomRole, error) {
+	if err := tb.checkRoleGrant(ctx, tenantID, callerID, perms); err != nil {
+		return nil, err
+	}
+
+	cr, err := tb.getRole(ctx, tenantID, roleID)
+	if err != nil {
+		return nil, err
+	}
+	before := roleFields(cr)
+	cr.Name = strings.TrimSpace(name)
+	cr.Description = strings.TrimSpace(description)
+	cr.Permissions = permission.NewSet(perms...)
+
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.UpdateRole(ctx, cr); err != nil {
+			return err
+		}
+		b, a := audit.Diff(before, roleFields(cr))
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "role.update",
+			TargetType: "role",
+			TargetID:   cr.ID.String(),
+			Before:     b,
+			After:      a,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrRoleNotFound):
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		case errors.Is(err, ErrRoleExists):
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		default:
+			return nil, fmt.Errorf("updaterole: %w", err)
+		}
+	}
+	return cr, nil
+}
+
+func (tb *TenantBusiness) DeleteRole(ctx context.Context, tenantID, callerID, roleID uuid.UUID) error {
+	if err := tb.checkRoleGrant(ctx, tenantID, callerID, nil); err != nil {
+		return err
+	}
+
+	cr, err := tb.getRole(ctx, tenantID, roleID)
+	if err != nil {
+		return err
+	}
+
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.DeleteRole(ctx, tenantID, roleID); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "role.delete",
+			TargetType: "role",
+			TargetID:   roleID.String(),
+			Before:     roleFields(cr),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("deleterole-trx: %w", err)
+	}
+	return nil
+}
+
+func roleFields(cr *CustomRole) map[string]any {
+	return map[string]any{
+		"name":        cr.Name,
+		"description": cr.Description,
+		"permissions": cr.Permissions.Strings(),
+	}
+}
+
+// checkRoleGrant makes sure the caller may manage roles and is not handing
+// out more than they hold themselves. Only store permissions can go into a
+// custom role.
+func (tb *TenantBusiness) checkRoleGrant(ctx context.Context, tenantID, callerID uuid.UUID, perms []permission.Permission) error {
+	held, err := tb.storePermissions(ctx, tenantID, callerID)
+	if err != nil {
+		return err
+	}
+	if !held.Has(permission.RolesManage) {
+		return errs.NewDomainError(errs.PermissionDenied, errors.New("not allowed to manage roles"))
+	}
+	for _, p := range perms {
+		if !p.IsStoreScoped() {
+			return errs.NewDomainError(errs.InvalidArgument, fmt.Errorf("%s cannot be granted by a store role", p))
+		}
+		if !held.Has(p) {
+			return errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("cannot grant %s", p))
+		}
+	}
+	return nil
+}
+
+func (tb *TenantBusiness) getRole(ctx context.Context, tenantID, roleID uuid.UUID) (*CustomRole, error) {
+	cr, err := tb.storer.GetRole(ctx, tenantID, roleID)
+	if err != nil {
+		if errors.Is(err, ErrRoleNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("getrole: %w", err)
+	}
+	return cr, nil
+}
@@ -0,0 +1,367 @@
+package tenant
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const invitationTTL = 7 * 24 * time.Hour
+
+var errCannotManage = errors.New("not allowed to manage this team member")
+
+// MemberRole resolves the role userID holds in the store: store_owner for
+// its owner, the member role for its team. Anyone else gets NotFound so the
+// store cannot be probed by people outside it.
+func (tb *TenantBusiness) MemberRole(ctx context.Context, tenantID, userID uuid.UUID) (role.Role, error) {
+	t, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return role.Role{}, errs.NewDomainError(errs.NotFound, err)
+		}
+		return role.Role{}, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if t.UserID == userID {
+		return role.StoreOwner, nil
+	}
+
+	m, err := tb.storer.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return role.Role{}, errs.NewDomainError(errs.NotFound, ErrTenantNotFound)
+		}
+		return role.Role{}, fmt.Errorf("getmember: %w", err)
+	}
+	return m.Role, nil
+}
+
+// InviteMember creates an invitation for email. The returned invitation
+// carries the plaintext token to mail out.
+func (tb *TenantBusiness) InviteMember(ctx context.Context, tenantID, inviterID uuid.UUID, email *mail.Address, r role.Role) (*Invitation, error) {
+	actor, err := tb.MemberRole(ctx, tenantID, inviterID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(actor, r) {
+		return nil, errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("%s cannot invite %s", actor, r))
+	}
+
+	t, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if err := tb.checkQuota(ctx, t, QuotaStaffSeats, 1); err != nil {
+		return nil, err
+	}
+
+	token, hash, err := newInviteToken()
+	if err != nil {
+		return nil, err
+	}
+
+	inv := &Invitation{
+		ID:           uuid.New(),
+		TenantID:     tenantID,
+		BusinessName: t.BusinessName,
+		Email:        strings.ToLower(email.Address),
+		Role:         r,
+		TokenHash:    hash,
+		Token:        token,
+		InvitedBy:    inviterID,
+		Status:       InvitationPending,
+		ExpiresAt:    time.Now().Add(invitationTTL),
+	}
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.CreateInvitation(ctx, inv); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "team.invite",
+			TargetType: "invitation",
+			TargetID:   inv.ID.String(),
+			After:      map[string]any{"email": inv.Email, "role": inv.Role.String()},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrInviteExists) {
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		}
+		return nil, fmt.Errorf("createinvitation: %w", err)
+	}
+	return inv, nil
+}
+
+// AcceptInvitation adds the signed-in user to the store. The invitation is
+// bound to the address it was sent to, the caller passes the user's
+// verified email.
+func (tb *TenantBusiness) AcceptInvitation(ctx context.Context, token string, userID uuid.UUID, email string) (*Member, error) {
+	inv, err := tb.pendingInvitation(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(inv.Email, email) {
+		return nil, errs.NewDomainError(errs.PermissionDenied, errors.New("invitation was sent to a different email"))
+	}
+
+	t, err := tb.storer.GetTenantByID(ctx, inv.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	if t.UserID == userID {
+		return nil, errs.NewDomainError(errs.AlreadyExists, errors.New("you already own this store"))
+	}
+
+	member := &Member{
+		TenantID:  inv.TenantID,
+		UserID:    userID,
+		Role:      inv.Role,
+		InvitedBy: &inv.InvitedBy,
+	}
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.SetInvitationStatus(ctx, inv.ID, InvitationAccepted); err != nil {
+			return err
+		}
+		if err := tb.storer.AddMember(ctx, member); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &inv.TenantID,
+			Action:     "team.join",
+			TargetType: "member",
+			TargetID:   userID.String(),
+			After:      map[string]any{"invitation_id": inv.ID, "role": inv.Role.String()},
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrInviteNotFound):
+			return nil, errs.NewDomainError(errs.NotFound, errors.New("invalid or expired invitation"))
+		case errors.Is(err, ErrMemberExists):
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		default:
+			return nil, fmt.Errorf("acceptinvitation-trx: %w", err)
+		}
+	}
+
+	return tb.getMember(ctx, inv.TenantID, userID)
+}
+
+// RejectInvitation declines an invitation. Holding the token is enough, the
+// invitee may not have an account.
+func (tb *TenantBusiness) RejectInvitation(ctx context.Context, token string) error {
+	inv, err := tb.pendingInvitation(ctx, token)
+	if err != nil {
+		return err
+	}
+	if err := tb.storer.SetInvitationStatus(ctx, inv.ID, InvitationRejected); err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			return errs.NewDomainError(errs.NotFound, errors.New("invalid or expired invitation"))
+		}
+		return fmt.Errorf("setinvitationstatus: %w", err)
+	}
+	return nil
+}
+
+func (tb *TenantBusiness) RevokeInvitation(ctx context.Context, tenantID, callerID, invitationID uuid.UUID) error {
+	actor, err := tb.MemberRole(ctx, tenantID, callerID)
+	if err != nil {
+		return err
+	}
+
+	inv, err := tb.storer.GetInvitation(ctx, tenantID, invitationID)
+	if err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("getinvitation: %w", err)
+	}
+	if !canManage(actor, inv.Role) {
+		return errs.NewDomainError(errs.PermissionDenied, errCannotManage)
+	}
+
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.SetInvitationStatus(ctx, inv.ID, InvitationRevoked); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "team.invite_revoke",
+			TargetType: "invitation",
+			TargetID:   inv.ID.String(),
+			Before:     map[string]any{"email": inv.Email, "status": string(inv.Status)},
+			After:      map[string]any{"status": string(InvitationRevoked)},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("revokeinvitation-trx: %w", err)
+	}
+	return nil
+}
+
+// ListTeam returns the members and pending invitations of the store. Any
+// member may look.
+func (tb *TenantBusiness) ListTeam(ctx context.Context, tenantID, callerID uuid.UUID) ([]Member, []Invitation, error) {
+	if _, err := tb.MemberRole(ctx, tenantID, callerID); err != nil {
+		return nil, nil, err
+	}
+
+	members, err := tb.storer.ListMembers(ctx, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listmembers: %w", err)
+	}
+	invitations, err := tb.storer.ListInvitations(ctx, tenantID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listinvitations: %w", err)
+	}
+	return members, invitations, nil
+}
+
+// UpdateMemberRole moves a member to r. A non-nil customRoleID also gives
+// them one of the store's custom roles, the caller must hold every
+// permission it grants.
+func (tb *TenantBusiness) UpdateMemberRole(ctx context.Context, tenantID, callerID, memberID uuid.UUID, r role.Role, customRoleID *uuid.UUID) (*Member, error) {
+	actor, err := tb.MemberRole(ctx, tenantID, callerID)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := tb.getMember(ctx, tenantID, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(actor, m.Role) || !canManage(actor, r) {
+		return nil, errs.NewDomainError(errs.PermissionDenied, errCannotManage)
+	}
+
+	if customRoleID != nil {
+		cr, err := tb.getRole(ctx, tenantID, *customRoleID)
+		if err != nil {
+			return nil, err
+		}
+		held, err := tb.storePermissions(ctx, tenantID, callerID)
+		if err != nil {
+			return nil, err
+		}
+		if !held.HasAll(cr.Permissions.Slice()...) {
+			return nil, errs.NewDomainError(errs.PermissionDenied, fmt.Errorf("cannot assign role %q", cr.Name))
+		}
+	}
+
+	before := map[string]any{"role": m.Role.String(), "custom_role_id": m.CustomRoleID}
+	after := map[string]any{"role": r.String(), "custom_role_id": customRoleID}
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.UpdateMemberRole(ctx, tenantID, memberID, r, customRoleID); err != nil {
+			return err
+		}
+		b, a := audit.Diff(before, after)
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     "team.role_change",
+			TargetType: "member",
+			TargetID:   memberID.String(),
+			Before:     b,
+			After:      a,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("updatememberrole-trx: %w", err)
+	}
+	m.Role = r
+	m.CustomRoleID = customRoleID
+	return m, nil
+}
+
+// RemoveMember takes a member off the team. Who may do so is the route's
+// team:manage check, the owner is not a member and cannot be removed.
+func (tb *TenantBusiness) RemoveMember(ctx context.Context, tenantID, memberID uuid.UUID) error {
+	return tb.removeMember(ctx, tenantID, memberID, "team.remove")
+}
+
+// LeaveTeam takes the caller off the team of a store they are a member of.
+// It needs no permission, members may always leave.
+func (tb *TenantBusiness) LeaveTeam(ctx context.Context, tenantID, callerID uuid.UUID) error {
+	return tb.removeMember(ctx, tenantID, callerID, "team.leave")
+}
+
+func (tb *TenantBusiness) removeMember(ctx context.Context, tenantID, memberID uuid.UUID, action string) error {
+	m, err := tb.getMember(ctx, tenantID, memberID)
+	if err != nil {
+		return err
+	}
+
+	err = tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.RemoveMember(ctx, tenantID, memberID); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &tenantID,
+			Action:     action,
+			TargetType: "member",
+			TargetID:   memberID.String(),
+			Before:     map[string]any{"role": m.Role.String(), "custom_role_id": m.CustomRoleID},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("removemember-trx: %w", err)
+	}
+	return nil
+}
+
+func (tb *TenantBusiness) getMember(ctx context.Context, tenantID, userID uuid.UUID) (*Member, error) {
+	m, err := tb.storer.GetMember(ctx, tenantID, userID)
+	if err != nil {
+		if errors.Is(err, ErrMemberNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("getmember: %w", err)
+	}
+	return m, nil
+}
+
+func (tb *TenantBusiness) pendingInvitation(ctx context.Context, token string) (*Invitation, error) {
+	invalid := errs.NewDomainError(errs.NotFound, errors.New("invalid or expired invitation"))
+
+	sha := sha256.Sum256([]byte(token))
+	inv, err := tb.storer.GetInvitationByToken(ctx, sha[:])
+	if err != nil {
+		if errors.Is(err, ErrInviteNotFound) {
+			return nil, invalid
+		}
+		return nil, fmt.Errorf("getinvitationbytoken: %w", err)
+	}
+	if inv.Status != InvitationPending || inv.IsExpired() {
+		return nil, invalid
+	}
+	return inv, nil
+}
+
+func newInviteToken() (string, []byte, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, fmt.Errorf("newinvitetoken: %w", err)
+	}
+	token := base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(b)
+	hash := sha256.Sum256([]byte(token))
+	return token, hash[:], nil
+}
@@ -4,14 +4,30 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/infra/cache"
 	"github.com/iamonah/merchcore/internal/infra/database"
 	"github.com/iamonah/merchcore/internal/sdk/errs"
+
+	"github.com/google/uuid"
+)
+
+// How long a host stays mapped to its store, and how long an unknown host
+// is remembered as such.
+const (
+	hostCacheTTL     = 5 * time.Minute
+	hostMissCacheTTL = 30 * time.Second
 )
 
 type TenantBusiness struct {
-	storer TenantRepository
-	trx    database.TransactorTX
+	storer   TenantRepository
+	trx      database.TransactorTX
+	auditor  audit.Recorder
+	cache    cache.Cache
+	hooks    []StatusHook
+	notifier TrialNotifier
 }
 
 type TenantBusinessCfg func(tb *TenantBusiness) error
@@ -46,6 +62,31 @@ func WithTransactor(trx database.TransactorTX) TenantBusinessCfg {
 	}
 }
 
+func WithAuditor(auditor audit.Recorder) TenantBusinessCfg {
+	return func(tb *TenantBusiness) error {
+		tb.auditor = auditor
+		return nil
+	}
+}
+
+// WithCache keeps resolved storefront hosts in cache. Without one every
+// lookup goes to the database.
+func WithCache(c cache.Cache) TenantBusinessCfg {
+	return func(tb *TenantBusiness) error {
+		tb.cache = c
+		return nil
+	}
+}
+
+// recordAudit writes e within the transaction on ctx. Without an auditor
+// configured nothing is recorded.
+func (tb *TenantBusiness) recordAudit(ctx context.Context, e audit.Entry) error {
+	if tb.auditor == nil {
+		return nil
+	}
+	return tb.auditor.Record(ctx, e)
+}
+
 func (tb *TenantBusiness) CreateTenant(ctx context.Context, input CreateTenant) (*TenantProfile, error) {
 	tenantProfile, err := NewTenantProfile(input)
 	if err != nil {
@@ -75,7 +116,18 @@ func (tb *TenantBusiness) CreateTenant(ctx context.Context, input CreateTenant)
 		if err := tb.storer.CreateTenantSchema(txCtx, tenantProfile.UserID); err != nil {
 			return err
 		}
-		return nil
+		return tb.recordAudit(txCtx, audit.Entry{
+			TenantID:   &tenantProfile.ID,
+			Action:     "tenant.create",
+			TargetType: "tenant",
+			TargetID:   tenantProfile.ID.String(),
+			After: map[string]any{
+				"business_name": tenantProfile.BusinessName,
+				"subdomain":     tenantProfile.Subdomain,
+				"domain":        tenantProfile.Domain,
+				"plan":          tenantProfile.Plan,
+			},
+		})
 	}); err != nil {
 		switch {
 		case errors.Is(err, ErrDomain), errors.Is(err, ErrSubDomain):
@@ -87,5 +139,74 @@ func (tb *TenantBusiness) CreateTenant(ctx context.Context, input CreateTenant)
 		}
 	}
 
+	// a storefront visit before the store existed may have cached a miss
+	tb.forgetHosts(ctx, tenantProfile)
 	return tenantProfile, nil
 }
+
+// ResolveTenant returns the store a storefront request is for, by the host
+// it was sent to: a custom domain or a subdomain of PlatformDomain.
+// Suspended and archived stores are refused.
+func (tb *TenantBusiness) ResolveTenant(ctx context.Context, host string) (*TenantProfile, error) {
+	host = NormalizeHost(host)
+	if host == "" {
+		return nil, errs.NewDomainError(errs.InvalidArgument, errors.New("missing host"))
+	}
+
+	te, err := tb.tenantByHost(ctx, host)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return nil, errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, fmt.Errorf("tenantbyhost: %w", err)
+	}
+
+	switch te.Status {
+	case TenantStatusSuspended:
+		return nil, errs.NewDomainError(errs.PermissionDenied, ErrTenantSuspended)
+	case TenantStatusArchived:
+		return nil, errs.NewDomainError(errs.NotFound, ErrTenantArchived)
+	}
+	return te, nil
+}
+
+// tenantByHost reads through the cache. An unknown host is cached as a
+// zero profile so floods of made-up hosts do not reach the database. The
+// cache is best effort, when it fails the database answers.
+func (tb *TenantBusiness) tenantByHost(ctx context.Context, host string) (*TenantProfile, error) {
+	key := TenantHost(host)
+	if tb.cache != nil {
+		var cached TenantProfile
+		if err := tb.cache.Get(ctx, key, &cached); err == nil {
+			if cached.ID == uuid.Nil {
+				return nil, ErrTenantNotFound
+			}
+			return &cached, nil
+		}
+	}
+
+	te, err := tb.storer.GetTenantByHost(ctx, host)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) && tb.cache != nil {
+			_ = tb.cache.Set(ctx, key, TenantProfile{}, hostMissCacheTTL)
+		}
+		return nil, err
+	}
+	if tb.cache != nil {
+		_ = tb.cache.Set(ctx, key, *te, hostCacheTTL)
+	}
+	return te, nil
+}
+
+// forgetHosts drops the cached resolution of the store's hosts, call it
+// after changing its domain, subdomain or status.
+func (tb *TenantBusiness) forgetHosts(ctx context.Context, te *TenantProfile) {
+	if tb.cache == nil {
+		return
+	}
+	for _, host := range []*string{te.Domain, te.Subdomain} {
+		if host != nil && *host != "" {
+			_ = tb.cache.Delete(ctx, TenantHost(*host))
+		}
+	}
+}
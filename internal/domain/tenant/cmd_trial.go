@@ -0,0 +1,155 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const trialBatch = 100
+
+// TrialNotifier tells a store's owner their trial is about to end.
+type TrialNotifier interface {
+	TrialReminder(ctx context.Context, te TenantProfile, trial Trial) error
+}
+
+// WithTrialNotifier sends the trial reminders. Without one the trial scan
+// only ends trials.
+func WithTrialNotifier(n TrialNotifier) TenantBusinessCfg {
+	return func(tb *TenantBusiness) error {
+		tb.notifier = n
+		return nil
+	}
+}
+
+// GetTrial returns the store's trial countdown.
+func (tb *TenantBusiness) GetTrial(ctx context.Context, tenantID uuid.UUID) (Trial, error) {
+	te, err := tb.storer.GetTenantByID(ctx, tenantID)
+	if err != nil {
+		if errors.Is(err, ErrTenantNotFound) {
+			return Trial{}, errs.NewDomainError(errs.NotFound, err)
+		}
+		return Trial{}, fmt.Errorf("gettenantbyid: %w", err)
+	}
+	return te.Trial(time.Now()), nil
+}
+
+// ProcessTrials is run by the worker. It reminds the owners of live stores
+// whose trial ends within three days, then again within one, and ends the
+// trials that ran out as their plan says. Each store is handled on its own,
+// the error returned joins those that failed.
+func (tb *TenantBusiness) ProcessTrials(ctx context.Context) (TrialReport, error) {
+	var (
+		report  TrialReport
+		errList []error
+		afterAt time.Time
+		afterID uuid.UUID
+	)
+	now := time.Now()
+	for {
+		page, err := tb.storer.ListTrialsEndingBefore(ctx, now.Add(firstReminder), afterAt, afterID, trialBatch)
+		if err != nil {
+			return report, errors.Join(append(errList, fmt.Errorf("listtrialsendingbefore: %w", err))...)
+		}
+		for i := range page {
+			te := &page[i]
+			report.Checked++
+			if err := tb.processTrial(ctx, te, now, &report); err != nil {
+				errList = append(errList, fmt.Errorf("tenant[%s]: %w", te.ID, err))
+			}
+		}
+		if len(page) < trialBatch || ctx.Err() != nil {
+			return report, errors.Join(errList...)
+		}
+		last := page[len(page)-1]
+		afterAt, afterID = *last.TrialEndAt, last.ID
+	}
+}
+
+func (tb *TenantBusiness) processTrial(ctx context.Context, te *TenantProfile, now time.Time, report *TrialReport) error {
+	event, ok := te.dueTrialEvent(now)
+	switch {
+	case !ok:
+		return nil
+	case event == TrialExpired:
+		return tb.expireTrial(ctx, te, report)
+	default:
+		return tb.remindTrial(ctx, te, event, now, report)
+	}
+}
+
+// remindTrial sends a reminder unless it went out already. A reminder that
+// cannot be queued is given back for the next run.
+func (tb *TenantBusiness) remindTrial(ctx context.Context, te *TenantProfile, event TrialEvent, now time.Time, report *TrialReport) error {
+	if tb.notifier == nil {
+		return nil
+	}
+	claimed, err := tb.storer.ClaimTrialEvent(ctx, te.ID, event, *te.TrialEndAt)
+	if err != nil {
+		return fmt.Errorf("claimtrialevent: %w", err)
+	}
+	if !claimed {
+		return nil
+	}
+
+	if err := tb.notifier.TrialReminder(ctx, *te, te.Trial(now)); err != nil {
+		if rerr := tb.storer.ReleaseTrialEvent(ctx, te.ID, event, *te.TrialEndAt); rerr != nil {
+			return errors.Join(fmt.Errorf("trialreminder: %w", err), fmt.Errorf("releasetrialevent: %w", rerr))
+		}
+		return fmt.Errorf("trialreminder: %w", err)
+	}
+	report.Reminded++
+	return nil
+}
+
+// expireTrial applies the plan's TrialOutcome. Only stores without a
+// recorded expiry for this trial are listed, so a store reinstated after
+// its trial ended is not suspended again.
+func (tb *TenantBusiness) expireTrial(ctx context.Context, te *TenantProfile, report *TrialReport) error {
+	outcome := TrialExpiry(te.Plan)
+	if outcome.Suspend {
+		// the expiry is recorded with the suspension, a store is never
+		// left suspended with its trial still to expire
+		_, err := tb.changeStatus(ctx, te.ID, StatusRequest{
+			To:      TenantStatusSuspended,
+			Reason:  TrialEndedReason,
+			Trigger: TriggerSystem,
+		}, func(ctx context.Context) error {
+			_, err := tb.storer.ClaimTrialEvent(ctx, te.ID, TrialExpired, *te.TrialEndAt)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("changestatus: %w", err)
+		}
+		report.Suspended++
+		return nil
+	}
+
+	err := tb.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := tb.storer.EndTrial(ctx, te.ID, outcome.DowngradeTo); err != nil {
+			return err
+		}
+		if _, err := tb.storer.ClaimTrialEvent(ctx, te.ID, TrialExpired, *te.TrialEndAt); err != nil {
+			return err
+		}
+		return tb.recordAudit(ctx, audit.Entry{
+			TenantID:   &te.ID,
+			Action:     "tenant.trial_end",
+			TargetType: "tenant",
+			TargetID:   te.ID.String(),
+			Before:     map[string]any{"plan": te.Plan, "trial_end_at": te.TrialEndAt},
+			After:      map[string]any{"plan": outcome.DowngradeTo},
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("endtrial-trx: %w", err)
+	}
+	tb.forgetHosts(ctx, te)
+	report.Downgraded++
+	return nil
+}
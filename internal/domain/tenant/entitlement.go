@@ -0,0 +1,173 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Unlimited is the limit of a quota a plan does not cap.
+const Unlimited int64 = -1
+
+var (
+	ErrQuotaExceeded  = errors.New("plan limit reached")
+	ErrFeatureMissing = errors.New("feature not included in plan")
+)
+
+// Quota is something a store consumes that its plan caps.
+type Quota string
+
+const (
+	QuotaProducts   Quota = "products"
+	QuotaStaffSeats Quota = "staff_seats"
+	QuotaAPIKeys    Quota = "api_keys"
+	// QuotaStorageMB and QuotaMonthlyOrders are counted and shown in the
+	// usage but not enforced yet: nothing uploads files or takes orders
+	// to check them at. See Enforced.
+	QuotaStorageMB     Quota = "storage_mb"
+	QuotaMonthlyOrders Quota = "monthly_orders"
+)
+
+// Quotas lists every quota, in the order they are shown.
+var Quotas = []Quota{QuotaProducts, QuotaStaffSeats, QuotaAPIKeys, QuotaStorageMB, QuotaMonthlyOrders}
+
+// Enforced reports whether going over q is refused, the usage tells stores
+// which limits are only shown.
+func (q Quota) Enforced() bool {
+	switch q {
+	case QuotaProducts, QuotaStaffSeats, QuotaAPIKeys:
+		return true
+	}
+	return false
+}
+
+// Feature is something a plan includes or not.
+type Feature string
+
+const FeatureCustomDomain Feature = "custom_domain"
+
+// Entitlements is what a plan allows a store.
+type Entitlements struct {
+	MaxProducts int64
+	// StaffSeats counts team members and pending invitations, the owner
+	// does not take one.
+	StaffSeats    int64
+	APIKeys       int64
+	StorageMB     int64
+	MonthlyOrders int64
+	CustomDomain  bool
+}
+
+// planOrder runs from the cheapest plan up, upgrades are looked for in it.
+var planOrder = []PlanType{FreePlan, CorePlan, ProPlan, EnterprisePlan}
+
+var planEntitlements = map[PlanType]Entitlements{
+	FreePlan: {
+		MaxProducts:   25,
+		StaffSeats:    1,
+		APIKeys:       0,
+		StorageMB:     100,
+		MonthlyOrders: 50,
+	},
+	CorePlan: {
+		MaxProducts:   500,
+		StaffSeats:    3,
+		APIKeys:       2,
+		StorageMB:     1024,
+		MonthlyOrders: 1000,
+		CustomDomain:  true,
+	},
+	ProPlan: {
+		MaxProducts:   5000,
+		StaffSeats:    10,
+		APIKeys:       10,
+		StorageMB:     10240,
+		MonthlyOrders: 10000,
+		CustomDomain:  true,
+	},
+	EnterprisePlan: {
+		MaxProducts:   Unlimited,
+		StaffSeats:    Unlimited,
+		APIKeys:       Unlimited,
+		StorageMB:     Unlimited,
+		MonthlyOrders: Unlimited,
+		CustomDomain:  true,
+	},
+}
+
+// EntitlementsFor returns what plan allows. An unknown plan gets the free
+// plan's.
+func EntitlementsFor(plan PlanType) Entitlements {
+	if e, ok := planEntitlements[plan]; ok {
+		return e
+	}
+	return planEntitlements[FreePlan]
+}
+
+// Limit returns the cap on q, Unlimited when there is none.
+func (e Entitlements) Limit(q Quota) int64 {
+	switch q {
+	case QuotaProducts:
+		return e.MaxProducts
+	case QuotaStaffSeats:
+		return e.StaffSeats
+	case QuotaAPIKeys:
+		return e.APIKeys
+	case QuotaStorageMB:
+		return e.StorageMB
+	case QuotaMonthlyOrders:
+		return e.MonthlyOrders
+	}
+	return 0
+}
+
+// Allows reports whether n more of q fit next to the used ones.
+func (e Entitlements) Allows(q Quota, used, n int64) bool {
+	limit := e.Limit(q)
+	return limit == Unlimited || used+n <= limit
+}
+
+func (e Entitlements) Has(f Feature) bool {
+	switch f {
+	case FeatureCustomDomain:
+		return e.CustomDomain
+	}
+	return false
+}
+
+// upgradeFor returns the cheapest plan above plan that ok accepts.
+func upgradeFor(plan PlanType, ok func(Entitlements) bool) (PlanType, bool) {
+	above := false
+	for _, p := range planOrder {
+		if above && ok(planEntitlements[p]) {
+			return p, true
+		}
+		above = above || p == plan
+	}
+	return "", false
+}
+
+// quotaError tells the store it is out of q and which plan would have
+// room.
+func quotaError(plan PlanType, q Quota, used, n int64) error {
+	err := fmt.Errorf("%w: %d of %d %s used on the %s plan", ErrQuotaExceeded, used, EntitlementsFor(plan).Limit(q), q, plan)
+	if up, ok := upgradeFor(plan, func(e Entitlements) bool { return e.Allows(q, used, n) }); ok {
+		return fmt.Errorf("%w, upgrade to %s for more", err, up)
+	}
+	return err
+}
+
+func featureError(plan PlanType, f Feature) error {
+	err := fmt.Errorf("%w: %s is not on the %s plan", ErrFeatureMissing, f, plan)
+	if up, ok := upgradeFor(plan, func(e Entitlements) bool { return e.Has(f) }); ok {
+		return fmt.Errorf("%w, upgrade to %s to use it", err, up)
+	}
+	return err
+}
+
+// Usage is what a store consumes of each quota. Monthly orders count the
+// current calendar month.
+type Usage struct {
+	Plan         PlanType
+	Entitlements Entitlements
+	Used         map[Quota]int64
+}
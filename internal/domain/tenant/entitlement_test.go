@@ -0,0 +1,57 @@
+package tenant
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeHint(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+		hint string
+	}{
+		{"products on free", quotaError(FreePlan, QuotaProducts, 25, 1), ErrQuotaExceeded, "upgrade to core"},
+		{"bulk past core", quotaError(CorePlan, QuotaProducts, 400, 200), ErrQuotaExceeded, "upgrade to pro"},
+		{"api keys on free", quotaError(FreePlan, QuotaAPIKeys, 0, 1), ErrQuotaExceeded, "upgrade to core"},
+		{"domain on free", featureError(FreePlan, FeatureCustomDomain), ErrFeatureMissing, "upgrade to core"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.want) {
+				t.Fatalf("error %q is not %v", tt.err, tt.want)
+			}
+			if !strings.Contains(tt.err.Error(), tt.hint) {
+				t.Fatalf("error %q lacks hint %q", tt.err, tt.hint)
+			}
+		})
+	}
+
+	if _, ok := upgradeFor(EnterprisePlan, func(Entitlements) bool { return true }); ok {
+		t.Fatal("enterprise has an upgrade")
+	}
+}
+
+func TestEntitlementsAllows(t *testing.T) {
+	if !EntitlementsFor(EnterprisePlan).Allows(QuotaProducts, 1_000_000, 1) {
+		t.Fatal("enterprise products are capped")
+	}
+	free := EntitlementsFor(FreePlan)
+	if !free.Allows(QuotaStaffSeats, 0, 1) || free.Allows(QuotaStaffSeats, 1, 1) {
+		t.Fatal("free plan staff seat limit is not 1")
+	}
+	if EntitlementsFor("unknown") != free {
+		t.Fatal("unknown plan does not fall back to free")
+	}
+}
+
+func TestQuotaEnforced(t *testing.T) {
+	for _, q := range Quotas {
+		want := q != QuotaStorageMB && q != QuotaMonthlyOrders
+		if got := q.Enforced(); got != want {
+			t.Errorf("%s enforced = %v, want %v", q, got, want)
+		}
+	}
+}
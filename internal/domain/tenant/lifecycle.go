@@ -0,0 +1,135 @@
+package tenant
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// Trigger is who moved a store to a new status.
+type Trigger string
+
+var triggers = make(map[string]Trigger)
+
+func newTrigger(v string) Trigger {
+	t := Trigger(v)
+	triggers[v] = t
+	return t
+}
+
+var (
+	// TriggerOwner is the store's owner, taking it live or pausing it.
+	TriggerOwner = newTrigger("owner")
+	// TriggerPlatform is platform staff.
+	TriggerPlatform = newTrigger("platform")
+	// TriggerSystem is a job acting on trials, billing and the like.
+	TriggerSystem = newTrigger("system")
+)
+
+func ParseTrigger(v string) (Trigger, error) {
+	t, ok := triggers[strings.ToLower(v)]
+	if !ok {
+		return "", fmt.Errorf("invalid status trigger: %v", v)
+	}
+	return t, nil
+}
+
+var (
+	ErrInvalidTransition = errors.New("store cannot move between these statuses")
+	ErrStatusConflict    = errors.New("store status changed meanwhile, try again")
+	ErrNotSuspended      = errors.New("store is not suspended")
+)
+
+// transitions lists, for each status, where a store may go from it and who
+// may take it there. A store starts in maintenance, its owner takes it live
+// and may pause it again. Suspension is the platform's or a job's doing and
+// only they lift it. Archived is final.
+var transitions = map[TenantStatus]map[TenantStatus][]Trigger{
+	TenantStatusMaintenance: {
+		TenantStatusActive:    {TriggerOwner, TriggerPlatform},
+		TenantStatusSuspended: {TriggerPlatform, TriggerSystem},
+		TenantStatusArchived:  {TriggerPlatform, TriggerSystem},
+	},
+	TenantStatusActive: {
+		TenantStatusMaintenance: {TriggerOwner, TriggerPlatform},
+		TenantStatusSuspended:   {TriggerPlatform, TriggerSystem},
+		TenantStatusArchived:    {TriggerPlatform, TriggerSystem},
+	},
+	TenantStatusSuspended: {
+		TenantStatusMaintenance: {TriggerPlatform, TriggerSystem},
+		TenantStatusActive:      {TriggerPlatform, TriggerSystem},
+		TenantStatusArchived:    {TriggerPlatform, TriggerSystem},
+	},
+	TenantStatusArchived: {},
+}
+
+// needsReason holds the statuses nobody should land a store in without
+// saying why.
+var needsReason = map[TenantStatus]bool{
+	TenantStatusSuspended: true,
+	TenantStatusArchived:  true,
+}
+
+const maxReasonLength = 500
+
+// StatusChange is one entry of a store's status history.
+type StatusChange struct {
+	ID       uuid.UUID
+	TenantID uuid.UUID
+	From     TenantStatus
+	To       TenantStatus
+	Reason   string
+	Trigger  Trigger
+	// ActorID is the user behind an owner or platform change, nil for the
+	// system.
+	ActorID   *uuid.UUID
+	CreatedAt time.Time
+}
+
+// StatusRequest asks for a store to be moved to To.
+type StatusRequest struct {
+	To      TenantStatus
+	Reason  string
+	Trigger Trigger
+	ActorID *uuid.UUID
+}
+
+// CanTransition reports whether trigger may move a store from one status
+// to the other.
+func CanTransition(from, to TenantStatus, trigger Trigger) bool {
+	return slices.Contains(transitions[from][to], trigger)
+}
+
+// newStatusChange checks req against the store's current status and
+// returns the history entry recording it.
+func newStatusChange(te *TenantProfile, req StatusRequest) (*StatusChange, error) {
+	reason := strings.TrimSpace(req.Reason)
+	switch {
+	case te.Status == req.To:
+		return nil, fmt.Errorf("store is already %s", req.To)
+	case !CanTransition(te.Status, req.To, req.Trigger):
+		return nil, fmt.Errorf("%w: %s to %s by %s", ErrInvalidTransition, te.Status, req.To, req.Trigger)
+	case needsReason[req.To] && reason == "":
+		return nil, errors.New("a reason is required")
+	case utf8.RuneCountInString(reason) > maxReasonLength:
+		return nil, fmt.Errorf("reason cannot be more than %d characters", maxReasonLength)
+	case req.Trigger != TriggerSystem && req.ActorID == nil:
+		return nil, fmt.Errorf("%s status change needs an actor", req.Trigger)
+	}
+
+	return &StatusChange{
+		ID:        uuid.New(),
+		TenantID:  te.ID,
+		From:      te.Status,
+		To:        req.To,
+		Reason:    reason,
+		Trigger:   req.Trigger,
+		ActorID:   req.ActorID,
+		CreatedAt: time.Now(),
+	}, nil
+}
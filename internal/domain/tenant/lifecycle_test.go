@@ -0,0 +1,45 @@
+package tenant
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestNewStatusChange(t *testing.T) {
+	actor := uuid.New()
+	tests := []struct {
+		name    string
+		from    TenantStatus
+		req     StatusRequest
+		wantErr error
+		ok      bool
+	}{
+		{"owner goes live", TenantStatusMaintenance, StatusRequest{To: TenantStatusActive, Trigger: TriggerOwner, ActorID: &actor}, nil, true},
+		{"owner pauses", TenantStatusActive, StatusRequest{To: TenantStatusMaintenance, Trigger: TriggerOwner, ActorID: &actor}, nil, true},
+		{"owner cannot suspend", TenantStatusActive, StatusRequest{To: TenantStatusSuspended, Reason: "x", Trigger: TriggerOwner, ActorID: &actor}, ErrInvalidTransition, false},
+		{"owner cannot lift suspension", TenantStatusSuspended, StatusRequest{To: TenantStatusActive, Trigger: TriggerOwner, ActorID: &actor}, ErrInvalidTransition, false},
+		{"platform suspends", TenantStatusActive, StatusRequest{To: TenantStatusSuspended, Reason: "fraud", Trigger: TriggerPlatform, ActorID: &actor}, nil, true},
+		{"suspend needs reason", TenantStatusActive, StatusRequest{To: TenantStatusSuspended, Trigger: TriggerPlatform, ActorID: &actor}, nil, false},
+		{"system suspends without actor", TenantStatusActive, StatusRequest{To: TenantStatusSuspended, Reason: "trial ended", Trigger: TriggerSystem}, nil, true},
+		{"platform needs actor", TenantStatusActive, StatusRequest{To: TenantStatusArchived, Reason: "closed", Trigger: TriggerPlatform}, nil, false},
+		{"archived is final", TenantStatusArchived, StatusRequest{To: TenantStatusActive, Trigger: TriggerPlatform, ActorID: &actor}, ErrInvalidTransition, false},
+		{"same status", TenantStatusActive, StatusRequest{To: TenantStatusActive, Trigger: TriggerOwner, ActorID: &actor}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			te := &TenantProfile{ID: uuid.New(), Status: tt.from}
+			c, err := newStatusChange(te, tt.req)
+			if (err == nil) != tt.ok {
+				t.Fatalf("err = %v, want ok %v", err, tt.ok)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if tt.ok && (c.From != tt.from || c.To != tt.req.To || c.TenantID != te.ID) {
+				t.Fatalf("change = %+v", c)
+			}
+		})
+	}
+}
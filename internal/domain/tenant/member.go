@@ -0,0 +1,92 @@
+package tenant
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+)
+
+// ParseMemberRole accepts the roles a store can hand out to its team. The
+// owner role comes with the store and is never granted by invitation.
+func ParseMemberRole(v string) (role.Role, error) {
+	r, err := role.Parse(v)
+	if err != nil {
+		return role.Role{}, err
+	}
+	if r != role.StoreAdmin && r != role.Staff {
+		return role.Role{}, fmt.Errorf("invalid team role: %q", v)
+	}
+	return r, nil
+}
+
+// canManage reports whether actor may invite or re-role a member
+// holding target. Owners manage everyone, store admins manage staff.
+func canManage(actor, target role.Role) bool {
+	switch actor {
+	case role.StoreOwner:
+		return target == role.StoreAdmin || target == role.Staff
+	case role.StoreAdmin:
+		return target == role.Staff
+	default:
+		return false
+	}
+}
+
+// Member is a team member of a store. Role decides who may manage whom,
+// CustomRoleID, when set, replaces the permissions that come with Role.
+type Member struct {
+	TenantID     uuid.UUID
+	UserID       uuid.UUID
+	Email        string
+	FirstName    string
+	LastName     string
+	Role         role.Role
+	CustomRoleID *uuid.UUID
+	InvitedBy    *uuid.UUID
+	JoinedAt     time.Time
+	UpdatedAt    time.Time
+}
+
+// CustomRole is a permission set a store defines for its team.
+type CustomRole struct {
+	ID          uuid.UUID
+	TenantID    uuid.UUID
+	Name        string
+	Description string
+	Permissions permission.Set
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+type InvitationStatus string
+
+const (
+	InvitationPending  InvitationStatus = "pending"
+	InvitationAccepted InvitationStatus = "accepted"
+	InvitationRejected InvitationStatus = "rejected"
+	InvitationRevoked  InvitationStatus = "revoked"
+)
+
+// Invitation asks Email to join a store with Role. Token is only set when
+// the invitation is created, the store keeps its hash.
+type Invitation struct {
+	ID           uuid.UUID
+	TenantID     uuid.UUID
+	BusinessName string
+	Email        string
+	Role         role.Role
+	TokenHash    []byte
+	Token        string
+	InvitedBy    uuid.UUID
+	Status       InvitationStatus
+	ExpiresAt    time.Time
+	CreatedAt    time.Time
+	RespondedAt  *time.Time
+}
+
+func (inv *Invitation) IsExpired() bool {
+	return time.Now().After(inv.ExpiresAt)
+}
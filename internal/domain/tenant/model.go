@@ -12,7 +12,6 @@ type CreateTenant struct {
 	BusinessName      string
 	Description       string
 	Subdomain         *string
-	Domain            *string
 	LogoURL           *string
 	BusinessMode      string
 	BusinessCategory  string
@@ -3,8 +3,10 @@ package tenant
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
 )
 
 var (
@@ -13,6 +15,17 @@ var (
 	ErrInvalidUser      = errors.New("invalid or non-existent user")
 	ErrInvalidEnumValue = errors.New("invalid value for enum field")
 	ErrDatabase         = errors.New("database error")
+	ErrTenantNotFound   = errors.New("store not found")
+	ErrTenantSuspended  = errors.New("store is suspended")
+	ErrTenantArchived   = errors.New("store is no longer available")
+	ErrMemberNotFound   = errors.New("team member not found")
+	ErrMemberExists     = errors.New("user is already a team member")
+	ErrInviteNotFound   = errors.New("invitation not found")
+	ErrInviteExists     = errors.New("a pending invitation already exists for this email")
+	ErrRoleNotFound     = errors.New("role not found")
+	ErrRoleExists       = errors.New("a role with this name already exists")
+	ErrAPIKeyNotFound   = errors.New("api key not found")
+	ErrSeveralStores    = errors.New("user is in several stores, name one")
 )
 
 type TenantRepository interface {
@@ -20,4 +33,48 @@ type TenantRepository interface {
 	CreateTenantSchema(ctx context.Context, userID uuid.UUID) error
 	CheckDomainAvailability(ctx context.Context, domain string) (bool, error)
 	CheckSubdomainAvailability(ctx context.Context, subdomain string) (bool, error)
+	GetTenantByID(ctx context.Context, tenantID uuid.UUID) (*TenantProfile, error)
+	GetTenantByHost(ctx context.Context, host string) (*TenantProfile, error)
+	// UpdateTenantStatus moves the store from one status to another, with
+	// ErrStatusConflict when it is no longer in from.
+	UpdateTenantStatus(ctx context.Context, tenantID uuid.UUID, from, to TenantStatus) error
+	CreateStatusChange(ctx context.Context, c *StatusChange) error
+	// ListStatusChanges returns the store's status history, newest first.
+	ListStatusChanges(ctx context.Context, tenantID uuid.UUID) ([]StatusChange, error)
+	// ListTrialsEndingBefore pages through live stores whose trial ends
+	// before the given time and has no recorded expiry, by trial end then
+	// id, starting after the afterAt, afterID pair.
+	ListTrialsEndingBefore(ctx context.Context, before, afterAt time.Time, afterID uuid.UUID, limit int) ([]TenantProfile, error)
+	// ClaimTrialEvent records event for the store's trial ending at
+	// trialEnd, false when it was recorded already.
+	ClaimTrialEvent(ctx context.Context, tenantID uuid.UUID, event TrialEvent, trialEnd time.Time) (bool, error)
+	ReleaseTrialEvent(ctx context.Context, tenantID uuid.UUID, event TrialEvent, trialEnd time.Time) error
+	// EndTrial moves the store to plan and clears its trial end.
+	EndTrial(ctx context.Context, tenantID uuid.UUID, plan PlanType) error
+	// ListUserTenantIDs returns the stores userID owns or is a team member
+	// of, oldest first.
+	ListUserTenantIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	GetMember(ctx context.Context, tenantID, userID uuid.UUID) (*Member, error)
+	ListMembers(ctx context.Context, tenantID uuid.UUID) ([]Member, error)
+	AddMember(ctx context.Context, m *Member) error
+	UpdateMemberRole(ctx context.Context, tenantID, userID uuid.UUID, r role.Role, customRoleID *uuid.UUID) error
+	RemoveMember(ctx context.Context, tenantID, userID uuid.UUID) error
+	CreateInvitation(ctx context.Context, inv *Invitation) error
+	GetInvitation(ctx context.Context, tenantID, invitationID uuid.UUID) (*Invitation, error)
+	GetInvitationByToken(ctx context.Context, hash []byte) (*Invitation, error)
+	ListInvitations(ctx context.Context, tenantID uuid.UUID) ([]Invitation, error)
+	SetInvitationStatus(ctx context.Context, invitationID uuid.UUID, status InvitationStatus) error
+	CreateRole(ctx context.Context, cr *CustomRole) error
+	GetRole(ctx context.Context, tenantID, roleID uuid.UUID) (*CustomRole, error)
+	ListRoles(ctx context.Context, tenantID uuid.UUID) ([]CustomRole, error)
+	UpdateRole(ctx context.Context, cr *CustomRole) error
+	DeleteRole(ctx context.Context, tenantID, roleID uuid.UUID) error
+	CreateAPIKey(ctx context.Context, key *APIKey) error
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]APIKey, error)
+	RevokeAPIKey(ctx context.Context, tenantID, keyID uuid.UUID) error
+	TouchAPIKey(ctx context.Context, keyID uuid.UUID) error
+	// CountUsage returns how much of q the store consumes, storage in
+	// whole megabytes rounded up.
+	CountUsage(ctx context.Context, t *TenantProfile, q Quota) (int64, error)
 }
@@ -2,6 +2,7 @@ package tenant
 
 import (
 	"errors"
+	"net"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -12,6 +13,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// PlatformDomain is the parent of every store's subdomain.
+const PlatformDomain = "merchcore.com"
+
 type TenantProfile struct {
 	ID                uuid.UUID
 	UserID            uuid.UUID
@@ -50,9 +54,12 @@ func NewTenantProfile(storeInfo CreateTenant) (*TenantProfile, error) {
 
 	var subdomain string
 	if storeInfo.Subdomain == nil {
-		subdomain = strings.ToLower(strings.ReplaceAll(businessName, " ", "")) + ".merchcore.com"
+		subdomain = strings.ToLower(strings.ReplaceAll(businessName, " ", "")) + "." + PlatformDomain
 	} else {
-		subdomain = *storeInfo.Subdomain
+		subdomain = NormalizeHost(*storeInfo.Subdomain)
+		if !strings.HasSuffix(subdomain, "."+PlatformDomain) {
+			subdomain += "." + PlatformDomain
+		}
 	}
 
 	plan, err := ParsePlanType(storeInfo.Plan)
@@ -74,11 +81,6 @@ func NewTenantProfile(storeInfo CreateTenant) (*TenantProfile, error) {
 		storeInfo.LogoURL = &logo
 	}
 
-	if storeInfo.Domain != nil {
-		domain := strings.TrimSpace(*storeInfo.Domain)
-		storeInfo.Domain = &domain
-	}
-
 	var addresses address.Addresses
 
 	if mode == BusinessModeHybrid {
@@ -124,11 +126,9 @@ func NewTenantProfile(storeInfo CreateTenant) (*TenantProfile, error) {
 		logoURL = *storeInfo.LogoURL
 	}
 
-	domain := storeInfo.Domain
-	if domain == nil || strings.TrimSpace(*domain) == "" {
-		defaultDomain := subdomain
-		domain = &defaultDomain
-	}
+	// a store is served on its subdomain until a custom domain it adds is
+	// verified, see the customdomain package
+	domain := subdomain
 
 	tenant := &TenantProfile{
 		ID:           uuid.New(),
@@ -136,7 +136,7 @@ func NewTenantProfile(storeInfo CreateTenant) (*TenantProfile, error) {
 		BusinessName: businessName,
 		Description:  description,
 		Subdomain:    &subdomain,
-		Domain:       domain,
+		Domain:       &domain,
 		LogoURL:      logoURL,
 		Status:       TenantStatusMaintenance,
 		Plan:         plan,
@@ -151,14 +151,12 @@ func NewTenantProfile(storeInfo CreateTenant) (*TenantProfile, error) {
 	return tenant, nil
 }
 
-// // Example methods for invariants
-// func (t *TenantProfile) IsTrialActive(now time.Time) bool {
-// 	return t.Status == TenantStatusTrial && now.Before(*t.TrialEndAt)
-// }
-
-// func (t *TenantProfile) UpgradeToActive(domain string) {
-// 	t.Status = TenantStatusActive
-// 	t.Domain = domain
-// 	t.UpdatedAt = time.Now()
-// 	// Trigger hooks in service: provision custom domain, etc.
-// }
+// NormalizeHost lowercases a host name and drops any port and trailing dot,
+// the form domains and subdomains are stored and looked up in.
+func NormalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
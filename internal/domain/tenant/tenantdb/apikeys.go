@@ -0,0 +1,142 @@
+package tenantdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+const apiKeyColumns = `id, tenant_id, name, prefix, key_hash, scopes, created_by,
+		       expires_at, last_used_at, revoked_at, created_at`
+
+func scanAPIKey(row pgx.Row) (tenant.APIKey, error) {
+	var (
+		k      tenant.APIKey
+		scopes []string
+	)
+	err := row.Scan(
+		&k.ID,
+		&k.TenantID,
+		&k.Name,
+		&k.Prefix,
+		&k.KeyHash,
+		&scopes,
+		&k.CreatedBy,
+		&k.ExpiresAt,
+		&k.LastUsedAt,
+		&k.RevokedAt,
+		&k.CreatedAt,
+	)
+	if err != nil {
+		return tenant.APIKey{}, err
+	}
+
+	k.Scopes = permission.NewSet()
+	for _, v := range scopes {
+		if p, err := permission.Parse(v); err == nil {
+			k.Scopes.Add(p)
+		}
+	}
+	return k, nil
+}
+
+func (t *tenantStore) CreateAPIKey(ctx context.Context, key *tenant.APIKey) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		INSERT INTO tenant_api_keys (id, tenant_id, name, prefix, key_hash, scopes, created_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query,
+		key.ID,
+		key.TenantID,
+		key.Name,
+		key.Prefix,
+		key.KeyHash,
+		key.Scopes.Strings(),
+		key.CreatedBy,
+		key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (t *tenantStore) GetAPIKeyByPrefix(ctx context.Context, prefix string) (*tenant.APIKey, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `SELECT ` + apiKeyColumns + ` FROM tenant_api_keys WHERE prefix = $1`
+	k, err := scanAPIKey(conn.QueryRow(ctx, query, prefix))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return &k, nil
+}
+
+func (t *tenantStore) ListAPIKeys(ctx context.Context, tenantID uuid.UUID) ([]tenant.APIKey, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + apiKeyColumns + `
+		FROM tenant_api_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	keys, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.APIKey, error) {
+		return scanAPIKey(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return keys, nil
+}
+
+func (t *tenantStore) RevokeAPIKey(ctx context.Context, tenantID, keyID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenant_api_keys
+		SET revoked_at = now()
+		WHERE tenant_id = $1 AND id = $2 AND revoked_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, tenantID, keyID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchAPIKey records use of the key at most once a minute, a busy
+// integration should not turn every request into a write.
+func (t *tenantStore) TouchAPIKey(ctx context.Context, keyID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenant_api_keys
+		SET last_used_at = now()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < now() - interval '1 minute')
+	`
+	if _, err := conn.Exec(ctx, query, keyID); err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package tenantdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func (t *tenantStore) UpdateTenantStatus(ctx context.Context, tenantID uuid.UUID, from, to tenant.TenantStatus) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenants
+		SET status = $3, updated_at = now()
+		WHERE id = $1 AND status = $2 AND deleted_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, tenantID, from, to)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrStatusConflict
+	}
+	return nil
+}
+
+func (t *tenantStore) CreateStatusChange(ctx context.Context, c *tenant.StatusChange) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		INSERT INTO tenant_status_changes (id, tenant_id, from_status, to_status, reason, trigger, actor_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := conn.Exec(ctx, query, c.ID, c.TenantID, c.From, c.To, c.Reason, c.Trigger, c.ActorID, c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (t *tenantStore) ListStatusChanges(ctx context.Context, tenantID uuid.UUID) ([]tenant.StatusChange, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT id, tenant_id, from_status, to_status, reason, trigger, actor_id, created_at
+		FROM tenant_status_changes
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	changes, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.StatusChange, error) {
+		var (
+			c                 tenant.StatusChange
+			from, to, trigger string
+		)
+		err := row.Scan(&c.ID, &c.TenantID, &from, &to, &c.Reason, &trigger, &c.ActorID, &c.CreatedAt)
+		c.From, c.To, c.Trigger = tenant.TenantStatus(from), tenant.TenantStatus(to), tenant.Trigger(trigger)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return changes, nil
+}
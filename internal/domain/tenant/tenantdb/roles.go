@@ -0,0 +1,147 @@
+package tenantdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const roleColumns = `id, tenant_id, name, description, permissions, created_at, updated_at`
+
+// scanRole drops permissions that are no longer in the catalog rather than
+// failing, a retired permission simply stops granting anything.
+func scanRole(row pgx.Row) (tenant.CustomRole, error) {
+	var (
+		cr    tenant.CustomRole
+		perms []string
+	)
+	err := row.Scan(
+		&cr.ID,
+		&cr.TenantID,
+		&cr.Name,
+		&cr.Description,
+		&perms,
+		&cr.CreatedAt,
+		&cr.UpdatedAt,
+	)
+	if err != nil {
+		return tenant.CustomRole{}, err
+	}
+
+	cr.Permissions = permission.NewSet()
+	for _, v := range perms {
+		if p, err := permission.Parse(v); err == nil {
+			cr.Permissions.Add(p)
+		}
+	}
+	return cr, nil
+}
+
+func (t *tenantStore) CreateRole(ctx context.Context, cr *tenant.CustomRole) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		INSERT INTO tenant_roles (id, tenant_id, name, description, permissions)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING created_at, updated_at
+	`
+	err := conn.QueryRow(ctx, query,
+		cr.ID,
+		cr.TenantID,
+		cr.Name,
+		cr.Description,
+		cr.Permissions.Strings(),
+	).Scan(&cr.CreatedAt, &cr.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "tenant_roles_name_uq" {
+			return tenant.ErrRoleExists
+		}
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (t *tenantStore) GetRole(ctx context.Context, tenantID, roleID uuid.UUID) (*tenant.CustomRole, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `SELECT ` + roleColumns + ` FROM tenant_roles WHERE tenant_id = $1 AND id = $2`
+	cr, err := scanRole(conn.QueryRow(ctx, query, tenantID, roleID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrRoleNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return &cr, nil
+}
+
+func (t *tenantStore) ListRoles(ctx context.Context, tenantID uuid.UUID) ([]tenant.CustomRole, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `SELECT ` + roleColumns + ` FROM tenant_roles WHERE tenant_id = $1 ORDER BY name`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	roles, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.CustomRole, error) {
+		return scanRole(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return roles, nil
+}
+
+func (t *tenantStore) UpdateRole(ctx context.Context, cr *tenant.CustomRole) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenant_roles
+		SET name = $3, description = $4, permissions = $5, updated_at = now()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING updated_at
+	`
+	err := conn.QueryRow(ctx, query,
+		cr.TenantID,
+		cr.ID,
+		cr.Name,
+		cr.Description,
+		cr.Permissions.Strings(),
+	).Scan(&cr.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return tenant.ErrRoleNotFound
+		}
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "tenant_roles_name_uq" {
+			return tenant.ErrRoleExists
+		}
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+// DeleteRole removes the role, members holding it fall back to the
+// permissions of their built-in role.
+func (t *tenantStore) DeleteRole(ctx context.Context, tenantID, roleID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `DELETE FROM tenant_roles WHERE tenant_id = $1 AND id = $2`
+	res, err := conn.Exec(ctx, query, tenantID, roleID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrRoleNotFound
+	}
+	return nil
+}
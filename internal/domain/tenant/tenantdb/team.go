@@ -0,0 +1,373 @@
+package tenantdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const tenantColumns = `id, user_id, business_name, domain, subdomain, COALESCE(logo_url, ''),
+		       plan, status, business_mode, COALESCE(number_of_employees, 0),
+		       trial_start_at, trial_end_at, created_at, updated_at`
+
+func scanTenant(row pgx.Row) (*tenant.TenantProfile, error) {
+	var (
+		te                 tenant.TenantProfile
+		domain, subdomain  string
+		plan, status, mode string
+	)
+	err := row.Scan(
+		&te.ID,
+		&te.UserID,
+		&te.BusinessName,
+		&domain,
+		&subdomain,
+		&te.LogoURL,
+		&plan,
+		&status,
+		&mode,
+		&te.NumberOfEmployees,
+		&te.TrialStartAt,
+		&te.TrialEndAt,
+		&te.CreatedAt,
+		&te.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrTenantNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	te.Domain, te.Subdomain = &domain, &subdomain
+	te.Plan = tenant.PlanType(plan)
+	te.Status = tenant.TenantStatus(status)
+	te.BusinessMode = tenant.BusinessMode(mode)
+	return &te, nil
+}
+
+func (t *tenantStore) GetTenantByID(ctx context.Context, tenantID uuid.UUID) (*tenant.TenantProfile, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + tenantColumns + `
+		FROM tenants
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	return scanTenant(conn.QueryRow(ctx, query, tenantID))
+}
+
+// GetTenantByHost finds the store serving a normalized host name, its own
+// domain first, then its subdomain. Archived stores are returned too so
+// the caller can tell them from unknown hosts.
+func (t *tenantStore) GetTenantByHost(ctx context.Context, host string) (*tenant.TenantProfile, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + tenantColumns + `
+		FROM tenants
+		WHERE domain = $1 OR subdomain = $1
+		ORDER BY domain = $1 DESC
+		LIMIT 1
+	`
+	return scanTenant(conn.QueryRow(ctx, query, host))
+}
+
+func (t *tenantStore) ListUserTenantIDs(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT t.id
+		FROM tenants t
+		LEFT JOIN tenant_members m ON m.tenant_id = t.id AND m.user_id = $1
+		WHERE t.deleted_at IS NULL AND (t.user_id = $1 OR m.user_id IS NOT NULL)
+		ORDER BY t.created_at
+	`
+	rows, err := conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return ids, nil
+}
+
+const memberColumns = `m.tenant_id, m.user_id, u.email, u.first_name, u.last_name,
+		       m.role, m.custom_role_id, m.invited_by, m.joined_at, m.updated_at`
+
+func scanMember(row pgx.Row) (tenant.Member, error) {
+	var (
+		m       tenant.Member
+		roleStr string
+	)
+	err := row.Scan(
+		&m.TenantID,
+		&m.UserID,
+		&m.Email,
+		&m.FirstName,
+		&m.LastName,
+		&roleStr,
+		&m.CustomRoleID,
+		&m.InvitedBy,
+		&m.JoinedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return tenant.Member{}, err
+	}
+	if m.Role, err = role.Parse(roleStr); err != nil {
+		return tenant.Member{}, err
+	}
+	return m, nil
+}
+
+func (t *tenantStore) GetMember(ctx context.Context, tenantID, userID uuid.UUID) (*tenant.Member, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + memberColumns + `
+		FROM tenant_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1 AND m.user_id = $2
+	`
+	m, err := scanMember(conn.QueryRow(ctx, query, tenantID, userID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrMemberNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return &m, nil
+}
+
+func (t *tenantStore) ListMembers(ctx context.Context, tenantID uuid.UUID) ([]tenant.Member, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + memberColumns + `
+		FROM tenant_members m
+		JOIN users u ON u.id = m.user_id
+		WHERE m.tenant_id = $1
+		ORDER BY m.joined_at
+	`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	members, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.Member, error) {
+		return scanMember(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return members, nil
+}
+
+func (t *tenantStore) AddMember(ctx context.Context, m *tenant.Member) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		INSERT INTO tenant_members (tenant_id, user_id, role, invited_by)
+		VALUES ($1, $2, $3, $4)
+	`
+	_, err := conn.Exec(ctx, query, m.TenantID, m.UserID, m.Role.String(), m.InvitedBy)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "tenant_members_pk" {
+			return tenant.ErrMemberExists
+		}
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (t *tenantStore) UpdateMemberRole(ctx context.Context, tenantID, userID uuid.UUID, r role.Role, customRoleID *uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenant_members
+		SET role = $3, custom_role_id = $4, updated_at = now()
+		WHERE tenant_id = $1 AND user_id = $2
+	`
+	res, err := conn.Exec(ctx, query, tenantID, userID, r.String(), customRoleID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrMemberNotFound
+	}
+	return nil
+}
+
+func (t *tenantStore) RemoveMember(ctx context.Context, tenantID, userID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `DELETE FROM tenant_members WHERE tenant_id = $1 AND user_id = $2`
+	res, err := conn.Exec(ctx, query, tenantID, userID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrMemberNotFound
+	}
+	return nil
+}
+
+// CreateInvitation retires an expired invitation for the same address
+// first, only a live one blocks a new invite.
+func (t *tenantStore) CreateInvitation(ctx context.Context, inv *tenant.Invitation) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		WITH expired AS (
+			UPDATE tenant_invitations
+			SET status = 'revoked', responded_at = now()
+			WHERE tenant_id = $2 AND email = $3 AND status = 'pending' AND expires_at <= now()
+		)
+		INSERT INTO tenant_invitations (id, tenant_id, email, role, token_hash, invited_by, status, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query,
+		inv.ID,
+		inv.TenantID,
+		inv.Email,
+		inv.Role.String(),
+		inv.TokenHash,
+		inv.InvitedBy,
+		inv.Status,
+		inv.ExpiresAt,
+	).Scan(&inv.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "tenant_invitations_pending_uq" {
+			return tenant.ErrInviteExists
+		}
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+const invitationColumns = `i.id, i.tenant_id, t.business_name, i.email, i.role, i.token_hash,
+		       i.invited_by, i.status, i.expires_at, i.created_at, i.responded_at`
+
+func scanInvitation(row pgx.Row) (tenant.Invitation, error) {
+	var (
+		inv     tenant.Invitation
+		roleStr string
+	)
+	err := row.Scan(
+		&inv.ID,
+		&inv.TenantID,
+		&inv.BusinessName,
+		&inv.Email,
+		&roleStr,
+		&inv.TokenHash,
+		&inv.InvitedBy,
+		&inv.Status,
+		&inv.ExpiresAt,
+		&inv.CreatedAt,
+		&inv.RespondedAt,
+	)
+	if err != nil {
+		return tenant.Invitation{}, err
+	}
+	if inv.Role, err = role.Parse(roleStr); err != nil {
+		return tenant.Invitation{}, err
+	}
+	return inv, nil
+}
+
+func (t *tenantStore) GetInvitation(ctx context.Context, tenantID, invitationID uuid.UUID) (*tenant.Invitation, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + invitationColumns + `
+		FROM tenant_invitations i
+		JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.tenant_id = $1 AND i.id = $2
+	`
+	inv, err := scanInvitation(conn.QueryRow(ctx, query, tenantID, invitationID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return &inv, nil
+}
+
+func (t *tenantStore) GetInvitationByToken(ctx context.Context, hash []byte) (*tenant.Invitation, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + invitationColumns + `
+		FROM tenant_invitations i
+		JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.token_hash = $1
+	`
+	inv, err := scanInvitation(conn.QueryRow(ctx, query, hash))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, tenant.ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return &inv, nil
+}
+
+// ListInvitations returns the invitations still waiting for an answer.
+func (t *tenantStore) ListInvitations(ctx context.Context, tenantID uuid.UUID) ([]tenant.Invitation, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + invitationColumns + `
+		FROM tenant_invitations i
+		JOIN tenants t ON t.id = i.tenant_id
+		WHERE i.tenant_id = $1 AND i.status = 'pending' AND i.expires_at > now()
+		ORDER BY i.created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	invitations, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.Invitation, error) {
+		return scanInvitation(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return invitations, nil
+}
+
+// SetInvitationStatus answers a pending invitation. Only the first answer
+// wins, later ones report ErrInviteNotFound.
+func (t *tenantStore) SetInvitationStatus(ctx context.Context, invitationID uuid.UUID, status tenant.InvitationStatus) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenant_invitations
+		SET status = $2, responded_at = now()
+		WHERE id = $1 AND status = 'pending'
+	`
+	res, err := conn.Exec(ctx, query, invitationID, status)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrInviteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package tenantdb
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func (t *tenantStore) ListTrialsEndingBefore(ctx context.Context, before, afterAt time.Time, afterID uuid.UUID, limit int) ([]tenant.TenantProfile, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		SELECT ` + tenantColumns + `
+		FROM tenants t
+		WHERE t.trial_end_at IS NOT NULL
+		  AND t.trial_end_at < $1
+		  AND (t.trial_end_at, t.id) > ($2, $3)
+		  AND t.status IN ('active', 'maintenance')
+		  AND t.deleted_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM tenant_trial_events e
+			WHERE e.tenant_id = t.id AND e.kind = 'expired' AND e.trial_end_at = t.trial_end_at
+		  )
+		ORDER BY t.trial_end_at, t.id
+		LIMIT $4
+	`
+	rows, err := conn.Query(ctx, query, before, afterAt, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+
+	tenants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tenant.TenantProfile, error) {
+		te, err := scanTenant(row)
+		if err != nil {
+			return tenant.TenantProfile{}, err
+		}
+		return *te, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return tenants, nil
+}
+
+func (t *tenantStore) ClaimTrialEvent(ctx context.Context, tenantID uuid.UUID, event tenant.TrialEvent, trialEnd time.Time) (bool, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		INSERT INTO tenant_trial_events (tenant_id, kind, trial_end_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+	res, err := conn.Exec(ctx, query, tenantID, event, trialEnd)
+	if err != nil {
+		return false, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return res.RowsAffected() == 1, nil
+}
+
+func (t *tenantStore) ReleaseTrialEvent(ctx context.Context, tenantID uuid.UUID, event tenant.TrialEvent, trialEnd time.Time) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `DELETE FROM tenant_trial_events WHERE tenant_id = $1 AND kind = $2 AND trial_end_at = $3`
+	if _, err := conn.Exec(ctx, query, tenantID, event, trialEnd); err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (t *tenantStore) EndTrial(ctx context.Context, tenantID uuid.UUID, plan tenant.PlanType) error {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	const query = `
+		UPDATE tenants
+		SET plan = $2, trial_end_at = NULL, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, tenantID, plan)
+	if err != nil {
+		return fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return tenant.ErrTenantNotFound
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package tenantdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/lib/pq"
+)
+
+// CountUsage counts in the shared tables, orders and storage in the store's
+// own schema. Storage is what that schema takes on disk.
+func (t *tenantStore) CountUsage(ctx context.Context, te *tenant.TenantProfile, q tenant.Quota) (int64, error) {
+	conn := database.GetTXFromContext(ctx, t.conn)
+
+	schema := fmt.Sprintf("tenant_%s", te.UserID)
+	var (
+		query string
+		args  []any
+	)
+	switch q {
+	case tenant.QuotaProducts:
+		query = `SELECT count(*) FROM products WHERE tenant_id = $1`
+		args = []any{te.ID}
+	case tenant.QuotaStaffSeats:
+		query = `
+			SELECT (SELECT count(*) FROM tenant_members WHERE tenant_id = $1)
+			     + (SELECT count(*) FROM tenant_invitations
+			        WHERE tenant_id = $1 AND status = 'pending' AND expires_at > now())
+		`
+		args = []any{te.ID}
+	case tenant.QuotaAPIKeys:
+		query = `
+			SELECT count(*) FROM tenant_api_keys
+			WHERE tenant_id = $1 AND revoked_at IS NULL
+			  AND (expires_at IS NULL OR expires_at > now())
+		`
+		args = []any{te.ID}
+	case tenant.QuotaStorageMB:
+		query = `
+			SELECT COALESCE(ceil(sum(pg_total_relation_size(c.oid)) / 1048576.0), 0)::bigint
+			FROM pg_class c
+			JOIN pg_namespace n ON n.oid = c.relnamespace
+			WHERE n.nspname = $1 AND c.relkind IN ('r', 'm')
+		`
+		args = []any{schema}
+	case tenant.QuotaMonthlyOrders:
+		query = fmt.Sprintf(`
+			SELECT count(*) FROM %s.orders
+			WHERE created_at >= date_trunc('month', now())
+		`, pq.QuoteIdentifier(schema))
+	default:
+		return 0, fmt.Errorf("unknown quota %q", q)
+	}
+
+	var n int64
+	if err := conn.QueryRow(ctx, query, args...).Scan(&n); err != nil {
+		return 0, fmt.Errorf("%w: %w", tenant.ErrDatabase, err)
+	}
+	return n, nil
+}
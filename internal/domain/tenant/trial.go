@@ -0,0 +1,110 @@
+package tenant
+
+import (
+	"time"
+)
+
+// A store is reminded twice before its trial ends, then the trial is ended
+// for it.
+const (
+	firstReminder = 3 * 24 * time.Hour
+	lastReminder  = 24 * time.Hour
+)
+
+// TrialEvent is something done about a store's trial. Each is done once
+// per trial end date, which is what keeps reruns of the trial scan from
+// mailing or suspending twice.
+type TrialEvent string
+
+const (
+	TrialReminderFirst TrialEvent = "reminder_3d"
+	TrialReminderLast  TrialEvent = "reminder_1d"
+	TrialExpired       TrialEvent = "expired"
+)
+
+// TrialEndedReason is the reason recorded when the trial scan suspends a
+// store. Billing reinstates such a store once it pays for a plan.
+const TrialEndedReason = "trial ended"
+
+// TrialOutcome is what happens to a store once its trial runs out.
+type TrialOutcome struct {
+	// Suspend takes the store offline until it moves to a paid plan.
+	Suspend bool
+	// DowngradeTo is the plan the store continues on otherwise.
+	DowngradeTo PlanType
+}
+
+// trialOutcomes holds the plan rules. The free plan is a trial of the
+// platform, the store is suspended when it ends. A trial of a paid plan
+// falls back to the free plan and the store stays up.
+var trialOutcomes = map[PlanType]TrialOutcome{
+	FreePlan:       {Suspend: true},
+	CorePlan:       {DowngradeTo: FreePlan},
+	ProPlan:        {DowngradeTo: FreePlan},
+	EnterprisePlan: {DowngradeTo: FreePlan},
+}
+
+// TrialExpiry returns what happens to a store on plan when its trial ends.
+func TrialExpiry(plan PlanType) TrialOutcome {
+	if o, ok := trialOutcomes[plan]; ok {
+		return o
+	}
+	return TrialOutcome{Suspend: true}
+}
+
+// Trial is a store's trial as shown to its owner.
+type Trial struct {
+	Plan     PlanType
+	StartAt  *time.Time
+	EndAt    *time.Time
+	Active   bool
+	DaysLeft int
+	// Outcome applies when an active trial ends.
+	Outcome TrialOutcome
+}
+
+// IsTrialActive reports whether the store is on a trial that has not ended.
+func (t *TenantProfile) IsTrialActive(now time.Time) bool {
+	return t.TrialEndAt != nil && now.Before(*t.TrialEndAt)
+}
+
+// Trial is the store's trial countdown as of now. DaysLeft counts started
+// days, a trial ending in two hours has one day left.
+func (t *TenantProfile) Trial(now time.Time) Trial {
+	tr := Trial{
+		Plan:    t.Plan,
+		StartAt: t.TrialStartAt,
+		EndAt:   t.TrialEndAt,
+		Active:  t.IsTrialActive(now),
+		Outcome: TrialExpiry(t.Plan),
+	}
+	if tr.Active {
+		left := t.TrialEndAt.Sub(now)
+		tr.DaysLeft = int((left + 24*time.Hour - 1) / (24 * time.Hour))
+	}
+	return tr
+}
+
+// dueTrialEvent is what the trial scan owes the store now, if anything.
+func (t *TenantProfile) dueTrialEvent(now time.Time) (TrialEvent, bool) {
+	if t.TrialEndAt == nil {
+		return "", false
+	}
+	switch left := t.TrialEndAt.Sub(now); {
+	case left <= 0:
+		return TrialExpired, true
+	case left <= lastReminder:
+		return TrialReminderLast, true
+	case left <= firstReminder:
+		return TrialReminderFirst, true
+	}
+	return "", false
+}
+
+// TrialReport is what one trial scan did.
+type TrialReport struct {
+	Checked    int
+	Reminded   int
+	Suspended  int
+	Downgraded int
+}
@@ -0,0 +1,57 @@
+package tenant
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDueTrialEvent(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		left time.Duration
+		want TrialEvent
+		ok   bool
+	}{
+		{"far off", 5 * 24 * time.Hour, "", false},
+		{"three days", 3*24*time.Hour - time.Minute, TrialReminderFirst, true},
+		{"two days", 2 * 24 * time.Hour, TrialReminderFirst, true},
+		{"one day", 24*time.Hour - time.Minute, TrialReminderLast, true},
+		{"ended", -time.Minute, TrialExpired, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			end := now.Add(tt.left)
+			te := &TenantProfile{TrialEndAt: &end}
+			got, ok := te.dueTrialEvent(now)
+			if got != tt.want || ok != tt.ok {
+				t.Fatalf("dueTrialEvent = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+
+	if _, ok := (&TenantProfile{}).dueTrialEvent(now); ok {
+		t.Fatal("store without a trial has a trial event")
+	}
+}
+
+func TestTrialDaysLeft(t *testing.T) {
+	now := time.Now()
+	for left, want := range map[time.Duration]int{
+		2 * time.Hour:      1,
+		24 * time.Hour:     1,
+		25 * time.Hour:     2,
+		-time.Hour:         0,
+		3 * 24 * time.Hour: 3,
+	} {
+		end := now.Add(left)
+		te := &TenantProfile{Plan: ProPlan, TrialEndAt: &end}
+		tr := te.Trial(now)
+		if tr.DaysLeft != want || tr.Active != (left > 0) {
+			t.Errorf("%s left: days = %d, active = %v, want %d", left, tr.DaysLeft, tr.Active, want)
+		}
+		if tr.Outcome.DowngradeTo != FreePlan {
+			t.Errorf("pro trial downgrades to %q", tr.Outcome.DowngradeTo)
+		}
+	}
+}
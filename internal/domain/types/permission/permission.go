@@ -0,0 +1,206 @@
+package permission
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+)
+
+type Permission struct {
+	value string
+}
+
+var (
+	catalog = make(map[string]Permission)
+	// storeScoped are the permissions that only mean something inside a
+	// store, the ones custom store roles may grant.
+	storeScoped = make(map[Permission]struct{})
+)
+
+func newPermission(v string, store bool) Permission {
+	p := Permission{v}
+	catalog[v] = p
+	if store {
+		storeScoped[p] = struct{}{}
+	}
+	return p
+}
+
+// store permissions
+var (
+	ProductsRead    = newPermission("products:read", true)
+	ProductsWrite   = newPermission("products:write", true)
+	OrdersRead      = newPermission("orders:read", true)
+	OrdersWrite     = newPermission("orders:write", true)
+	OrdersRefund    = newPermission("orders:refund", true)
+	CustomersRead   = newPermission("customers:read", true)
+	CustomersWrite  = newPermission("customers:write", true)
+	AnalyticsRead   = newPermission("analytics:read", true)
+	FinanceRead     = newPermission("finance:read", true)
+	FinanceWithdraw = newPermission("finance:withdraw", true)
+	SettingsRead    = newPermission("settings:read", true)
+	SettingsWrite   = newPermission("settings:write", true)
+	TeamRead        = newPermission("team:read", true)
+	TeamManage      = newPermission("team:manage", true)
+	RolesManage     = newPermission("roles:manage", true)
+	APIKeysManage   = newPermission("api_keys:manage", true)
+	BillingManage   = newPermission("billing:manage", true)
+	AuditRead       = newPermission("audit:read", true)
+	AppsManage      = newPermission("apps:manage", true)
+)
+
+// platform permissions
+var (
+	PlatformTenantsRead   = newPermission("platform:tenants:read", false)
+	PlatformTenantsManage = newPermission("platform:tenants:manage", false)
+	PlatformUsersRead     = newPermission("platform:users:read", false)
+	PlatformUsersManage   = newPermission("platform:users:manage", false)
+	PlatformImpersonate   = newPermission("platform:users:impersonate", false)
+	PlatformAuditRead     = newPermission("platform:audit:read", false)
+)
+
+// sensitive permissions move money or hand out access, they are refused to
+// admins impersonating a user.
+var sensitive = map[Permission]struct{}{
+	FinanceWithdraw: {},
+	BillingManage:   {},
+	APIKeysManage:   {},
+	AppsManage:      {},
+	RolesManage:     {},
+	TeamManage:      {},
+}
+
+func (p Permission) String() string { return p.value }
+
+func (p Permission) IsStoreScoped() bool {
+	_, ok := storeScoped[p]
+	return ok
+}
+
+func (p Permission) IsSensitive() bool {
+	_, ok := sensitive[p]
+	return ok
+}
+
+func Parse(v string) (Permission, error) {
+	p, ok := catalog[strings.ToLower(strings.TrimSpace(v))]
+	if !ok {
+		return Permission{}, fmt.Errorf("invalid permission: %q", v)
+	}
+	return p, nil
+}
+
+// All returns the catalog sorted by name.
+func All() []Permission {
+	out := make([]Permission, 0, len(catalog))
+	for _, p := range catalog {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b Permission) int { return strings.Compare(a.value, b.value) })
+	return out
+}
+
+func storeAll() []Permission {
+	var out []Permission
+	for _, p := range All() {
+		if p.IsStoreScoped() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// platformRoles apply everywhere, storeRoles only inside the store the role
+// was granted in.
+var (
+	platformRoles = map[role.Role][]Permission{
+		role.SystemAdmin: All(),
+		role.Admin: {
+			PlatformTenantsRead, PlatformTenantsManage,
+			PlatformUsersRead,
+			PlatformAuditRead,
+		},
+	}
+
+	storeRoles = map[role.Role][]Permission{
+		role.StoreOwner: storeAll(),
+		role.StoreAdmin: {
+			ProductsRead, ProductsWrite,
+			OrdersRead, OrdersWrite, OrdersRefund,
+			CustomersRead, CustomersWrite,
+			AnalyticsRead, FinanceRead, SettingsRead, SettingsWrite,
+			TeamRead, TeamManage,
+		},
+		role.Staff: {
+			ProductsRead, ProductsWrite,
+			OrdersRead, OrdersWrite,
+			CustomersRead,
+			TeamRead,
+		},
+	}
+)
+
+// ForPlatformRole returns what a platform role may do in any store.
+func ForPlatformRole(r role.Role) Set {
+	return NewSet(platformRoles[r]...)
+}
+
+// ForStoreRole returns the built-in permissions of a store role.
+func ForStoreRole(r role.Role) Set {
+	return NewSet(storeRoles[r]...)
+}
+
+type Set map[Permission]struct{}
+
+func NewSet(perms ...Permission) Set {
+	s := make(Set, len(perms))
+	s.Add(perms...)
+	return s
+}
+
+func (s Set) Add(perms ...Permission) {
+	for _, p := range perms {
+		s[p] = struct{}{}
+	}
+}
+
+func (s Set) Has(p Permission) bool {
+	_, ok := s[p]
+	return ok
+}
+
+func (s Set) HasAll(perms ...Permission) bool {
+	for _, p := range perms {
+		if !s.Has(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Union adds every permission of other to s.
+func (s Set) Union(other Set) Set {
+	for p := range other {
+		s[p] = struct{}{}
+	}
+	return s
+}
+
+func (s Set) Slice() []Permission {
+	out := make([]Permission, 0, len(s))
+	for p := range s {
+		out = append(out, p)
+	}
+	slices.SortFunc(out, func(a, b Permission) int { return strings.Compare(a.value, b.value) })
+	return out
+}
+
+func (s Set) Strings() []string {
+	out := make([]string, 0, len(s))
+	for _, p := range s.Slice() {
+		out = append(out, p.value)
+	}
+	return out
+}
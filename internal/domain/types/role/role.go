@@ -6,6 +6,7 @@ var (
 	SystemAdmin = newRole("system_admin")
 	Admin       = newRole("admin")
 	StoreOwner  = newRole("store_owner")
+	StoreAdmin  = newRole("store_admin")
 	Staff       = newRole("staff")
 	Guest       = newRole("guest")
 )
@@ -0,0 +1,92 @@
+package users
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+)
+
+// BreachedList is a local copy of known breached passwords, kept as sorted
+// SHA-1 digests so no plaintext is ever loaded.
+type BreachedList struct {
+	hashes [][sha1.Size]byte
+}
+
+// LoadBreachedList reads a dump in the "SHA1[:COUNT]" per line format of
+// the Pwned Passwords downloads, gzipped if the name ends in .gz. Lines may
+// also be grouped k-anonymity style, a "PREFIX" line of 5 hex characters
+// followed by "SUFFIX:COUNT" lines as the range API returns them.
+func LoadBreachedList(path string) (*BreachedList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	bl := &BreachedList{}
+	var prefix string
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		digest, _, _ := strings.Cut(line, ":")
+		switch len(digest) {
+		case 5:
+			prefix = digest
+			continue
+		case 35:
+			digest = prefix + digest
+		}
+
+		var h [sha1.Size]byte
+		if len(digest) != 2*sha1.Size {
+			return nil, fmt.Errorf("line %d: invalid hash %q", n, digest)
+		}
+		if _, err := hex.Decode(h[:], []byte(digest)); err != nil {
+			return nil, fmt.Errorf("line %d: invalid hash %q", n, digest)
+		}
+		bl.hashes = append(bl.hashes, h)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+
+	slices.SortFunc(bl.hashes, func(a, b [sha1.Size]byte) int { return bytes.Compare(a[:], b[:]) })
+	bl.hashes = slices.Compact(bl.hashes)
+	return bl, nil
+}
+
+func (bl *BreachedList) Len() int {
+	if bl == nil {
+		return 0
+	}
+	return len(bl.hashes)
+}
+
+func (bl *BreachedList) Contains(password string) bool {
+	if bl == nil {
+		return false
+	}
+	h := sha1.Sum([]byte(password))
+	_, found := slices.BinarySearchFunc(bl.hashes, h, func(a, b [sha1.Size]byte) int { return bytes.Compare(a[:], b[:]) })
+	return found
+}
@@ -0,0 +1,112 @@
+package users
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sha1("password") and sha1("123456")
+const (
+	passwordSHA1 = "5BAA61E4C9B93F3F0682250B6CF8331B7EE68FD8"
+	digitsSHA1   = "7C4A8D09CA3762AF61E59520943DC26494F8941B"
+)
+
+// writeList writes content to a file called name, gzipped if asked.
+func writeList(t *testing.T, name, content string, gzipped bool) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if !gzipped {
+		if _, err := f.WriteString(content); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	gz := gzip.NewWriter(f)
+	if _, err := gz.Write([]byte(content)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadBreachedList(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		content string
+		gzipped bool
+		want    int
+		wantErr bool
+	}{
+		{"plain with counts", "list.txt", passwordSHA1 + ":3861493\n" + digitsSHA1 + ":37359195\n", false, 2, false},
+		{"plain without counts", "list.txt", passwordSHA1 + "\n" + digitsSHA1 + "\n", false, 2, false},
+		{"lower case, comments and blanks", "list.txt", "# pwned\n\n" + "5baa61e4c9b93f3f0682250b6cf8331b7ee68fd8:1\n", false, 1, false},
+		{"duplicates are dropped", "list.txt", passwordSHA1 + ":1\n" + passwordSHA1 + ":2\n", false, 1, false},
+		{"gzipped", "list.txt.gz", passwordSHA1 + ":3861493\n" + digitsSHA1 + ":37359195\n", true, 2, false},
+		{"prefix and suffixes", "list.txt", "5BAA6\n" + passwordSHA1[5:] + ":3861493\n" + "7C4A8\n" + digitsSHA1[5:] + ":37359195\n", false, 2, false},
+		{"prefix mixed with full hashes", "list.txt", "5BAA6\n" + passwordSHA1[5:] + ":1\n" + digitsSHA1 + ":1\n", false, 2, false},
+		{"suffix without prefix", "list.txt", passwordSHA1[5:] + ":1\n", false, 0, true},
+		{"short hash", "list.txt", "5BAA61E4:1\n", false, 0, true},
+		{"not hex", "list.txt", "ZZAA61E4C9B93F3F0682250B6CF8331B7EE68FD8:1\n", false, 0, true},
+		{"not gzip", "list.txt.gz", passwordSHA1 + "\n", false, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bl, err := LoadBreachedList(writeList(t, tt.file, tt.content, tt.gzipped))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("LoadBreachedList succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadBreachedList: %v", err)
+			}
+			if bl.Len() != tt.want {
+				t.Fatalf("Len = %d, want %d", bl.Len(), tt.want)
+			}
+			if !bl.Contains("password") {
+				t.Fatal("list does not contain \"password\"")
+			}
+		})
+	}
+
+	if _, err := LoadBreachedList(filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Fatal("missing file loaded")
+	}
+}
+
+func TestBreachedListContains(t *testing.T) {
+	bl, err := LoadBreachedList(writeList(t, "list.txt", passwordSHA1+":1\n"+digitsSHA1+":1\n", false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		list     *BreachedList
+		password string
+		want     bool
+	}{
+		{bl, "password", true},
+		{bl, "123456", true},
+		{bl, "Password", false},
+		{bl, "password ", false},
+		{bl, "", false},
+		{nil, "password", false},
+		{&BreachedList{}, "password", false},
+	}
+	for _, tt := range tests {
+		if got := tt.list.Contains(tt.password); got != tt.want {
+			t.Errorf("Contains(%q) on %d hashes = %v, want %v", tt.password, tt.list.Len(), got, tt.want)
+		}
+	}
+}
@@ -9,6 +9,20 @@ func SessionRefreshKeys(userID any) string {
 	return fmt.Sprintf("refresh:%v", userID)
 }
 
+// RevokedSession marks a session family whose access tokens must be refused
+// before they expire.
+func RevokedSession(sessionID any) string {
+	return fmt.Sprintf("session:revoked:%v", sessionID)
+}
+
+func AttemptCount(subject string) string {
+	return fmt.Sprintf("attempts:count:%s", subject)
+}
+
+func AttemptLock(subject string) string {
+	return fmt.Sprintf("attempts:lock:%s", subject)
+}
+
 func UserProfile(userID any) string {
 	return fmt.Sprintf("user:profile:%v", userID)
 }
@@ -16,3 +30,14 @@ func UserProfile(userID any) string {
 func TenantSettings(tenantID any) string {
 	return fmt.Sprintf("tenant:settings:%v", tenantID)
 }
+
+// KeyPatterns matches every key above. All of them are written with a TTL,
+// so one found without is left over and safe to drop.
+var KeyPatterns = []string{
+	"access:*",
+	"refresh:*",
+	"session:revoked:*",
+	"attempts:*",
+	"user:profile:*",
+	"tenant:settings:*",
+}
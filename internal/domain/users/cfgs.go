@@ -2,9 +2,11 @@ package users
 
 import (
 	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
 	"github.com/iamonah/merchcore/internal/infra/cache"
 	"github.com/iamonah/merchcore/internal/infra/database"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/rs/zerolog"
 )
 
 func WithUserRepository(store UserRepository) UserBusinessCfg {
@@ -14,20 +16,51 @@ func WithUserRepository(store UserRepository) UserBusinessCfg {
 	}
 }
 
-func WithTrxManager(trx *database.TRXManager) UserBusinessCfg {
+func WithTrxManager(trx database.TransactorTX) UserBusinessCfg {
 	return func(ub *UserBusiness) error {
 		ub.trx = trx
 		return nil
 	}
 }
 
-func WithAuthz(authz *authz.JWTAuthMaker) UserBusinessCfg {
+func WithAuthz(authz authz.TokenMaker) UserBusinessCfg {
 	return func(ub *UserBusiness) error {
 		ub.authz = authz
 		return nil
 	}
 }
 
+func WithPasswordPolicy(policy *PasswordPolicy) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.passwords = policy
+		return nil
+	}
+}
+
+// WithSignInNotifier turns on new sign-in alerts.
+func WithSignInNotifier(notifier SignInNotifier) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.signins = notifier
+		return nil
+	}
+}
+
+// WithLogger reports what fails on the side of a request without failing
+// it, a lost sign-in alert say. Without one nothing is logged.
+func WithLogger(log *zerolog.Logger) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.log = log
+		return nil
+	}
+}
+
+func WithIPLocator(locator IPLocator) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.locator = locator
+		return nil
+	}
+}
+
 func WithConfigs(cfg *config.Config) UserBusinessCfg {
 	return func(ub *UserBusiness) error {
 		ub.config = cfg
@@ -41,3 +74,40 @@ func WithCache(cache cache.Cache) UserBusinessCfg {
 		return nil
 	}
 }
+
+func WithIDTokenVerifier(verifier authz.IDTokenVerifier) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.idverifier = verifier
+		return nil
+	}
+}
+
+func WithSecretBox(box *authz.SecretBox) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.secrets = box
+		return nil
+	}
+}
+
+func WithLockNotifier(notifier LockNotifier) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.notifier = notifier
+		return nil
+	}
+}
+
+func WithAuditor(auditor audit.Recorder) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.auditor = auditor
+		return nil
+	}
+}
+
+// WithStoreArchiver is how PurgeAccount archives the stores the account
+// owns.
+func WithStoreArchiver(stores StoreArchiver) UserBusinessCfg {
+	return func(ub *UserBusiness) error {
+		ub.stores = stores
+		return nil
+	}
+}
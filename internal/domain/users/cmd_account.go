@@ -0,0 +1,309 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	dataExportTTL = 7 * 24 * time.Hour
+	// purgeSkew lets the purge job run a little early, its schedule and the
+	// database clock can disagree slightly.
+	purgeSkew = time.Minute
+	// purgeSweepDelay is how long past its due time a purge is left to its
+	// job before the cleanup sweep does it.
+	purgeSweepDelay = time.Hour
+)
+
+// DeleteAccount soft deletes the account and signs it out everywhere. The
+// data is anonymized once the grace period ends, signing in before that
+// cancels the deletion. It returns when the purge becomes due.
+func (s *UserBusiness) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) (time.Time, error) {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return time.Time{}, errs.NewDomainError(errs.NotFound, err)
+		}
+		return time.Time{}, fmt.Errorf("getuserbyid: %w", err)
+	}
+
+	if len(user.PasswordHash) > 0 {
+		if err := ComparePassword(user.PasswordHash, []byte(password)); err != nil {
+			if errors.Is(err, ErrInvalidPassword) {
+				return time.Time{}, errs.NewDomainError(errs.InvalidArgument, errors.New("current password incorrect"))
+			}
+			return time.Time{}, fmt.Errorf("comparepassword: %w", err)
+		}
+	}
+
+	var deletedAt time.Time
+	var families []uuid.UUID
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		if deletedAt, err = s.storer.SoftDeleteUser(ctx, userID); err != nil {
+			return err
+		}
+		if families, err = s.storer.BlockUserSessions(ctx, userID); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			Action:     "user.account_delete",
+			TargetType: "user",
+			TargetID:   userID.String(),
+			After:      map[string]any{"deleted_at": deletedAt},
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return time.Time{}, errs.NewDomainError(errs.FailedPrecondition, errors.New("account already scheduled for deletion"))
+		}
+		return time.Time{}, fmt.Errorf("deleteaccount-trx: %w", err)
+	}
+
+	if err := s.clearSessionCache(ctx, userID, families...); err != nil {
+		return time.Time{}, err
+	}
+	return deletedAt.Add(s.config.Auth.AccountDeletionGrace), nil
+}
+
+// restoreAccount cancels a pending deletion when its owner signs back in.
+func (s *UserBusiness) restoreAccount(ctx context.Context, user User) error {
+	if user.DeletedAt == nil {
+		return nil
+	}
+	if err := s.storer.RestoreUser(ctx, user.UserID); err != nil && !errors.Is(err, ErrUserNotFound) {
+		return fmt.Errorf("restoreuser: %w", err)
+	}
+	return nil
+}
+
+// unusablePassword hashes a random secret, a password nobody knows.
+func (s *UserBusiness) unusablePassword() ([]byte, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("randread: %w", err)
+	}
+	hash, err := HashPassword(secret, s.passwords.HashParams())
+	if err != nil {
+		return nil, fmt.Errorf("hashpassword: %w", err)
+	}
+	return hash, nil
+}
+
+// dropUnprovenAccess replaces the password of an account nobody has proved
+// the address of with unusable, and drops its MFA enrollment and sessions:
+// whoever set them up may not own the mailbox. It runs within the
+// transaction on ctx and returns the blocked session families.
+func (s *UserBusiness) dropUnprovenAccess(ctx context.Context, userID uuid.UUID, unusable []byte) ([]uuid.UUID, error) {
+	if err := s.storer.UpdatePassword(ctx, userID, unusable); err != nil {
+		return nil, err
+	}
+	if err := s.storer.DeleteMFA(ctx, userID); err != nil && !errors.Is(err, ErrMFANotFound) {
+		return nil, err
+	}
+	return s.storer.BlockUserSessions(ctx, userID)
+}
+
+// PurgeAccount anonymizes a soft deleted user whose grace period is over
+// and archives the stores they own. It reports false when there was nothing
+// to do: the account was restored, deleted again later, or already purged.
+func (s *UserBusiness) PurgeAccount(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getuserbyid: %w", err)
+	}
+	if user.DeletedAt == nil || time.Since(*user.DeletedAt)+purgeSkew < s.config.Auth.AccountDeletionGrace {
+		return false, nil
+	}
+
+	if err := s.archiveOwnedStores(ctx, userID); err != nil {
+		return false, err
+	}
+
+	unusable, err := s.unusablePassword()
+	if err != nil {
+		return false, err
+	}
+
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		return s.storer.AnonymizeUser(ctx, userID, unusable)
+	})
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("purgeaccount-trx: %w", err)
+	}
+	return true, nil
+}
+
+// StoreArchiver moves the stores of a purged owner along their lifecycle,
+// *tenant.TenantBusiness satisfies it.
+type StoreArchiver interface {
+	ChangeStatus(ctx context.Context, tenantID uuid.UUID, req tenant.StatusRequest) (*tenant.TenantProfile, error)
+}
+
+// archiveOwnedStores takes the stores of a purged owner offline through
+// their lifecycle, so each gets a status history entry and stops being
+// served. The stores are kept for the records the platform must retain.
+// Stores already archived or deleted are skipped, a retried purge picks up
+// where the last one failed.
+func (s *UserBusiness) archiveOwnedStores(ctx context.Context, userID uuid.UUID) error {
+	owned, err := s.storer.ListOwnedTenants(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("listownedtenants: %w", err)
+	}
+
+	for _, t := range owned {
+		if tenant.TenantStatus(t.Status) == tenant.TenantStatusArchived {
+			continue
+		}
+		if s.stores == nil {
+			return fmt.Errorf("tenant[%s]: no store archiver configured", t.ID)
+		}
+		_, err := s.stores.ChangeStatus(ctx, t.ID, tenant.StatusRequest{
+			To:      tenant.TenantStatusArchived,
+			Reason:  "owner account deleted",
+			Trigger: tenant.TriggerSystem,
+		})
+		if err != nil {
+			if derr, ok := errs.IsDomainError(err); ok && derr.Code == errs.NotFound {
+				continue
+			}
+			return fmt.Errorf("tenant[%s]: changestatus: %w", t.ID, err)
+		}
+	}
+	return nil
+}
+
+// PurgeOverdueAccounts purges up to limit deleted accounts whose purge job
+// should have run a while ago, whether it was never enqueued or gave up.
+// It returns how many were purged.
+func (s *UserBusiness) PurgeOverdueAccounts(ctx context.Context, limit int) (int64, error) {
+	before := time.Now().Add(-s.config.Auth.AccountDeletionGrace - purgeSweepDelay)
+	ids, err := s.storer.ListOverduePurges(ctx, before, limit)
+	if err != nil {
+		return 0, fmt.Errorf("listoverduepurges: %w", err)
+	}
+
+	var purged int64
+	for _, id := range ids {
+		ok, err := s.PurgeAccount(ctx, id)
+		if err != nil {
+			return purged, fmt.Errorf("user[%s]: %w", id, err)
+		}
+		if ok {
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// RequestDataExport records a pending export for the export job to build.
+func (s *UserBusiness) RequestDataExport(ctx context.Context, userID uuid.UUID) (*DataExport, error) {
+	export := &DataExport{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: ExportPending,
+	}
+	if err := s.storer.CreateDataExport(ctx, export); err != nil {
+		if errors.Is(err, ErrExportInProgress) {
+			return nil, errs.NewDomainError(errs.Aborted, err)
+		}
+		return nil, fmt.Errorf("createdataexport: %w", err)
+	}
+	return export, nil
+}
+
+// BuildDataExport is run by the export job. It stores the archive and
+// returns its owner with the download link to mail them. Running it again
+// rebuilds the archive and invalidates the previous link.
+func (s *UserBusiness) BuildDataExport(ctx context.Context, exportID uuid.UUID) (*User, string, error) {
+	export, err := s.storer.GetDataExport(ctx, exportID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getdataexport: %w", err)
+	}
+
+	user, err := s.storer.GetUserByID(ctx, export.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getuserbyid: %w", err)
+	}
+	sessions, err := s.storer.ListUserSessions(ctx, export.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("listusersessions: %w", err)
+	}
+	addresses, err := s.storer.ListAddresses(ctx, export.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("listaddresses: %w", err)
+	}
+	tenants, err := s.storer.ListOwnedTenants(ctx, export.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("listownedtenants: %w", err)
+	}
+
+	archive, err := buildExportArchive(*user, sessions, addresses, tenants)
+	if err != nil {
+		return nil, "", fmt.Errorf("buildexportarchive: %w", err)
+	}
+
+	token, err := GenerateToken(export.UserID, dataExportTTL, DataDownload)
+	if err != nil {
+		return nil, "", fmt.Errorf("generatetoken: %w", err)
+	}
+
+	export.Status = ExportReady
+	export.Archive = archive
+	export.TokenHash = token.TokenHash
+	export.ExpiresAt = &token.Expiry
+	if err := s.storer.CompleteDataExport(ctx, export); err != nil {
+		return nil, "", fmt.Errorf("completedataexport: %w", err)
+	}
+
+	link, err := url.Parse(s.config.Auth.DataExportURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsedataexporturl: %w", err)
+	}
+	link = link.JoinPath(export.ID.String())
+	q := link.Query()
+	q.Set("token", token.Plaintext)
+	link.RawQuery = q.Encode()
+
+	return user, link.String(), nil
+}
+
+// DownloadDataExport returns a ready export if the token matches. Unknown,
+// pending and expired exports all look the same to the caller.
+func (s *UserBusiness) DownloadDataExport(ctx context.Context, exportID uuid.UUID, token string) (*DataExport, error) {
+	notFound := errs.NewDomainError(errs.NotFound, errors.New("export not found or link expired"))
+
+	export, err := s.storer.GetDataExport(ctx, exportID)
+	if err != nil {
+		if errors.Is(err, ErrExportNotFound) {
+			return nil, notFound
+		}
+		return nil, fmt.Errorf("getdataexport: %w", err)
+	}
+
+	if export.Status != ExportReady || export.ExpiresAt == nil || time.Now().After(*export.ExpiresAt) {
+		return nil, notFound
+	}
+	sha := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare(sha[:], export.TokenHash) != 1 {
+		return nil, notFound
+	}
+	return export, nil
+}
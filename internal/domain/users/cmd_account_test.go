@@ -0,0 +1,76 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"go.uber.org/mock/gomock"
+)
+
+// fakeArchiver records the status requests PurgeAccount makes.
+type fakeArchiver struct {
+	requests map[uuid.UUID]tenant.StatusRequest
+	missing  map[uuid.UUID]bool
+}
+
+func (f *fakeArchiver) ChangeStatus(_ context.Context, tenantID uuid.UUID, req tenant.StatusRequest) (*tenant.TenantProfile, error) {
+	if f.missing[tenantID] {
+		return nil, errs.NewDomainError(errs.NotFound, tenant.ErrTenantNotFound)
+	}
+	f.requests[tenantID] = req
+	return &tenant.TenantProfile{ID: tenantID, Status: req.To}, nil
+}
+
+func TestPurgeAccountArchivesOwnedStores(t *testing.T) {
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	archiver := &fakeArchiver{requests: map[uuid.UUID]tenant.StatusRequest{}, missing: map[uuid.UUID]bool{}}
+	cfg := &config.Config{}
+	cfg.Auth.AccountDeletionGrace = 30 * 24 * time.Hour
+
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithConfigs(cfg),
+		users.WithStoreArchiver(archiver),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deletedAt := time.Now().Add(-31 * 24 * time.Hour)
+	user := localUser(true)
+	user.DeletedAt = &deletedAt
+	live, archived, deleted := uuid.New(), uuid.New(), uuid.New()
+	archiver.missing[deleted] = true
+
+	repo.EXPECT().GetUserByID(gomock.Any(), user.UserID).Return(user, nil)
+	repo.EXPECT().ListOwnedTenants(gomock.Any(), user.UserID).Return([]users.OwnedTenant{
+		{ID: live, Status: string(tenant.TenantStatusActive)},
+		{ID: archived, Status: string(tenant.TenantStatusArchived)},
+		{ID: deleted, Status: string(tenant.TenantStatusMaintenance)},
+	}, nil)
+	repo.EXPECT().AnonymizeUser(gomock.Any(), user.UserID, gomock.Any()).Return(nil)
+
+	purged, err := ub.PurgeAccount(context.Background(), user.UserID)
+	if err != nil || !purged {
+		t.Fatalf("PurgeAccount = %v, %v", purged, err)
+	}
+
+	if len(archiver.requests) != 1 {
+		t.Fatalf("archived %d stores, want 1: %v", len(archiver.requests), archiver.requests)
+	}
+	req, ok := archiver.requests[live]
+	if !ok {
+		t.Fatal("live store was not archived")
+	}
+	if req.To != tenant.TenantStatusArchived || req.Trigger != tenant.TriggerSystem || req.Reason == "" {
+		t.Fatalf("request = %+v, want a system archive with a reason", req)
+	}
+}
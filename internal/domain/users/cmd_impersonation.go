@@ -0,0 +1,115 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/types/role"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// Impersonate issues adminID a short-lived access token acting as targetID.
+// Only system admins may impersonate, and never another system admin. There
+// is no refresh token, the admin starts over once it expires.
+func (s *UserBusiness) Impersonate(ctx context.Context, adminID, targetID uuid.UUID, reason, clientIP string) (*Impersonation, string, error) {
+	if adminID == targetID {
+		return nil, "", errs.NewDomainError(errs.InvalidArgument, errors.New("cannot impersonate yourself"))
+	}
+
+	admin, err := s.storer.GetUserByID(ctx, adminID)
+	if err != nil {
+		return nil, "", fmt.Errorf("getuserbyid: %w", err)
+	}
+	if admin.Role != role.SystemAdmin {
+		return nil, "", errs.NewDomainError(errs.PermissionDenied, errors.New("only system admins can impersonate"))
+	}
+
+	target, err := s.storer.GetUserByID(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, "", errs.NewDomainError(errs.NotFound, err)
+		}
+		return nil, "", fmt.Errorf("getuserbyid: %w", err)
+	}
+	if target.Role == role.SystemAdmin {
+		return nil, "", errs.NewDomainError(errs.PermissionDenied, errors.New("system admins cannot be impersonated"))
+	}
+
+	imp := &Impersonation{
+		ID:        uuid.New(),
+		AdminID:   adminID,
+		UserID:    targetID,
+		Reason:    strings.TrimSpace(reason),
+		ClientIP:  clientIP,
+		ExpiresAt: time.Now().Add(s.config.Auth.ImpersonationTTL),
+	}
+
+	data := authz.NewJWTData(targetID, target.GetRole(), s.config.Auth.ImpersonationTTL, s.config.Observability.ServiceName)
+	data.SessionID = imp.ID
+	data.ImpersonatorID = adminID
+	token, payload, err := s.authz.GenerateToken(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("generatetoken: %w", err)
+	}
+	imp.ExpiresAt = payload.ExpiresAt.Time
+
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.CreateImpersonation(ctx, imp); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			ActorID:    &adminID,
+			Action:     "user.impersonate",
+			TargetType: "user",
+			TargetID:   targetID.String(),
+			After: map[string]any{
+				"session_id": imp.ID,
+				"reason":     imp.Reason,
+				"expires_at": imp.ExpiresAt,
+			},
+		})
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("impersonate-trx: %w", err)
+	}
+	return imp, token, nil
+}
+
+// StopImpersonation ends the session and revokes its token.
+func (s *UserBusiness) StopImpersonation(ctx context.Context, adminID, sessionID uuid.UUID) error {
+	if err := s.storer.EndImpersonation(ctx, sessionID, adminID); err != nil {
+		if errors.Is(err, ErrImpersonationEnded) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("endimpersonation: %w", err)
+	}
+
+	err := s.cache.Set(ctx, RevokedSession(sessionID), true, s.config.Auth.ImpersonationTTL)
+	if err != nil {
+		return fmt.Errorf("setcache: %w", err)
+	}
+	return nil
+}
+
+// RecordImpersonatedRequest writes the audit row for a request made with an
+// impersonation token. It runs before the request is served so a request
+// that cannot be recorded is not served at all.
+func (s *UserBusiness) RecordImpersonatedRequest(ctx context.Context, req *ImpersonatedRequest) error {
+	if err := s.storer.CreateImpersonatedRequest(ctx, req); err != nil {
+		return fmt.Errorf("createimpersonatedrequest: %w", err)
+	}
+	return nil
+}
+
+func (s *UserBusiness) CompleteImpersonatedRequest(ctx context.Context, id int64, status int) error {
+	if err := s.storer.SetImpersonatedRequestStatus(ctx, id, status); err != nil {
+		return fmt.Errorf("setimpersonatedrequeststatus: %w", err)
+	}
+	return nil
+}
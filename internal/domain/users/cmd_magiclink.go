@@ -0,0 +1,115 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const magicLinkTTL = 15 * time.Minute
+
+// RequestMagicLink issues a one-time sign-in link. Unknown emails return a
+// nil user and no error so callers cannot probe for accounts.
+func (s *UserBusiness) RequestMagicLink(ctx context.Context, email *mail.Address) (*User, string, error) {
+	user, err := s.storer.GetUserByEmail(ctx, email.Address)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", fmt.Errorf("getuserbyemail: %w", err)
+	}
+
+	token, err := GenerateToken(user.UserID, magicLinkTTL, MagicLogin)
+	if err != nil {
+		return nil, "", fmt.Errorf("generatetoken: %w", err)
+	}
+	if err := s.storer.CreateToken(ctx, token); err != nil {
+		return nil, "", fmt.Errorf("createtoken: %w", err)
+	}
+
+	link, err := url.Parse(s.config.Auth.MagicLinkURL)
+	if err != nil {
+		return nil, "", fmt.Errorf("parsemagiclinkurl: %w", err)
+	}
+	q := link.Query()
+	q.Set("token", token.Plaintext)
+	link.RawQuery = q.Encode()
+
+	return user, link.String(), nil
+}
+
+// VerifyMagicLink consumes the token and returns the signed-in user. Opening
+// the link proves control of the mailbox, so the email is marked verified.
+// A password, MFA enrollment or session set up before that is dropped, as
+// when linking google.
+func (s *UserBusiness) VerifyMagicLink(ctx context.Context, token, clientIP string) (User, error) {
+	subjects := []attemptSubject{ipSubject(attemptMagicLink, clientIP)}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return User{}, err
+	}
+
+	sha := sha256.Sum256([]byte(token))
+	var userID uuid.UUID
+
+	err := s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		id, err := s.storer.GetUserIDByToken(ctx, sha[:], string(MagicLogin))
+		if err != nil {
+			return err
+		}
+		// deleting is what makes the link single use
+		if err := s.storer.DeleteToken(ctx, sha[:], string(MagicLogin)); err != nil {
+			return err
+		}
+		userID = id
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrDatabase) {
+			return User{}, fmt.Errorf("verifymagiclink-trx: %w", err)
+		}
+		invalid := errs.NewDomainError(errs.Unauthenticated, errors.New("link expired or invalid"))
+		return User{}, s.failAttempt(ctx, uuid.Nil, invalid, subjects...)
+	}
+
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("getuserbyid: %w", err)
+	}
+
+	if user.IsVerified {
+		return *user, nil
+	}
+
+	unusable, err := s.unusablePassword()
+	if err != nil {
+		return User{}, err
+	}
+	var families []uuid.UUID
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.VerifyUser(ctx, user.UserID); err != nil {
+			return err
+		}
+		var err error
+		families, err = s.dropUnprovenAccess(ctx, user.UserID, unusable)
+		return err
+	})
+	if err != nil {
+		return User{}, fmt.Errorf("verifyunproven-trx: %w", err)
+	}
+	if len(families) > 0 {
+		if err := s.clearSessionCache(ctx, user.UserID, families...); err != nil {
+			return User{}, err
+		}
+	}
+
+	user.PasswordHash = unusable
+	user.IsVerified = true
+	return *user, nil
+}
@@ -0,0 +1,75 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"go.uber.org/mock/gomock"
+)
+
+func newMagicLinkBusiness(t *testing.T) (*users.UserBusiness, *mockdb.MockUserRepository) {
+	t.Helper()
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithCache(newMemCache()),
+		users.WithConfigs(&config.Config{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ub, repo
+}
+
+func TestVerifyMagicLinkUnverifiedUserLosesPassword(t *testing.T) {
+	ub, repo := newMagicLinkBusiness(t)
+	existing := localUser(false)
+	family := uuid.New()
+
+	repo.EXPECT().GetUserIDByToken(gomock.Any(), gomock.Any(), string(users.MagicLogin)).Return(existing.UserID, nil)
+	repo.EXPECT().DeleteToken(gomock.Any(), gomock.Any(), string(users.MagicLogin)).Return(nil)
+	repo.EXPECT().GetUserByID(gomock.Any(), existing.UserID).Return(existing, nil)
+	repo.EXPECT().VerifyUser(gomock.Any(), existing.UserID).Return(nil)
+	repo.EXPECT().UpdatePassword(gomock.Any(), existing.UserID, gomock.Any()).Return(nil)
+	repo.EXPECT().DeleteMFA(gomock.Any(), existing.UserID).Return(nil)
+	repo.EXPECT().BlockUserSessions(gomock.Any(), existing.UserID).Return([]uuid.UUID{family}, nil)
+
+	user, err := ub.VerifyMagicLink(context.Background(), "magic-token", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("VerifyMagicLink: %v", err)
+	}
+	if !user.IsVerified {
+		t.Fatal("user is not verified")
+	}
+	if err := users.ComparePassword(user.PasswordHash, []byte("$argon2id$attacker")); !errors.Is(err, users.ErrInvalidPassword) {
+		t.Fatalf("old password still matches: %v", err)
+	}
+
+	revoked, err := ub.IsSessionRevoked(context.Background(), family)
+	if err != nil || !revoked {
+		t.Fatalf("session family revoked = %v, %v", revoked, err)
+	}
+}
+
+func TestVerifyMagicLinkVerifiedUserKeepsPassword(t *testing.T) {
+	ub, repo := newMagicLinkBusiness(t)
+	existing := localUser(true)
+
+	repo.EXPECT().GetUserIDByToken(gomock.Any(), gomock.Any(), string(users.MagicLogin)).Return(existing.UserID, nil)
+	repo.EXPECT().DeleteToken(gomock.Any(), gomock.Any(), string(users.MagicLogin)).Return(nil)
+	repo.EXPECT().GetUserByID(gomock.Any(), existing.UserID).Return(existing, nil)
+
+	user, err := ub.VerifyMagicLink(context.Background(), "magic-token", "203.0.113.7")
+	if err != nil {
+		t.Fatalf("VerifyMagicLink: %v", err)
+	}
+	if string(user.PasswordHash) != "$argon2id$attacker" {
+		t.Fatal("verified user's password was replaced")
+	}
+}
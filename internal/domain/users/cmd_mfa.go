@@ -0,0 +1,279 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	recoveryCodeCount = 10
+	mfaChallengeTTL   = 5 * time.Minute
+)
+
+var errInvalidMFACode = errors.New("invalid verification code")
+
+type MFAEnrollment struct {
+	Secret string
+	URI    string
+}
+
+// EnrollMFA creates (or replaces) a pending TOTP secret. It only becomes
+// active once ConfirmMFA receives a valid code for it.
+func (s *UserBusiness) EnrollMFA(ctx context.Context, userID uuid.UUID) (MFAEnrollment, error) {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		return MFAEnrollment{}, fmt.Errorf("getuserbyid: %w", err)
+	}
+
+	secret, err := authz.NewTOTPSecret()
+	if err != nil {
+		return MFAEnrollment{}, err
+	}
+
+	sealed, err := s.secrets.Seal([]byte(secret))
+	if err != nil {
+		return MFAEnrollment{}, fmt.Errorf("sealsecret: %w", err)
+	}
+
+	if err := s.storer.UpsertMFA(ctx, &MFA{UserID: userID, Secret: sealed}); err != nil {
+		if errors.Is(err, ErrMFAAlreadyEnabled) {
+			return MFAEnrollment{}, errs.NewDomainError(errs.FailedPrecondition, err)
+		}
+		return MFAEnrollment{}, fmt.Errorf("upsertmfa: %w", err)
+	}
+
+	return MFAEnrollment{
+		Secret: secret,
+		URI:    authz.TOTPURI(s.config.Observability.ServiceName, user.GetEmail(), secret),
+	}, nil
+}
+
+// ConfirmMFA enables the pending secret and returns one-time recovery codes.
+// The plaintext codes are only ever shown here.
+func (s *UserBusiness) ConfirmMFA(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	mfa, err := s.storer.GetMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return nil, errs.NewDomainError(errs.FailedPrecondition, errors.New("mfa enrollment not started"))
+		}
+		return nil, fmt.Errorf("getmfa: %w", err)
+	}
+	if mfa.IsEnabled {
+		return nil, errs.NewDomainError(errs.FailedPrecondition, ErrMFAAlreadyEnabled)
+	}
+
+	step, err := s.validateTOTP(mfa, code)
+	if err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.EnableMFA(ctx, userID, step); err != nil {
+			return err
+		}
+		if err := s.storer.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			Action:     "user.mfa_enable",
+			TargetType: "user",
+			TargetID:   userID.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return nil, errs.NewDomainError(errs.FailedPrecondition, ErrMFAAlreadyEnabled)
+		}
+		return nil, fmt.Errorf("confirmmfa-trx: %w", err)
+	}
+	return codes, nil
+}
+
+// DisableMFA requires the current password. Accounts without a local
+// password (google sign-in) confirm with a TOTP code instead.
+func (s *UserBusiness) DisableMFA(ctx context.Context, userID uuid.UUID, password, code string) error {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("getuserbyid: %w", err)
+	}
+
+	mfa, err := s.storer.GetMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return errs.NewDomainError(errs.FailedPrecondition, err)
+		}
+		return fmt.Errorf("getmfa: %w", err)
+	}
+
+	if len(user.PasswordHash) > 0 {
+		if err := ComparePassword(user.PasswordHash, []byte(password)); err != nil {
+			if errors.Is(err, ErrInvalidPassword) {
+				return errs.NewDomainError(errs.InvalidArgument, errors.New("current password incorrect"))
+			}
+			return fmt.Errorf("comparepassword: %w", err)
+		}
+	} else if _, err := s.validateTOTP(mfa, code); err != nil {
+		return err
+	}
+
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.DeleteMFA(ctx, userID); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			Action:     "user.mfa_disable",
+			TargetType: "user",
+			TargetID:   userID.String(),
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("disablemfa-trx: %w", err)
+	}
+	return nil
+}
+
+func (s *UserBusiness) IsMFAEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	mfa, err := s.storer.GetMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getmfa: %w", err)
+	}
+	return mfa.IsEnabled, nil
+}
+
+// CreateMFAChallenge issues the short-lived "mfa_pending" token returned by
+// sign-in when the user has two-factor authentication enabled.
+func (s *UserBusiness) CreateMFAChallenge(ctx context.Context, userID uuid.UUID) (*Token, error) {
+	token, err := GenerateToken(userID, mfaChallengeTTL, MFAPending)
+	if err != nil {
+		return nil, fmt.Errorf("generatetoken: %w", err)
+	}
+	if err := s.storer.CreateToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("createtoken: %w", err)
+	}
+	return token, nil
+}
+
+// VerifyMFAChallenge exchanges an mfa_pending token and a TOTP or recovery
+// code for the signed-in user. The token is consumed on success. Failed
+// codes count against the user and the client IP, a fresh token does not
+// reset them.
+func (s *UserBusiness) VerifyMFAChallenge(ctx context.Context, mfaToken, code, clientIP string) (User, error) {
+	ipSub := ipSubject(attemptMFA, clientIP)
+	if err := s.checkAttempts(ctx, ipSub); err != nil {
+		return User{}, err
+	}
+
+	sha := sha256.Sum256([]byte(mfaToken))
+	userID, err := s.storer.GetUserIDByToken(ctx, sha[:], string(MFAPending))
+	if err != nil {
+		if errors.Is(err, ErrDatabase) {
+			return User{}, fmt.Errorf("getuseridbytoken: %w", err)
+		}
+		invalid := errs.NewDomainError(errs.Unauthenticated, errors.New("invalid or expired mfa token"))
+		return User{}, s.failAttempt(ctx, uuid.Nil, invalid, ipSub)
+	}
+
+	subjects := []attemptSubject{accountSubject(attemptMFA, userID), ipSub}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return User{}, err
+	}
+
+	mfa, err := s.storer.GetMFA(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("getmfa: %w", err)
+	}
+
+	code = strings.TrimSpace(code)
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if len(code) == authz.TOTPDigits {
+			step, err := s.validateTOTP(mfa, code)
+			if err != nil {
+				return err
+			}
+			if err := s.storer.UpdateMFAStep(ctx, userID, step); err != nil {
+				return err
+			}
+		} else {
+			hash := hashRecoveryCode(code)
+			if err := s.storer.UseRecoveryCode(ctx, userID, hash[:]); err != nil {
+				return err
+			}
+		}
+		return s.storer.DeleteToken(ctx, sha[:], string(MFAPending))
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errInvalidMFACode), errors.Is(err, ErrMFACodeReused), errors.Is(err, ErrRecoveryCodeInvalid):
+			invalid := errs.NewDomainError(errs.Unauthenticated, errInvalidMFACode)
+			return User{}, s.failAttempt(ctx, userID, invalid, subjects...)
+		case errors.Is(err, ErrTokenNotFound):
+			return User{}, errs.NewDomainError(errs.Unauthenticated, errors.New("invalid or expired mfa token"))
+		default:
+			return User{}, fmt.Errorf("verifymfa-trx: %w", err)
+		}
+	}
+
+	if err := s.clearAttempts(ctx, subjects...); err != nil {
+		return User{}, err
+	}
+
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		return User{}, fmt.Errorf("getuserbyid: %w", err)
+	}
+	return *user, nil
+}
+
+func (s *UserBusiness) validateTOTP(mfa *MFA, code string) (int64, error) {
+	secret, err := s.secrets.Open(mfa.Secret)
+	if err != nil {
+		return 0, fmt.Errorf("opensecret: %w", err)
+	}
+
+	step, ok := authz.ValidateTOTP(string(secret), strings.TrimSpace(code), time.Now())
+	if !ok || step <= mfa.LastUsedStep {
+		return 0, errs.NewDomainError(errs.Unauthenticated, errInvalidMFACode)
+	}
+	return step, nil
+}
+
+func generateRecoveryCodes(n int) ([]string, [][]byte, error) {
+	codes := make([]string, 0, n)
+	hashes := make([][]byte, 0, n)
+	for range n {
+		raw := make([]byte, 5)
+		if _, err := rand.Read(raw); err != nil {
+			return nil, nil, fmt.Errorf("recoverycode: %w", err)
+		}
+		enc := hex.EncodeToString(raw)
+		code := enc[:5] + "-" + enc[5:]
+		hash := hashRecoveryCode(code)
+		codes = append(codes, code)
+		hashes = append(hashes, hash[:])
+	}
+	return codes, hashes, nil
+}
+
+func hashRecoveryCode(code string) [32]byte {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	return sha256.Sum256([]byte(normalized))
+}
@@ -0,0 +1,50 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestVerifyMFAChallengeLocksOut(t *testing.T) {
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithCache(newMemCache()),
+		users.WithConfigs(&config.Config{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID := uuid.New()
+	repo.EXPECT().GetUserIDByToken(gomock.Any(), gomock.Any(), string(users.MFAPending)).Return(userID, nil).AnyTimes()
+	repo.EXPECT().GetMFA(gomock.Any(), userID).Return(&users.MFA{IsEnabled: true}, nil).AnyTimes()
+	repo.EXPECT().UseRecoveryCode(gomock.Any(), userID, gomock.Any()).Return(users.ErrRecoveryCodeInvalid).AnyTimes()
+
+	ctx := context.Background()
+	var last error
+	for i := 0; i < 20; i++ {
+		_, last = ub.VerifyMFAChallenge(ctx, "mfa-token", "aaaaa-bbbbb", "203.0.113.7")
+		if derr, ok := errs.IsDomainError(last); ok && derr.Code == errs.TooManyRequests {
+			break
+		}
+	}
+	if derr, ok := errs.IsDomainError(last); !ok || derr.Code != errs.TooManyRequests {
+		t.Fatalf("guessing was never stopped, last err = %v", last)
+	}
+
+	// a fresh mfa_pending token from signing in again does not reset the count
+	if _, err := ub.VerifyMFAChallenge(ctx, "fresh-token", "aaaaa-bbbbb", "198.51.100.9"); err == nil {
+		t.Fatal("locked user verified")
+	} else if derr, ok := errs.IsDomainError(err); !ok || derr.Code != errs.TooManyRequests {
+		t.Fatalf("err = %v, want TooManyRequests", err)
+	}
+}
@@ -0,0 +1,132 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// GoogleSignUp carries the fields a Google ID token does not provide.
+// They are only required the first time an account is created.
+type GoogleSignUp struct {
+	FirstName   string
+	LastName    string
+	PhoneNumber string
+	Country     string
+}
+
+// AuthenticateGoogle verifies a Google ID token and returns the matching user.
+// An existing account with the same verified email is linked to the Google
+// identity, otherwise a new google provider account is created.
+func (s *UserBusiness) AuthenticateGoogle(ctx context.Context, idToken string, signup GoogleSignUp) (User, error) {
+	claims, err := s.idverifier.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		return User{}, errs.NewDomainError(errs.Unauthenticated, errors.New("invalid google credentials"))
+	}
+	if claims.Email == "" || !claims.EmailVerified {
+		return User{}, errs.NewDomainError(errs.Unauthenticated, errors.New("google email not verified"))
+	}
+
+	user, err := s.storer.GetUserByProviderID(ctx, claims.Subject)
+	if err == nil {
+		return *user, nil
+	}
+	if !errors.Is(err, ErrUserNotFound) {
+		return User{}, fmt.Errorf("getuserbyproviderid: %w", err)
+	}
+
+	user, err = s.storer.GetUserByEmail(ctx, claims.Email)
+	switch {
+	case err == nil:
+		return s.linkGoogle(ctx, user, claims.Subject)
+	case !errors.Is(err, ErrUserNotFound):
+		return User{}, fmt.Errorf("getuserbyemail: %w", err)
+	}
+
+	if signup.FirstName == "" {
+		signup.FirstName = claims.GivenName
+	}
+	if signup.LastName == "" {
+		signup.LastName = claims.FamilyName
+	}
+
+	provider := Google.String()
+	newUser, err := NewUser(UserCreate{
+		FirstName:   signup.FirstName,
+		LastName:    signup.LastName,
+		Email:       claims.Email,
+		PhoneNumber: signup.PhoneNumber,
+		Country:     signup.Country,
+		Provider:    &provider,
+		ProviderID:  &claims.Subject,
+	}, s.passwords)
+	if err != nil {
+		return User{}, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+	newUser.IsVerified = true
+
+	if err := s.storer.CreateUser(ctx, &newUser); err != nil {
+		switch {
+		case errors.Is(err, ErrEmailAlreadyExists), errors.Is(err, ErrPhoneNumberExists), errors.Is(err, ErrProviderIDExists):
+			return User{}, errs.NewDomainError(errs.AlreadyExists, err)
+		default:
+			return User{}, fmt.Errorf("createuser: %w", err)
+		}
+	}
+	return newUser, nil
+}
+
+// linkGoogle attaches the Google identity to the account registered with
+// its email. An unverified account may have been registered by someone
+// else before the address owner, its password, MFA and sessions are
+// dropped so that only the Google identity signs in to it.
+func (s *UserBusiness) linkGoogle(ctx context.Context, user *User, subject string) (User, error) {
+	if user.ProviderID != nil {
+		return User{}, errs.NewDomainError(errs.AlreadyExists, errors.New("account already linked to another google identity"))
+	}
+
+	var (
+		unusable []byte
+		families []uuid.UUID
+	)
+	if !user.IsVerified {
+		var err error
+		if unusable, err = s.unusablePassword(); err != nil {
+			return User{}, err
+		}
+	}
+
+	err := s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.LinkProviderID(ctx, user.UserID, subject); err != nil {
+			return err
+		}
+		if user.IsVerified {
+			return nil
+		}
+		var err error
+		families, err = s.dropUnprovenAccess(ctx, user.UserID, unusable)
+		return err
+	})
+	if err != nil {
+		if errors.Is(err, ErrProviderIDExists) {
+			return User{}, errs.NewDomainError(errs.AlreadyExists, err)
+		}
+		return User{}, fmt.Errorf("linkgoogle-trx: %w", err)
+	}
+
+	if len(families) > 0 {
+		if err := s.clearSessionCache(ctx, user.UserID, families...); err != nil {
+			return User{}, err
+		}
+	}
+
+	user.ProviderID = &subject
+	if !user.IsVerified {
+		user.PasswordHash = unusable
+		user.IsVerified = true
+	}
+	return *user, nil
+}
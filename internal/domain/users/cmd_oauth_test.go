@@ -0,0 +1,173 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"github.com/iamonah/merchcore/internal/sdk/authz/authzfake"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"go.uber.org/mock/gomock"
+)
+
+const googleToken = "google-id-token"
+
+func newGoogleBusiness(t *testing.T, claims authz.GoogleClaims) (*users.UserBusiness, *mockdb.MockUserRepository) {
+	t.Helper()
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	verifier := authzfake.NewVerifier()
+	verifier.Issue(googleToken, claims)
+
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithIDTokenVerifier(verifier),
+		users.WithCache(newMemCache()),
+		users.WithConfigs(&config.Config{}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ub, repo
+}
+
+func googleClaims() authz.GoogleClaims {
+	return authz.GoogleClaims{
+		Subject:       "google-sub-1",
+		Email:         "ada@example.com",
+		EmailVerified: true,
+		GivenName:     "Ada",
+		FamilyName:    "Lovelace",
+	}
+}
+
+func localUser(verified bool) *users.User {
+	return &users.User{
+		UserID:       uuid.New(),
+		Email:        &mail.Address{Address: "ada@example.com"},
+		Provider:     users.Local,
+		PasswordHash: []byte("$argon2id$attacker"),
+		IsVerified:   verified,
+	}
+}
+
+func TestAuthenticateGoogleNewUser(t *testing.T) {
+	claims := googleClaims()
+	ub, repo := newGoogleBusiness(t, claims)
+
+	repo.EXPECT().GetUserByProviderID(gomock.Any(), claims.Subject).Return(nil, users.ErrUserNotFound)
+	repo.EXPECT().GetUserByEmail(gomock.Any(), claims.Email).Return(nil, users.ErrUserNotFound)
+	repo.EXPECT().CreateUser(gomock.Any(), gomock.Any()).Return(nil)
+
+	user, err := ub.AuthenticateGoogle(context.Background(), googleToken, users.GoogleSignUp{
+		PhoneNumber: "+2348012345678",
+		Country:     "NG",
+	})
+	if err != nil {
+		t.Fatalf("AuthenticateGoogle: %v", err)
+	}
+	if user.Provider != users.Google || user.ProviderID == nil || *user.ProviderID != claims.Subject {
+		t.Fatalf("user provider = %s %v, want google %s", user.Provider, user.ProviderID, claims.Subject)
+	}
+	if !user.IsVerified || user.FirstName != "Ada" {
+		t.Fatalf("user = %+v, want verified Ada", user)
+	}
+}
+
+func TestAuthenticateGoogleLinksVerifiedUser(t *testing.T) {
+	claims := googleClaims()
+	ub, repo := newGoogleBusiness(t, claims)
+	existing := localUser(true)
+
+	repo.EXPECT().GetUserByProviderID(gomock.Any(), claims.Subject).Return(nil, users.ErrUserNotFound)
+	repo.EXPECT().GetUserByEmail(gomock.Any(), claims.Email).Return(existing, nil)
+	repo.EXPECT().LinkProviderID(gomock.Any(), existing.UserID, claims.Subject).Return(nil)
+
+	user, err := ub.AuthenticateGoogle(context.Background(), googleToken, users.GoogleSignUp{})
+	if err != nil {
+		t.Fatalf("AuthenticateGoogle: %v", err)
+	}
+	if user.UserID != existing.UserID || *user.ProviderID != claims.Subject {
+		t.Fatalf("linked user = %s %v", user.UserID, user.ProviderID)
+	}
+	if string(user.PasswordHash) != "$argon2id$attacker" {
+		t.Fatal("verified user's password was replaced")
+	}
+}
+
+func TestAuthenticateGoogleUnverifiedUserLosesPassword(t *testing.T) {
+	claims := googleClaims()
+	ub, repo := newGoogleBusiness(t, claims)
+	existing := localUser(false)
+	family := uuid.New()
+
+	repo.EXPECT().GetUserByProviderID(gomock.Any(), claims.Subject).Return(nil, users.ErrUserNotFound)
+	repo.EXPECT().GetUserByEmail(gomock.Any(), claims.Email).Return(existing, nil)
+	repo.EXPECT().LinkProviderID(gomock.Any(), existing.UserID, claims.Subject).Return(nil)
+	repo.EXPECT().UpdatePassword(gomock.Any(), existing.UserID, gomock.Any()).Return(nil)
+	repo.EXPECT().DeleteMFA(gomock.Any(), existing.UserID).Return(users.ErrMFANotFound)
+	repo.EXPECT().BlockUserSessions(gomock.Any(), existing.UserID).Return([]uuid.UUID{family}, nil)
+
+	user, err := ub.AuthenticateGoogle(context.Background(), googleToken, users.GoogleSignUp{})
+	if err != nil {
+		t.Fatalf("AuthenticateGoogle: %v", err)
+	}
+	if !user.IsVerified {
+		t.Fatal("linked user is not verified")
+	}
+	if err := users.ComparePassword(user.PasswordHash, []byte("$argon2id$attacker")); !errors.Is(err, users.ErrInvalidPassword) {
+		t.Fatalf("old password still matches: %v", err)
+	}
+
+	revoked, err := ub.IsSessionRevoked(context.Background(), family)
+	if err != nil || !revoked {
+		t.Fatalf("session family revoked = %v, %v", revoked, err)
+	}
+}
+
+func TestAuthenticateGoogleRefusesConflicts(t *testing.T) {
+	claims := googleClaims()
+
+	t.Run("linked to another identity", func(t *testing.T) {
+		ub, repo := newGoogleBusiness(t, claims)
+		existing := localUser(true)
+		other := "google-sub-2"
+		existing.ProviderID = &other
+
+		repo.EXPECT().GetUserByProviderID(gomock.Any(), claims.Subject).Return(nil, users.ErrUserNotFound)
+		repo.EXPECT().GetUserByEmail(gomock.Any(), claims.Email).Return(existing, nil)
+
+		_, err := ub.AuthenticateGoogle(context.Background(), googleToken, users.GoogleSignUp{})
+		if derr, ok := errs.IsDomainError(err); !ok || derr.Code != errs.AlreadyExists {
+			t.Fatalf("err = %v, want AlreadyExists", err)
+		}
+	})
+
+	t.Run("provider id taken", func(t *testing.T) {
+		ub, repo := newGoogleBusiness(t, claims)
+		existing := localUser(true)
+
+		repo.EXPECT().GetUserByProviderID(gomock.Any(), claims.Subject).Return(nil, users.ErrUserNotFound)
+		repo.EXPECT().GetUserByEmail(gomock.Any(), claims.Email).Return(existing, nil)
+		repo.EXPECT().LinkProviderID(gomock.Any(), existing.UserID, claims.Subject).Return(users.ErrProviderIDExists)
+
+		_, err := ub.AuthenticateGoogle(context.Background(), googleToken, users.GoogleSignUp{})
+		if derr, ok := errs.IsDomainError(err); !ok || derr.Code != errs.AlreadyExists {
+			t.Fatalf("err = %v, want AlreadyExists", err)
+		}
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		ub, _ := newGoogleBusiness(t, claims)
+		_, err := ub.AuthenticateGoogle(context.Background(), "forged", users.GoogleSignUp{})
+		if derr, ok := errs.IsDomainError(err); !ok || derr.Code != errs.Unauthenticated {
+			t.Fatalf("err = %v, want Unauthenticated", err)
+		}
+	})
+}
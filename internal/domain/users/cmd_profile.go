@@ -0,0 +1,127 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const emailChangeTTL = 30 * time.Minute
+
+func (s *UserBusiness) GetUser(ctx context.Context, userID uuid.UUID) (User, error) {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return User{}, errs.NewDomainError(errs.NotFound, err)
+		}
+		return User{}, fmt.Errorf("getuserbyid: %w", err)
+	}
+	return *user, nil
+}
+
+// RequestEmailChange stores a pending change and returns the token to send
+// to the new address. The current email stays active until it is confirmed.
+// Accounts with a local password must confirm it first.
+func (s *UserBusiness) RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail *mail.Address, password string) (*User, *Token, error) {
+	user, err := s.storer.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getuserbyid: %w", err)
+	}
+
+	if len(user.PasswordHash) > 0 {
+		if err := ComparePassword(user.PasswordHash, []byte(password)); err != nil {
+			if errors.Is(err, ErrInvalidPassword) {
+				return nil, nil, errs.NewDomainError(errs.InvalidArgument, errors.New("current password incorrect"))
+			}
+			return nil, nil, fmt.Errorf("comparepassword: %w", err)
+		}
+	}
+
+	if strings.EqualFold(user.GetEmail(), newEmail.Address) {
+		return nil, nil, errs.NewDomainError(errs.InvalidArgument, errors.New("new email matches the current one"))
+	}
+
+	_, err = s.storer.GetUserByEmail(ctx, newEmail.Address)
+	switch {
+	case err == nil:
+		return nil, nil, errs.NewDomainError(errs.AlreadyExists, ErrEmailAlreadyExists)
+	case !errors.Is(err, ErrUserNotFound):
+		return nil, nil, fmt.Errorf("getuserbyemail: %w", err)
+	}
+
+	token, err := GenerateToken(userID, emailChangeTTL, ChangeEmail)
+	if err != nil {
+		return nil, nil, fmt.Errorf("generatetoken: %w", err)
+	}
+
+	err = s.storer.CreateEmailChange(ctx, &EmailChange{
+		TokenHash: token.TokenHash,
+		UserID:    userID,
+		NewEmail:  newEmail.Address,
+		Expiry:    token.Expiry,
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("createemailchange: %w", err)
+	}
+
+	// the token goes to the new address, the caller needs it there
+	user.Email = newEmail
+	return user, token, nil
+}
+
+// ConfirmEmailChange applies the pending change. Receiving the token proves
+// the new mailbox, so the account stays verified.
+func (s *UserBusiness) ConfirmEmailChange(ctx context.Context, token string) (User, error) {
+	sha := sha256.Sum256([]byte(token))
+	invalid := errs.NewDomainError(errs.InvalidArgument, errors.New("invalid or expired token"))
+
+	var userID uuid.UUID
+	err := s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		ec, err := s.storer.ConsumeEmailChange(ctx, sha[:])
+		if err != nil {
+			return err
+		}
+		if time.Now().After(ec.Expiry) {
+			return ErrTokenExpired
+		}
+		userID = ec.UserID
+		user, err := s.storer.GetUserByID(ctx, ec.UserID)
+		if err != nil {
+			return err
+		}
+		if err := s.storer.UpdateEmail(ctx, ec.UserID, ec.NewEmail); err != nil {
+			return err
+		}
+		if err := s.storer.VerifyUser(ctx, ec.UserID); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			ActorID:    &userID,
+			Action:     "user.email_change",
+			TargetType: "user",
+			TargetID:   userID.String(),
+			Before:     map[string]any{"email": user.GetEmail()},
+			After:      map[string]any{"email": ec.NewEmail},
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrEmailChangeNotFound), errors.Is(err, ErrTokenExpired):
+			return User{}, invalid
+		case errors.Is(err, ErrEmailAlreadyExists):
+			return User{}, errs.NewDomainError(errs.AlreadyExists, err)
+		default:
+			return User{}, fmt.Errorf("confirmemailchange-trx: %w", err)
+		}
+	}
+
+	return s.GetUser(ctx, userID)
+}
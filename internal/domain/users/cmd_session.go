@@ -0,0 +1,83 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/infra/cache"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+// ListSessions returns one entry per signed-in device. Sessions are
+// identified by their family ID, which stays stable across refreshes.
+func (s *UserBusiness) ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error) {
+	sessions, err := s.storer.ListActiveSessions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("listactivesessions: %w", err)
+	}
+	return sessions, nil
+}
+
+func (s *UserBusiness) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if err := s.storer.BlockSessionFamily(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, ErrSessionNotFound) {
+			return errs.NewDomainError(errs.NotFound, err)
+		}
+		return fmt.Errorf("blocksessionfamily: %w", err)
+	}
+
+	return s.clearSessionCache(ctx, userID, sessionID)
+}
+
+// RevokeAllSessions signs the user out everywhere, including the session
+// making the request, and returns how many sessions were revoked.
+func (s *UserBusiness) RevokeAllSessions(ctx context.Context, userID uuid.UUID) (int, error) {
+	families, err := s.storer.BlockUserSessions(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("blockusersessions: %w", err)
+	}
+
+	if err := s.clearSessionCache(ctx, userID, families...); err != nil {
+		return 0, err
+	}
+	return len(families), nil
+}
+
+// IsSessionRevoked is consulted by the auth middleware on every request.
+// Tokens issued before sessions were tracked carry no session ID and pass.
+func (s *UserBusiness) IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error) {
+	if sessionID == uuid.Nil {
+		return false, nil
+	}
+
+	var revoked bool
+	err := s.cache.Get(ctx, RevokedSession(sessionID), &revoked)
+	if err != nil {
+		if errors.Is(err, cache.ErrCacheMiss) {
+			return false, nil
+		}
+		return false, fmt.Errorf("getcache: %w", err)
+	}
+	return revoked, nil
+}
+
+// clearSessionCache drops the cached tokens of the user and marks the given
+// families as revoked for as long as a token issued to them can live.
+func (s *UserBusiness) clearSessionCache(ctx context.Context, userID uuid.UUID, families ...uuid.UUID) error {
+	if err := s.cache.Delete(ctx, SessionAccessKeys(userID)); err != nil {
+		return fmt.Errorf("deletesession: %w", err)
+	}
+	if err := s.cache.Delete(ctx, SessionRefreshKeys(userID)); err != nil {
+		return fmt.Errorf("deletesession: %w", err)
+	}
+
+	for _, familyID := range families {
+		err := s.cache.Set(ctx, RevokedSession(familyID), true, s.config.Auth.RefreshTokenLifeTime)
+		if err != nil {
+			return fmt.Errorf("setcache: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,181 @@
+package users
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const (
+	// knownDeviceWindow is how far back a session still vouches for its
+	// device and IP.
+	knownDeviceWindow = 90 * 24 * time.Hour
+	signInReportTTL   = 7 * 24 * time.Hour
+	passwordResetTTL  = 15 * time.Minute
+)
+
+// SignInAlert is what the new sign-in email says.
+type SignInAlert struct {
+	Email      string
+	FirstName  string
+	Device     string
+	Location   string
+	ClientIP   string
+	At         time.Time
+	ReportLink string
+	UserID     uuid.UUID
+}
+
+// SignInNotifier warns the account owner of a sign-in from a device and IP
+// not seen in their recent sessions.
+type SignInNotifier interface {
+	NewSignInEmailJob(alert SignInAlert) error
+}
+
+// IPLocator gives the approximate place of an IP, empty when unknown.
+type IPLocator interface {
+	Locate(ip string) string
+}
+
+// isNewDevice reports whether no recent session of the user came from the
+// same device and IP. A user's very first sign-in is not alerted on.
+func (s *UserBusiness) isNewDevice(ctx context.Context, userID uuid.UUID, userAgent, clientIP string) (bool, error) {
+	sessions, err := s.storer.ListUserSessions(ctx, userID)
+	if err != nil {
+		return false, fmt.Errorf("listusersessions: %w", err)
+	}
+	if len(sessions) == 0 {
+		return false, nil
+	}
+
+	device := base.ParseUserAgent(userAgent).Label()
+	since := time.Now().Add(-knownDeviceWindow)
+	for _, ses := range sessions {
+		if ses.CreatedAt.Before(since) {
+			continue
+		}
+		if ses.ClientIP == clientIP && base.ParseUserAgent(ses.UserAgent).Label() == device {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// alertNewSignIn mails the owner about the sign-in that started familyID,
+// with a link to disown it.
+func (s *UserBusiness) alertNewSignIn(ctx context.Context, user User, familyID uuid.UUID, userAgent, clientIP string) error {
+	token, err := GenerateToken(user.UserID, signInReportTTL, DisownSignIn)
+	if err != nil {
+		return fmt.Errorf("generatetoken: %w", err)
+	}
+	err = s.storer.CreateSignInReport(ctx, &SignInReport{
+		TokenHash: token.TokenHash,
+		UserID:    user.UserID,
+		FamilyID:  familyID,
+		Expiry:    token.Expiry,
+	})
+	if err != nil {
+		return fmt.Errorf("createsigninreport: %w", err)
+	}
+
+	link, err := url.Parse(s.config.Auth.SignInReportURL)
+	if err != nil {
+		return fmt.Errorf("parsesigninreporturl: %w", err)
+	}
+	q := link.Query()
+	q.Set("token", token.Plaintext)
+	link.RawQuery = q.Encode()
+
+	var location string
+	if s.locator != nil {
+		location = s.locator.Locate(clientIP)
+	}
+
+	alert := SignInAlert{
+		Email:      user.GetEmail(),
+		FirstName:  user.FirstName,
+		Device:     base.ParseUserAgent(userAgent).Label(),
+		Location:   location,
+		ClientIP:   clientIP,
+		At:         time.Now(),
+		ReportLink: link.String(),
+		UserID:     user.UserID,
+	}
+	if err := s.signins.NewSignInEmailJob(alert); err != nil {
+		return fmt.Errorf("newsigninemailjob: %w", err)
+	}
+	return nil
+}
+
+// ReportSignIn handles "this wasn't me": the reported session is revoked and
+// the password scrapped, the returned reset token is the only way back in.
+func (s *UserBusiness) ReportSignIn(ctx context.Context, token, clientIP string) (*Token, error) {
+	subjects := []attemptSubject{ipSubject(attemptSignInReport, clientIP)}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return nil, err
+	}
+
+	// nobody knows this password, the account waits for the reset
+	unusable, err := s.unusablePassword()
+	if err != nil {
+		return nil, err
+	}
+
+	sha := sha256.Sum256([]byte(token))
+	var (
+		report *SignInReport
+		reset  *Token
+	)
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		r, err := s.storer.ConsumeSignInReport(ctx, sha[:])
+		if err != nil {
+			return err
+		}
+		if time.Now().After(r.Expiry) {
+			return ErrSignInReportInvalid
+		}
+		report = r
+
+		err = s.storer.BlockSessionFamily(ctx, r.UserID, r.FamilyID)
+		if err != nil && !errors.Is(err, ErrSessionNotFound) {
+			return err
+		}
+		if err := s.storer.UpdatePassword(ctx, r.UserID, unusable); err != nil {
+			return err
+		}
+
+		if reset, err = GenerateToken(r.UserID, passwordResetTTL, PasswordReset); err != nil {
+			return err
+		}
+		if err := s.storer.CreateToken(ctx, reset); err != nil {
+			return err
+		}
+
+		return s.recordAudit(ctx, audit.Entry{
+			ActorID:    &r.UserID,
+			Action:     "user.signin_report",
+			TargetType: "session",
+			TargetID:   r.FamilyID.String(),
+		})
+	})
+	if err != nil {
+		if errors.Is(err, ErrSignInReportInvalid) {
+			invalid := errs.NewDomainError(errs.Unauthenticated, err)
+			return nil, s.failAttempt(ctx, uuid.Nil, invalid, subjects...)
+		}
+		return nil, fmt.Errorf("reportsignin-trx: %w", err)
+	}
+
+	if err := s.clearSessionCache(ctx, report.UserID, report.FamilyID); err != nil {
+		return nil, err
+	}
+	return reset, nil
+}
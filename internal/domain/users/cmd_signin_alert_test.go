@@ -0,0 +1,65 @@
+package users_test
+
+import (
+	"context"
+	"errors"
+	"net/mail"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"github.com/iamonah/merchcore/internal/sdk/authz"
+	"go.uber.org/mock/gomock"
+)
+
+type fakeSignIns struct {
+	alerts []users.SignInAlert
+}
+
+func (f *fakeSignIns) NewSignInEmailJob(alert users.SignInAlert) error {
+	f.alerts = append(f.alerts, alert)
+	return nil
+}
+
+func TestCreateSessionSurvivesAlertFailure(t *testing.T) {
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	maker := authz.NewJWTMaker("0123456789abcdef0123456789abcdef")
+	signins := &fakeSignIns{}
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithAuthz(&maker),
+		users.WithCache(newMemCache()),
+		users.WithSignInNotifier(signins),
+		users.WithConfigs(&config.Config{Auth: config.AuthConfig{
+			AccessTokenLifeTime:  time.Minute,
+			RefreshTokenLifeTime: time.Hour,
+			SignInReportURL:      "https://merchcore.com/signin/report",
+		}}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := users.User{UserID: uuid.New(), Email: &mail.Address{Address: "ada@example.com"}}
+
+	// the only recent session came from elsewhere
+	repo.EXPECT().ListUserSessions(gomock.Any(), user.UserID).Return([]users.Session{{
+		UserID: user.UserID, ClientIP: "203.0.113.9", UserAgent: "curl/8.0", CreatedAt: time.Now(),
+	}}, nil)
+	repo.EXPECT().CreateSession(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().CreateSignInReport(gomock.Any(), gomock.Any()).Return(errors.New("connection reset"))
+
+	data, err := ub.CreateSession(context.Background(), user, "Mozilla/5.0 (X11; Linux x86_64) Firefox/130.0", "198.51.100.7")
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+	if data.AccessToken == "" || data.RefreshToken == "" {
+		t.Fatalf("session = %+v", data)
+	}
+	if len(signins.alerts) != 0 {
+		t.Fatal("alert sent without a report to disown the sign-in with")
+	}
+}
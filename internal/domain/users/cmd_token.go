@@ -20,6 +20,11 @@ type tokenscope string
 const (
 	ActivationToken tokenscope = "activationToken"
 	PasswordReset   tokenscope = "passwordReset"
+	MFAPending      tokenscope = "mfaPending"
+	MagicLogin      tokenscope = "magicLogin"
+	ChangeEmail     tokenscope = "emailChange"
+	DataDownload    tokenscope = "dataDownload"
+	DisownSignIn    tokenscope = "disownSignIn"
 )
 
 type Token struct {
@@ -68,12 +73,18 @@ func GenerateToken(userID uuid.UUID, ttl time.Duration, scope tokenscope) (*Toke
 }
 
 type tokenData struct {
-	AccessToken    string
-	AcessExpiresAt time.Time
-	UserId         uuid.UUID
+	AccessToken      string
+	AcessExpiresAt   time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+	UserId           uuid.UUID
 }
 
-func (s *UserBusiness) RenewAccessToken(ctx context.Context, payload *authz.Payload) (tokenData, error) {
+// RenewAccessToken rotates the refresh token: the presented session is
+// consumed and a new one is issued in the same family, keeping the family's
+// original expiry. Presenting an already consumed token is treated as theft
+// and blocks the whole family.
+func (s *UserBusiness) RenewAccessToken(ctx context.Context, payload *authz.Payload, userAgent, clientIP string) (tokenData, error) {
 	session, err := s.storer.GetSession(ctx, payload.ID)
 	if err != nil {
 		if errors.Is(err, ErrSessionNotFound) {
@@ -90,41 +101,65 @@ func (s *UserBusiness) RenewAccessToken(ctx context.Context, payload *authz.Payl
 		return tokenData{}, errs.NewDomainError(errs.Unauthenticated, errors.New("session user mismatch"))
 	}
 
-	jwtData := authz.NewJWTData(session.UserID, payload.RoleID, 15*time.Minute, s.config.Observability.ServiceName)
-	accessToken, accessPayload, err := s.authz.GenerateToken(jwtData)
+	if session.IsSessionExpired() {
+		return tokenData{}, errs.NewDomainError(errs.Unauthenticated, errors.New("invalid or expired refresh token"))
+	}
+
+	if session.IsConsumed() {
+		return tokenData{}, s.revokeSessionFamily(ctx, session)
+	}
+
+	var newSession *SessionData
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.ConsumeSession(ctx, session.ID); err != nil {
+			return err
+		}
+
+		var err error
+		newSession, err = s.newSession(ctx, session.UserID, payload.RoleID, session.FamilyID,
+			time.Until(session.ExpiresAt), userAgent, clientIP)
+		return err
+	})
 	if err != nil {
-		return tokenData{}, fmt.Errorf("generatetoken: %w", err)
+		if errors.Is(err, ErrSessionConsumed) {
+			return tokenData{}, s.revokeSessionFamily(ctx, session)
+		}
+		return tokenData{}, fmt.Errorf("renewaccesstoken-trx: %w", err)
 	}
 
 	data := tokenData{
-		AccessToken:    accessToken,
-		AcessExpiresAt: accessPayload.ExpiresAt.Time,
-		UserId:         session.UserID,
+		AccessToken:      newSession.AccessToken,
+		AcessExpiresAt:   newSession.AccessTokenExpiresAt,
+		RefreshToken:     newSession.RefreshToken,
+		RefreshExpiresAt: newSession.RefreshTokenExpiresAt,
+		UserId:           session.UserID,
 	}
 	return data, nil
 }
 
-func (s *UserBusiness) BlockSession(ctx context.Context, userID uuid.UUID, refreshToken string) error {
-	shaToken := sha256.Sum256([]byte(refreshToken))
-
-	acckey := SessionAccessKeys(userID)
-	err := s.cache.Delete(ctx, acckey)
-	if err != nil {
-		return fmt.Errorf("deletesession: %w", err)
+func (s *UserBusiness) revokeSessionFamily(ctx context.Context, session *Session) error {
+	err := s.storer.BlockSessionFamily(ctx, session.UserID, session.FamilyID)
+	if err != nil && !errors.Is(err, ErrSessionNotFound) {
+		return fmt.Errorf("blocksessionfamily: %w", err)
 	}
 
-	refkey := SessionAccessKeys(userID)
-	err = s.cache.Delete(ctx, refkey)
-	if err != nil {
-		return fmt.Errorf("deletesession: %w", err)
+	if err := s.clearSessionCache(ctx, session.UserID, session.FamilyID); err != nil {
+		return err
 	}
 
-	err = s.storer.BlockSession(ctx, shaToken[:])
+	return errs.NewDomainError(errs.Unauthenticated, ErrRefreshTokenReused)
+}
+
+func (s *UserBusiness) BlockSession(ctx context.Context, userID uuid.UUID, refreshToken string) error {
+	shaToken := sha256.Sum256([]byte(refreshToken))
+
+	familyID, err := s.storer.BlockSession(ctx, shaToken[:])
 	if err != nil {
 		if errors.Is(err, ErrSessionNotFound) {
 			return errs.NewDomainError(errs.Unauthenticated, err)
 		}
 		return fmt.Errorf("blocksession: %w", err)
 	}
-	return nil
+
+	return s.clearSessionCache(ctx, userID, familyID)
 }
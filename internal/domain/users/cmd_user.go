@@ -11,38 +11,74 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/audit"
 	"github.com/iamonah/merchcore/internal/infra/cache"
 	"github.com/iamonah/merchcore/internal/infra/database"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
 	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"github.com/rs/zerolog"
 )
 
 type UserBusiness struct {
-	storer UserRepository
-	trx    database.TransactorTX
-	authz  authz.TokenMaker
-	config *config.Config
-	cache  cache.Cache
+	storer     UserRepository
+	trx        database.TransactorTX
+	authz      authz.TokenMaker
+	idverifier authz.IDTokenVerifier
+	secrets    *authz.SecretBox
+	config     *config.Config
+	cache      cache.Cache
+	notifier   LockNotifier
+	auditor    audit.Recorder
+	passwords  *PasswordPolicy
+	signins    SignInNotifier
+	locator    IPLocator
+	stores     StoreArchiver
+	log        *zerolog.Logger
 }
 
 type ExtUserBusiness interface {
 	CreateUser(ctx context.Context, info UserCreate) (User, Token, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (User, error)
 	UpdateUser(ctx context.Context, userID uuid.UUID, uu UpdateUser) (*User, error)
-	ActivateUser(ctx context.Context, usrID uuid.UUID, token string) error
+	RequestEmailChange(ctx context.Context, userID uuid.UUID, newEmail *mail.Address, password string) (*User, *Token, error)
+	ConfirmEmailChange(ctx context.Context, token string) (User, error)
+	ActivateUser(ctx context.Context, usrID uuid.UUID, token, clientIP string) error
 	ResendActivationToken(ctx context.Context, userID uuid.UUID) (User, Token, error)
-	Authenticate(ctx context.Context, email *mail.Address, password string) (User, error)
+	Authenticate(ctx context.Context, email *mail.Address, password, clientIP string) (User, error)
+	AuthenticateGoogle(ctx context.Context, idToken string, signup GoogleSignUp) (User, error)
 	CreateSession(ctx context.Context, user User, userAgent, clientIP string) (*SessionData, error)
 	ForgetPassword(ctx context.Context, email *mail.Address) (*User, *Token, error)
-	PasswordReset(ctx context.Context, newPass string, token string) (uuid.UUID, error)
+	PasswordReset(ctx context.Context, newPass, token, clientIP string) (uuid.UUID, error)
 	ChangePassword(ctx context.Context, userId uuid.UUID, oldPass, newPass string) (User, error)
-	RenewAccessToken(ctx context.Context, payload *authz.Payload) (tokenData, error)
+	RenewAccessToken(ctx context.Context, payload *authz.Payload, userAgent, clientIP string) (tokenData, error)
 	BlockSession(ctx context.Context, userID uuid.UUID, refreshToken string) error
+	ListSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) (int, error)
+	ReportSignIn(ctx context.Context, token, clientIP string) (*Token, error)
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+	EnrollMFA(ctx context.Context, userID uuid.UUID) (MFAEnrollment, error)
+	ConfirmMFA(ctx context.Context, userID uuid.UUID, code string) ([]string, error)
+	DisableMFA(ctx context.Context, userID uuid.UUID, password, code string) error
+	IsMFAEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	CreateMFAChallenge(ctx context.Context, userID uuid.UUID) (*Token, error)
+	VerifyMFAChallenge(ctx context.Context, mfaToken, code, clientIP string) (User, error)
+	RequestMagicLink(ctx context.Context, email *mail.Address) (*User, string, error)
+	VerifyMagicLink(ctx context.Context, token, clientIP string) (User, error)
+	DeleteAccount(ctx context.Context, userID uuid.UUID, password string) (time.Time, error)
+	RequestDataExport(ctx context.Context, userID uuid.UUID) (*DataExport, error)
+	DownloadDataExport(ctx context.Context, exportID uuid.UUID, token string) (*DataExport, error)
+	Impersonate(ctx context.Context, adminID, targetID uuid.UUID, reason, clientIP string) (*Impersonation, string, error)
+	StopImpersonation(ctx context.Context, adminID, sessionID uuid.UUID) error
+	RecordImpersonatedRequest(ctx context.Context, req *ImpersonatedRequest) error
+	CompleteImpersonatedRequest(ctx context.Context, id int64, status int) error
 }
 
 type UserBusinessCfg func(ub *UserBusiness) error
 
 func NewUserBusiness(cfgs ...UserBusinessCfg) (*UserBusiness, error) {
-	usb := &UserBusiness{}
+	nop := zerolog.Nop()
+	usb := &UserBusiness{log: &nop}
 	for _, cfg := range cfgs {
 		err := cfg(usb)
 		if err != nil {
@@ -53,7 +89,7 @@ func NewUserBusiness(cfgs ...UserBusinessCfg) (*UserBusiness, error) {
 }
 
 func (s *UserBusiness) CreateUser(ctx context.Context, info UserCreate) (User, Token, error) {
-	user, err := NewUser(info)
+	user, err := NewUser(info, s.passwords)
 	if err != nil {
 		return User{}, Token{}, errs.NewDomainError(errs.InvalidArgument, err)
 	}
@@ -80,7 +116,19 @@ func (s *UserBusiness) CreateUser(ctx context.Context, info UserCreate) (User, T
 			return fmt.Errorf("createOTP: %w", err)
 		}
 		token = *t
-		return nil
+
+		if len(user.PasswordHash) != 0 {
+			if err := s.rememberPassword(ctx, user.UserID, user.PasswordHash); err != nil {
+				return err
+			}
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			ActorID:    &user.UserID,
+			Action:     "user.register",
+			TargetType: "user",
+			TargetID:   user.UserID.String(),
+			After:      map[string]any{"email": user.GetEmail(), "role": user.GetRole()},
+		})
 	})
 
 	if err != nil {
@@ -95,62 +143,101 @@ func (s *UserBusiness) CreateUser(ctx context.Context, info UserCreate) (User, T
 	return user, token, nil
 }
 
-// TODO: update functionlity not complete yet
+// UpdateUser applies a partial profile update. Email and password have
+// their own flows (RequestEmailChange, ChangePassword).
 func (s *UserBusiness) UpdateUser(ctx context.Context, userID uuid.UUID, uu UpdateUser) (*User, error) {
+	uu.Sanitize()
+
 	usr, err := s.storer.GetUserByID(ctx, userID)
 	if err != nil {
-		return nil, fmt.Errorf("get user: %w", err)
+		return nil, fmt.Errorf("getuserbyid: %w", err)
 	}
+	before := profileFields(usr)
 
-	if uu.FirstName != nil {
-		usr.FirstName = *uu.FirstName
+	if !usr.UpdatedAT.Equal(uu.UpdatedAt) {
+		return nil, errs.NewDomainError(errs.Aborted, ErrUpdateConflict)
 	}
 
-	if uu.Email != nil {
-		usr.Email = uu.Email
+	fieldErrs := errs.NewFieldErrors()
+
+	if uu.FirstName != nil {
+		if *uu.FirstName == "" {
+			fieldErrs.AddFieldError("first_name", errors.New("cannot be empty"))
+		}
+		usr.FirstName = *uu.FirstName
 	}
 
-	if uu.Roles != nil {
-		usr.Role = *uu.Roles
+	if uu.LastName != nil {
+		if *uu.LastName == "" {
+			fieldErrs.AddFieldError("last_name", errors.New("cannot be empty"))
+		}
+		usr.LastName = *uu.LastName
 	}
 
-	if uu.Password != nil {
-		err := ComparePassword(usr.PasswordHash, []byte(*uu.Password))
-		if err != nil {
-			return nil, fmt.Errorf("comparepassword: %w", err)
+	// a new country changes how the stored number is read, so both are
+	// validated together
+	if uu.PhoneNumber != nil || uu.Country != nil {
+		newContact := usr.Contact
+		if uu.PhoneNumber != nil {
+			newContact.Number = *uu.PhoneNumber
 		}
-		pw, err := HashPassword([]byte(*uu.Password))
-		if err != nil {
-			return nil, fmt.Errorf("generate password hash: %w", err)
+		if uu.Country != nil {
+			newContact.Country = *uu.Country
 		}
-		usr.PasswordHash = pw
+		if err := newContact.ValidateContact(); err != nil {
+			fieldErrs.AddFieldError("phone_number", err)
+		}
+		usr.Contact = newContact
 	}
 
-	if uu.IsEnabled != nil {
-		usr.IsEnabled = *uu.IsEnabled
+	if err := fieldErrs.ToError(); err != nil {
+		return nil, errs.NewDomainError(errs.InvalidArgument, err)
 	}
 
-	usr.UpdatedAT = time.Now()
-
-	if err := s.storer.UpdateUser(ctx, usr); err != nil {
-		return nil, fmt.Errorf("update user: %w", err)
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
+		if err := s.storer.UpdateUser(ctx, usr); err != nil {
+			return err
+		}
+		b, a := audit.Diff(before, profileFields(usr))
+		return s.recordAudit(ctx, audit.Entry{
+			Action:     "user.profile_update",
+			TargetType: "user",
+			TargetID:   usr.UserID.String(),
+			Before:     b,
+			After:      a,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrUpdateConflict):
+			return nil, errs.NewDomainError(errs.Aborted, err)
+		case errors.Is(err, ErrPhoneNumberExists):
+			return nil, errs.NewDomainError(errs.AlreadyExists, err)
+		default:
+			return nil, fmt.Errorf("updateuser: %w", err)
+		}
 	}
-
 	return usr, nil
 }
 
-func (s *UserBusiness) ActivateUser(ctx context.Context, usrID uuid.UUID, token string) error {
+func (s *UserBusiness) ActivateUser(ctx context.Context, usrID uuid.UUID, token, clientIP string) error {
+	subjects := []attemptSubject{accountSubject(attemptActivation, usrID), ipSubject(attemptActivation, clientIP)}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return err
+	}
+
 	sha := sha256.Sum256([]byte(token))
+	invalid := errs.NewDomainError(errs.InvalidArgument, errors.New("invalid or expired token"))
 
 	id, err := s.storer.GetUserIDByToken(ctx, sha[:], string(ActivationToken))
 	if err != nil {
 		if errors.Is(err, ErrDatabase) {
 			return fmt.Errorf("getuseridbytoken: %w", err)
 		}
-		return errs.NewDomainError(errs.InvalidArgument, errors.New("invalid or expired token"))
+		return s.failAttempt(ctx, usrID, invalid, subjects...)
 	}
 	if id != usrID {
-		return errs.NewDomainError(errs.InvalidArgument, errors.New("invalid or expired token"))
+		return s.failAttempt(ctx, usrID, invalid, subjects...)
 	}
 
 	if err := s.trx.WithTransaction(ctx, func(ctx context.Context) error {
@@ -168,7 +255,7 @@ func (s *UserBusiness) ActivateUser(ctx context.Context, usrID uuid.UUID, token
 		return errs.NewDomainError(errs.InvalidArgument, errors.New("invalid or expired OTP"))
 	}
 
-	return nil
+	return s.clearAttempts(ctx, subjects...)
 }
 
 func (s *UserBusiness) ResendActivationToken(ctx context.Context, userID uuid.UUID) (User, Token, error) {
@@ -195,11 +282,21 @@ func (s *UserBusiness) ResendActivationToken(ctx context.Context, userID uuid.UU
 	return *u, *t, nil
 }
 
-func (s *UserBusiness) Authenticate(ctx context.Context, email *mail.Address, password string) (User, error) {
+func (s *UserBusiness) Authenticate(ctx context.Context, email *mail.Address, password, clientIP string) (User, error) {
+	subjects := []attemptSubject{
+		accountSubject(attemptSignIn, strings.ToLower(email.Address)),
+		ipSubject(attemptSignIn, clientIP),
+	}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return User{}, err
+	}
+
+	invalid := errs.NewDomainError(errs.Unauthenticated, errors.New("invalid credentials"))
+
 	user, err := s.storer.GetUserByEmail(ctx, email.Address)
 	if err != nil {
 		if errors.Is(err, ErrUserNotFound) {
-			return User{}, errs.NewDomainError(errs.Unauthenticated, errors.New("invalid credentials"))
+			return User{}, s.failAttempt(ctx, uuid.Nil, invalid, subjects...)
 		}
 		return User{}, fmt.Errorf("getinguserbyemail: %w", err)
 	}
@@ -207,16 +304,47 @@ func (s *UserBusiness) Authenticate(ctx context.Context, email *mail.Address, pa
 	if len(strings.TrimSpace(password)) == 0 {
 		return User{}, errs.NewDomainError(errs.Internal, errors.New("invalid credentials"))
 	}
+	// accounts created through google have no local password
+	if len(user.PasswordHash) == 0 {
+		return User{}, s.failAttempt(ctx, user.UserID, invalid, subjects...)
+	}
 	if err := ComparePassword(user.PasswordHash, []byte(password)); err != nil {
 		if errors.Is(err, ErrInvalidPassword) {
-			return User{}, errs.NewDomainError(errs.Unauthenticated, errors.New("invalid credentials"))
+			return User{}, s.failAttempt(ctx, user.UserID, invalid, subjects...)
 		}
 		return User{}, fmt.Errorf("comparepassword: %w", err)
 	}
+
+	if err := s.clearAttempts(ctx, subjects...); err != nil {
+		return User{}, err
+	}
+	if err := s.upgradePasswordHash(ctx, user, password); err != nil {
+		return User{}, err
+	}
 	return *user, nil
 }
 
+// upgradePasswordHash rehashes a verified password whose stored hash uses
+// an old algorithm or old parameters.
+func (s *UserBusiness) upgradePasswordHash(ctx context.Context, user *User, password string) error {
+	params := s.passwords.HashParams()
+	if !NeedsRehash(user.PasswordHash, params) {
+		return nil
+	}
+
+	hash, err := HashPassword([]byte(password), params)
+	if err != nil {
+		return fmt.Errorf("hashpassword: %w", err)
+	}
+	if err := s.storer.UpdatePassword(ctx, user.UserID, hash); err != nil {
+		return fmt.Errorf("updatepassword: %w", err)
+	}
+	user.PasswordHash = hash
+	return nil
+}
+
 type SessionData struct {
+	FamilyID              uuid.UUID
 	AccessToken           string
 	AccessTokenExpiresAt  time.Time
 	RefreshToken          string
@@ -224,33 +352,67 @@ type SessionData struct {
 }
 
 func (s *UserBusiness) CreateSession(ctx context.Context, user User, userAgent, clientIP string) (*SessionData, error) {
-	accessTokenData := authz.NewJWTData(user.UserID, user.GetRole(), 30*time.Minute, s.config.Observability.ServiceName)
+	if err := s.restoreAccount(ctx, user); err != nil {
+		return nil, err
+	}
+
+	var newDevice bool
+	if s.signins != nil {
+		var err error
+		if newDevice, err = s.isNewDevice(ctx, user.UserID, userAgent, clientIP); err != nil {
+			return nil, err
+		}
+	}
+
+	data, err := s.newSession(ctx, user.UserID, user.GetRole(), uuid.Nil, s.config.Auth.RefreshTokenLifeTime, userAgent, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	// the session is committed by now, a lost alert must not fail the
+	// sign-in
+	if newDevice {
+		if err := s.alertNewSignIn(ctx, user, data.FamilyID, userAgent, clientIP); err != nil {
+			s.log.Error().Err(err).
+				Str("user_id", user.UserID.String()).
+				Str("family_id", data.FamilyID.String()).
+				Msg("new sign-in alert failed")
+		}
+	}
+	return data, nil
+}
+
+// newSession signs an access/refresh pair and stores the refresh session.
+// A nil familyID starts a new session family. Both tokens carry the family
+// ID so a revoked family can be refused by the auth middleware.
+func (s *UserBusiness) newSession(ctx context.Context, userID uuid.UUID, role string, familyID uuid.UUID,
+	refreshTTL time.Duration, userAgent, clientIP string,
+) (*SessionData, error) {
+	if familyID == uuid.Nil {
+		familyID = uuid.New()
+	}
+
+	accessTokenData := authz.NewJWTData(userID, role, s.config.Auth.AccessTokenLifeTime, s.config.Observability.ServiceName)
+	accessTokenData.SessionID = familyID
 	accessToken, accessPayload, err := s.authz.GenerateToken(accessTokenData)
 	if err != nil {
 		return nil, fmt.Errorf("generatetoken: %w", err)
 	}
 
-	refreshTokenData := authz.NewJWTData(user.UserID, user.GetRole(), 24*time.Hour, s.config.Observability.ServiceName)
+	refreshTokenData := authz.NewJWTData(userID, role, refreshTTL, s.config.Observability.ServiceName)
+	refreshTokenData.SessionID = familyID
 	refreshToken, refreshPayload, err := s.authz.GenerateToken(refreshTokenData)
 	if err != nil {
 		return nil, fmt.Errorf("generatetoken: %w", err)
 	}
-	//cache
-	acckey := SessionAccessKeys(user.UserID)
-	err = s.cache.Set(ctx, acckey, accessToken, time.Duration(accessPayload.ExpiresAt.Second()))
-	if err != nil {
-		return nil, fmt.Errorf("setcache: %w", err)
-	}
-	refkey := SessionRefreshKeys(user.UserID)
-	err = s.cache.Set(ctx, refkey, accessToken, time.Duration(refreshPayload.ExpiresAt.Second()))
-	if err != nil {
-		return nil, fmt.Errorf("setcache: %w", err)
-	}
+
+	sessionID := uuid.MustParse(refreshPayload.ID)
 
 	hashRefresh := sha256.Sum256([]byte(refreshToken))
 	err = s.storer.CreateSession(ctx, &Session{
-		ID:           uuid.MustParse(refreshPayload.ID),
-		UserID:       user.UserID,
+		ID:           sessionID,
+		FamilyID:     familyID,
+		UserID:       userID,
 		RefreshToken: hashRefresh[:],
 		ClientIP:     clientIP,
 		UserAgent:    userAgent,
@@ -261,7 +423,20 @@ func (s *UserBusiness) CreateSession(ctx context.Context, user User, userAgent,
 		return nil, fmt.Errorf("createsession: %w", err)
 	}
 
+	//cache
+	acckey := SessionAccessKeys(userID)
+	err = s.cache.Set(ctx, acckey, accessToken, time.Until(accessPayload.ExpiresAt.Time))
+	if err != nil {
+		return nil, fmt.Errorf("setcache: %w", err)
+	}
+	refkey := SessionRefreshKeys(userID)
+	err = s.cache.Set(ctx, refkey, sessionID.String(), time.Until(refreshPayload.ExpiresAt.Time))
+	if err != nil {
+		return nil, fmt.Errorf("setcache: %w", err)
+	}
+
 	data := &SessionData{
+		FamilyID:              familyID,
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiresAt.Time,
 		RefreshToken:          refreshToken,
@@ -280,7 +455,7 @@ func (s *UserBusiness) ForgetPassword(ctx context.Context, email *mail.Address)
 		return nil, nil, fmt.Errorf("getuserbyemail: %w", err)
 	}
 
-	token, err := GenerateToken(user.UserID, 15*time.Minute, PasswordReset)
+	token, err := GenerateToken(user.UserID, passwordResetTTL, PasswordReset)
 	if err != nil {
 		return nil, nil, fmt.Errorf("generatetoken: %w", err)
 	}
@@ -294,43 +469,87 @@ func (s *UserBusiness) ForgetPassword(ctx context.Context, email *mail.Address)
 }
 
 // result of forgetting password
-func (s *UserBusiness) PasswordReset(ctx context.Context, newPass string, token string) (uuid.UUID, error) {
-	newPassword, err := HashPassword([]byte(newPass))
+func (s *UserBusiness) PasswordReset(ctx context.Context, newPass, token, clientIP string) (uuid.UUID, error) {
+	// the user is unknown until the token matches, till then only the IP is
+	// counted
+	ipSub := ipSubject(attemptPasswordReset, clientIP)
+	if err := s.checkAttempts(ctx, ipSub); err != nil {
+		return uuid.Nil, err
+	}
+
+	if err := s.passwords.Validate(newPass); err != nil {
+		return uuid.Nil, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+	newPassword, err := HashPassword([]byte(newPass), s.passwords.HashParams())
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("hashpassword: %w", err)
 	}
 
+	invalid := errs.NewDomainError(errs.Unauthenticated, errors.New("link expired or invalid"))
 	shaToken := sha256.Sum256([]byte(token))
-	var userID uuid.UUID
-
-	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
-		id, err := s.storer.GetUserIDByToken(ctx, shaToken[:], string(PasswordReset))
-		if err != nil {
-			return fmt.Errorf("getuseridbytoken: %w", err)
+	userID, err := s.storer.GetUserIDByToken(ctx, shaToken[:], string(PasswordReset))
+	if err != nil {
+		if errors.Is(err, ErrDatabase) {
+			return uuid.Nil, fmt.Errorf("getuseridbytoken: %w", err)
 		}
-		userID = id
+		return uuid.Nil, s.failAttempt(ctx, uuid.Nil, invalid, ipSub)
+	}
+
+	subjects := []attemptSubject{accountSubject(attemptPasswordReset, userID), ipSub}
+	if err := s.checkAttempts(ctx, subjects...); err != nil {
+		return uuid.Nil, err
+	}
 
+	err = s.trx.WithTransaction(ctx, func(ctx context.Context) error {
 		if err := s.storer.DeleteToken(ctx, shaToken[:], string(PasswordReset)); err != nil {
 			return fmt.Errorf("deletetoken: %w", err)
 		}
 
+		user, err := s.storer.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("getuserbyid: %w", err)
+		}
+		if err := s.checkPasswordReuse(ctx, userID, user.PasswordHash, newPass); err != nil {
+			return err
+		}
+
 		if err := s.storer.UpdatePassword(ctx, userID, newPassword); err != nil {
 			return fmt.Errorf("updatepassword: %w", err)
 		}
-		return nil
+		if err := s.rememberPassword(ctx, userID, newPassword); err != nil {
+			return err
+		}
+		return s.recordAudit(ctx, audit.Entry{
+			ActorID:    &userID,
+			Action:     "user.password_reset",
+			TargetType: "user",
+			TargetID:   userID.String(),
+		})
 	})
 
 	if err != nil {
+		if _, ok := errs.IsDomainError(err); ok {
+			return uuid.Nil, err
+		}
 		if errors.Is(err, ErrDatabase) {
 			return uuid.Nil, fmt.Errorf("dbtransaction: %w", err)
 		}
-		return uuid.Nil, errs.NewDomainError(errs.Unauthenticated, errors.New("link expired or invalid"))
+		// the link was used meanwhile
+		return uuid.Nil, s.failAttempt(ctx, userID, invalid, subjects...)
+	}
+
+	if err := s.clearAttempts(ctx, subjects...); err != nil {
+		return uuid.Nil, err
 	}
 	return userID, nil
 }
 
 // logged in user changing password
 func (s *UserBusiness) ChangePassword(ctx context.Context, userId uuid.UUID, oldPass, newPass string) (User, error) {
+	if err := s.passwords.Validate(newPass); err != nil {
+		return User{}, errs.NewDomainError(errs.InvalidArgument, err)
+	}
+
 	var userData *User
 	err := s.trx.WithTransaction(ctx, func(ctx context.Context) error {
 		// user not found db inconsistency because user must be logged in to change password
@@ -343,7 +562,11 @@ func (s *UserBusiness) ChangePassword(ctx context.Context, userId uuid.UUID, old
 			return fmt.Errorf("comparePassword: %w", err)
 		}
 
-		newHash, err := HashPassword([]byte(newPass))
+		if err := s.checkPasswordReuse(ctx, userId, userValue.PasswordHash, newPass); err != nil {
+			return err
+		}
+
+		newHash, err := HashPassword([]byte(newPass), s.passwords.HashParams())
 		if err != nil {
 			return fmt.Errorf("hashpassword: %w", err)
 		}
@@ -352,6 +575,17 @@ func (s *UserBusiness) ChangePassword(ctx context.Context, userId uuid.UUID, old
 		if err := s.storer.UpdateUser(ctx, userValue); err != nil {
 			return err
 		}
+		if err := s.rememberPassword(ctx, userId, newHash); err != nil {
+			return err
+		}
+		err = s.recordAudit(ctx, audit.Entry{
+			Action:     "user.password_change",
+			TargetType: "user",
+			TargetID:   userValue.UserID.String(),
+		})
+		if err != nil {
+			return err
+		}
 
 		userData = userValue
 		return nil
@@ -361,8 +595,66 @@ func (s *UserBusiness) ChangePassword(ctx context.Context, userId uuid.UUID, old
 		if errors.Is(err, ErrInvalidPassword) {
 			return User{}, errs.NewDomainError(errs.InvalidArgument, errors.New("current password incorrect"))
 		}
+		if _, ok := errs.IsDomainError(err); ok {
+			return User{}, err
+		}
 		return User{}, fmt.Errorf("dbtransaction : %w", err)
 
 	}
 	return *userData, nil
 }
+
+// recordAudit writes e within the transaction on ctx. Without an auditor
+// configured nothing is recorded.
+func (s *UserBusiness) recordAudit(ctx context.Context, e audit.Entry) error {
+	if s.auditor == nil {
+		return nil
+	}
+	return s.auditor.Record(ctx, e)
+}
+
+// profileFields is what a profile update can change, as it goes into the
+// audit log.
+func profileFields(u *User) map[string]any {
+	return map[string]any{
+		"first_name":   u.FirstName,
+		"last_name":    u.LastName,
+		"phone_number": u.Contact.Number,
+		"country":      u.Contact.Country,
+	}
+}
+
+// checkPasswordReuse refuses password if it is the current one or one of
+// the previous ones the policy remembers.
+func (s *UserBusiness) checkPasswordReuse(ctx context.Context, userID uuid.UUID, current []byte, password string) error {
+	n := s.passwords.History()
+	if n == 0 {
+		return nil
+	}
+
+	hashes, err := s.storer.ListPasswordHistory(ctx, userID, n)
+	if err != nil {
+		return fmt.Errorf("listpasswordhistory: %w", err)
+	}
+	reused, err := s.passwords.reused(password, append(hashes, current)...)
+	if err != nil {
+		return err
+	}
+	if reused {
+		fe := errs.NewFieldErrors()
+		fe.AddFieldError("password", errReusedPassword)
+		return errs.NewDomainError(errs.InvalidArgument, fe)
+	}
+	return nil
+}
+
+func (s *UserBusiness) rememberPassword(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	n := s.passwords.History()
+	if n == 0 {
+		return nil
+	}
+	if err := s.storer.AddPasswordHistory(ctx, userID, hash, n); err != nil {
+		return fmt.Errorf("addpasswordhistory: %w", err)
+	}
+	return nil
+}
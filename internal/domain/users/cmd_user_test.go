@@ -0,0 +1,41 @@
+package users_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	mockdb "github.com/iamonah/merchcore/internal/domain/users/userdb/mock"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"go.uber.org/mock/gomock"
+)
+
+func TestPasswordResetCountsAgainstAccount(t *testing.T) {
+	repo := mockdb.NewMockUserRepository(gomock.NewController(t))
+	cache := newMemCache()
+	ub, err := users.NewUserBusiness(
+		users.WithUserRepository(repo),
+		users.WithTrxManager(fakeTrx{}),
+		users.WithCache(cache),
+		users.WithConfigs(&config.Config{}),
+		users.WithPasswordPolicy(users.NewPasswordPolicy(config.PasswordConfig{MinLength: 8}, nil)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userID := uuid.New()
+	// the account is locked, from another IP
+	if err := cache.Set(context.Background(), users.AttemptLock("passwordreset:account:"+userID.String()), true, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	repo.EXPECT().GetUserIDByToken(gomock.Any(), gomock.Any(), string(users.PasswordReset)).Return(userID, nil)
+
+	_, err = ub.PasswordReset(context.Background(), "correct horse battery", "reset-token", "198.51.100.4")
+	if derr, ok := errs.IsDomainError(err); !ok || derr.Code != errs.TooManyRequests {
+		t.Fatalf("err = %v, want TooManyRequests", err)
+	}
+}
@@ -0,0 +1,141 @@
+package users
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// The export records are the stable, documented shape of a data export,
+// they do not follow the API response types.
+
+type exportProfile struct {
+	ID          uuid.UUID  `json:"id"`
+	Email       string     `json:"email"`
+	FirstName   string     `json:"first_name"`
+	LastName    string     `json:"last_name"`
+	PhoneNumber string     `json:"phone_number"`
+	Country     string     `json:"country"`
+	Provider    string     `json:"provider"`
+	Role        string     `json:"role"`
+	IsVerified  bool       `json:"is_verified"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+}
+
+type exportSession struct {
+	ID         uuid.UUID  `json:"id"`
+	SessionID  uuid.UUID  `json:"session_id"`
+	UserAgent  string     `json:"user_agent"`
+	ClientIP   string     `json:"client_ip"`
+	Revoked    bool       `json:"revoked"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+}
+
+type exportAddress struct {
+	Street      string    `json:"street"`
+	City        string    `json:"city"`
+	State       string    `json:"state"`
+	PostalCode  string    `json:"postal_code"`
+	Country     string    `json:"country"`
+	AddressType string    `json:"address_type"`
+	IsDefault   bool      `json:"is_default"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type exportTenant struct {
+	ID           uuid.UUID `json:"id"`
+	BusinessName string    `json:"business_name"`
+	Domain       string    `json:"domain"`
+	Subdomain    string    `json:"subdomain"`
+	Plan         string    `json:"plan"`
+	Status       string    `json:"status"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// buildExportArchive zips one JSON file per kind of record.
+func buildExportArchive(user User, sessions []Session, addresses []Address, tenants []OwnedTenant) ([]byte, error) {
+	profile := exportProfile{
+		ID:          user.UserID,
+		Email:       user.GetEmail(),
+		FirstName:   user.FirstName,
+		LastName:    user.LastName,
+		PhoneNumber: user.Contact.Number,
+		Country:     user.Contact.Country,
+		Provider:    user.Provider.String(),
+		Role:        user.GetRole(),
+		IsVerified:  user.IsVerified,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAT,
+		DeletedAt:   user.DeletedAt,
+	}
+
+	sess := make([]exportSession, 0, len(sessions))
+	for _, s := range sessions {
+		sess = append(sess, exportSession{
+			ID:         s.ID,
+			SessionID:  s.FamilyID,
+			UserAgent:  s.UserAgent,
+			ClientIP:   s.ClientIP,
+			Revoked:    s.IsBlocked,
+			CreatedAt:  s.CreatedAt,
+			ExpiresAt:  s.ExpiresAt,
+			ConsumedAt: s.ConsumedAt,
+		})
+	}
+
+	addrs := make([]exportAddress, 0, len(addresses))
+	for _, a := range addresses {
+		addrs = append(addrs, exportAddress{
+			Street:      a.Street,
+			City:        a.City,
+			State:       a.State,
+			PostalCode:  a.PostalCode,
+			Country:     a.Country,
+			AddressType: a.AddressType,
+			IsDefault:   a.IsDefault,
+			CreatedAt:   a.CreatedAt,
+			UpdatedAt:   a.UpdatedAt,
+		})
+	}
+
+	stores := make([]exportTenant, 0, len(tenants))
+	for _, t := range tenants {
+		stores = append(stores, exportTenant(t))
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	files := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", profile},
+		{"sessions.json", sess},
+		{"addresses.json", addrs},
+		{"stores.json", stores},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, fmt.Errorf("zip create %s: %w", f.name, err)
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return nil, fmt.Errorf("encode %s: %w", f.name, err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("zip close: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,77 @@
+package users_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/iamonah/merchcore/internal/infra/cache"
+)
+
+type fakeTrx struct{}
+
+func (fakeTrx) WithTransaction(ctx context.Context, fn func(context.Context) error) error {
+	return fn(ctx)
+}
+
+// memCache is a cache.Cache whose keys never expire, TTL reports the one
+// they were set with.
+type memCache struct {
+	mu   sync.Mutex
+	vals map[string][]byte
+	ttls map[string]time.Duration
+	ctrs map[string]int64
+}
+
+var _ cache.Cache = (*memCache)(nil)
+
+func newMemCache() *memCache {
+	return &memCache{vals: make(map[string][]byte), ttls: make(map[string]time.Duration), ctrs: make(map[string]int64)}
+}
+
+func (c *memCache) Set(_ context.Context, key string, value any, ttl time.Duration) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.vals[key] = b
+	c.ttls[key] = ttl
+	return nil
+}
+
+func (c *memCache) Get(_ context.Context, key string, dest any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	b, ok := c.vals[key]
+	if !ok {
+		return cache.ErrCacheMiss
+	}
+	return json.Unmarshal(b, dest)
+}
+
+func (c *memCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.vals, key)
+	delete(c.ttls, key)
+	delete(c.ctrs, key)
+	return nil
+}
+
+func (c *memCache) Incr(_ context.Context, key string, _ time.Duration) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ctrs[key]++
+	return c.ctrs[key], nil
+}
+
+func (c *memCache) TTL(_ context.Context, key string) (time.Duration, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ttls[key], nil
+}
+
+func (c *memCache) Close() error { return nil }
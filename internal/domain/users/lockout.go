@@ -0,0 +1,152 @@
+package users
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+var ErrTooManyAttempts = errors.New("too many failed attempts, try again later")
+
+// LockNotifier warns the account owner that sign-in was locked.
+type LockNotifier interface {
+	AccountLockedEmailJob(email, firstName string, until time.Time) error
+}
+
+type attemptAction string
+
+const (
+	attemptSignIn        attemptAction = "signin"
+	attemptActivation    attemptAction = "activation"
+	attemptPasswordReset attemptAction = "passwordreset"
+	attemptMagicLink     attemptAction = "magiclink"
+	attemptSignInReport  attemptAction = "signinreport"
+	attemptMFA           attemptAction = "mfa"
+)
+
+const (
+	attemptWindow   = 15 * time.Minute
+	lockoutDuration = 15 * time.Minute
+	backoffBase     = time.Second
+)
+
+// attemptLimit is the number of failures tolerated before backing off and
+// before the subject is locked. IPs get more room, many users may share one.
+type attemptLimit struct {
+	free   int64
+	lockAt int64
+}
+
+var (
+	accountLimit = attemptLimit{free: 3, lockAt: 10}
+	ipLimit      = attemptLimit{free: 20, lockAt: 100}
+)
+
+type attemptSubject struct {
+	key     string
+	limit   attemptLimit
+	account bool
+}
+
+func accountSubject(action attemptAction, id any) attemptSubject {
+	return attemptSubject{key: fmt.Sprintf("%s:account:%v", action, id), limit: accountLimit, account: true}
+}
+
+func ipSubject(action attemptAction, ip string) attemptSubject {
+	return attemptSubject{key: fmt.Sprintf("%s:ip:%s", action, ip), limit: ipLimit}
+}
+
+// backoff grows exponentially once the free attempts are used up.
+func (l attemptLimit) backoff(failures int64) time.Duration {
+	if failures >= l.lockAt {
+		return lockoutDuration
+	}
+	if failures <= l.free {
+		return 0
+	}
+	d := backoffBase << (failures - l.free - 1)
+	return min(d, lockoutDuration)
+}
+
+// checkAttempts refuses the request while any subject is backing off.
+func (s *UserBusiness) checkAttempts(ctx context.Context, subjects ...attemptSubject) error {
+	var wait time.Duration
+	for _, sub := range subjects {
+		ttl, err := s.cache.TTL(ctx, AttemptLock(sub.key))
+		if err != nil {
+			return fmt.Errorf("attemptlock: %w", err)
+		}
+		wait = max(wait, ttl)
+	}
+
+	if wait > 0 {
+		return errs.NewDomainError(errs.TooManyRequests, errs.NewRetryError(ErrTooManyAttempts, wait))
+	}
+	return nil
+}
+
+// recordFailure counts a failed attempt and reports whether it locked an
+// account subject, so the owner can be told exactly once.
+func (s *UserBusiness) recordFailure(ctx context.Context, subjects ...attemptSubject) (bool, error) {
+	var locked bool
+	for _, sub := range subjects {
+		n, err := s.cache.Incr(ctx, AttemptCount(sub.key), attemptWindow)
+		if err != nil {
+			return false, fmt.Errorf("attemptcount: %w", err)
+		}
+
+		wait := sub.limit.backoff(n)
+		if wait == 0 {
+			continue
+		}
+		if err := s.cache.Set(ctx, AttemptLock(sub.key), true, wait); err != nil {
+			return false, fmt.Errorf("attemptlock: %w", err)
+		}
+		if sub.account && n == sub.limit.lockAt {
+			locked = true
+		}
+	}
+	return locked, nil
+}
+
+// clearAttempts resets account counters after a success. IP counters are
+// left to expire so one valid account cannot launder a spraying IP.
+func (s *UserBusiness) clearAttempts(ctx context.Context, subjects ...attemptSubject) error {
+	for _, sub := range subjects {
+		if !sub.account {
+			continue
+		}
+		if err := s.cache.Delete(ctx, AttemptCount(sub.key)); err != nil {
+			return fmt.Errorf("attemptcount: %w", err)
+		}
+		if err := s.cache.Delete(ctx, AttemptLock(sub.key)); err != nil {
+			return fmt.Errorf("attemptlock: %w", err)
+		}
+	}
+	return nil
+}
+
+// failAttempt records the failure and returns failErr, or an internal error
+// if bookkeeping failed. A newly locked account gets a notice email.
+func (s *UserBusiness) failAttempt(ctx context.Context, userID uuid.UUID, failErr error, subjects ...attemptSubject) error {
+	locked, err := s.recordFailure(ctx, subjects...)
+	if err != nil {
+		return err
+	}
+
+	if locked && userID != uuid.Nil && s.notifier != nil {
+		user, err := s.storer.GetUserByID(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("getuserbyid: %w", err)
+		}
+		until := time.Now().Add(lockoutDuration)
+		if err := s.notifier.AccountLockedEmailJob(user.GetEmail(), user.FirstName, until); err != nil {
+			return fmt.Errorf("accountlockedemailjob: %w", err)
+		}
+	}
+	return failErr
+}
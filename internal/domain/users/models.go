@@ -1,13 +1,10 @@
 package users
 
 import (
-	"net/mail"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
-	"github.com/iamonah/merchcore/internal/domain/types/contact"
-	"github.com/iamonah/merchcore/internal/domain/types/role"
 )
 
 type UserCreate struct {
@@ -47,12 +44,14 @@ type UserUpdate struct {
 
 type Session struct {
 	ID           uuid.UUID
+	FamilyID     uuid.UUID // shared by every rotation of one sign-in
 	UserID       uuid.UUID
 	RefreshToken []byte
 	UserAgent    string
 	ClientIP     string
 	IsBlocked    bool
 	ExpiresAt    time.Time
+	ConsumedAt   *time.Time
 	CreatedAt    time.Time
 }
 
@@ -68,15 +67,122 @@ func (ses *Session) IsSessionExpired() bool {
 	return time.Now().After(ses.ExpiresAt)
 }
 
+func (ses *Session) IsConsumed() bool {
+	return ses.ConsumedAt != nil
+}
+
+type MFA struct {
+	UserID       uuid.UUID
+	Secret       []byte // encrypted TOTP seed
+	IsEnabled    bool
+	LastUsedStep int64
+	ConfirmedAt  *time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// UpdateUser is a partial profile update. UpdatedAt is the version the
+// client last read, the update is rejected if the row changed since.
 type UpdateUser struct {
-	FirstName      *string
-	LastName       *string
-	Email          *mail.Address
-	Roles          *role.Role
-	Password       *string
-	IsEnabled      *bool
-	PhoneNumber    *contact.Contact
-	UpdatedAt      *time.Time
-	NumOfStore     *int
-	IsStoreCreated *bool
+	FirstName   *string
+	LastName    *string
+	PhoneNumber *string
+	Country     *string
+	UpdatedAt   time.Time
+}
+
+func (u *UpdateUser) Sanitize() {
+	for _, f := range []*string{u.FirstName, u.LastName, u.PhoneNumber, u.Country} {
+		if f != nil {
+			*f = strings.TrimSpace(*f)
+		}
+	}
+}
+
+// EmailChange is a pending switch to NewEmail, applied once the token sent
+// to the new address comes back.
+type EmailChange struct {
+	TokenHash []byte
+	UserID    uuid.UUID
+	NewEmail  string
+	Expiry    time.Time
+}
+
+// SignInReport lets the owner disown a sign-in from the alert email. The
+// token revokes FamilyID.
+type SignInReport struct {
+	TokenHash []byte
+	UserID    uuid.UUID
+	FamilyID  uuid.UUID
+	Expiry    time.Time
+}
+
+type Address struct {
+	ID          int64
+	Street      string
+	City        string
+	State       string
+	PostalCode  string
+	Country     string
+	AddressType string
+	IsDefault   bool
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// OwnedTenant is the part of a store the owner gets back in a data export.
+type OwnedTenant struct {
+	ID           uuid.UUID
+	BusinessName string
+	Domain       string
+	Subdomain    string
+	Plan         string
+	Status       string
+	CreatedAt    time.Time
+}
+
+type ExportStatus string
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportReady   ExportStatus = "ready"
+)
+
+// DataExport is a GDPR export request. Archive is the zip built by the
+// export job, downloadable with the token mailed to the user until
+// ExpiresAt.
+type DataExport struct {
+	ID          uuid.UUID
+	UserID      uuid.UUID
+	Status      ExportStatus
+	Archive     []byte
+	TokenHash   []byte
+	ExpiresAt   *time.Time
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// Impersonation is a platform admin acting as another user. ID is also the
+// session family of the token it issued, revoking it ends the session.
+type Impersonation struct {
+	ID        uuid.UUID
+	AdminID   uuid.UUID
+	UserID    uuid.UUID
+	Reason    string
+	ClientIP  string
+	StartedAt time.Time
+	ExpiresAt time.Time
+	EndedAt   *time.Time
+}
+
+// ImpersonatedRequest is the audit record of one request made while
+// impersonating. Status is filled in once the request has been served.
+type ImpersonatedRequest struct {
+	ID        int64
+	SessionID uuid.UUID
+	Method    string
+	Path      string
+	ReqID     string
+	ClientIP  string
+	Status    int
 }
@@ -1,31 +1,71 @@
 package users
 
 import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-const HashCost = 12
+// Passwords are hashed with Argon2id and stored in the PHC string format,
+// $argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>, so the parameters travel
+// with each hash. Older bcrypt hashes still verify and are replaced on the
+// next sign-in, see NeedsRehash.
+const (
+	argon2SaltLen = 16
+	argon2KeyLen  = 32
+)
 
-// Todo: handle the password to long approach sha256->hex before bycrpt
-var ErrInvalidPassword = errors.New("incorrect password")
+// HashParams tune Argon2id. Memory is in KiB.
+type HashParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+}
 
-func HashPassword(password []byte) ([]byte, error) {
-	hashPassword, err := bcrypt.GenerateFromPassword([]byte(password), HashCost)
-	if err != nil {
-		switch {
-		case errors.Is(err, bcrypt.ErrHashTooShort):
-			return nil, fmt.Errorf("hash too short: %w", err)
-		}
-		return nil, err
+var DefaultHashParams = HashParams{Memory: 64 * 1024, Iterations: 3, Parallelism: 2}
+
+// prehashTag marks bcrypt hashes of the base64 SHA-256 of the password
+// rather than the password itself, bcrypt ignores everything past 72 bytes.
+var prehashTag = []byte("$sha256")
+
+var (
+	ErrInvalidPassword = errors.New("incorrect password")
+	errUnknownHash     = errors.New("unknown password hash format")
+	phcEncoding        = base64.RawStdEncoding
+)
+
+func HashPassword(password []byte, params HashParams) ([]byte, error) {
+	salt := make([]byte, argon2SaltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("randread: %w", err)
 	}
-	return hashPassword, nil
+	key := argon2.IDKey(password, salt, params.Iterations, params.Memory, params.Parallelism, argon2KeyLen)
+
+	phc := fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, params.Memory, params.Iterations, params.Parallelism,
+		phcEncoding.EncodeToString(salt), phcEncoding.EncodeToString(key))
+	return []byte(phc), nil
 }
 
+// ComparePassword checks password against a hash in any format this
+// package has ever written.
 func ComparePassword(storedHash, password []byte) error {
-	err := bcrypt.CompareHashAndPassword(storedHash, []byte(password))
+	if bytes.HasPrefix(storedHash, []byte("$argon2id$")) {
+		return compareArgon2id(storedHash, password)
+	}
+	if h, ok := bytes.CutPrefix(storedHash, prehashTag); ok {
+		storedHash, password = h, prehash(password)
+	}
+
+	err := bcrypt.CompareHashAndPassword(storedHash, password)
 	if err != nil {
 		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
 			return ErrInvalidPassword
@@ -34,3 +74,55 @@ func ComparePassword(storedHash, password []byte) error {
 	}
 	return nil
 }
+
+// NeedsRehash reports whether storedHash was made with another algorithm or
+// other parameters than params.
+func NeedsRehash(storedHash []byte, params HashParams) bool {
+	p, version, _, _, err := parseArgon2id(storedHash)
+	return err != nil || version != argon2.Version || p != params
+}
+
+func compareArgon2id(storedHash, password []byte) error {
+	p, _, salt, key, err := parseArgon2id(storedHash)
+	if err != nil {
+		return err
+	}
+	other := argon2.IDKey(password, salt, p.Iterations, p.Memory, p.Parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(key, other) != 1 {
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+func parseArgon2id(storedHash []byte) (HashParams, int, []byte, []byte, error) {
+	var (
+		p       HashParams
+		version int
+	)
+	parts := strings.Split(string(storedHash), "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return p, 0, nil, nil, errUnknownHash
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil {
+		return p, 0, nil, nil, fmt.Errorf("%w: %w", errUnknownHash, err)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Iterations, &p.Parallelism); err != nil {
+		return p, 0, nil, nil, fmt.Errorf("%w: %w", errUnknownHash, err)
+	}
+	salt, err := phcEncoding.DecodeString(parts[4])
+	if err != nil {
+		return p, 0, nil, nil, fmt.Errorf("%w: %w", errUnknownHash, err)
+	}
+	key, err := phcEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return p, 0, nil, nil, fmt.Errorf("%w: bad key", errUnknownHash)
+	}
+	return p, version, salt, key, nil
+}
+
+func prehash(password []byte) []byte {
+	sum := sha256.Sum256(password)
+	out := make([]byte, base64.StdEncoding.EncodedLen(len(sum)))
+	base64.StdEncoding.Encode(out, sum[:])
+	return out
+}
@@ -0,0 +1,128 @@
+package users
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testParams keep the tests fast, the format does not depend on them.
+var testParams = HashParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 1}
+
+func TestHashPasswordRoundTrip(t *testing.T) {
+	passwords := []string{"correct horse battery staple", "", "ünïcödé-pässwörd", strings.Repeat("long", 50)}
+	for _, pw := range passwords {
+		hash, err := HashPassword([]byte(pw), testParams)
+		if err != nil {
+			t.Fatalf("HashPassword(%q): %v", pw, err)
+		}
+		if !bytes.HasPrefix(hash, []byte("$argon2id$v=19$m=8192,t=1,p=1$")) {
+			t.Fatalf("hash = %s", hash)
+		}
+		if err := ComparePassword(hash, []byte(pw)); err != nil {
+			t.Errorf("ComparePassword(%q): %v", pw, err)
+		}
+		if err := ComparePassword(hash, []byte(pw+"x")); !errors.Is(err, ErrInvalidPassword) {
+			t.Errorf("ComparePassword(%q+x) = %v, want %v", pw, err, ErrInvalidPassword)
+		}
+	}
+
+	a, _ := HashPassword([]byte("same"), testParams)
+	b, _ := HashPassword([]byte("same"), testParams)
+	if bytes.Equal(a, b) {
+		t.Fatal("two hashes of one password are equal, salt is not random")
+	}
+}
+
+func TestComparePasswordLegacyBcrypt(t *testing.T) {
+	short := []byte("hunter22")
+	plain, err := bcrypt.GenerateFromPassword(short, bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// bcrypt stops at 72 bytes, the prehashed format tells these apart
+	long := []byte(strings.Repeat("a", 72) + "tail")
+	ph, err := bcrypt.GenerateFromPassword(prehash(long), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prehashed := append(append([]byte{}, prehashTag...), ph...)
+
+	tests := []struct {
+		name     string
+		hash     []byte
+		password []byte
+		want     error
+	}{
+		{"bcrypt", plain, short, nil},
+		{"bcrypt wrong password", plain, []byte("hunter23"), ErrInvalidPassword},
+		{"prehashed bcrypt", prehashed, long, nil},
+		{"prehashed bcrypt past 72 bytes", prehashed, []byte(strings.Repeat("a", 72) + "other"), ErrInvalidPassword},
+		{"prehashed tag read as plain bcrypt", ph, long, ErrInvalidPassword},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ComparePassword(tt.hash, tt.password); !errors.Is(err, tt.want) {
+				t.Fatalf("ComparePassword = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	if err := ComparePassword([]byte("not a hash"), short); err == nil || errors.Is(err, ErrInvalidPassword) {
+		t.Fatalf("ComparePassword on garbage = %v, want a format error", err)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := HashPassword([]byte("pw"), testParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte("pw"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		hash   []byte
+		params HashParams
+		want   bool
+	}{
+		{"same params", current, testParams, false},
+		{"more memory", current, HashParams{Memory: 16 * 1024, Iterations: 1, Parallelism: 1}, true},
+		{"more iterations", current, HashParams{Memory: 8 * 1024, Iterations: 2, Parallelism: 1}, true},
+		{"more parallelism", current, HashParams{Memory: 8 * 1024, Iterations: 1, Parallelism: 2}, true},
+		{"bcrypt", legacy, testParams, true},
+		{"prehashed bcrypt", append(append([]byte{}, prehashTag...), legacy...), testParams, true},
+		{"older argon2 version", bytes.Replace(current, []byte("v=19"), []byte("v=16"), 1), testParams, true},
+		{"malformed params", []byte("$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5"), testParams, true},
+		{"missing key", []byte("$argon2id$v=19$m=8192,t=1,p=1$c2FsdA$"), testParams, true},
+		{"empty", nil, testParams, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NeedsRehash(tt.hash, tt.params); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComparePasswordMalformedArgon2id(t *testing.T) {
+	hashes := []string{
+		"$argon2id$v=19$m=8192,t=1,p=1$c2FsdA",
+		"$argon2id$v=19$m=x,t=1,p=1$c2FsdA$a2V5",
+		"$argon2id$v=19$m=8192,t=1,p=1$!!$a2V5",
+		"$argon2id$v=19$m=8192,t=1,p=1$c2FsdA$",
+	}
+	for _, h := range hashes {
+		if err := ComparePassword([]byte(h), []byte("pw")); !errors.Is(err, errUnknownHash) {
+			t.Errorf("ComparePassword(%s) = %v, want %v", h, err, errUnknownHash)
+		}
+	}
+}
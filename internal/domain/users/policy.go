@@ -0,0 +1,140 @@
+package users
+
+import (
+	"errors"
+	"fmt"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+const defaultMinPasswordLength = 8
+
+var (
+	errBreachedPassword = errors.New("has appeared in a data breach, choose another")
+	errReusedPassword   = errors.New("was used recently, choose another")
+)
+
+// PasswordPolicy decides which new passwords are acceptable. A nil policy
+// only enforces the default minimum length.
+type PasswordPolicy struct {
+	minLength  int
+	minClasses int
+	history    int
+	breached   *BreachedList
+	hash       HashParams
+}
+
+// NewPasswordPolicy builds the policy from cfg. breached may be nil when no
+// list is configured.
+func NewPasswordPolicy(cfg config.PasswordConfig, breached *BreachedList) *PasswordPolicy {
+	p := &PasswordPolicy{
+		minLength:  cfg.MinLength,
+		minClasses: cfg.MinClasses,
+		history:    cfg.History,
+		breached:   breached,
+		hash:       DefaultHashParams,
+	}
+	if p.minLength <= 0 {
+		p.minLength = defaultMinPasswordLength
+	}
+	if cfg.HashMemory > 0 {
+		p.hash.Memory = cfg.HashMemory
+	}
+	if cfg.HashIterations > 0 {
+		p.hash.Iterations = cfg.HashIterations
+	}
+	if cfg.HashParallelism > 0 {
+		p.hash.Parallelism = cfg.HashParallelism
+	}
+	return p
+}
+
+// HashParams are the Argon2id parameters new hashes are made with.
+func (p *PasswordPolicy) HashParams() HashParams {
+	if p == nil {
+		return DefaultHashParams
+	}
+	return p.hash
+}
+
+// History is how many previous passwords may not be reused.
+func (p *PasswordPolicy) History() int {
+	if p == nil {
+		return 0
+	}
+	return p.history
+}
+
+// Validate checks password against the policy. Violations come back as
+// field errors on "password".
+func (p *PasswordPolicy) Validate(password string) error {
+	fe := errs.NewFieldErrors()
+	p.check(fe, password)
+	return fe.ToError()
+}
+
+func (p *PasswordPolicy) check(fe *errs.FieldErrors, password string) {
+	if password == "" {
+		fe.AddFieldError("password", errors.New("cannot be empty"))
+		return
+	}
+
+	minLength, minClasses := defaultMinPasswordLength, 0
+	if p != nil {
+		minLength, minClasses = p.minLength, p.minClasses
+	}
+
+	if utf8.RuneCountInString(password) < minLength {
+		fe.AddFieldError("password", fmt.Errorf("must be at least %d characters", minLength))
+	}
+	if charClasses(password) < minClasses {
+		fe.AddFieldError("password", fmt.Errorf("must mix at least %d of lowercase, uppercase, digits and symbols", minClasses))
+	}
+	if p != nil && p.breached.Contains(password) {
+		fe.AddFieldError("password", errBreachedPassword)
+	}
+}
+
+// reused reports whether password matches any of the given hashes.
+func (p *PasswordPolicy) reused(password string, hashes ...[]byte) (bool, error) {
+	for _, h := range hashes {
+		if len(h) == 0 {
+			continue
+		}
+		err := ComparePassword(h, []byte(password))
+		switch {
+		case err == nil:
+			return true, nil
+		case !errors.Is(err, ErrInvalidPassword):
+			return false, fmt.Errorf("comparepassword: %w", err)
+		}
+	}
+	return false, nil
+}
+
+func charClasses(s string) int {
+	var lower, upper, digit, other bool
+	for _, r := range s {
+		switch {
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			other = true
+		}
+	}
+
+	n := 0
+	for _, ok := range []bool{lower, upper, digit, other} {
+		if ok {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,83 @@
+package users
+
+import (
+	"crypto/sha1"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+func TestCharClasses(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 1},
+		{"ABC", 1},
+		{"123", 1},
+		{"!@#", 1},
+		{"abcDEF", 2},
+		{"abc123", 2},
+		{"abcDEF123", 3},
+		{"abcDEF123!", 4},
+		{"aA1 ", 4},
+		{"ünïcödé", 1},
+		{"ÜnÏ", 2},
+		{"٣٤٥", 1},
+	}
+	for _, tt := range tests {
+		if got := charClasses(tt.in); got != tt.want {
+			t.Errorf("charClasses(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPasswordPolicyValidate(t *testing.T) {
+	breached := &BreachedList{hashes: [][sha1.Size]byte{sha1.Sum([]byte("Summer2024!"))}}
+	strict := NewPasswordPolicy(config.PasswordConfig{MinLength: 10, MinClasses: 3}, breached)
+
+	tests := []struct {
+		name     string
+		policy   *PasswordPolicy
+		password string
+		want     []string
+	}{
+		{"nil policy accepts default length", nil, "abcdefgh", nil},
+		{"nil policy refuses short", nil, "abcdefg", []string{"at least 8 characters"}},
+		{"empty", strict, "", []string{"cannot be empty"}},
+		{"length counts runes", NewPasswordPolicy(config.PasswordConfig{MinLength: 8}, nil), "ééééééé", []string{"at least 8 characters"}},
+		{"zero min length falls back to default", NewPasswordPolicy(config.PasswordConfig{}, nil), "short", []string{"at least 8 characters"}},
+		{"too few classes", strict, "alllowercase1", []string{"at least 3 of"}},
+		{"short and too few classes", strict, "abc", []string{"at least 10 characters", "at least 3 of"}},
+		{"breached", strict, "Summer2024!", []string{errBreachedPassword.Error()}},
+		{"acceptable", strict, "Correct-Horse-9", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate(tt.password)
+			if len(tt.want) == 0 {
+				if err != nil {
+					t.Fatalf("Validate: %v", err)
+				}
+				return
+			}
+
+			var fe *errs.FieldErrors
+			if !errors.As(err, &fe) {
+				t.Fatalf("Validate = %v, want field errors", err)
+			}
+			if len(*fe) != len(tt.want) {
+				t.Fatalf("Validate = %v, want %d errors", err, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if f := (*fe)[i]; f.Field != "password" || !strings.Contains(f.Err, want) {
+					t.Errorf("error %d = %s: %s, want password: ...%s...", i, f.Field, f.Err, want)
+				}
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package users
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,20 +19,68 @@ var (
 	ErrTokenNotFound       = errors.New("otp not found")
 	ErrTokenExpired        = errors.New("otp is expired")
 	ErrSessionNotFound     = errors.New("user session not found")
+	ErrSessionConsumed     = errors.New("refresh session already consumed")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected")
+	ErrMFANotFound         = errors.New("mfa not configured")
+	ErrMFAAlreadyEnabled   = errors.New("mfa already enabled")
+	ErrMFACodeReused       = errors.New("mfa code already used")
+	ErrRecoveryCodeInvalid = errors.New("recovery code invalid or used")
+	ErrUpdateConflict      = errors.New("user was modified by another request")
+	ErrEmailChangeNotFound = errors.New("email change request not found")
+	ErrExportNotFound      = errors.New("data export not found")
+	ErrExportInProgress    = errors.New("a data export is already being prepared")
+	ErrImpersonationEnded  = errors.New("impersonation session not found or already ended")
+	ErrSignInReportInvalid = errors.New("sign-in report link expired or invalid")
 )
 
 type UserRepository interface {
 	CreateUser(context.Context, *User) error
 	GetUserByID(ctx context.Context, userID uuid.UUID) (*User, error)
 	GetUserByEmail(ctx context.Context, email string) (*User, error)
+	GetUserByProviderID(ctx context.Context, providerID string) (*User, error)
+	LinkProviderID(ctx context.Context, userID uuid.UUID, providerID string) error
 	GetUserPhoneNumber(ctx context.Context, phoneNum string) error
 	UpdateUser(ctx context.Context, user *User) error
 	VerifyUser(ctx context.Context, userID uuid.UUID) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+	CreateEmailChange(ctx context.Context, ec *EmailChange) error
+	ConsumeEmailChange(ctx context.Context, hash []byte) (*EmailChange, error)
+	CreateSignInReport(ctx context.Context, r *SignInReport) error
+	ConsumeSignInReport(ctx context.Context, hash []byte) (*SignInReport, error)
 	CreateSession(ctx context.Context, s *Session) error
 	GetSession(ctx context.Context, sessionId string) (*Session, error)
 	CreateToken(ctx context.Context, otp *Token) error
 	GetUserIDByToken(ctx context.Context, hash []byte, scope string) (uuid.UUID, error)
 	DeleteToken(ctx context.Context, hash []byte, scope string) error
 	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash []byte) error
-	BlockSession(ctx context.Context, token []byte) error
+	ListPasswordHistory(ctx context.Context, userID uuid.UUID, n int) ([][]byte, error)
+	AddPasswordHistory(ctx context.Context, userID uuid.UUID, hash []byte, keep int) error
+	BlockSession(ctx context.Context, token []byte) (uuid.UUID, error)
+	ConsumeSession(ctx context.Context, sessionID uuid.UUID) error
+	BlockSessionFamily(ctx context.Context, userID, familyID uuid.UUID) error
+	BlockUserSessions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error)
+	ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	UpsertMFA(ctx context.Context, m *MFA) error
+	GetMFA(ctx context.Context, userID uuid.UUID) (*MFA, error)
+	EnableMFA(ctx context.Context, userID uuid.UUID, step int64) error
+	UpdateMFAStep(ctx context.Context, userID uuid.UUID, step int64) error
+	DeleteMFA(ctx context.Context, userID uuid.UUID) error
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error
+	SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error)
+	RestoreUser(ctx context.Context, userID uuid.UUID) error
+	AnonymizeUser(ctx context.Context, userID uuid.UUID, passwordHash []byte) error
+	// ListOverduePurges returns soft deleted users not yet anonymized whose
+	// deletion started before deletedBefore, oldest first.
+	ListOverduePurges(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error)
+	ListUserSessions(ctx context.Context, userID uuid.UUID) ([]Session, error)
+	ListAddresses(ctx context.Context, userID uuid.UUID) ([]Address, error)
+	ListOwnedTenants(ctx context.Context, userID uuid.UUID) ([]OwnedTenant, error)
+	CreateDataExport(ctx context.Context, e *DataExport) error
+	GetDataExport(ctx context.Context, exportID uuid.UUID) (*DataExport, error)
+	CompleteDataExport(ctx context.Context, e *DataExport) error
+	CreateImpersonation(ctx context.Context, imp *Impersonation) error
+	EndImpersonation(ctx context.Context, sessionID, adminID uuid.UUID) error
+	CreateImpersonatedRequest(ctx context.Context, req *ImpersonatedRequest) error
+	SetImpersonatedRequestStatus(ctx context.Context, id int64, status int) error
 }
@@ -63,7 +63,9 @@ func generateUserID() uuid.UUID {
 	return uuid.New()
 }
 
-func NewUser(userInfo UserCreate) (User, error) {
+// NewUser validates userInfo into a new guest user. The password, when
+// given, must satisfy policy.
+func NewUser(userInfo UserCreate, policy *PasswordPolicy) (User, error) {
 	userInfo.Sanitize()
 	fieldErrs := errs.NewFieldErrors()
 
@@ -96,30 +98,32 @@ func NewUser(userInfo UserCreate) (User, error) {
 		fieldErrs.AddFieldError("email", err)
 	}
 
-	if userInfo.Password == "" {
-		fieldErrs.AddFieldError("password", errors.New("cannot be empty"))
-	} else {
-		hashed, err := HashPassword([]byte(userInfo.Password))
+	//used password and email signup
+	user.Provider = Local
+	if userInfo.ProviderID != nil && userInfo.Provider != nil {
+		provider, err := ParseProvider(*userInfo.Provider)
 		if err != nil {
-			return User{}, fmt.Errorf("hashpassword: %w", err)
+			return User{}, fmt.Errorf("parseprovider: %w", err)
 		}
-		user.PasswordHash = hashed
+		user.Provider = provider
+		user.ProviderID = userInfo.ProviderID
+	}
+
+	// external providers own the credential, a password is optional
+	if userInfo.Password != "" || user.Provider == Local {
+		policy.check(fieldErrs, userInfo.Password)
 	}
 
 	if err := fieldErrs.ToError(); err != nil {
 		return User{}, err
 	}
 
-	if userInfo.ProviderID != nil && userInfo.Provider != nil {
-		provider, err := ParseProvider(*userInfo.Provider)
+	if userInfo.Password != "" {
+		hashed, err := HashPassword([]byte(userInfo.Password), policy.HashParams())
 		if err != nil {
-			return User{}, fmt.Errorf("parseprovider: %w", err)
+			return User{}, fmt.Errorf("hashpassword: %w", err)
 		}
-		user.Provider = provider
-		user.ProviderID = userInfo.ProviderID
-	} else {
-		//used password and email signup
-		user.Provider = Local
+		user.PasswordHash = hashed
 	}
 
 	user.Email = email
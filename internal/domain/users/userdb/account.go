@@ -0,0 +1,284 @@
+package userdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// SoftDeleteUser starts the deletion grace period and returns its start.
+func (us *userdb) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE users
+		SET deleted_at = now(), updated_at = now()
+		WHERE id = $1 AND deleted_at IS NULL AND NOT is_deleted
+		RETURNING deleted_at
+	`
+	var deletedAt time.Time
+	err := conn.QueryRow(ctx, query, userID).Scan(&deletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, users.ErrUserNotFound
+		}
+		return time.Time{}, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return deletedAt, nil
+}
+
+// RestoreUser cancels a pending deletion. Anonymized accounts stay deleted.
+func (us *userdb) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE users
+		SET deleted_at = NULL, updated_at = now()
+		WHERE id = $1 AND deleted_at IS NOT NULL AND NOT is_deleted
+	`
+	res, err := conn.Exec(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return users.ErrUserNotFound
+	}
+	return nil
+}
+
+// AnonymizeUser overwrites the personal data of a soft deleted user and
+// drops everything that hangs off the account. The row itself is kept so
+// orders and tenants keep a valid owner. Unique columns get values derived
+// from the user ID.
+func (us *userdb) AnonymizeUser(ctx context.Context, userID uuid.UUID, passwordHash []byte) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE users
+		SET email         = 'deleted+' || id::text || '@deleted.invalid',
+		    first_name    = 'Deleted',
+		    last_name     = 'User',
+		    phone_number  = 'deleted-' || left(replace(id::text, '-', ''), 12),
+		    password_hash = $2,
+		    provider_id   = CASE WHEN provider = 'google' THEN 'deleted:' || id::text END,
+		    country       = NULL,
+		    is_verified   = false,
+		    is_deleted    = true,
+		    updated_at    = now()
+		WHERE id = $1 AND deleted_at IS NOT NULL AND NOT is_deleted
+	`
+	res, err := conn.Exec(ctx, query, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return users.ErrUserNotFound
+	}
+
+	for _, table := range []string{
+		"sessions", "tokens", "user_mfa", "mfa_recovery_codes",
+		"email_changes", "data_exports", "addresses", "password_history", "signin_reports",
+		"oauth_codes", "oauth_grants",
+	} {
+		if _, err := conn.Exec(ctx, `DELETE FROM `+table+` WHERE user_id = $1`, userID); err != nil {
+			return fmt.Errorf("%w: delete %s: %w", users.ErrDatabase, table, err)
+		}
+	}
+	return nil
+}
+
+func (us *userdb) ListOverduePurges(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id FROM users
+		WHERE deleted_at < $1 AND NOT is_deleted
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+	rows, err := conn.Query(ctx, query, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	ids, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return ids, nil
+}
+
+func (us *userdb) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]users.Session, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id, family_id, user_id, refresh_token, user_agent, client_ip,
+		       is_blocked, expires_at, consumed_at, created_at
+		FROM sessions
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	sessions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (users.Session, error) {
+		var s users.Session
+		err := row.Scan(
+			&s.ID,
+			&s.FamilyID,
+			&s.UserID,
+			&s.RefreshToken,
+			&s.UserAgent,
+			&s.ClientIP,
+			&s.IsBlocked,
+			&s.ExpiresAt,
+			&s.ConsumedAt,
+			&s.CreatedAt,
+		)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return sessions, nil
+}
+
+func (us *userdb) ListAddresses(ctx context.Context, userID uuid.UUID) ([]users.Address, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id, street, city, state, postal_code, country, address_type, is_default,
+		       COALESCE(created_at, now()), COALESCE(updated_at, now())
+		FROM addresses
+		WHERE user_id = $1
+		ORDER BY id
+	`
+	rows, err := conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	addrs, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (users.Address, error) {
+		var a users.Address
+		err := row.Scan(
+			&a.ID,
+			&a.Street,
+			&a.City,
+			&a.State,
+			&a.PostalCode,
+			&a.Country,
+			&a.AddressType,
+			&a.IsDefault,
+			&a.CreatedAt,
+			&a.UpdatedAt,
+		)
+		return a, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return addrs, nil
+}
+
+func (us *userdb) ListOwnedTenants(ctx context.Context, userID uuid.UUID) ([]users.OwnedTenant, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id, business_name, domain, subdomain,
+		       COALESCE(plan::text, ''), COALESCE(status::text, ''), COALESCE(created_at, now())
+		FROM tenants
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+	rows, err := conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	tenants, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (users.OwnedTenant, error) {
+		var t users.OwnedTenant
+		err := row.Scan(&t.ID, &t.BusinessName, &t.Domain, &t.Subdomain, &t.Plan, &t.Status, &t.CreatedAt)
+		return t, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return tenants, nil
+}
+
+// CreateDataExport refuses a new request while a recent one is still being
+// built, a failed job stops blocking after an hour.
+func (us *userdb) CreateDataExport(ctx context.Context, e *users.DataExport) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		INSERT INTO data_exports (id, user_id, status)
+		SELECT $1, $2, $3
+		WHERE NOT EXISTS (
+			SELECT 1 FROM data_exports
+			WHERE user_id = $2 AND status = $3 AND created_at > now() - interval '1 hour'
+		)
+		RETURNING created_at
+	`
+	err := conn.QueryRow(ctx, query, e.ID, e.UserID, e.Status).Scan(&e.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return users.ErrExportInProgress
+		}
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (us *userdb) GetDataExport(ctx context.Context, exportID uuid.UUID) (*users.DataExport, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id, user_id, status, archive, token_hash, expires_at, created_at, completed_at
+		FROM data_exports
+		WHERE id = $1
+	`
+	var e users.DataExport
+	err := conn.QueryRow(ctx, query, exportID).Scan(
+		&e.ID,
+		&e.UserID,
+		&e.Status,
+		&e.Archive,
+		&e.TokenHash,
+		&e.ExpiresAt,
+		&e.CreatedAt,
+		&e.CompletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrExportNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return &e, nil
+}
+
+func (us *userdb) CompleteDataExport(ctx context.Context, e *users.DataExport) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE data_exports
+		SET status = $2, archive = $3, token_hash = $4, expires_at = $5, completed_at = now()
+		WHERE id = $1
+	`
+	res, err := conn.Exec(ctx, query, e.ID, e.Status, e.Archive, e.TokenHash, e.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return users.ErrExportNotFound
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package userdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// CreateEmailChange replaces any pending request of the user, only the most
+// recent link stays valid.
+func (us *userdb) CreateEmailChange(ctx context.Context, ec *users.EmailChange) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		WITH cleared AS (
+			DELETE FROM email_changes WHERE user_id = $2
+		)
+		INSERT INTO email_changes (hash, user_id, new_email, expiry)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := conn.Exec(ctx, query, ec.TokenHash, ec.UserID, ec.NewEmail, ec.Expiry); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (us *userdb) ConsumeEmailChange(ctx context.Context, hash []byte) (*users.EmailChange, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		DELETE FROM email_changes
+		WHERE hash = $1
+		RETURNING hash, user_id, new_email, expiry
+	`
+	var ec users.EmailChange
+	err := conn.QueryRow(ctx, query, hash).Scan(&ec.TokenHash, &ec.UserID, &ec.NewEmail, &ec.Expiry)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrEmailChangeNotFound
+		}
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return &ec, nil
+}
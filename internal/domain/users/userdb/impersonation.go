@@ -0,0 +1,82 @@
+package userdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+)
+
+func (us *userdb) CreateImpersonation(ctx context.Context, imp *users.Impersonation) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		INSERT INTO impersonation_sessions (id, admin_id, user_id, reason, client_ip, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING started_at
+	`
+	err := conn.QueryRow(ctx, query,
+		imp.ID,
+		imp.AdminID,
+		imp.UserID,
+		imp.Reason,
+		imp.ClientIP,
+		imp.ExpiresAt,
+	).Scan(&imp.StartedAt)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+// EndImpersonation closes a live session started by adminID.
+func (us *userdb) EndImpersonation(ctx context.Context, sessionID, adminID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE impersonation_sessions
+		SET ended_at = now()
+		WHERE id = $1 AND admin_id = $2 AND ended_at IS NULL
+	`
+	res, err := conn.Exec(ctx, query, sessionID, adminID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if res.RowsAffected() == 0 {
+		return users.ErrImpersonationEnded
+	}
+	return nil
+}
+
+func (us *userdb) CreateImpersonatedRequest(ctx context.Context, req *users.ImpersonatedRequest) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		INSERT INTO impersonation_requests (session_id, method, path, req_id, client_ip)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id
+	`
+	err := conn.QueryRow(ctx, query,
+		req.SessionID,
+		req.Method,
+		req.Path,
+		req.ReqID,
+		req.ClientIP,
+	).Scan(&req.ID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (us *userdb) SetImpersonatedRequestStatus(ctx context.Context, id int64, status int) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `UPDATE impersonation_requests SET status = $2 WHERE id = $1`
+	if _, err := conn.Exec(ctx, query, id, status); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package userdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func (us *userdb) UpsertMFA(ctx context.Context, m *users.MFA) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	// an enabled configuration is never overwritten by a new enrollment
+	const query = `
+		INSERT INTO user_mfa (user_id, secret, is_enabled, last_used_step)
+		VALUES ($1, $2, false, 0)
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret,
+			last_used_step = 0,
+			updated_at = now()
+		WHERE NOT user_mfa.is_enabled
+		RETURNING created_at, updated_at
+	`
+	err := conn.QueryRow(ctx, query, m.UserID, m.Secret).Scan(&m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return users.ErrMFAAlreadyEnabled
+		}
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (us *userdb) GetMFA(ctx context.Context, userID uuid.UUID) (*users.MFA, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT user_id, secret, is_enabled, last_used_step, confirmed_at, created_at, updated_at
+		FROM user_mfa
+		WHERE user_id = $1
+	`
+	var m users.MFA
+	err := conn.QueryRow(ctx, query, userID).Scan(
+		&m.UserID,
+		&m.Secret,
+		&m.IsEnabled,
+		&m.LastUsedStep,
+		&m.ConfirmedAt,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrMFANotFound
+		}
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return &m, nil
+}
+
+func (us *userdb) EnableMFA(ctx context.Context, userID uuid.UUID, step int64) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE user_mfa
+		SET is_enabled = true,
+			last_used_step = $2,
+			confirmed_at = now(),
+			updated_at = now()
+		WHERE user_id = $1 AND NOT is_enabled
+	`
+	cmdTag, err := conn.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrMFANotFound
+	}
+	return nil
+}
+
+func (us *userdb) UpdateMFAStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE user_mfa
+		SET last_used_step = $2,
+			updated_at = now()
+		WHERE user_id = $1 AND last_used_step < $2
+	`
+	cmdTag, err := conn.Exec(ctx, query, userID, step)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrMFACodeReused
+	}
+	return nil
+}
+
+func (us *userdb) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	if _, err := conn.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	cmdTag, err := conn.Exec(ctx, `DELETE FROM user_mfa WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrMFANotFound
+	}
+	return nil
+}
+
+func (us *userdb) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	if _, err := conn.Exec(ctx, `DELETE FROM mfa_recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	const query = `
+		INSERT INTO mfa_recovery_codes (user_id, code_hash)
+		SELECT $1, unnest($2::bytea[])
+	`
+	if _, err := conn.Exec(ctx, query, userID, hashes); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+func (us *userdb) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		UPDATE mfa_recovery_codes
+		SET used_at = now()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`
+	cmdTag, err := conn.Exec(ctx, query, userID, hash)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrRecoveryCodeInvalid
+	}
+	return nil
+}
@@ -12,10 +12,10 @@ package mockdb
 import (
 	context "context"
 	reflect "reflect"
-
-	users "github.com/iamonah/merchcore/internal/domain/users"
+	time "time"
 
 	uuid "github.com/google/uuid"
+	users "github.com/iamonah/merchcore/internal/domain/users"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -43,20 +43,192 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
-// BlockSession mocks base method.
-func (m *MockUserRepository) BlockSession(ctx context.Context, token []byte) error {
+// AddPasswordHistory mocks base method.
+func (m *MockUserRepository) AddPasswordHistory(ctx context.Context, userID uuid.UUID, hash []byte, keep int) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BlockSession", ctx, token)
+	ret := m.ctrl.Call(m, "AddPasswordHistory", ctx, userID, hash, keep)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// AddPasswordHistory indicates an expected call of AddPasswordHistory.
+func (mr *MockUserRepositoryMockRecorder) AddPasswordHistory(ctx, userID, hash, keep any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPasswordHistory", reflect.TypeOf((*MockUserRepository)(nil).AddPasswordHistory), ctx, userID, hash, keep)
+}
+
+// AnonymizeUser mocks base method.
+func (m *MockUserRepository) AnonymizeUser(ctx context.Context, userID uuid.UUID, passwordHash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AnonymizeUser", ctx, userID, passwordHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AnonymizeUser indicates an expected call of AnonymizeUser.
+func (mr *MockUserRepositoryMockRecorder) AnonymizeUser(ctx, userID, passwordHash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AnonymizeUser", reflect.TypeOf((*MockUserRepository)(nil).AnonymizeUser), ctx, userID, passwordHash)
+}
+
+// BlockSession mocks base method.
+func (m *MockUserRepository) BlockSession(ctx context.Context, token []byte) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSession", ctx, token)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // BlockSession indicates an expected call of BlockSession.
 func (mr *MockUserRepositoryMockRecorder) BlockSession(ctx, token any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSession", reflect.TypeOf((*MockUserRepository)(nil).BlockSession), ctx, token)
 }
 
+// BlockSessionFamily mocks base method.
+func (m *MockUserRepository) BlockSessionFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockSessionFamily", ctx, userID, familyID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BlockSessionFamily indicates an expected call of BlockSessionFamily.
+func (mr *MockUserRepositoryMockRecorder) BlockSessionFamily(ctx, userID, familyID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockSessionFamily", reflect.TypeOf((*MockUserRepository)(nil).BlockSessionFamily), ctx, userID, familyID)
+}
+
+// BlockUserSessions mocks base method.
+func (m *MockUserRepository) BlockUserSessions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockUserSessions indicates an expected call of BlockUserSessions.
+func (mr *MockUserRepositoryMockRecorder) BlockUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockUserSessions", reflect.TypeOf((*MockUserRepository)(nil).BlockUserSessions), ctx, userID)
+}
+
+// CompleteDataExport mocks base method.
+func (m *MockUserRepository) CompleteDataExport(ctx context.Context, e *users.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteDataExport", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteDataExport indicates an expected call of CompleteDataExport.
+func (mr *MockUserRepositoryMockRecorder) CompleteDataExport(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteDataExport", reflect.TypeOf((*MockUserRepository)(nil).CompleteDataExport), ctx, e)
+}
+
+// ConsumeEmailChange mocks base method.
+func (m *MockUserRepository) ConsumeEmailChange(ctx context.Context, hash []byte) (*users.EmailChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeEmailChange", ctx, hash)
+	ret0, _ := ret[0].(*users.EmailChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeEmailChange indicates an expected call of ConsumeEmailChange.
+func (mr *MockUserRepositoryMockRecorder) ConsumeEmailChange(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeEmailChange", reflect.TypeOf((*MockUserRepository)(nil).ConsumeEmailChange), ctx, hash)
+}
+
+// ConsumeSession mocks base method.
+func (m *MockUserRepository) ConsumeSession(ctx context.Context, sessionID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSession", ctx, sessionID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ConsumeSession indicates an expected call of ConsumeSession.
+func (mr *MockUserRepositoryMockRecorder) ConsumeSession(ctx, sessionID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSession", reflect.TypeOf((*MockUserRepository)(nil).ConsumeSession), ctx, sessionID)
+}
+
+// ConsumeSignInReport mocks base method.
+func (m *MockUserRepository) ConsumeSignInReport(ctx context.Context, hash []byte) (*users.SignInReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ConsumeSignInReport", ctx, hash)
+	ret0, _ := ret[0].(*users.SignInReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ConsumeSignInReport indicates an expected call of ConsumeSignInReport.
+func (mr *MockUserRepositoryMockRecorder) ConsumeSignInReport(ctx, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConsumeSignInReport", reflect.TypeOf((*MockUserRepository)(nil).ConsumeSignInReport), ctx, hash)
+}
+
+// CreateDataExport mocks base method.
+func (m *MockUserRepository) CreateDataExport(ctx context.Context, e *users.DataExport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDataExport", ctx, e)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDataExport indicates an expected call of CreateDataExport.
+func (mr *MockUserRepositoryMockRecorder) CreateDataExport(ctx, e any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDataExport", reflect.TypeOf((*MockUserRepository)(nil).CreateDataExport), ctx, e)
+}
+
+// CreateEmailChange mocks base method.
+func (m *MockUserRepository) CreateEmailChange(ctx context.Context, ec *users.EmailChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateEmailChange", ctx, ec)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateEmailChange indicates an expected call of CreateEmailChange.
+func (mr *MockUserRepositoryMockRecorder) CreateEmailChange(ctx, ec any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateEmailChange", reflect.TypeOf((*MockUserRepository)(nil).CreateEmailChange), ctx, ec)
+}
+
+// CreateImpersonatedRequest mocks base method.
+func (m *MockUserRepository) CreateImpersonatedRequest(ctx context.Context, req *users.ImpersonatedRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonatedRequest", ctx, req)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateImpersonatedRequest indicates an expected call of CreateImpersonatedRequest.
+func (mr *MockUserRepositoryMockRecorder) CreateImpersonatedRequest(ctx, req any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonatedRequest", reflect.TypeOf((*MockUserRepository)(nil).CreateImpersonatedRequest), ctx, req)
+}
+
+// CreateImpersonation mocks base method.
+func (m *MockUserRepository) CreateImpersonation(ctx context.Context, imp *users.Impersonation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateImpersonation", ctx, imp)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateImpersonation indicates an expected call of CreateImpersonation.
+func (mr *MockUserRepositoryMockRecorder) CreateImpersonation(ctx, imp any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateImpersonation", reflect.TypeOf((*MockUserRepository)(nil).CreateImpersonation), ctx, imp)
+}
+
 // CreateSession mocks base method.
 func (m *MockUserRepository) CreateSession(ctx context.Context, s *users.Session) error {
 	m.ctrl.T.Helper()
@@ -71,6 +243,20 @@ func (mr *MockUserRepositoryMockRecorder) CreateSession(ctx, s any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSession", reflect.TypeOf((*MockUserRepository)(nil).CreateSession), ctx, s)
 }
 
+// CreateSignInReport mocks base method.
+func (m *MockUserRepository) CreateSignInReport(ctx context.Context, r *users.SignInReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateSignInReport", ctx, r)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateSignInReport indicates an expected call of CreateSignInReport.
+func (mr *MockUserRepositoryMockRecorder) CreateSignInReport(ctx, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateSignInReport", reflect.TypeOf((*MockUserRepository)(nil).CreateSignInReport), ctx, r)
+}
+
 // CreateToken mocks base method.
 func (m *MockUserRepository) CreateToken(ctx context.Context, otp *users.Token) error {
 	m.ctrl.T.Helper()
@@ -99,6 +285,20 @@ func (mr *MockUserRepositoryMockRecorder) CreateUser(arg0, arg1 any) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepository)(nil).CreateUser), arg0, arg1)
 }
 
+// DeleteMFA mocks base method.
+func (m *MockUserRepository) DeleteMFA(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMFA", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMFA indicates an expected call of DeleteMFA.
+func (mr *MockUserRepositoryMockRecorder) DeleteMFA(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMFA", reflect.TypeOf((*MockUserRepository)(nil).DeleteMFA), ctx, userID)
+}
+
 // DeleteToken mocks base method.
 func (m *MockUserRepository) DeleteToken(ctx context.Context, hash []byte, scope string) error {
 	m.ctrl.T.Helper()
@@ -113,51 +313,66 @@ func (mr *MockUserRepositoryMockRecorder) DeleteToken(ctx, hash, scope any) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteToken", reflect.TypeOf((*MockUserRepository)(nil).DeleteToken), ctx, hash, scope)
 }
 
-// FindUserByEmail mocks base method.
-func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string) (*users.User, error) {
+// EnableMFA mocks base method.
+func (m *MockUserRepository) EnableMFA(ctx context.Context, userID uuid.UUID, step int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUserByEmail", ctx, email)
-	ret0, _ := ret[0].(*users.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "EnableMFA", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// FindUserByEmail indicates an expected call of FindUserByEmail.
-func (mr *MockUserRepositoryMockRecorder) FindUserByEmail(ctx, email any) *gomock.Call {
+// EnableMFA indicates an expected call of EnableMFA.
+func (mr *MockUserRepositoryMockRecorder) EnableMFA(ctx, userID, step any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByEmail", reflect.TypeOf((*MockUserRepository)(nil).FindUserByEmail), ctx, email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnableMFA", reflect.TypeOf((*MockUserRepository)(nil).EnableMFA), ctx, userID, step)
 }
 
-func (m *MockUserRepository) FindUserByID(ctx context.Context, userID uuid.UUID) (*users.User, error) {
+// EndImpersonation mocks base method.
+func (m *MockUserRepository) EndImpersonation(ctx context.Context, sessionID, adminID uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUserByID", ctx, userID)
-	ret0, _ := ret[0].(*users.User)
+	ret := m.ctrl.Call(m, "EndImpersonation", ctx, sessionID, adminID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndImpersonation indicates an expected call of EndImpersonation.
+func (mr *MockUserRepositoryMockRecorder) EndImpersonation(ctx, sessionID, adminID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndImpersonation", reflect.TypeOf((*MockUserRepository)(nil).EndImpersonation), ctx, sessionID, adminID)
+}
+
+// GetDataExport mocks base method.
+func (m *MockUserRepository) GetDataExport(ctx context.Context, exportID uuid.UUID) (*users.DataExport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDataExport", ctx, exportID)
+	ret0, _ := ret[0].(*users.DataExport)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// FindUserByID indicates an expected call of FindUserByID.
-func (mr *MockUserRepositoryMockRecorder) FindUserByID(ctx, userID any) *gomock.Call {
+// GetDataExport indicates an expected call of GetDataExport.
+func (mr *MockUserRepositoryMockRecorder) GetDataExport(ctx, exportID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockUserRepository)(nil).FindUserByID), ctx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDataExport", reflect.TypeOf((*MockUserRepository)(nil).GetDataExport), ctx, exportID)
 }
 
-// FindUserPhoneNumber mocks base method.
-func (m *MockUserRepository) FindUserPhoneNumber(ctx context.Context, phoneNum string) error {
+// GetMFA mocks base method.
+func (m *MockUserRepository) GetMFA(ctx context.Context, userID uuid.UUID) (*users.MFA, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "FindUserPhoneNumber", ctx, phoneNum)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetMFA", ctx, userID)
+	ret0, _ := ret[0].(*users.MFA)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// FindUserPhoneNumber indicates an expected call of FindUserPhoneNumber.
-func (mr *MockUserRepositoryMockRecorder) FindUserPhoneNumber(ctx, phoneNum any) *gomock.Call {
+// GetMFA indicates an expected call of GetMFA.
+func (mr *MockUserRepositoryMockRecorder) GetMFA(ctx, userID any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserPhoneNumber", reflect.TypeOf((*MockUserRepository)(nil).FindUserPhoneNumber), ctx, phoneNum)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMFA", reflect.TypeOf((*MockUserRepository)(nil).GetMFA), ctx, userID)
 }
 
 // GetSession mocks base method.
-func (m *MockUserRepository) GetSession(ctx context.Context, sessionId uuid.UUID) (*users.Session, error) {
+func (m *MockUserRepository) GetSession(ctx context.Context, sessionId string) (*users.Session, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetSession", ctx, sessionId)
 	ret0, _ := ret[0].(*users.Session)
@@ -171,6 +386,51 @@ func (mr *MockUserRepositoryMockRecorder) GetSession(ctx, sessionId any) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSession", reflect.TypeOf((*MockUserRepository)(nil).GetSession), ctx, sessionId)
 }
 
+// GetUserByEmail mocks base method.
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByEmail", ctx, email)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByEmail indicates an expected call of GetUserByEmail.
+func (mr *MockUserRepositoryMockRecorder) GetUserByEmail(ctx, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByEmail", reflect.TypeOf((*MockUserRepository)(nil).GetUserByEmail), ctx, email)
+}
+
+// GetUserByID mocks base method.
+func (m *MockUserRepository) GetUserByID(ctx context.Context, userID uuid.UUID) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, userID)
+}
+
+// GetUserByProviderID mocks base method.
+func (m *MockUserRepository) GetUserByProviderID(ctx context.Context, providerID string) (*users.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserByProviderID", ctx, providerID)
+	ret0, _ := ret[0].(*users.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByProviderID indicates an expected call of GetUserByProviderID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByProviderID(ctx, providerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByProviderID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByProviderID), ctx, providerID)
+}
+
 // GetUserIDByToken mocks base method.
 func (m *MockUserRepository) GetUserIDByToken(ctx context.Context, hash []byte, scope string) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -186,6 +446,209 @@ func (mr *MockUserRepositoryMockRecorder) GetUserIDByToken(ctx, hash, scope any)
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserIDByToken", reflect.TypeOf((*MockUserRepository)(nil).GetUserIDByToken), ctx, hash, scope)
 }
 
+// GetUserPhoneNumber mocks base method.
+func (m *MockUserRepository) GetUserPhoneNumber(ctx context.Context, phoneNum string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPhoneNumber", ctx, phoneNum)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetUserPhoneNumber indicates an expected call of GetUserPhoneNumber.
+func (mr *MockUserRepositoryMockRecorder) GetUserPhoneNumber(ctx, phoneNum any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPhoneNumber", reflect.TypeOf((*MockUserRepository)(nil).GetUserPhoneNumber), ctx, phoneNum)
+}
+
+// LinkProviderID mocks base method.
+func (m *MockUserRepository) LinkProviderID(ctx context.Context, userID uuid.UUID, providerID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinkProviderID", ctx, userID, providerID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LinkProviderID indicates an expected call of LinkProviderID.
+func (mr *MockUserRepositoryMockRecorder) LinkProviderID(ctx, userID, providerID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinkProviderID", reflect.TypeOf((*MockUserRepository)(nil).LinkProviderID), ctx, userID, providerID)
+}
+
+// ListActiveSessions mocks base method.
+func (m *MockUserRepository) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]users.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListActiveSessions", ctx, userID)
+	ret0, _ := ret[0].([]users.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListActiveSessions indicates an expected call of ListActiveSessions.
+func (mr *MockUserRepositoryMockRecorder) ListActiveSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListActiveSessions", reflect.TypeOf((*MockUserRepository)(nil).ListActiveSessions), ctx, userID)
+}
+
+// ListAddresses mocks base method.
+func (m *MockUserRepository) ListAddresses(ctx context.Context, userID uuid.UUID) ([]users.Address, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAddresses", ctx, userID)
+	ret0, _ := ret[0].([]users.Address)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAddresses indicates an expected call of ListAddresses.
+func (mr *MockUserRepositoryMockRecorder) ListAddresses(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAddresses", reflect.TypeOf((*MockUserRepository)(nil).ListAddresses), ctx, userID)
+}
+
+// ListOverduePurges mocks base method.
+func (m *MockUserRepository) ListOverduePurges(ctx context.Context, deletedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOverduePurges", ctx, deletedBefore, limit)
+	ret0, _ := ret[0].([]uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOverduePurges indicates an expected call of ListOverduePurges.
+func (mr *MockUserRepositoryMockRecorder) ListOverduePurges(ctx, deletedBefore, limit any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOverduePurges", reflect.TypeOf((*MockUserRepository)(nil).ListOverduePurges), ctx, deletedBefore, limit)
+}
+
+// ListOwnedTenants mocks base method.
+func (m *MockUserRepository) ListOwnedTenants(ctx context.Context, userID uuid.UUID) ([]users.OwnedTenant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOwnedTenants", ctx, userID)
+	ret0, _ := ret[0].([]users.OwnedTenant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOwnedTenants indicates an expected call of ListOwnedTenants.
+func (mr *MockUserRepositoryMockRecorder) ListOwnedTenants(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOwnedTenants", reflect.TypeOf((*MockUserRepository)(nil).ListOwnedTenants), ctx, userID)
+}
+
+// ListPasswordHistory mocks base method.
+func (m *MockUserRepository) ListPasswordHistory(ctx context.Context, userID uuid.UUID, n int) ([][]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPasswordHistory", ctx, userID, n)
+	ret0, _ := ret[0].([][]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPasswordHistory indicates an expected call of ListPasswordHistory.
+func (mr *MockUserRepositoryMockRecorder) ListPasswordHistory(ctx, userID, n any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPasswordHistory", reflect.TypeOf((*MockUserRepository)(nil).ListPasswordHistory), ctx, userID, n)
+}
+
+// ListUserSessions mocks base method.
+func (m *MockUserRepository) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]users.Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUserSessions", ctx, userID)
+	ret0, _ := ret[0].([]users.Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserSessions indicates an expected call of ListUserSessions.
+func (mr *MockUserRepositoryMockRecorder) ListUserSessions(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserSessions", reflect.TypeOf((*MockUserRepository)(nil).ListUserSessions), ctx, userID)
+}
+
+// ReplaceRecoveryCodes mocks base method.
+func (m *MockUserRepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, hashes [][]byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceRecoveryCodes", ctx, userID, hashes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReplaceRecoveryCodes indicates an expected call of ReplaceRecoveryCodes.
+func (mr *MockUserRepositoryMockRecorder) ReplaceRecoveryCodes(ctx, userID, hashes any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceRecoveryCodes", reflect.TypeOf((*MockUserRepository)(nil).ReplaceRecoveryCodes), ctx, userID, hashes)
+}
+
+// RestoreUser mocks base method.
+func (m *MockUserRepository) RestoreUser(ctx context.Context, userID uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUser", ctx, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUser indicates an expected call of RestoreUser.
+func (mr *MockUserRepositoryMockRecorder) RestoreUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUser", reflect.TypeOf((*MockUserRepository)(nil).RestoreUser), ctx, userID)
+}
+
+// SetImpersonatedRequestStatus mocks base method.
+func (m *MockUserRepository) SetImpersonatedRequestStatus(ctx context.Context, id int64, status int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetImpersonatedRequestStatus", ctx, id, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetImpersonatedRequestStatus indicates an expected call of SetImpersonatedRequestStatus.
+func (mr *MockUserRepositoryMockRecorder) SetImpersonatedRequestStatus(ctx, id, status any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetImpersonatedRequestStatus", reflect.TypeOf((*MockUserRepository)(nil).SetImpersonatedRequestStatus), ctx, id, status)
+}
+
+// SoftDeleteUser mocks base method.
+func (m *MockUserRepository) SoftDeleteUser(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteUser", ctx, userID)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SoftDeleteUser indicates an expected call of SoftDeleteUser.
+func (mr *MockUserRepositoryMockRecorder) SoftDeleteUser(ctx, userID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteUser", reflect.TypeOf((*MockUserRepository)(nil).SoftDeleteUser), ctx, userID)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockUserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", ctx, userID, email)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockUserRepositoryMockRecorder) UpdateEmail(ctx, userID, email any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockUserRepository)(nil).UpdateEmail), ctx, userID, email)
+}
+
+// UpdateMFAStep mocks base method.
+func (m *MockUserRepository) UpdateMFAStep(ctx context.Context, userID uuid.UUID, step int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMFAStep", ctx, userID, step)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateMFAStep indicates an expected call of UpdateMFAStep.
+func (mr *MockUserRepositoryMockRecorder) UpdateMFAStep(ctx, userID, step any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMFAStep", reflect.TypeOf((*MockUserRepository)(nil).UpdateMFAStep), ctx, userID, step)
+}
+
 // UpdatePassword mocks base method.
 func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash []byte) error {
 	m.ctrl.T.Helper()
@@ -214,6 +677,34 @@ func (mr *MockUserRepositoryMockRecorder) UpdateUser(ctx, user any) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockUserRepository)(nil).UpdateUser), ctx, user)
 }
 
+// UpsertMFA mocks base method.
+func (m_2 *MockUserRepository) UpsertMFA(ctx context.Context, m *users.MFA) error {
+	m_2.ctrl.T.Helper()
+	ret := m_2.ctrl.Call(m_2, "UpsertMFA", ctx, m)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertMFA indicates an expected call of UpsertMFA.
+func (mr *MockUserRepositoryMockRecorder) UpsertMFA(ctx, m any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertMFA", reflect.TypeOf((*MockUserRepository)(nil).UpsertMFA), ctx, m)
+}
+
+// UseRecoveryCode mocks base method.
+func (m *MockUserRepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, hash []byte) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseRecoveryCode", ctx, userID, hash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UseRecoveryCode indicates an expected call of UseRecoveryCode.
+func (mr *MockUserRepositoryMockRecorder) UseRecoveryCode(ctx, userID, hash any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseRecoveryCode", reflect.TypeOf((*MockUserRepository)(nil).UseRecoveryCode), ctx, userID, hash)
+}
+
 // VerifyUser mocks base method.
 func (m *MockUserRepository) VerifyUser(ctx context.Context, userID uuid.UUID) error {
 	m.ctrl.T.Helper()
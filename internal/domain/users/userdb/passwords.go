@@ -0,0 +1,58 @@
+package userdb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+// ListPasswordHistory returns the last n password hashes of the user,
+// newest first.
+func (us *userdb) ListPasswordHistory(ctx context.Context, userID uuid.UUID, n int) ([][]byte, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT password_hash
+		FROM password_history
+		WHERE user_id = $1
+		ORDER BY created_at DESC, id DESC
+		LIMIT $2
+	`
+	rows, err := conn.Query(ctx, query, userID, n)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	hashes, err := pgx.CollectRows(rows, pgx.RowTo[[]byte])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return hashes, nil
+}
+
+// AddPasswordHistory stores hash and drops all but the newest keep entries.
+func (us *userdb) AddPasswordHistory(ctx context.Context, userID uuid.UUID, hash []byte, keep int) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const insert = `INSERT INTO password_history (user_id, password_hash) VALUES ($1, $2)`
+	if _, err := conn.Exec(ctx, insert, userID, hash); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	const trim = `
+		DELETE FROM password_history
+		WHERE user_id = $1 AND id NOT IN (
+			SELECT id FROM password_history
+			WHERE user_id = $1
+			ORDER BY created_at DESC, id DESC
+			LIMIT $2
+		)
+	`
+	if _, err := conn.Exec(ctx, trim, userID, keep); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
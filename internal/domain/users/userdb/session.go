@@ -15,8 +15,8 @@ import (
 
 func (us *userdb) CreateSession(ctx context.Context, s *users.Session) error {
 	query := `
-		INSERT INTO sessions (id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6,$7)
+		INSERT INTO sessions (id, family_id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
 	conn := database.GetTXFromContext(ctx, us.conn)
 
@@ -24,6 +24,7 @@ func (us *userdb) CreateSession(ctx context.Context, s *users.Session) error {
 		ctx,
 		query,
 		s.ID,
+		s.FamilyID,
 		s.UserID,
 		s.RefreshToken,
 		s.UserAgent,
@@ -97,31 +98,142 @@ func (us *userdb) DeleteToken(ctx context.Context, hash []byte, scope string) er
 	return nil
 }
 
-func (us *userdb) BlockSession(ctx context.Context, token []byte) error {
+func (us *userdb) BlockSession(ctx context.Context, token []byte) (uuid.UUID, error) {
 	conn := database.GetTXFromContext(ctx, us.conn)
 
 	const q = `
 		UPDATE sessions
 		SET is_blocked = true
 		WHERE refresh_token = $1 AND NOT is_blocked
+		RETURNING family_id
 	`
 
-	res, err := conn.Exec(ctx, q, token)
+	var familyID uuid.UUID
+	err := conn.QueryRow(ctx, q, token).Scan(&familyID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, users.ErrSessionNotFound
+		}
+		return uuid.Nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	return familyID, nil
+}
+
+// ConsumeSession marks a refresh session as used. Only the first caller
+// succeeds, a second attempt with the same token reports ErrSessionConsumed.
+func (us *userdb) ConsumeSession(ctx context.Context, sessionID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const q = `
+		UPDATE sessions
+		SET consumed_at = now()
+		WHERE id = $1 AND consumed_at IS NULL AND NOT is_blocked
+	`
+	res, err := conn.Exec(ctx, q, sessionID)
 	if err != nil {
 		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
 	}
 
 	if res.RowsAffected() == 0 {
-		return users.ErrSessionNotFound
+		return users.ErrSessionConsumed
+	}
+	return nil
+}
+
+func (us *userdb) BlockSessionFamily(ctx context.Context, userID, familyID uuid.UUID) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const q = `
+		UPDATE sessions
+		SET is_blocked = true
+		WHERE user_id = $1 AND family_id = $2 AND NOT is_blocked
+	`
+	res, err := conn.Exec(ctx, q, userID, familyID)
+	if err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
 	}
 
+	if res.RowsAffected() == 0 {
+		return users.ErrSessionNotFound
+	}
 	return nil
 }
+
+// BlockUserSessions blocks every session of the user and returns the
+// distinct families that were still active.
+func (us *userdb) BlockUserSessions(ctx context.Context, userID uuid.UUID) ([]uuid.UUID, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const q = `
+		WITH blocked AS (
+			UPDATE sessions
+			SET is_blocked = true
+			WHERE user_id = $1 AND NOT is_blocked
+			RETURNING family_id
+		)
+		SELECT DISTINCT family_id FROM blocked
+	`
+	rows, err := conn.Query(ctx, q, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	families, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return families, nil
+}
+
+// ListActiveSessions returns the live head of each session family: the
+// latest rotation that is neither consumed, blocked nor expired.
+func (us *userdb) ListActiveSessions(ctx context.Context, userID uuid.UUID) ([]users.Session, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		SELECT id, family_id, user_id, refresh_token, user_agent, client_ip,
+		       is_blocked, expires_at, consumed_at, created_at
+		FROM sessions
+		WHERE user_id = $1
+		  AND NOT is_blocked
+		  AND consumed_at IS NULL
+		  AND expires_at > now()
+		ORDER BY created_at DESC
+	`
+	rows, err := conn.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+
+	sessions, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (users.Session, error) {
+		var s users.Session
+		err := row.Scan(
+			&s.ID,
+			&s.FamilyID,
+			&s.UserID,
+			&s.RefreshToken,
+			&s.UserAgent,
+			&s.ClientIP,
+			&s.IsBlocked,
+			&s.ExpiresAt,
+			&s.ConsumedAt,
+			&s.CreatedAt,
+		)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return sessions, nil
+}
+
 func (us *userdb) GetSession(ctx context.Context, sessionID string) (*users.Session, error) {
 	conn := database.GetTXFromContext(ctx, us.conn)
 
 	const query = `
-        SELECT id, user_id, refresh_token, user_agent, client_ip, is_blocked, expires_at, created_at
+        SELECT id, family_id, user_id, refresh_token, user_agent, client_ip,
+               is_blocked, expires_at, consumed_at, created_at
         FROM sessions
         WHERE id = $1
     `
@@ -129,12 +241,14 @@ func (us *userdb) GetSession(ctx context.Context, sessionID string) (*users.Sess
 	var s users.Session
 	err := conn.QueryRow(ctx, query, sessionID).Scan(
 		&s.ID,
+		&s.FamilyID,
 		&s.UserID,
 		&s.RefreshToken,
 		&s.UserAgent,
 		&s.ClientIP,
 		&s.IsBlocked,
 		&s.ExpiresAt,
+		&s.ConsumedAt,
 		&s.CreatedAt,
 	)
 	if err != nil {
@@ -0,0 +1,44 @@
+package userdb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/infra/database"
+	"github.com/jackc/pgx/v5"
+)
+
+func (us *userdb) CreateSignInReport(ctx context.Context, r *users.SignInReport) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		INSERT INTO signin_reports (hash, user_id, family_id, expiry)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := conn.Exec(ctx, query, r.TokenHash, r.UserID, r.FamilyID, r.Expiry); err != nil {
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return nil
+}
+
+// ConsumeSignInReport deletes the report so its link works once.
+func (us *userdb) ConsumeSignInReport(ctx context.Context, hash []byte) (*users.SignInReport, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	const query = `
+		DELETE FROM signin_reports
+		WHERE hash = $1
+		RETURNING hash, user_id, family_id, expiry
+	`
+	var r users.SignInReport
+	err := conn.QueryRow(ctx, query, hash).Scan(&r.TokenHash, &r.UserID, &r.FamilyID, &r.Expiry)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, users.ErrSignInReportInvalid
+		}
+		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
+	}
+	return &r, nil
+}
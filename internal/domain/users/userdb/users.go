@@ -32,8 +32,8 @@ func (us *userdb) CreateUser(ctx context.Context, usr *users.User) error {
 	query := `
 		INSERT INTO users (id, email, first_name, last_name,
 			password_hash, provider_id, phone_number,
-			provider, country, number_of_store, is_store_created, role) 
-			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+			provider, country, number_of_store, is_store_created, role, is_verified)
+			VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
 		RETURNING created_at, updated_at
 	`
 
@@ -51,6 +51,7 @@ func (us *userdb) CreateUser(ctx context.Context, usr *users.User) error {
 		usr.NumOfStore,
 		usr.IsStoreCreated,
 		usr.Role.String(),
+		usr.IsVerified,
 	).Scan(&usr.CreatedAt, &usr.UpdatedAT)
 
 	if err != nil {
@@ -79,72 +80,47 @@ func (us *userdb) GetUserByEmail(ctx context.Context, emailValue string) (*users
 	conn := database.GetTXFromContext(ctx, us.conn)
 
 	query := `
-		SELECT id, email, first_name, last_name, password_hash,
-			provider_id, phone_number, provider, country,
-			created_at, updated_at, is_verified, deleted_at,
-			role, is_store_created, number_of_store AS num_of_store
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE email = $1;        
+		WHERE email = $1;
     `
+	return scanUser(conn.QueryRow(ctx, query, emailValue))
+}
 
-	var (
-		emailStr string
-		phoneNum string
-		country  string
-		roleStr  string
-		provider string
-		u        users.User
-	)
-	err := conn.QueryRow(ctx, query, emailValue).Scan(
-		&u.UserID,
-		&emailStr,
-		&u.FirstName,
-		&u.LastName,
-		&u.PasswordHash,
-		&u.ProviderID,
-		&phoneNum,
-		&provider,
-		&country,
-		&u.CreatedAt,
-		&u.UpdatedAT,
-		&u.IsVerified,
-		&u.DeletedAt,
-		&roleStr,
-		&u.IsStoreCreated,
-		&u.NumOfStore,
-	)
-
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, users.ErrUserNotFound
-		}
-		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
-	}
+func (us *userdb) GetUserByProviderID(ctx context.Context, providerID string) (*users.User, error) {
+	conn := database.GetTXFromContext(ctx, us.conn)
 
-	email, err := mail.ParseAddress(emailStr)
-	if err != nil {
-		return nil, fmt.Errorf("%w invalid email %q: %w", users.ErrDatabase, emailStr, err)
-	}
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE provider_id = $1;
+    `
+	return scanUser(conn.QueryRow(ctx, query, providerID))
+}
 
-	contact := contact.NewContact(phoneNum, country)
-	if err := contact.ValidateContact(); err != nil {
-		return nil, fmt.Errorf("%w invalid contact %q,%q: %w", users.ErrDatabase, phoneNum, country, err)
-	}
+func (us *userdb) LinkProviderID(ctx context.Context, userID uuid.UUID, providerID string) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
 
-	u.Role, err = role.Parse(roleStr)
+	query := `
+		UPDATE users
+		SET provider_id = $1,
+			is_verified = TRUE,
+			updated_at = now()
+		WHERE id = $2
+	`
+	cmdTag, err := conn.Exec(ctx, query, providerID, userID)
 	if err != nil {
-		return nil, fmt.Errorf("%w invalid role %q: %w", users.ErrDatabase, roleStr, err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.ConstraintName == "users_provider_id_uq" {
+			return users.ErrProviderIDExists
+		}
+		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
 	}
 
-	u.Provider, err = users.ParseProvider(provider)
-	if err != nil {
-		return nil, fmt.Errorf("%w invalid provider %q: %w", users.ErrDatabase, provider, err)
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrUserNotFound
 	}
-
-	u.Email = email
-	u.Contact = contact
-	return &u, nil
-
+	return nil
 }
 
 func (us *userdb) GetUserPhoneNumber(ctx context.Context, phone string) error {
@@ -162,7 +138,8 @@ func (us *userdb) GetUserPhoneNumber(ctx context.Context, phone string) error {
 	return users.ErrPhoneNumberExists
 }
 
-// Todo: still in works
+// UpdateUser writes the user back only if updated_at still holds the value
+// read earlier, and refreshes it on success.
 func (us *userdb) UpdateUser(ctx context.Context, usr *users.User) error {
 	conn := database.GetTXFromContext(ctx, us.conn)
 	query := `
@@ -180,9 +157,10 @@ func (us *userdb) UpdateUser(ctx context.Context, usr *users.User) error {
 			number_of_store = $10,
 			is_store_created = $11,
 			updated_at = now()
-		WHERE id = $12
+		WHERE id = $12 AND updated_at = $13
+		RETURNING updated_at
 	`
-	_, err := conn.Exec(ctx,
+	err := conn.QueryRow(ctx,
 		query,
 		usr.Email.Address,
 		usr.FirstName,
@@ -196,57 +174,90 @@ func (us *userdb) UpdateUser(ctx context.Context, usr *users.User) error {
 		usr.NumOfStore,
 		usr.IsStoreCreated,
 		usr.UserID,
-	)
+		usr.UpdatedAT,
+	).Scan(&usr.UpdatedAT)
 
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) {
-			switch pgErr.ConstraintName {
-			case "users_email_uq":
-				return users.ErrEmailAlreadyExists
-			case "users_user_id_uq":
-				return users.ErrUserIDConflict
-			case "users_provider_id_uq":
-				return users.ErrProviderIDExists
-			case "users_phone_number_uq":
-				return users.ErrPhoneNumberExists
-			case "provider_fields_chk":
-				return users.ErrProviderFieldsCheck
-			default:
-				return fmt.Errorf("unhandled db constraint: %s (%s)", pgErr.ConstraintName, pgErr.Message)
-			}
+		if errors.Is(err, pgx.ErrNoRows) {
+			return users.ErrUpdateConflict
 		}
-		return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+		return userConstraintErr(err)
 	}
-	if errors.Is(err, pgx.ErrNoRows) {
-		return users.ErrUserNotFound
+
+	return nil
+}
+
+func (us *userdb) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	conn := database.GetTXFromContext(ctx, us.conn)
+
+	query := `
+		UPDATE users
+		SET email = $1,
+			updated_at = now()
+		WHERE id = $2
+	`
+	cmdTag, err := conn.Exec(ctx, query, email, userID)
+	if err != nil {
+		return userConstraintErr(err)
 	}
 
+	if cmdTag.RowsAffected() == 0 {
+		return users.ErrUserNotFound
+	}
 	return nil
 }
 
+func userConstraintErr(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.ConstraintName {
+		case "users_email_uq":
+			return users.ErrEmailAlreadyExists
+		case "users_user_id_uq":
+			return users.ErrUserIDConflict
+		case "users_provider_id_uq":
+			return users.ErrProviderIDExists
+		case "users_phone_number_uq":
+			return users.ErrPhoneNumberExists
+		case "provider_fields_chk":
+			return users.ErrProviderFieldsCheck
+		default:
+			return fmt.Errorf("unhandled db constraint: %s (%s)", pgErr.ConstraintName, pgErr.Message)
+		}
+	}
+	return fmt.Errorf("%w: %w", users.ErrDatabase, err)
+}
+
 func (us *userdb) GetUserByID(ctx context.Context, userID uuid.UUID) (*users.User, error) {
 	conn := database.GetTXFromContext(ctx, us.conn)
 
 	query := `
-		SELECT id, email, first_name, last_name, password_hash,
-			   provider_id, phone_number, provider, country,
-			   created_at, updated_at, is_verified, deleted_at,
-			   role, is_store_created, number_of_store AS num_of_store
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1;
 	`
+	return scanUser(conn.QueryRow(ctx, query, userID))
+}
 
+const userColumns = `id, email, first_name, last_name, password_hash,
+			provider_id, phone_number, provider, country,
+			created_at, updated_at, is_verified, deleted_at,
+			role, is_store_created, number_of_store AS num_of_store, is_deleted`
+
+// scanUser reads a row selected with userColumns. An anonymized user is
+// reported as not found, its placeholder contact details do not validate.
+func scanUser(row pgx.Row) (*users.User, error) {
 	var (
-		emailStr string
-		phoneNum string
-		country  string
-		roleStr  string
-		u        users.User
-		provider string
+		emailStr   string
+		phoneNum   string
+		country    *string
+		roleStr    string
+		provider   string
+		anonymized bool
+		u          users.User
 	)
 
-	err := conn.QueryRow(ctx, query, userID).Scan(
+	err := row.Scan(
 		&u.UserID,
 		&emailStr,
 		&u.FirstName,
@@ -263,14 +274,17 @@ func (us *userdb) GetUserByID(ctx context.Context, userID uuid.UUID) (*users.Use
 		&roleStr,
 		&u.IsStoreCreated,
 		&u.NumOfStore,
+		&anonymized,
 	)
-
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, users.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("%w: %w", users.ErrDatabase, err)
 	}
+	if anonymized {
+		return nil, users.ErrUserNotFound
+	}
 
 	email, err := mail.ParseAddress(emailStr)
 	if err != nil {
@@ -278,9 +292,13 @@ func (us *userdb) GetUserByID(ctx context.Context, userID uuid.UUID) (*users.Use
 	}
 	u.Email = email
 
-	contact := contact.NewContact(phoneNum, country)
+	var region string
+	if country != nil {
+		region = *country
+	}
+	contact := contact.NewContact(phoneNum, region)
 	if err := contact.ValidateContact(); err != nil {
-		return nil, fmt.Errorf("%w: invalid contact %s,%s: %w", users.ErrDatabase, phoneNum, country, err)
+		return nil, fmt.Errorf("%w: invalid contact %s,%s: %w", users.ErrDatabase, phoneNum, region, err)
 	}
 	u.Contact = contact
 
@@ -18,6 +18,8 @@ type Cache interface {
 	Set(ctx context.Context, key string, value any, ttl time.Duration) error
 	Get(ctx context.Context, key string, dest any) error
 	Delete(ctx context.Context, key string) error
+	Incr(ctx context.Context, key string, window time.Duration) (int64, error)
+	TTL(ctx context.Context, key string) (time.Duration, error)
 	Close() error
 }
 
@@ -67,6 +69,57 @@ func (rc *rediscache) Delete(ctx context.Context, key string) error {
 	return nil
 }
 
+// Incr increments a plain integer counter. The window starts with the first
+// increment and is not extended by later ones.
+func (rc *rediscache) Incr(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := rc.client.TxPipeline()
+	incr := pipe.Incr(ctx, key)
+	pipe.ExpireNX(ctx, key, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("redis incr: %w", err)
+	}
+	return incr.Val(), nil
+}
+
+// TTL returns the remaining lifetime of key, zero when it does not exist.
+func (rc *rediscache) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := rc.client.TTL(ctx, key).Result()
+	if err != nil {
+		return 0, fmt.Errorf("redis ttl: %w", err)
+	}
+	if ttl < 0 {
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// dropPersistent deletes a key only while it has no expiry, so a key
+// rewritten with a TTL between the scan and the delete is kept.
+var dropPersistent = redis.NewScript(`
+if redis.call("TTL", KEYS[1]) == -1 then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// DeleteStale scans the keys matching pattern, count at a time, and deletes
+// those without a TTL. It returns how many it deleted.
+func (rc *rediscache) DeleteStale(ctx context.Context, pattern string, count int64) (int64, error) {
+	var deleted int64
+	iter := rc.client.Scan(ctx, 0, pattern, count).Iterator()
+	for iter.Next(ctx) {
+		n, err := dropPersistent.Run(ctx, rc.client, []string{iter.Val()}).Int64()
+		if err != nil {
+			return deleted, fmt.Errorf("redis del stale: %w", err)
+		}
+		deleted += n
+	}
+	if err := iter.Err(); err != nil {
+		return deleted, fmt.Errorf("redis scan: %w", err)
+	}
+	return deleted, nil
+}
+
 func (rc *rediscache) Close() error {
 	return rc.client.Close()
 }
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id         UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret          BYTEA NOT NULL,
+    is_enabled      BOOLEAN NOT NULL DEFAULT false,
+    last_used_step  BIGINT NOT NULL DEFAULT 0,
+    confirmed_at    TIMESTAMPTZ,
+    created_at      TIMESTAMPTZ DEFAULT now(),
+    updated_at      TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash   BYTEA NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ DEFAULT now(),
+
+    CONSTRAINT mfa_recovery_codes_uq UNIQUE (user_id, code_hash)
+);
+
+CREATE INDEX IF NOT EXISTS mfa_recovery_codes_user_id_idx ON mfa_recovery_codes(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS mfa_recovery_codes_user_id_idx;
+DROP TABLE IF EXISTS mfa_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
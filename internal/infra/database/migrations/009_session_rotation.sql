@@ -0,0 +1,16 @@
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS family_id UUID;
+ALTER TABLE sessions ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMPTZ;
+
+-- existing sessions each start their own family
+UPDATE sessions SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE sessions ALTER COLUMN family_id SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS sessions_family_id_idx ON sessions(family_id);
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS sessions_user_id_idx;
+DROP INDEX IF EXISTS sessions_family_id_idx;
+ALTER TABLE sessions DROP COLUMN IF EXISTS consumed_at;
+ALTER TABLE sessions DROP COLUMN IF EXISTS family_id;
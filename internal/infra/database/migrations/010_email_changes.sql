@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS email_changes (
+    hash        BYTEA PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    new_email   citext NOT NULL,
+    expiry      TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS email_changes_user_id_idx ON email_changes(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS email_changes_user_id_idx;
+DROP TABLE IF EXISTS email_changes;
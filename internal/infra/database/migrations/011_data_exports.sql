@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS data_exports (
+    id            UUID PRIMARY KEY,
+    user_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'ready')),
+    archive       BYTEA,
+    token_hash    BYTEA,
+    expires_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    completed_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_user_id_idx ON data_exports(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS data_exports_user_id_idx;
+DROP TABLE IF EXISTS data_exports;
@@ -0,0 +1,39 @@
+CREATE TABLE IF NOT EXISTS tenant_members (
+    tenant_id   UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role        TEXT NOT NULL CHECK (role IN ('store_admin', 'staff')),
+    invited_by  UUID REFERENCES users(id) ON DELETE SET NULL,
+    joined_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT tenant_members_pk PRIMARY KEY (tenant_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS tenant_members_user_id_idx ON tenant_members(user_id);
+
+CREATE TABLE IF NOT EXISTS tenant_invitations (
+    id            UUID PRIMARY KEY,
+    tenant_id     UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    email         citext NOT NULL,
+    role          TEXT NOT NULL CHECK (role IN ('store_admin', 'staff')),
+    token_hash    BYTEA NOT NULL,
+    invited_by    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status        TEXT NOT NULL DEFAULT 'pending'
+                  CHECK (status IN ('pending', 'accepted', 'rejected', 'revoked')),
+    expires_at    TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+    responded_at  TIMESTAMPTZ,
+
+    CONSTRAINT tenant_invitations_token_uq UNIQUE (token_hash)
+);
+
+-- one open invitation per address and store, expired ones are replaced
+CREATE UNIQUE INDEX IF NOT EXISTS tenant_invitations_pending_uq
+    ON tenant_invitations(tenant_id, email) WHERE status = 'pending';
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS tenant_invitations_pending_uq;
+DROP TABLE IF EXISTS tenant_invitations;
+DROP INDEX IF EXISTS tenant_members_user_id_idx;
+DROP TABLE IF EXISTS tenant_members;
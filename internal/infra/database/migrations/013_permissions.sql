@@ -0,0 +1,31 @@
+-- role_type predates the team roles, bring it in line with the roles table
+ALTER TYPE role_type ADD VALUE IF NOT EXISTS 'store_admin';
+ALTER TYPE role_type ADD VALUE IF NOT EXISTS 'staff';
+
+INSERT INTO roles (name, description) VALUES
+('staff', 'Store team member with day to day access')
+ON CONFLICT (name) DO NOTHING;
+
+-- custom roles a store defines for its team, permissions come from the
+-- catalog in internal/domain/types/permission
+CREATE TABLE IF NOT EXISTS tenant_roles (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name         TEXT NOT NULL,
+    description  TEXT NOT NULL DEFAULT '',
+    permissions  TEXT[] NOT NULL DEFAULT '{}',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT tenant_roles_name_uq UNIQUE (tenant_id, name)
+);
+
+ALTER TABLE tenant_members
+    ADD COLUMN IF NOT EXISTS custom_role_id UUID REFERENCES tenant_roles(id) ON DELETE SET NULL;
+
+---- create above / drop below ----
+
+ALTER TABLE tenant_members DROP COLUMN IF EXISTS custom_role_id;
+DROP TABLE IF EXISTS tenant_roles;
+DELETE FROM roles WHERE name = 'staff';
+-- enum values cannot be dropped, store_admin and staff stay on role_type
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS tenant_api_keys (
+    id            UUID PRIMARY KEY,
+    tenant_id     UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    name          TEXT NOT NULL,
+    prefix        TEXT NOT NULL,
+    key_hash      BYTEA NOT NULL,
+    scopes        TEXT[] NOT NULL DEFAULT '{}',
+    created_by    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at    TIMESTAMPTZ,
+    last_used_at  TIMESTAMPTZ,
+    revoked_at    TIMESTAMPTZ,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT tenant_api_keys_prefix_uq UNIQUE (prefix)
+);
+
+CREATE INDEX IF NOT EXISTS tenant_api_keys_tenant_id_idx ON tenant_api_keys(tenant_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS tenant_api_keys_tenant_id_idx;
+DROP TABLE IF EXISTS tenant_api_keys;
@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id          UUID PRIMARY KEY,
+    admin_id    UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason      TEXT NOT NULL,
+    client_ip   TEXT NOT NULL DEFAULT '',
+    started_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at  TIMESTAMPTZ NOT NULL,
+    ended_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS impersonation_sessions_admin_id_idx ON impersonation_sessions(admin_id);
+CREATE INDEX IF NOT EXISTS impersonation_sessions_user_id_idx ON impersonation_sessions(user_id);
+
+-- one row per request made with an impersonation token, written before the
+-- request runs
+CREATE TABLE IF NOT EXISTS impersonation_requests (
+    id          BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    session_id  UUID NOT NULL REFERENCES impersonation_sessions(id) ON DELETE CASCADE,
+    method      TEXT NOT NULL,
+    path        TEXT NOT NULL,
+    req_id      TEXT NOT NULL,
+    client_ip   TEXT NOT NULL DEFAULT '',
+    status      INT,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS impersonation_requests_session_id_idx ON impersonation_requests(session_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS impersonation_requests_session_id_idx;
+DROP TABLE IF EXISTS impersonation_requests;
+DROP INDEX IF EXISTS impersonation_sessions_user_id_idx;
+DROP INDEX IF EXISTS impersonation_sessions_admin_id_idx;
+DROP TABLE IF EXISTS impersonation_sessions;
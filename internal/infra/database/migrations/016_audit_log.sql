@@ -0,0 +1,28 @@
+-- rows outlive the tenants and users they mention, so no foreign keys
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id               UUID PRIMARY KEY,
+    tenant_id        UUID,
+    actor_kind       TEXT NOT NULL CHECK (actor_kind IN ('user', 'api_key', 'system')),
+    actor_id         UUID,
+    impersonator_id  UUID,
+    api_key_id       UUID,
+    action           TEXT NOT NULL,
+    target_type      TEXT NOT NULL,
+    target_id        TEXT NOT NULL DEFAULT '',
+    before           JSONB,
+    after            JSONB,
+    client_ip        TEXT NOT NULL DEFAULT '',
+    req_id           TEXT NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS audit_logs_tenant_created_idx ON audit_logs(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_logs_actor_created_idx ON audit_logs(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS audit_logs_created_idx ON audit_logs(created_at DESC);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS audit_logs_created_idx;
+DROP INDEX IF EXISTS audit_logs_actor_created_idx;
+DROP INDEX IF EXISTS audit_logs_tenant_created_idx;
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,14 @@
+-- previous password hashes, trimmed to the configured history length
+CREATE TABLE IF NOT EXISTS password_history (
+    id             BIGINT GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+    user_id        UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash  BYTEA NOT NULL,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS password_history_user_created_idx ON password_history(user_id, created_at DESC);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS password_history_user_created_idx;
+DROP TABLE IF EXISTS password_history;
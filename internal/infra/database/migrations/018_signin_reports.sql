@@ -0,0 +1,15 @@
+-- "this wasn't me" links sent with new sign-in alerts
+CREATE TABLE IF NOT EXISTS signin_reports (
+    hash        BYTEA PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id   UUID NOT NULL,
+    expiry      TIMESTAMPTZ NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS signin_reports_user_id_idx ON signin_reports(user_id);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS signin_reports_user_id_idx;
+DROP TABLE IF EXISTS signin_reports;
@@ -0,0 +1,67 @@
+-- apps registered by outside developers, public clients have no secret
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id             UUID PRIMARY KEY,
+    owner_id       UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name           TEXT NOT NULL,
+    redirect_uris  TEXT[] NOT NULL,
+    scopes         TEXT[] NOT NULL DEFAULT '{}',
+    secret_hash    BYTEA,
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at     TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS oauth_clients_owner_id_idx ON oauth_clients(owner_id);
+
+CREATE TABLE IF NOT EXISTS oauth_codes (
+    code_hash       BYTEA PRIMARY KEY,
+    client_id       UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    user_id         UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    tenant_id       UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    redirect_uri    TEXT NOT NULL,
+    scopes          TEXT[] NOT NULL DEFAULT '{}',
+    code_challenge  TEXT NOT NULL,
+    expiry          TIMESTAMPTZ NOT NULL,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- an app installed into a store, its id is the session of its access tokens
+CREATE TABLE IF NOT EXISTS oauth_grants (
+    id          UUID PRIMARY KEY,
+    client_id   UUID NOT NULL REFERENCES oauth_clients(id) ON DELETE CASCADE,
+    tenant_id   UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    scopes      TEXT[] NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    revoked_at  TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS oauth_grants_tenant_id_idx ON oauth_grants(tenant_id);
+CREATE INDEX IF NOT EXISTS oauth_grants_client_id_idx ON oauth_grants(client_id);
+
+CREATE TABLE IF NOT EXISTS oauth_refresh_tokens (
+    token_hash  BYTEA PRIMARY KEY,
+    grant_id    UUID NOT NULL REFERENCES oauth_grants(id) ON DELETE CASCADE,
+    expiry      TIMESTAMPTZ NOT NULL,
+    used_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS oauth_refresh_tokens_grant_id_idx ON oauth_refresh_tokens(grant_id);
+
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_actor_kind_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_actor_kind_check
+    CHECK (actor_kind IN ('user', 'api_key', 'app', 'system'));
+
+---- create above / drop below ----
+
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS audit_logs_actor_kind_check;
+ALTER TABLE audit_logs ADD CONSTRAINT audit_logs_actor_kind_check
+    CHECK (actor_kind IN ('user', 'api_key', 'system')) NOT VALID;
+DROP INDEX IF EXISTS oauth_refresh_tokens_grant_id_idx;
+DROP TABLE IF EXISTS oauth_refresh_tokens;
+DROP INDEX IF EXISTS oauth_grants_client_id_idx;
+DROP INDEX IF EXISTS oauth_grants_tenant_id_idx;
+DROP TABLE IF EXISTS oauth_grants;
+DROP TABLE IF EXISTS oauth_codes;
+DROP INDEX IF EXISTS oauth_clients_owner_id_idx;
+DROP TABLE IF EXISTS oauth_clients;
@@ -0,0 +1,18 @@
+-- let the cleanup job find expired rows without scanning whole tables
+CREATE INDEX IF NOT EXISTS tokens_expiry_idx ON tokens(expiry);
+CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions(expires_at);
+CREATE INDEX IF NOT EXISTS sessions_blocked_idx ON sessions(id) WHERE is_blocked;
+CREATE INDEX IF NOT EXISTS users_unverified_created_idx ON users(created_at) WHERE NOT is_verified;
+CREATE INDEX IF NOT EXISTS signin_reports_expiry_idx ON signin_reports(expiry);
+CREATE INDEX IF NOT EXISTS oauth_codes_expiry_idx ON oauth_codes(expiry);
+CREATE INDEX IF NOT EXISTS oauth_refresh_tokens_expiry_idx ON oauth_refresh_tokens(expiry);
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS oauth_refresh_tokens_expiry_idx;
+DROP INDEX IF EXISTS oauth_codes_expiry_idx;
+DROP INDEX IF EXISTS signin_reports_expiry_idx;
+DROP INDEX IF EXISTS users_unverified_created_idx;
+DROP INDEX IF EXISTS sessions_blocked_idx;
+DROP INDEX IF EXISTS sessions_expires_at_idx;
+DROP INDEX IF EXISTS tokens_expiry_idx;
@@ -0,0 +1,42 @@
+-- a store's own domain, served only once its DNS TXT challenge is verified
+CREATE TABLE IF NOT EXISTS tenant_domains (
+    id                 UUID PRIMARY KEY,
+    tenant_id          UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    domain             VARCHAR(255) NOT NULL,
+    status             TEXT NOT NULL DEFAULT 'pending'
+                       CHECK (status IN ('pending', 'verifying', 'verified', 'cert_issued', 'failed')),
+    token              TEXT NOT NULL,
+    attempts           INTEGER NOT NULL DEFAULT 0,
+    last_error         TEXT,
+    verify_started_at  TIMESTAMPTZ,
+    next_check_at      TIMESTAMPTZ,
+    verified_at        TIMESTAMPTZ,
+    cert_expires_at    TIMESTAMPTZ,
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT tenant_domains_domain_uq UNIQUE (domain),
+    CONSTRAINT tenant_domains_tenant_uq UNIQUE (tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS tenant_domains_next_check_idx ON tenant_domains(next_check_at)
+    WHERE next_check_at IS NOT NULL;
+
+-- custom domains set before they were verified start over as pending, the
+-- stores stay reachable on their subdomain meanwhile
+INSERT INTO tenant_domains (id, tenant_id, domain, token)
+SELECT gen_random_uuid(), id, lower(domain), md5(random()::text || id::text)
+FROM tenants
+WHERE domain <> subdomain AND deleted_at IS NULL
+ON CONFLICT DO NOTHING;
+
+UPDATE tenants SET domain = subdomain WHERE domain <> subdomain;
+
+---- create above / drop below ----
+
+UPDATE tenants t SET domain = d.domain
+FROM tenant_domains d
+WHERE d.tenant_id = t.id AND t.domain = t.subdomain;
+
+DROP INDEX IF EXISTS tenant_domains_next_check_idx;
+DROP TABLE IF EXISTS tenant_domains;
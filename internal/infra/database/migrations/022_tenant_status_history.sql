@@ -0,0 +1,23 @@
+-- every move of a store along its lifecycle, who made it and why
+CREATE TABLE IF NOT EXISTS tenant_status_changes (
+    id           UUID PRIMARY KEY,
+    tenant_id    UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    from_status  status_type NOT NULL,
+    to_status    status_type NOT NULL,
+    reason       TEXT NOT NULL DEFAULT '',
+    trigger      TEXT NOT NULL CHECK (trigger IN ('owner', 'platform', 'system')),
+    actor_id     UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS tenant_status_changes_tenant_idx ON tenant_status_changes(tenant_id, created_at DESC);
+
+-- stores start in maintenance until their owner takes them live
+ALTER TABLE tenants ALTER COLUMN status SET DEFAULT 'maintenance';
+
+---- create above / drop below ----
+
+ALTER TABLE tenants ALTER COLUMN status SET DEFAULT 'active';
+
+DROP INDEX IF EXISTS tenant_status_changes_tenant_idx;
+DROP TABLE IF EXISTS tenant_status_changes;
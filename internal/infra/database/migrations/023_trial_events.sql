@@ -0,0 +1,18 @@
+-- what the trial scan did for each store's trial, one row per action and
+-- trial end date so reruns never remind or expire twice
+CREATE TABLE IF NOT EXISTS tenant_trial_events (
+    tenant_id     UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    kind          TEXT NOT NULL CHECK (kind IN ('reminder_3d', 'reminder_1d', 'expired')),
+    trial_end_at  TIMESTAMPTZ NOT NULL,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (tenant_id, kind, trial_end_at)
+);
+
+CREATE INDEX IF NOT EXISTS tenants_trial_end_idx ON tenants(trial_end_at, id)
+    WHERE trial_end_at IS NOT NULL AND deleted_at IS NULL;
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS tenants_trial_end_idx;
+DROP TABLE IF EXISTS tenant_trial_events;
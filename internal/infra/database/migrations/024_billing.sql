@@ -0,0 +1,62 @@
+-- a store's paid plan, one per store. tenants.plan stays what the store is
+-- entitled to, it follows the subscription once it is paid for
+CREATE TABLE IF NOT EXISTS subscriptions (
+    id                    UUID PRIMARY KEY,
+    tenant_id             UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    plan                  plan_type NOT NULL,
+    billing_interval      TEXT NOT NULL CHECK (billing_interval IN ('monthly', 'annual')),
+    status                TEXT NOT NULL CHECK (status IN ('incomplete', 'active', 'past_due', 'unpaid', 'canceled')),
+    current_period_start  TIMESTAMPTZ NOT NULL,
+    current_period_end    TIMESTAMPTZ NOT NULL,
+    pending_plan          plan_type,
+    credit_amount         DECIMAL(20, 2) NOT NULL DEFAULT 0 CHECK (credit_amount >= 0),
+    currency              VARCHAR(3) NOT NULL,
+    past_due_since        TIMESTAMPTZ,
+    suspended_at          TIMESTAMPTZ,
+    created_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at            TIMESTAMPTZ NOT NULL DEFAULT now(),
+
+    CONSTRAINT subscriptions_tenant_uq UNIQUE (tenant_id)
+);
+
+CREATE INDEX IF NOT EXISTS subscriptions_renewal_idx ON subscriptions(current_period_end)
+    WHERE status = 'active';
+CREATE INDEX IF NOT EXISTS subscriptions_past_due_idx ON subscriptions(past_due_since)
+    WHERE status = 'past_due';
+
+CREATE TABLE IF NOT EXISTS invoices (
+    id                UUID PRIMARY KEY,
+    tenant_id         UUID NOT NULL REFERENCES tenants(id) ON DELETE CASCADE,
+    subscription_id   UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind              TEXT NOT NULL CHECK (kind IN ('subscription', 'proration', 'renewal')),
+    status            TEXT NOT NULL CHECK (status IN ('open', 'paid', 'void')),
+    plan              plan_type NOT NULL,
+    billing_interval  TEXT NOT NULL,
+    period_start      TIMESTAMPTZ NOT NULL,
+    period_end        TIMESTAMPTZ NOT NULL,
+    amount            DECIMAL(20, 2) NOT NULL CHECK (amount >= 0),
+    credit_applied    DECIMAL(20, 2) NOT NULL DEFAULT 0,
+    currency          VARCHAR(3) NOT NULL,
+    attempts          INT NOT NULL DEFAULT 0,
+    next_attempt_at   TIMESTAMPTZ,
+    last_error        TEXT,
+    provider_ref      TEXT,
+    paid_at           TIMESTAMPTZ,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at        TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS invoices_tenant_idx ON invoices(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS invoices_due_idx ON invoices(next_attempt_at)
+    WHERE status = 'open';
+-- a period is billed once
+CREATE UNIQUE INDEX IF NOT EXISTS invoices_renewal_uq ON invoices(subscription_id, period_start)
+    WHERE kind = 'renewal';
+-- one plan change awaits payment at a time
+CREATE UNIQUE INDEX IF NOT EXISTS invoices_pending_change_uq ON invoices(subscription_id)
+    WHERE status = 'open' AND kind IN ('subscription', 'proration');
+
+---- create above / drop below ----
+
+DROP TABLE IF EXISTS invoices;
+DROP TABLE IF EXISTS subscriptions;
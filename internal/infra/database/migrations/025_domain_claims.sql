@@ -0,0 +1,22 @@
+-- a domain belongs to the store that verifies it first, pending and failed
+-- claims no longer keep other stores off a name
+ALTER TABLE tenant_domains DROP CONSTRAINT IF EXISTS tenant_domains_domain_uq;
+CREATE UNIQUE INDEX IF NOT EXISTS tenant_domains_verified_uq ON tenant_domains(domain)
+    WHERE status IN ('verified', 'cert_issued');
+CREATE INDEX IF NOT EXISTS tenant_domains_domain_idx ON tenant_domains(domain);
+CREATE INDEX IF NOT EXISTS tenant_domains_stale_idx ON tenant_domains(updated_at)
+    WHERE status IN ('pending', 'failed');
+
+---- create above / drop below ----
+
+DROP INDEX IF EXISTS tenant_domains_stale_idx;
+DROP INDEX IF EXISTS tenant_domains_domain_idx;
+DROP INDEX IF EXISTS tenant_domains_verified_uq;
+
+-- of the claims on one name only the served or oldest one is kept
+DELETE FROM tenant_domains d
+USING tenant_domains o
+WHERE d.domain = o.domain AND d.id <> o.id
+  AND (o.status IN ('verified', 'cert_issued')
+       OR (d.status NOT IN ('verified', 'cert_issued') AND (o.created_at, o.id) < (d.created_at, d.id)));
+ALTER TABLE tenant_domains ADD CONSTRAINT tenant_domains_domain_uq UNIQUE (domain);
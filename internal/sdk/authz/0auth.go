@@ -2,14 +2,57 @@ package authz
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"google.golang.org/api/idtoken"
 )
 
-func VerifyGoogleIDToken(ctx context.Context, token string) (*idtoken.Payload, error) {
-	payload, err := idtoken.Validate(ctx, token, "YOUR_GOOGLE_CLIENT_ID")
+var ErrInvalidIDToken = errors.New("invalid id token")
+
+// GoogleClaims is the subset of a verified Google ID token we rely on.
+type GoogleClaims struct {
+	Subject       string
+	Email         string
+	EmailVerified bool
+	GivenName     string
+	FamilyName    string
+}
+
+// IDTokenVerifier checks a third-party ID token and returns its claims.
+// Tests swap in a local fake instead of calling Google.
+type IDTokenVerifier interface {
+	VerifyIDToken(ctx context.Context, token string) (*GoogleClaims, error)
+}
+
+var _ IDTokenVerifier = (*GoogleVerifier)(nil)
+
+type GoogleVerifier struct {
+	clientID string
+}
+
+func NewGoogleVerifier(clientID string) *GoogleVerifier {
+	return &GoogleVerifier{clientID: clientID}
+}
+
+func (gv *GoogleVerifier) VerifyIDToken(ctx context.Context, token string) (*GoogleClaims, error) {
+	payload, err := idtoken.Validate(ctx, token, gv.clientID)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w: %w", ErrInvalidIDToken, err)
+	}
+
+	claims := &GoogleClaims{Subject: payload.Subject}
+	if v, ok := payload.Claims["email"].(string); ok {
+		claims.Email = v
+	}
+	if v, ok := payload.Claims["email_verified"].(bool); ok {
+		claims.EmailVerified = v
+	}
+	if v, ok := payload.Claims["given_name"].(string); ok {
+		claims.GivenName = v
+	}
+	if v, ok := payload.Claims["family_name"].(string); ok {
+		claims.FamilyName = v
 	}
-	return payload, nil
+	return claims, nil
 }
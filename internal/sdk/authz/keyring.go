@@ -0,0 +1,222 @@
+package authz
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrUnknownKey     = errors.New("unknown signing key")
+	ErrKeyExpired     = errors.New("signing key expired")
+	ErrNoPrivateKey   = errors.New("active key has no private part")
+	ErrUnsupportedKey = errors.New("unsupported key type")
+)
+
+const minRSABits = 2048
+
+// SigningKey is one entry of the keyring. Retiring keys may hold only the
+// public part, they are never used to sign.
+type SigningKey struct {
+	ID        string // RFC 7638 thumbprint, sent as the kid header
+	Method    jwt.SigningMethod
+	ExpiresAt time.Time // zero for the active key
+
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+func (k *SigningKey) expired(now time.Time) bool {
+	return !k.ExpiresAt.IsZero() && now.After(k.ExpiresAt)
+}
+
+// ParseSigningKey reads an Ed25519 or RSA key from PEM. PKCS#8 and PKCS#1
+// private keys and PKIX public keys are accepted.
+func ParseSigningKey(data []byte, expiresAt time.Time) (*SigningKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("parse signing key: no PEM block found")
+	}
+
+	var raw any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		raw, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		raw, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		raw, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("parse signing key: %w: PEM type %q", ErrUnsupportedKey, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse signing key: %w", err)
+	}
+
+	key := &SigningKey{ExpiresAt: expiresAt}
+	switch k := raw.(type) {
+	case ed25519.PrivateKey:
+		key.private, key.public = k, k.Public()
+	case ed25519.PublicKey:
+		key.public = k
+	case *rsa.PrivateKey:
+		key.private, key.public = k, k.Public()
+	case *rsa.PublicKey:
+		key.public = k
+	default:
+		return nil, fmt.Errorf("parse signing key: %w: %T", ErrUnsupportedKey, raw)
+	}
+
+	switch pub := key.public.(type) {
+	case ed25519.PublicKey:
+		key.Method = jwt.SigningMethodEdDSA
+	case *rsa.PublicKey:
+		if pub.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("parse signing key: rsa key must be at least %d bits", minRSABits)
+		}
+		key.Method = jwt.SigningMethodRS256
+	}
+
+	key.ID = thumbprint(key.public)
+	return key, nil
+}
+
+func LoadSigningKey(path string, expiresAt time.Time) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("load signing key: %w", err)
+	}
+	return ParseSigningKey(data, expiresAt)
+}
+
+// KeyRing holds the key new tokens are signed with and the retiring keys
+// that still verify tokens issued before the last rotation.
+type KeyRing struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+func NewKeyRing(active *SigningKey, retiring ...*SigningKey) (*KeyRing, error) {
+	if active == nil || active.private == nil {
+		return nil, ErrNoPrivateKey
+	}
+
+	ring := &KeyRing{
+		active: active,
+		keys:   map[string]*SigningKey{active.ID: active},
+	}
+	for _, k := range retiring {
+		if _, ok := ring.keys[k.ID]; ok {
+			return nil, fmt.Errorf("keyring: duplicate key %s", k.ID)
+		}
+		ring.keys[k.ID] = k
+	}
+	return ring, nil
+}
+
+type RetiringKeyFile struct {
+	Path      string
+	ExpiresAt time.Time
+}
+
+func LoadKeyRing(activePath string, retiring ...RetiringKeyFile) (*KeyRing, error) {
+	active, err := LoadSigningKey(activePath, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]*SigningKey, 0, len(retiring))
+	for _, f := range retiring {
+		k, err := LoadSigningKey(f.Path, f.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return NewKeyRing(active, keys...)
+}
+
+func (r *KeyRing) Active() *SigningKey {
+	return r.active
+}
+
+// Lookup returns the key for kid unless it has passed its expiry.
+func (r *KeyRing) Lookup(kid string, now time.Time) (*SigningKey, error) {
+	k, ok := r.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if k.expired(now) {
+		return nil, ErrKeyExpired
+	}
+	return k, nil
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes the public part of every key that can still verify tokens.
+func (r *KeyRing) JWKS(now time.Time) JWKSet {
+	set := JWKSet{Keys: []JWK{toJWK(r.active)}}
+	for id, k := range r.keys {
+		if id == r.active.ID || k.expired(now) {
+			continue
+		}
+		set.Keys = append(set.Keys, toJWK(k))
+	}
+	return set
+}
+
+func toJWK(k *SigningKey) JWK {
+	jwk := JWK{KeyID: k.ID, Use: "sig", Algorithm: k.Method.Alg()}
+	switch pub := k.public.(type) {
+	case ed25519.PublicKey:
+		jwk.KeyType, jwk.Curve, jwk.X = "OKP", "Ed25519", b64url(pub)
+	case *rsa.PublicKey:
+		jwk.KeyType, jwk.N, jwk.E = "RSA", b64url(pub.N.Bytes()), b64url(big.NewInt(int64(pub.E)).Bytes())
+	}
+	return jwk
+}
+
+// thumbprint follows RFC 7638: sha256 over the required members in
+// lexicographic order.
+func thumbprint(pub crypto.PublicKey) string {
+	var canonical string
+	switch k := pub.(type) {
+	case ed25519.PublicKey:
+		canonical = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":%q}`, b64url(k))
+	case *rsa.PublicKey:
+		canonical = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`,
+			b64url(big.NewInt(int64(k.E)).Bytes()), b64url(k.N.Bytes()))
+	}
+	sum := sha256.Sum256([]byte(canonical))
+	return b64url(sum[:])
+}
+
+func b64url(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
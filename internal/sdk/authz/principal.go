@@ -0,0 +1,66 @@
+package authz
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+)
+
+type PrincipalKind string
+
+const (
+	PrincipalUser   PrincipalKind = "user"
+	PrincipalAPIKey PrincipalKind = "api_key"
+	PrincipalApp    PrincipalKind = "app"
+)
+
+// Principal is the authenticated caller, whichever way they signed the
+// request. For an API key UserID is the member who created it, the key
+// itself only reaches TenantID and only within Scopes. An app acts for the
+// user who installed it, held to the same store and to its granted Scopes.
+type Principal struct {
+	Kind      PrincipalKind
+	UserID    uuid.UUID
+	RoleID    string
+	SessionID uuid.UUID
+	// ImpersonatorID is the platform admin behind the request, if any.
+	ImpersonatorID uuid.UUID
+
+	TenantID uuid.UUID
+	APIKeyID uuid.UUID
+	ClientID uuid.UUID
+	Scopes   permission.Set
+}
+
+func PrincipalFromPayload(p *Payload) *Principal {
+	principal := &Principal{
+		Kind:           PrincipalUser,
+		UserID:         p.UserID,
+		RoleID:         p.RoleID,
+		SessionID:      p.SessionID,
+		ImpersonatorID: p.ImpersonatorID,
+	}
+	if p.IsApp() {
+		principal.Kind = PrincipalApp
+		principal.TenantID = p.TenantID
+		principal.ClientID = p.ClientID
+		principal.Scopes = permission.NewSet()
+		for _, v := range strings.Fields(p.Scope) {
+			if perm, err := permission.Parse(v); err == nil {
+				principal.Scopes.Add(perm)
+			}
+		}
+	}
+	return principal
+}
+
+func (p *Principal) IsAPIKey() bool { return p.Kind == PrincipalAPIKey }
+
+func (p *Principal) IsApp() bool { return p.Kind == PrincipalApp }
+
+// IsScoped reports whether the caller is held to one store and its Scopes
+// rather than to what UserID may do.
+func (p *Principal) IsScoped() bool { return p.IsAPIKey() || p.IsApp() }
+
+func (p *Principal) IsImpersonated() bool { return p.ImpersonatorID != uuid.Nil }
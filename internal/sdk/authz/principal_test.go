@@ -0,0 +1,67 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
+)
+
+func TestAppTokenPrincipal(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseSigningKey(pemKey(t, priv), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maker := NewAsymmetricMaker(ring)
+
+	data := NewJWTData(uuid.New(), "", time.Minute, "test")
+	data.SessionID = uuid.New()
+	data.TenantID = uuid.New()
+	data.ClientID = uuid.New()
+	data.Scopes = []string{"orders:read", "products:write", "not:a-scope"}
+
+	tok, _, err := maker.GenerateToken(data)
+	if err != nil {
+		t.Fatalf("generate: %v", err)
+	}
+	payload, err := maker.VerifyToken(tok)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+
+	p := PrincipalFromPayload(payload)
+	if !p.IsApp() || !p.IsScoped() {
+		t.Fatalf("kind = %s, want %s", p.Kind, PrincipalApp)
+	}
+	if p.TenantID != data.TenantID || p.ClientID != data.ClientID || p.SessionID != data.SessionID {
+		t.Errorf("principal = %+v, want tenant %s client %s session %s", p, data.TenantID, data.ClientID, data.SessionID)
+	}
+	want := permission.NewSet(permission.OrdersRead, permission.ProductsWrite)
+	if len(p.Scopes) != len(want) || !p.Scopes.HasAll(want.Slice()...) {
+		t.Errorf("scopes = %v, want %v", p.Scopes.Strings(), want.Strings())
+	}
+
+	// a user's token stays a user principal
+	userTok, _, err := maker.GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	userPayload, err := maker.VerifyToken(userTok)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if up := PrincipalFromPayload(userPayload); up.IsScoped() || up.Scopes != nil {
+		t.Errorf("user token principal = %+v, want unscoped user", up)
+	}
+}
@@ -0,0 +1,51 @@
+package authz
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+)
+
+var ErrCiphertext = errors.New("malformed ciphertext")
+
+// SecretBox encrypts small secrets (e.g. TOTP seeds) before they are stored.
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+// NewSecretBox derives an AES-256-GCM key from the configured secret.
+func NewSecretBox(secret string) (*SecretBox, error) {
+	key := sha256.Sum256([]byte(secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, fmt.Errorf("newcipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("newgcm: %w", err)
+	}
+	return &SecretBox{aead: aead}, nil
+}
+
+func (sb *SecretBox) Seal(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, sb.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("nonce: %w", err)
+	}
+	return sb.aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func (sb *SecretBox) Open(ciphertext []byte) ([]byte, error) {
+	ns := sb.aead.NonceSize()
+	if len(ciphertext) < ns {
+		return nil, ErrCiphertext
+	}
+	plaintext, err := sb.aead.Open(nil, ciphertext[:ns], ciphertext[ns:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCiphertext, err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,93 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var _ TokenMaker = (*AsymmetricMaker)(nil)
+
+// AsymmetricMaker signs with the active key of a KeyRing (EdDSA or RS256)
+// and verifies with any key of the ring that has not expired, so verifiers
+// only need the public keys published at /.well-known/jwks.json.
+type AsymmetricMaker struct {
+	ring   *KeyRing
+	legacy *JWTAuthMaker
+}
+
+func NewAsymmetricMaker(ring *KeyRing) *AsymmetricMaker {
+	return &AsymmetricMaker{ring: ring}
+}
+
+// AcceptLegacyKey has HS512 tokens signed with the symmetric key used
+// before the keyring verify too, until they expire. Nothing is signed
+// with it.
+func (am *AsymmetricMaker) AcceptLegacyKey(key string) {
+	legacy := NewJWTMaker(key)
+	am.legacy = &legacy
+}
+
+func (am *AsymmetricMaker) KeyRing() *KeyRing {
+	return am.ring
+}
+
+func (am *AsymmetricMaker) GenerateToken(data JWTData) (string, *Payload, error) {
+	payloadData, err := NewPayload(data.UserID, data.Role, data.Duration, data.ServiceName)
+	if err != nil {
+		return "", nil, fmt.Errorf("generate token: %w", err)
+	}
+	payloadData.setData(data)
+
+	key := am.ring.Active()
+	token := jwt.NewWithClaims(key.Method, payloadData)
+	token.Header["kid"] = key.ID
+
+	tokenString, err := token.SignedString(key.private)
+	if err != nil {
+		return "", nil, fmt.Errorf("signed jwt token: %w", err)
+	}
+	return tokenString, payloadData, nil
+}
+
+func (am *AsymmetricMaker) VerifyToken(tokenString string) (*Payload, error) {
+	if am.legacy != nil {
+		t, _, err := jwt.NewParser().ParseUnverified(tokenString, &Payload{})
+		if err == nil && t.Method.Alg() == jwt.SigningMethodHS512.Alg() {
+			return am.legacy.VerifyToken(tokenString)
+		}
+	}
+
+	payload := Payload{}
+
+	parsedToken, err := jwt.ParseWithClaims(tokenString, &payload, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := am.ring.Lookup(kid, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if t.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return key.public, nil
+	},
+		jwt.WithExpirationRequired(),
+		jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg(), jwt.SigningMethodRS256.Alg()}),
+	)
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpired
+		}
+		return nil, fmt.Errorf("verify token : %w", err)
+	}
+
+	parsedPayload, ok := parsedToken.Claims.(*Payload)
+	if !ok {
+		return nil, fmt.Errorf("invalid token")
+	}
+
+	return parsedPayload, nil
+}
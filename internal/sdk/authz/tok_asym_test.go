@@ -0,0 +1,123 @@
+package authz
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func pemKey(t *testing.T, key any) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+func TestAsymmetricMakerRotation(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaPriv, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	oldKey, err := ParseSigningKey(pemKey(t, rsaPriv), time.Time{})
+	if err != nil {
+		t.Fatalf("parse rsa: %v", err)
+	}
+	oldRing, err := NewKeyRing(oldKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldToken, _, err := NewAsymmetricMaker(oldRing).GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if err != nil {
+		t.Fatalf("generate rs256: %v", err)
+	}
+
+	newKey, err := ParseSigningKey(pemKey(t, edPriv), time.Time{})
+	if err != nil {
+		t.Fatalf("parse ed25519: %v", err)
+	}
+
+	// rotated: the rsa key is retiring and still verifies
+	retiring, _ := ParseSigningKey(pemKey(t, rsaPriv), time.Now().Add(time.Hour))
+	ring, err := NewKeyRing(newKey, retiring)
+	if err != nil {
+		t.Fatal(err)
+	}
+	maker := NewAsymmetricMaker(ring)
+
+	newToken, _, err := maker.GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if err != nil {
+		t.Fatalf("generate eddsa: %v", err)
+	}
+	for name, tok := range map[string]string{"eddsa": newToken, "rs256": oldToken} {
+		if _, err := maker.VerifyToken(tok); err != nil {
+			t.Errorf("verify %s: %v", name, err)
+		}
+	}
+	if got := len(ring.JWKS(time.Now()).Keys); got != 2 {
+		t.Errorf("jwks keys = %d, want 2", got)
+	}
+
+	// once the retiring key expires its tokens are refused
+	retiring.ExpiresAt = time.Now().Add(-time.Second)
+	if _, err := maker.VerifyToken(oldToken); !errors.Is(err, ErrKeyExpired) {
+		t.Errorf("verify with expired key: got %v, want %v", err, ErrKeyExpired)
+	}
+	if got := len(ring.JWKS(time.Now()).Keys); got != 1 {
+		t.Errorf("jwks keys after expiry = %d, want 1", got)
+	}
+}
+
+func TestAsymmetricMakerLegacyKey(t *testing.T) {
+	_, edPriv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ParseSigningKey(pemKey(t, edPriv), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ring, err := NewKeyRing(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	legacy := NewJWTMaker("legacy-symmetric-key-0123456789")
+	oldToken, _, err := legacy.GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	maker := NewAsymmetricMaker(ring)
+	if _, err := maker.VerifyToken(oldToken); err == nil {
+		t.Fatal("hs512 token verified without the legacy key")
+	}
+	maker.AcceptLegacyKey("legacy-symmetric-key-0123456789")
+	if _, err := maker.VerifyToken(oldToken); err != nil {
+		t.Fatalf("verify hs512: %v", err)
+	}
+	newToken, _, err := maker.GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := maker.VerifyToken(newToken); err != nil {
+		t.Fatalf("verify eddsa: %v", err)
+	}
+
+	forged, _, _ := (&JWTAuthMaker{SemetricKey: "some-other-key-0123456789abc"}).GenerateToken(NewJWTData(uuid.New(), "user", time.Minute, "test"))
+	if _, err := maker.VerifyToken(forged); err == nil {
+		t.Fatal("hs512 token signed with another key verified")
+	}
+}
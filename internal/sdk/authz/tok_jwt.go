@@ -3,6 +3,7 @@ package authz
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -23,6 +24,14 @@ type JWTData struct {
 	ServiceName string
 	Duration    time.Duration
 	UserID      uuid.UUID
+	SessionID   uuid.UUID // session family the token belongs to, if any
+	// ImpersonatorID is the platform admin acting as UserID, if any
+	ImpersonatorID uuid.UUID
+	// TenantID, ClientID and Scopes are set on tokens issued to a third-party
+	// app, which only reach one store and only within Scopes.
+	TenantID uuid.UUID
+	ClientID uuid.UUID
+	Scopes   []string
 }
 
 func NewJWTData(userid uuid.UUID, role string, duration time.Duration, svcName string) JWTData {
@@ -35,12 +44,33 @@ func NewJWTData(userid uuid.UUID, role string, duration time.Duration, svcName s
 }
 
 type Payload struct {
-	RoleID string    `json:"role_id"`
-	UserID uuid.UUID `json:"user_id"`
+	RoleID    string    `json:"role_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	SessionID uuid.UUID `json:"session_id"`
+	// ImpersonatorID is set on tokens an admin uses to act as UserID.
+	ImpersonatorID uuid.UUID `json:"impersonator_id,omitzero"`
+	// TenantID, ClientID and Scope are set on app tokens, Scope is space
+	// separated as in OAuth2.
+	TenantID uuid.UUID `json:"tenant_id,omitzero"`
+	ClientID uuid.UUID `json:"client_id,omitzero"`
+	Scope    string    `json:"scope,omitempty"`
 
 	jwt.RegisteredClaims
 }
 
+func (p *Payload) IsImpersonated() bool { return p.ImpersonatorID != uuid.Nil }
+
+func (p *Payload) IsApp() bool { return p.ClientID != uuid.Nil }
+
+// setData copies what NewPayload does not take from data.
+func (p *Payload) setData(data JWTData) {
+	p.SessionID = data.SessionID
+	p.ImpersonatorID = data.ImpersonatorID
+	p.TenantID = data.TenantID
+	p.ClientID = data.ClientID
+	p.Scope = strings.Join(data.Scopes, " ")
+}
+
 func NewPayload(userID uuid.UUID, roleid string, duration time.Duration, svcName string) (*Payload, error) {
 	id, err := uuid.NewRandom()
 	if err != nil {
@@ -75,6 +105,7 @@ func (jta *JWTAuthMaker) GenerateToken(data JWTData) (string, *Payload, error) {
 	if err != nil {
 		return "", nil, fmt.Errorf("generate token: %w", err)
 	}
+	payloadData.setData(data)
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS512, payloadData)
 	tokenString, err := token.SignedString([]byte(jta.SemetricKey))
@@ -0,0 +1,56 @@
+package authz
+
+import (
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B (SHA1), truncated to six digits.
+func TestTOTPCode(t *testing.T) {
+	secret := b32NoPadding.EncodeToString([]byte("12345678901234567890"))
+
+	cases := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, c := range cases {
+		got, err := TOTPCode(secret, TOTPStep(time.Unix(c.unix, 0)))
+		if err != nil {
+			t.Fatalf("totpcode(%d): %v", c.unix, err)
+		}
+		if got != c.want {
+			t.Errorf("totpcode(%d) = %s, want %s", c.unix, got, c.want)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	secret, err := NewTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	prev, err := TOTPCode(secret, TOTPStep(now)-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ValidateTOTP(secret, prev, now); !ok {
+		t.Error("previous step should be accepted")
+	}
+
+	old, err := TOTPCode(secret, TOTPStep(now)-3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := ValidateTOTP(secret, old, now); ok && old != prev {
+		t.Error("code outside the skew window accepted")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/iamonah/merchcore/internal/domain/tenant"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
 	"github.com/iamonah/merchcore/internal/sdk/midd"
 )
@@ -22,4 +23,22 @@ func GetJWTPayloadCTX(r *http.Request) (*authz.Payload, error) {
 		return nil, fmt.Errorf("jwt payload not in context")
 	}
 	return v, nil
-}
\ No newline at end of file
+}
+
+// GetPrincipalCTX returns the caller set by AuthBearer or AuthAPIKey.
+func GetPrincipalCTX(r *http.Request) (*authz.Principal, error) {
+	v, ok := r.Context().Value(midd.AuthContextPrincipalKey).(*authz.Principal)
+	if !ok {
+		return nil, fmt.Errorf("principal not in context")
+	}
+	return v, nil
+}
+
+// GetTenantCTX returns the store set by ResolveTenant on storefront routes.
+func GetTenantCTX(r *http.Request) (*tenant.TenantProfile, error) {
+	v, ok := r.Context().Value(midd.TenantContextKey).(*tenant.TenantProfile)
+	if !ok {
+		return nil, fmt.Errorf("tenant not in context")
+	}
+	return v, nil
+}
@@ -0,0 +1,22 @@
+package base
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+func GetPathParam(r *http.Request, key string) string {
+	return mux.Vars(r)[key]
+}
+
+func GetPathUUID(r *http.Request, key string) (uuid.UUID, error) {
+	v := GetPathParam(r, key)
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid %s: %q", key, v)
+	}
+	return id, nil
+}
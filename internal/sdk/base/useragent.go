@@ -0,0 +1,72 @@
+package base
+
+import "strings"
+
+// UserAgent holds coarse, human readable labels for a User-Agent header.
+type UserAgent struct {
+	Browser string
+	OS      string
+	Device  string // desktop, mobile, tablet or bot
+}
+
+func (ua UserAgent) Label() string {
+	return ua.Browser + " on " + ua.OS
+}
+
+// ordered: more specific tokens must come before the engines they embed
+var browserTokens = []struct{ token, name string }{
+	{"edg/", "Edge"},
+	{"opr/", "Opera"},
+	{"samsungbrowser/", "Samsung Internet"},
+	{"firefox/", "Firefox"},
+	{"fxios/", "Firefox"},
+	{"crios/", "Chrome"},
+	{"chrome/", "Chrome"},
+	{"safari/", "Safari"},
+	{"okhttp/", "Android App"},
+	{"cfnetwork/", "iOS App"},
+	{"curl/", "curl"},
+	{"postman", "Postman"},
+}
+
+var osTokens = []struct{ token, name string }{
+	{"iphone", "iOS"},
+	{"ipad", "iPadOS"},
+	{"android", "Android"},
+	{"windows", "Windows"},
+	{"mac os x", "macOS"},
+	{"macintosh", "macOS"},
+	{"cros", "ChromeOS"},
+	{"linux", "Linux"},
+}
+
+// ParseUserAgent is a best effort parser, good enough to label devices in a
+// session list. Unknown values are reported as "Unknown".
+func ParseUserAgent(raw string) UserAgent {
+	s := strings.ToLower(raw)
+	ua := UserAgent{Browser: "Unknown", OS: "Unknown", Device: "desktop"}
+
+	for _, b := range browserTokens {
+		if strings.Contains(s, b.token) {
+			ua.Browser = b.name
+			break
+		}
+	}
+
+	for _, o := range osTokens {
+		if strings.Contains(s, o.token) {
+			ua.OS = o.name
+			break
+		}
+	}
+
+	switch {
+	case strings.Contains(s, "bot") || strings.Contains(s, "spider") || strings.Contains(s, "crawl"):
+		ua.Device = "bot"
+	case strings.Contains(s, "ipad") || strings.Contains(s, "tablet"):
+		ua.Device = "tablet"
+	case strings.Contains(s, "mobi") || strings.Contains(s, "iphone") || strings.Contains(s, "android"):
+		ua.Device = "mobile"
+	}
+	return ua
+}
@@ -0,0 +1,40 @@
+// Package certs checks which certificate the edge serves for a host.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// Prober dials a domain over TLS as a browser would, through whatever its
+// DNS points at, and reads the served certificate. A certificate that does not verify for the host counts as
+// not issued yet.
+type Prober struct {
+	timeout time.Duration
+}
+
+func NewProber(timeout time.Duration) *Prober {
+	return &Prober{timeout: timeout}
+}
+
+func (p *Prober) ProbeCertificate(ctx context.Context, domain string) (time.Time, error) {
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: p.timeout},
+		Config:    &tls.Config{ServerName: domain, MinVersion: tls.VersionTLS12},
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(domain, "443"))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("tlsdial: %w", err)
+	}
+	defer conn.Close()
+
+	peers := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(peers) == 0 {
+		return time.Time{}, errors.New("no certificate served")
+	}
+	return peers[0].NotAfter, nil
+}
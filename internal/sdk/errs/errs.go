@@ -5,15 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"time"
 )
 
 type AppErr struct {
-	Err      string      `json:"error"`
-	Code     int         `json:"code"`
-	Message  string      `json:"message,omitempty"`
+	Err      string       `json:"error"`
+	Code     int          `json:"code"`
+	Message  string       `json:"message,omitempty"`
 	Fields   *FieldErrors `json:"fields,omitempty"`
-	FuncName string      `json:"-"`
-	FileName string      `json:"-"`
+	FuncName string       `json:"-"`
+	FileName string       `json:"-"`
+
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (e *AppErr) Error() string {
@@ -39,12 +42,16 @@ func New(code ErrCode, err error) *AppErr {
 		}
 	}
 
+	var retry *RetryError
+	errors.As(err, &retry)
+
 	return &AppErr{
-		Err:      code.String(),
-		Code:     code.HTTPStatus(),
-		Message:  err.Error(),
-		FuncName: runtime.FuncForPC(pc).Name(),
-		FileName: fmt.Sprintf("%s:%d", filename, line),
+		Err:        code.String(),
+		Code:       code.HTTPStatus(),
+		Message:    err.Error(),
+		FuncName:   runtime.FuncForPC(pc).Name(),
+		FileName:   fmt.Sprintf("%s:%d", filename, line),
+		RetryAfter: retry.after(),
 	}
 }
 
@@ -59,7 +66,7 @@ func Newf(code ErrCode, format string, v ...any) *AppErr {
 	}
 }
 
-//errs we expect are bound to happen
+// errs we expect are bound to happen
 type DomainError struct {
 	Msg  error
 	Code ErrCode
@@ -85,6 +92,32 @@ func IsDomainError(err error) (*DomainError, bool) {
 	return nil, false
 }
 
+// RetryError tells the client when it may try again, it ends up in the
+// Retry-After header.
+type RetryError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func NewRetryError(err error, retryAfter time.Duration) error {
+	return &RetryError{Err: err, RetryAfter: retryAfter}
+}
+
+func (e *RetryError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+func (e *RetryError) after() time.Duration {
+	if e == nil {
+		return 0
+	}
+	return e.RetryAfter
+}
+
 type FieldError struct {
 	Field string `json:"field"`
 	Err   string `json:"error"`
@@ -0,0 +1,138 @@
+// Package geoip answers "roughly where is this IP" from a local range file,
+// no lookups leave the process.
+package geoip
+
+import (
+	"compress/gzip"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+type Location struct {
+	City    string
+	Region  string
+	Country string
+}
+
+// String reads "City, Region, Country", skipping the parts that are unknown.
+func (l Location) String() string {
+	var parts []string
+	for _, p := range []string{l.City, l.Region, l.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+type ipRange struct {
+	start, end netip.Addr
+	loc        Location
+}
+
+type DB struct {
+	ranges []ipRange
+}
+
+// Open loads a range CSV in the DB-IP lite layout, gzipped if the name ends
+// in .gz. Both the country file (start,end,country) and the city file
+// (start,end,continent,country,region,city,...) are understood.
+func Open(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("gzip: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	db := &DB{}
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read: %w", err)
+		}
+		if len(rec) < 3 {
+			continue
+		}
+
+		start, err := netip.ParseAddr(rec[0])
+		if err != nil {
+			continue // header or comment
+		}
+		end, err := netip.ParseAddr(rec[1])
+		if err != nil || end.Less(start) || start.Is4() != end.Is4() {
+			return nil, fmt.Errorf("invalid range %q-%q", rec[0], rec[1])
+		}
+
+		var loc Location
+		if len(rec) >= 6 {
+			loc = Location{Country: rec[3], Region: rec[4], City: rec[5]}
+		} else {
+			loc = Location{Country: rec[2]}
+		}
+		db.ranges = append(db.ranges, ipRange{start: start, end: end, loc: loc})
+	}
+
+	slices.SortFunc(db.ranges, func(a, b ipRange) int { return a.start.Compare(b.start) })
+	return db, nil
+}
+
+func (db *DB) Len() int {
+	if db == nil {
+		return 0
+	}
+	return len(db.ranges)
+}
+
+// Lookup finds the range holding ip.
+func (db *DB) Lookup(ip string) (Location, bool) {
+	if db == nil {
+		return Location{}, false
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Location{}, false
+	}
+	addr = addr.Unmap()
+
+	// the last range starting at or before addr is the only candidate
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r ipRange, a netip.Addr) int { return r.start.Compare(a) })
+	if !found {
+		i--
+	}
+	if i < 0 {
+		return Location{}, false
+	}
+	r := db.ranges[i]
+	if r.start.Is4() != addr.Is4() || r.end.Less(addr) {
+		return Location{}, false
+	}
+	return r.loc, true
+}
+
+// Locate is Lookup as text, empty when the IP is unknown.
+func (db *DB) Locate(ip string) string {
+	loc, _ := db.Lookup(ip)
+	return loc.String()
+}
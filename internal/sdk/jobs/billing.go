@@ -0,0 +1,52 @@
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+)
+
+// billingMetrics counts what the billing runs did since start, on
+// /debug/vars.
+var billingMetrics = expvar.NewMap("billing")
+
+func recordBillingRun(report billing.RunReport, err error) {
+	billingMetrics.Add("runs", 1)
+	if err != nil {
+		billingMetrics.Add("failures", 1)
+	}
+	billingMetrics.Add("renewed", int64(report.Renewed))
+	billingMetrics.Add("canceled", int64(report.Canceled))
+	billingMetrics.Add("collected", int64(report.Collected))
+	billingMetrics.Add("declined", int64(report.Failed))
+	billingMetrics.Add("suspended", int64(report.Suspended))
+	billingMetrics.Add("reinstated", int64(report.Reinstated))
+}
+
+func (rt *JobProcessor) DoBillingRunJob(ctx context.Context, t *asynq.Task) error {
+	report, err := rt.billing.RunBilling(ctx)
+	recordBillingRun(report, err)
+
+	event := rt.logger.Info()
+	if err != nil {
+		event = rt.logger.Error().Err(err)
+	} else if report == (billing.RunReport{}) {
+		event = rt.logger.Debug()
+	}
+	event.Str("type", t.Type()).
+		Int("renewed", report.Renewed).
+		Int("canceled", report.Canceled).
+		Int("collected", report.Collected).
+		Int("declined", report.Failed).
+		Int("suspended", report.Suspended).
+		Int("reinstated", report.Reinstated).
+		Msg("billing run done")
+
+	if err != nil {
+		return fmt.Errorf("runbilling: %w", err)
+	}
+	return nil
+}
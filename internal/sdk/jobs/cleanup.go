@@ -0,0 +1,58 @@
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/cleanup"
+	"github.com/rs/zerolog"
+)
+
+// cleanupMetrics is published on /debug/vars wherever the process serves
+// expvar. deleted.<target> counts rows and keys removed since start.
+var cleanupMetrics = expvar.NewMap("cleanup")
+
+func recordCleanup(report cleanup.Report, err error) {
+	cleanupMetrics.Add("runs", 1)
+	if err != nil {
+		cleanupMetrics.Add("failures", 1)
+	}
+	for target, n := range report.Deleted {
+		cleanupMetrics.Add("deleted."+string(target), n)
+	}
+
+	lastRun, lastDuration, lastDeleted := new(expvar.Int), new(expvar.Int), new(expvar.Int)
+	lastRun.Set(time.Now().Unix())
+	lastDuration.Set(report.Duration.Milliseconds())
+	lastDeleted.Set(report.Total())
+	cleanupMetrics.Set("last_run_unix", lastRun)
+	cleanupMetrics.Set("last_duration_ms", lastDuration)
+	cleanupMetrics.Set("last_deleted", lastDeleted)
+}
+
+func (rt *JobProcessor) DoCleanupJob(ctx context.Context, t *asynq.Task) error {
+	report, err := rt.cleaner.Sweep(ctx)
+	recordCleanup(report, err)
+
+	deleted := zerolog.Dict()
+	for target, n := range report.Deleted {
+		deleted.Int64(string(target), n)
+	}
+	event := rt.logger.Info()
+	if err != nil {
+		event = rt.logger.Error().Err(err)
+	}
+	event.Str("type", t.Type()).
+		Dict("deleted", deleted).
+		Int("batches", report.Batches).
+		Dur("duration", report.Duration).
+		Msg("cleanup done")
+
+	if err != nil {
+		return fmt.Errorf("sweep: %w", err)
+	}
+	return nil
+}
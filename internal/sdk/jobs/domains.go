@@ -0,0 +1,50 @@
+package jobs
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+)
+
+// domainMetrics counts custom domain checks since start, next to cleanup's
+// on /debug/vars.
+var domainMetrics = expvar.NewMap("custom_domains")
+
+func recordDomainCheck(report customdomain.CheckReport, err error) {
+	domainMetrics.Add("runs", 1)
+	if err != nil {
+		domainMetrics.Add("failures", 1)
+	}
+	domainMetrics.Add("checked", int64(report.Checked))
+	domainMetrics.Add("verified", int64(report.Verified))
+	domainMetrics.Add("failed", int64(report.Failed))
+	domainMetrics.Add("certs_issued", int64(report.CertsIssued))
+	domainMetrics.Add("expired", report.Expired)
+}
+
+func (rt *JobProcessor) DoDomainCheckJob(ctx context.Context, t *asynq.Task) error {
+	report, err := rt.domains.CheckDomains(ctx)
+	recordDomainCheck(report, err)
+
+	event := rt.logger.Info()
+	if err != nil {
+		event = rt.logger.Error().Err(err)
+	} else if report.Checked == 0 && report.Expired == 0 {
+		event = rt.logger.Debug()
+	}
+	event.Str("type", t.Type()).
+		Int("checked", report.Checked).
+		Int("verified", report.Verified).
+		Int("failed", report.Failed).
+		Int("certs_issued", report.CertsIssued).
+		Int64("expired", report.Expired).
+		Msg("domain check done")
+
+	if err != nil {
+		return fmt.Errorf("checkdomains: %w", err)
+	}
+	return nil
+}
@@ -4,13 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/gob"
+	"errors"
 	"fmt"
 
 	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/users"
 )
 
 const (
-	UserWelcomeTemplate = "welcomemail.html"
+	UserWelcomeTemplate   = "welcomemail.html"
+	AccountLockedTemplate = "accountlocked.html"
+	MagicLinkTemplate     = "magiclink.html"
+	NewSignInTemplate     = "newsignin.html"
+	EmailChangeTemplate   = "emailchange.html"
+	StaffInviteTemplate   = "staffinvite.html"
+	DataExportTemplate    = "dataexport.html"
 )
 
 func (rt *JobProcessor) DoWelcomeEmailJob(ctx context.Context, t *asynq.Task) error {
@@ -39,3 +47,202 @@ func (rt *JobProcessor) DoWelcomeEmailJob(ctx context.Context, t *asynq.Task) er
 		Int("attempt", retryCount).Msg("email sent")
 	return nil
 }
+
+func (rt *JobProcessor) DoAccountLockedEmailJob(ctx context.Context, t *asynq.Task) error {
+	var payload AccountLockedPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	if err := rt.mailer.Send(AccountLockedTemplate, payload.Email, payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("email", payload.Email).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("email", payload.Email).
+		Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
+
+func (rt *JobProcessor) DoMagicLinkEmailJob(ctx context.Context, t *asynq.Task) error {
+	var payload MagicLinkPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	if err := rt.mailer.Send(MagicLinkTemplate, payload.Email, payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("user_id", payload.UserID.String()).
+		Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
+
+func (rt *JobProcessor) DoNewSignInEmailJob(ctx context.Context, t *asynq.Task) error {
+	var payload NewSignInPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	if err := rt.mailer.Send(NewSignInTemplate, payload.Email, payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("user_id", payload.UserID.String()).
+		Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
+
+func (rt *JobProcessor) DoEmailChangeJob(ctx context.Context, t *asynq.Task) error {
+	var payload VerifyEmailPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	if err := rt.mailer.Send(EmailChangeTemplate, payload.Email, payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("user_id", payload.UserID.String()).
+		Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
+
+func (rt *JobProcessor) DoStaffInviteEmailJob(ctx context.Context, t *asynq.Task) error {
+	var payload StaffInvitePayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	if err := rt.mailer.Send(StaffInviteTemplate, payload.Email, payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("tenant_id", payload.TenantID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("tenant_id", payload.TenantID.String()).
+		Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
+
+type DataExportEmail struct {
+	FirstName string
+	Link      string
+}
+
+// DoDataExportJob builds the archive and mails the download link. A retry
+// after a failed send builds a fresh archive and link.
+func (rt *JobProcessor) DoDataExportJob(ctx context.Context, t *asynq.Task) error {
+	var payload DataExportPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	user, link, err := rt.accounts.BuildDataExport(ctx, payload.ExportID)
+	if err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Str("export_id", payload.ExportID.String()).
+			Int("attempt", retryCount).
+			Msg("build export failed")
+		if errors.Is(err, users.ErrExportNotFound) || errors.Is(err, users.ErrUserNotFound) {
+			return fmt.Errorf("build export: %w: %w", asynq.SkipRetry, err)
+		}
+		return fmt.Errorf("build export: %w", err)
+	}
+
+	email := DataExportEmail{FirstName: user.FirstName, Link: link}
+	if err := rt.mailer.Send(DataExportTemplate, user.GetEmail(), email); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("user_id", payload.UserID.String()).
+		Str("export_id", payload.ExportID.String()).
+		Int("attempt", retryCount).Msg("data export sent")
+	return nil
+}
+
+func (rt *JobProcessor) DoAccountPurgeJob(ctx context.Context, t *asynq.Task) error {
+	var payload AccountPurgePayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	purged, err := rt.accounts.PurgeAccount(ctx, payload.UserID)
+	if err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("user_id", payload.UserID.String()).
+			Int("attempt", retryCount).
+			Msg("purge failed")
+		return fmt.Errorf("purge account: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("user_id", payload.UserID.String()).
+		Bool("purged", purged).Int("attempt", retryCount).Msg("account purge done")
+	return nil
+}
@@ -17,9 +17,22 @@ import (
 )
 
 const (
-	TypeEmailVerify = "email:verify"
-	TypeSetupStore  = "store:setup"
-	TypeImageResize = "image:resize"
+	TypeEmailVerify   = "email:verify"
+	TypeAccountLocked = "email:account_locked"
+	TypeMagicLink     = "email:magic_link"
+	TypeNewSignIn     = "email:new_signin"
+	TypeEmailChange   = "email:change"
+	TypeStaffInvite   = "email:staff_invite"
+	TypeDataExport    = "account:export"
+	TypeAccountPurge  = "account:purge"
+	TypeCleanup       = "maintenance:cleanup"
+	TypeDomainCheck   = "domain:check"
+	TypeStoreStatus   = "store:status_changed"
+	TypeTrialCheck    = "store:trial_check"
+	TypeTrialReminder = "email:trial_reminder"
+	TypeBillingRun    = "billing:run"
+	TypeSetupStore    = "store:setup"
+	TypeImageResize   = "image:resize"
 )
 
 type JobClient struct {
@@ -31,6 +44,13 @@ type JobService interface {
 	WelcomeEmailJob(user *users.User, code string) error
 	ResendVerificationTokenJob(firstname string, code string, userID uuid.UUID) error
 	PasswordResetEmailJob(email string, token string, userId uuid.UUID) error
+	AccountLockedEmailJob(email, firstName string, until time.Time) error
+	MagicLinkEmailJob(email, firstName, link string, userID uuid.UUID) error
+	NewSignInEmailJob(alert users.SignInAlert) error
+	EmailChangeJob(newEmail, firstName, code string, userID uuid.UUID) error
+	StaffInviteEmailJob(invite StaffInvitePayload) error
+	DataExportJob(exportID, userID uuid.UUID) error
+	AccountPurgeJob(userID uuid.UUID, at time.Time) error
 }
 
 func NewJobClient(cfg config.RedisConfig, logger *zerolog.Logger) *JobClient {
@@ -127,6 +147,258 @@ func (jq *JobClient) ResendVerificationTokenJob(firstname, code string, userID u
 func (jq *JobClient) PasswordResetEmailJob(email string, token string, userId uuid.UUID) error {
 	return nil
 }
+
+type AccountLockedPayload struct {
+	Email     string
+	FirstName string
+	Until     string
+}
+
+func (jq *JobClient) AccountLockedEmailJob(email, firstName string, until time.Time) error {
+	var buf bytes.Buffer
+	payload := AccountLockedPayload{
+		Email:     email,
+		FirstName: firstName,
+		Until:     until.UTC().Format(time.RFC1123),
+	}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeAccountLocked, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+
+	task := asynq.NewTask(TypeAccountLocked, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeAccountLocked, err)
+	}
+
+	jq.logger.Info().Str("task_type", TypeAccountLocked).Str("queue", info.Queue).
+		Msg("account locked notice enqueued")
+	return nil
+}
+
+type MagicLinkPayload struct {
+	Email     string
+	FirstName string
+	Link      string
+	UserID    uuid.UUID
+}
+
+func (jq *JobClient) MagicLinkEmailJob(email, firstName, link string, userID uuid.UUID) error {
+	var buf bytes.Buffer
+	payload := MagicLinkPayload{
+		Email:     email,
+		FirstName: firstName,
+		Link:      link,
+		UserID:    userID,
+	}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeMagicLink, err)
+	}
+
+	// the link is short lived, retrying for long is pointless
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Timeout(time.Minute),
+		asynq.Queue(QueueCritical),
+	}
+
+	task := asynq.NewTask(TypeMagicLink, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeMagicLink, err)
+	}
+
+	jq.logger.Info().Str("user_id", userID.String()).
+		Str("task_type", TypeMagicLink).Str("queue", info.Queue).
+		Msg("magic link enqueued")
+	return nil
+}
+
+type NewSignInPayload struct {
+	Email      string
+	FirstName  string
+	Device     string
+	Location   string
+	ClientIP   string
+	At         string
+	ReportLink string
+	UserID     uuid.UUID
+}
+
+func (jq *JobClient) NewSignInEmailJob(alert users.SignInAlert) error {
+	var buf bytes.Buffer
+	payload := NewSignInPayload{
+		Email:      alert.Email,
+		FirstName:  alert.FirstName,
+		Device:     alert.Device,
+		Location:   alert.Location,
+		ClientIP:   alert.ClientIP,
+		At:         alert.At.UTC().Format(time.RFC1123),
+		ReportLink: alert.ReportLink,
+		UserID:     alert.UserID,
+	}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeNewSignIn, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+
+	task := asynq.NewTask(TypeNewSignIn, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeNewSignIn, err)
+	}
+
+	jq.logger.Info().Str("user_id", alert.UserID.String()).
+		Str("task_type", TypeNewSignIn).Str("queue", info.Queue).
+		Msg("new sign-in alert enqueued")
+	return nil
+}
+
+func (jq *JobClient) EmailChangeJob(newEmail, firstName, code string, userID uuid.UUID) error {
+	var buf bytes.Buffer
+	payload := VerifyEmailPayload{
+		Email:     newEmail,
+		FirstName: firstName,
+		Code:      code,
+		UserID:    userID,
+	}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeEmailChange, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueCritical),
+	}
+
+	task := asynq.NewTask(TypeEmailChange, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeEmailChange, err)
+	}
+
+	jq.logger.Info().Str("user_id", userID.String()).
+		Str("task_type", TypeEmailChange).Str("queue", info.Queue).
+		Msg("email change confirmation enqueued")
+	return nil
+}
+
+type StaffInvitePayload struct {
+	Email        string
+	BusinessName string
+	InviterName  string
+	Role         string
+	Link         string
+	TenantID     uuid.UUID
+}
+
+func (jq *JobClient) StaffInviteEmailJob(payload StaffInvitePayload) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeStaffInvite, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueDefault),
+	}
+
+	task := asynq.NewTask(TypeStaffInvite, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeStaffInvite, err)
+	}
+
+	jq.logger.Info().Str("tenant_id", payload.TenantID.String()).
+		Str("task_type", TypeStaffInvite).Str("queue", info.Queue).
+		Msg("staff invitation enqueued")
+	return nil
+}
+
+type DataExportPayload struct {
+	ExportID uuid.UUID
+	UserID   uuid.UUID
+}
+
+func (jq *JobClient) DataExportJob(exportID, userID uuid.UUID) error {
+	var buf bytes.Buffer
+	payload := DataExportPayload{ExportID: exportID, UserID: userID}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeDataExport, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Timeout(5 * time.Minute),
+		asynq.Queue(QueueDefault),
+	}
+
+	task := asynq.NewTask(TypeDataExport, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue: type:%v, :%w", TypeDataExport, err)
+	}
+
+	jq.logger.Info().Str("user_id", userID.String()).Str("export_id", exportID.String()).
+		Str("task_type", TypeDataExport).Str("queue", info.Queue).
+		Msg("data export enqueued")
+	return nil
+}
+
+type AccountPurgePayload struct {
+	UserID uuid.UUID
+}
+
+// AccountPurgeJob schedules the anonymization of a deleted account for the
+// end of its grace period. The job checks the account again when it runs,
+// so a restored account is left alone.
+func (jq *JobClient) AccountPurgeJob(userID uuid.UUID, at time.Time) error {
+	var buf bytes.Buffer
+	payload := AccountPurgePayload{UserID: userID}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeAccountPurge, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(10),
+		asynq.ProcessAt(at),
+		asynq.Queue(QueueDefault),
+	}
+
+	task := asynq.NewTask(TypeAccountPurge, buf.Bytes(), opts...)
+
+	info, err := jq.client.Enqueue(task)
+	if err != nil {
+		return fmt.Errorf("enqueue: type:%v, :%w", TypeAccountPurge, err)
+	}
+
+	jq.logger.Info().Str("user_id", userID.String()).Time("process_at", at).
+		Str("task_type", TypeAccountPurge).Str("queue", info.Queue).
+		Msg("account purge scheduled")
+	return nil
+}
+
 func (jq *JobClient) StoreCreationJob() error {
 	return nil
 }
@@ -0,0 +1,67 @@
+package jobs
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/config"
+	"github.com/rs/zerolog"
+)
+
+// RunScheduler enqueues the periodic jobs until the process is signalled.
+// Every worker may run one: each periodic task is unique for its interval,
+// so the copies other schedulers enqueue meanwhile are dropped as
+// duplicates. A failed run is not retried, the next tick picks up where it
+// stopped.
+func RunScheduler(cfg *config.Config, logger *zerolog.Logger) error {
+	redisOpt := asynq.RedisClientOpt{
+		Addr:     cfg.Redis.Address,
+		Password: cfg.Redis.Password,
+		DB:       0,
+	}
+	scheduler := asynq.NewScheduler(redisOpt, &asynq.SchedulerOpts{
+		EnqueueErrorHandler: func(task *asynq.Task, _ []asynq.Option, err error) {
+			if errors.Is(err, asynq.ErrDuplicateTask) {
+				logger.Debug().Str("type", task.Type()).Msg("periodic task already queued")
+				return
+			}
+			logger.Error().Err(err).Str("type", task.Type()).Msg("enqueue periodic task failed")
+		},
+	})
+
+	periodic := []struct {
+		typename string
+		every    time.Duration
+	}{
+		{TypeCleanup, cfg.Cleanup.Interval},
+		{TypeDomainCheck, cfg.CustomDomain.CheckInterval},
+		{TypeTrialCheck, cfg.Trial.CheckInterval},
+		{TypeBillingRun, cfg.Billing.RunInterval},
+	}
+	for _, p := range periodic {
+		_, err := scheduler.Register(
+			fmt.Sprintf("@every %s", p.every),
+			asynq.NewTask(p.typename, nil),
+			asynq.Queue(QueueDefault),
+			asynq.MaxRetry(0),
+			asynq.Timeout(p.every),
+			asynq.Unique(p.every),
+		)
+		if err != nil {
+			return fmt.Errorf("register %s: %w", p.typename, err)
+		}
+	}
+
+	logger.Info().
+		Dur("cleanup_every", cfg.Cleanup.Interval).
+		Dur("domain_check_every", cfg.CustomDomain.CheckInterval).
+		Dur("trial_check_every", cfg.Trial.CheckInterval).
+		Dur("billing_run_every", cfg.Billing.RunInterval).
+		Msg("start scheduler")
+	if err := scheduler.Run(); err != nil {
+		return fmt.Errorf("runscheduler: %w", err)
+	}
+	return nil
+}
@@ -4,8 +4,14 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	"github.com/iamonah/merchcore/internal/config"
+	"github.com/iamonah/merchcore/internal/domain/billing"
+	"github.com/iamonah/merchcore/internal/domain/cleanup"
+	"github.com/iamonah/merchcore/internal/domain/customdomain"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/domain/users"
 	"github.com/iamonah/merchcore/internal/sdk/mailer"
 	"github.com/rs/zerolog"
 )
@@ -20,13 +26,55 @@ type JobServicer interface {
 	DoWelcomeEmailJob(ctx context.Context, task *asynq.Task) error
 }
 
+// AccountProcessor is the part of the users domain run by the account jobs.
+type AccountProcessor interface {
+	BuildDataExport(ctx context.Context, exportID uuid.UUID) (*users.User, string, error)
+	PurgeAccount(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetUser(ctx context.Context, userID uuid.UUID) (users.User, error)
+}
+
+// Cleaner is the sweep run by the periodic cleanup job.
+type Cleaner interface {
+	Sweep(ctx context.Context) (cleanup.Report, error)
+}
+
+// DomainChecker polls custom domains for their DNS challenge and
+// certificate.
+type DomainChecker interface {
+	CheckDomains(ctx context.Context) (customdomain.CheckReport, error)
+}
+
+// TrialProcessor reminds about and ends store trials.
+type TrialProcessor interface {
+	ProcessTrials(ctx context.Context) (tenant.TrialReport, error)
+}
+
+// BillingRunner renews subscriptions and chases their payments.
+type BillingRunner interface {
+	RunBilling(ctx context.Context) (billing.RunReport, error)
+}
+
+// Processors are the domain services the jobs hand their work to.
+type Processors struct {
+	Accounts AccountProcessor
+	Cleaner  Cleaner
+	Domains  DomainChecker
+	Trials   TrialProcessor
+	Billing  BillingRunner
+}
+
 type JobProcessor struct {
-	server *asynq.Server
-	logger *zerolog.Logger
-	mailer *mailer.Mail
+	server   *asynq.Server
+	logger   *zerolog.Logger
+	mailer   *mailer.Mail
+	accounts AccountProcessor
+	cleaner  Cleaner
+	domains  DomainChecker
+	trials   TrialProcessor
+	billing  BillingRunner
 }
 
-func NewJobProcessor(cfg config.RedisConfig, logger *zerolog.Logger, mailer *mailer.Mail) *JobProcessor {
+func NewJobProcessor(cfg config.RedisConfig, logger *zerolog.Logger, mailer *mailer.Mail, p Processors) *JobProcessor {
 	redisOpt := asynq.RedisClientOpt{
 		Addr:     cfg.Address,
 		Password: cfg.Password,
@@ -41,18 +89,40 @@ func NewJobProcessor(cfg config.RedisConfig, logger *zerolog.Logger, mailer *mai
 			},
 		},
 	)
-	return &JobProcessor{server: server, logger: logger, mailer: mailer}
+	return &JobProcessor{
+		server:   server,
+		logger:   logger,
+		mailer:   mailer,
+		accounts: p.Accounts,
+		cleaner:  p.Cleaner,
+		domains:  p.Domains,
+		trials:   p.Trials,
+		billing:  p.Billing,
+	}
 }
 
 func (js *JobProcessor) Start() error {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TypeEmailVerify, js.DoWelcomeEmailJob)
+	mux.HandleFunc(TypeAccountLocked, js.DoAccountLockedEmailJob)
+	mux.HandleFunc(TypeMagicLink, js.DoMagicLinkEmailJob)
+	mux.HandleFunc(TypeNewSignIn, js.DoNewSignInEmailJob)
+	mux.HandleFunc(TypeEmailChange, js.DoEmailChangeJob)
+	mux.HandleFunc(TypeStaffInvite, js.DoStaffInviteEmailJob)
+	mux.HandleFunc(TypeDataExport, js.DoDataExportJob)
+	mux.HandleFunc(TypeAccountPurge, js.DoAccountPurgeJob)
+	mux.HandleFunc(TypeCleanup, js.DoCleanupJob)
+	mux.HandleFunc(TypeDomainCheck, js.DoDomainCheckJob)
+	mux.HandleFunc(TypeStoreStatus, js.DoStoreStatusJob)
+	mux.HandleFunc(TypeTrialCheck, js.DoTrialCheckJob)
+	mux.HandleFunc(TypeTrialReminder, js.DoTrialReminderJob)
+	mux.HandleFunc(TypeBillingRun, js.DoBillingRunJob)
 
 	return js.server.Run(mux)
 }
 
-func RunJobService(cfg config.RedisConfig, logger *zerolog.Logger, mailer *mailer.Mail) error {
-	jobProcessor := NewJobProcessor(cfg, logger, mailer)
+func RunJobService(cfg config.RedisConfig, logger *zerolog.Logger, mailer *mailer.Mail, p Processors) error {
+	jobProcessor := NewJobProcessor(cfg, logger, mailer, p)
 	defer jobProcessor.server.Stop()
 
 	jobProcessor.logger.Info().Msg("start job service")
@@ -0,0 +1,134 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+)
+
+const StoreStatusTemplate = "storestatus.html"
+
+// StoreStatusPayload is the event published when a store changes status.
+type StoreStatusPayload struct {
+	TenantID     uuid.UUID
+	OwnerID      uuid.UUID
+	BusinessName string
+	From         string
+	To           string
+	Reason       string
+	Trigger      string
+	ChangedAt    time.Time
+}
+
+var _ tenant.StatusHook = (*JobClient)(nil)
+
+// TenantStatusChanged publishes the change for the worker. It runs after
+// the change is committed, a failed enqueue is logged and the change
+// stands.
+func (jq *JobClient) TenantStatusChanged(ctx context.Context, te tenant.TenantProfile, change tenant.StatusChange) {
+	payload := StoreStatusPayload{
+		TenantID:     te.ID,
+		OwnerID:      te.UserID,
+		BusinessName: te.BusinessName,
+		From:         string(change.From),
+		To:           string(change.To),
+		Reason:       change.Reason,
+		Trigger:      string(change.Trigger),
+		ChangedAt:    change.CreatedAt,
+	}
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		jq.logger.Error().Err(err).Str("task_type", TypeStoreStatus).Msg("gob encode failed")
+		return
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueDefault),
+		asynq.TaskID(change.ID.String()),
+	}
+
+	task := asynq.NewTask(TypeStoreStatus, buf.Bytes(), opts...)
+
+	info, err := jq.client.EnqueueContext(ctx, task)
+	if err != nil {
+		jq.logger.Error().Err(err).Str("tenant_id", te.ID.String()).
+			Str("task_type", TypeStoreStatus).Msg("enqueue store status failed")
+		return
+	}
+
+	jq.logger.Info().Str("tenant_id", te.ID.String()).Str("status", payload.To).
+		Str("task_type", TypeStoreStatus).Str("queue", info.Queue).
+		Msg("store status change published")
+}
+
+type StoreStatusEmail struct {
+	FirstName    string
+	BusinessName string
+	Status       string
+	Reason       string
+}
+
+// DoStoreStatusJob tells the owner when the platform or a job suspended,
+// closed or reinstated their store. Changes owners make themselves are
+// only logged.
+func (rt *JobProcessor) DoStoreStatusJob(ctx context.Context, t *asynq.Task) error {
+	var payload StoreStatusPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+	event := rt.logger.Info().Str("type", t.Type()).
+		Str("tenant_id", payload.TenantID.String()).
+		Str("from", payload.From).
+		Str("to", payload.To).
+		Str("trigger", payload.Trigger)
+
+	notify := payload.Trigger != string(tenant.TriggerOwner) &&
+		(payload.To == string(tenant.TenantStatusSuspended) ||
+			payload.To == string(tenant.TenantStatusArchived) ||
+			payload.From == string(tenant.TenantStatusSuspended))
+	if !notify {
+		event.Msg("store status changed")
+		return nil
+	}
+
+	owner, err := rt.accounts.GetUser(ctx, payload.OwnerID)
+	if err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("tenant_id", payload.TenantID.String()).
+			Int("attempt", retryCount).
+			Msg("get owner failed")
+		return fmt.Errorf("get owner: %w", err)
+	}
+
+	email := StoreStatusEmail{
+		FirstName:    owner.FirstName,
+		BusinessName: payload.BusinessName,
+		Status:       payload.To,
+		Reason:       payload.Reason,
+	}
+	if err := rt.mailer.Send(StoreStatusTemplate, owner.GetEmail(), email); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("tenant_id", payload.TenantID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	event.Int("attempt", retryCount).Msg("store status change sent to owner")
+	return nil
+}
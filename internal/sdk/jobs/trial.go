@@ -0,0 +1,156 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+)
+
+const TrialReminderTemplate = "trialreminder.html"
+
+// trialMetrics counts what the trial scans did since start, on /debug/vars.
+var trialMetrics = expvar.NewMap("trials")
+
+func recordTrials(report tenant.TrialReport, err error) {
+	trialMetrics.Add("runs", 1)
+	if err != nil {
+		trialMetrics.Add("failures", 1)
+	}
+	trialMetrics.Add("checked", int64(report.Checked))
+	trialMetrics.Add("reminded", int64(report.Reminded))
+	trialMetrics.Add("suspended", int64(report.Suspended))
+	trialMetrics.Add("downgraded", int64(report.Downgraded))
+}
+
+func (rt *JobProcessor) DoTrialCheckJob(ctx context.Context, t *asynq.Task) error {
+	report, err := rt.trials.ProcessTrials(ctx)
+	recordTrials(report, err)
+
+	event := rt.logger.Info()
+	if err != nil {
+		event = rt.logger.Error().Err(err)
+	} else if report.Checked == 0 {
+		event = rt.logger.Debug()
+	}
+	event.Str("type", t.Type()).
+		Int("checked", report.Checked).
+		Int("reminded", report.Reminded).
+		Int("suspended", report.Suspended).
+		Int("downgraded", report.Downgraded).
+		Msg("trial check done")
+
+	if err != nil {
+		return fmt.Errorf("processtrials: %w", err)
+	}
+	return nil
+}
+
+type TrialReminderPayload struct {
+	TenantID     uuid.UUID
+	OwnerID      uuid.UUID
+	BusinessName string
+	Plan         string
+	DaysLeft     int
+	EndAt        time.Time
+	Suspend      bool
+	DowngradeTo  string
+}
+
+var _ tenant.TrialNotifier = (*JobClient)(nil)
+
+// TrialReminder queues the reminder mail to the store's owner.
+func (jq *JobClient) TrialReminder(ctx context.Context, te tenant.TenantProfile, trial tenant.Trial) error {
+	var buf bytes.Buffer
+	payload := TrialReminderPayload{
+		TenantID:     te.ID,
+		OwnerID:      te.UserID,
+		BusinessName: te.BusinessName,
+		Plan:         string(trial.Plan),
+		DaysLeft:     trial.DaysLeft,
+		EndAt:        *trial.EndAt,
+		Suspend:      trial.Outcome.Suspend,
+		DowngradeTo:  string(trial.Outcome.DowngradeTo),
+	}
+
+	if err := gob.NewEncoder(&buf).Encode(payload); err != nil {
+		return fmt.Errorf("gob encode: type:%v :%w", TypeTrialReminder, err)
+	}
+
+	opts := []asynq.Option{
+		asynq.MaxRetry(5),
+		asynq.Queue(QueueDefault),
+	}
+
+	task := asynq.NewTask(TypeTrialReminder, buf.Bytes(), opts...)
+
+	info, err := jq.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return fmt.Errorf("enqueue email: type:%v, :%w", TypeTrialReminder, err)
+	}
+
+	jq.logger.Info().Str("tenant_id", te.ID.String()).Int("days_left", trial.DaysLeft).
+		Str("task_type", TypeTrialReminder).Str("queue", info.Queue).
+		Msg("trial reminder enqueued")
+	return nil
+}
+
+type TrialReminderEmail struct {
+	FirstName    string
+	BusinessName string
+	Plan         string
+	DaysLeft     int
+	EndAt        string
+	Suspend      bool
+	DowngradeTo  string
+}
+
+func (rt *JobProcessor) DoTrialReminderJob(ctx context.Context, t *asynq.Task) error {
+	var payload TrialReminderPayload
+	if err := gob.NewDecoder(bytes.NewReader(t.Payload())).Decode(&payload); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Msg("decode failed")
+		return fmt.Errorf("gob decode: %w: %w", asynq.SkipRetry, err)
+	}
+
+	retryCount, _ := asynq.GetRetryCount(ctx)
+
+	owner, err := rt.accounts.GetUser(ctx, payload.OwnerID)
+	if err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("tenant_id", payload.TenantID.String()).
+			Int("attempt", retryCount).
+			Msg("get owner failed")
+		return fmt.Errorf("get owner: %w", err)
+	}
+
+	email := TrialReminderEmail{
+		FirstName:    owner.FirstName,
+		BusinessName: payload.BusinessName,
+		Plan:         payload.Plan,
+		DaysLeft:     payload.DaysLeft,
+		EndAt:        payload.EndAt.UTC().Format("January 2, 2006 15:04 MST"),
+		Suspend:      payload.Suspend,
+		DowngradeTo:  payload.DowngradeTo,
+	}
+	if err := rt.mailer.Send(TrialReminderTemplate, owner.GetEmail(), email); err != nil {
+		rt.logger.Error().Err(err).
+			Str("type", t.Type()).
+			Str("tenant_id", payload.TenantID.String()).
+			Int("attempt", retryCount).
+			Msg("send failed")
+		return fmt.Errorf("send email: %w", err)
+	}
+
+	rt.logger.Info().Str("type", t.Type()).Str("tenant_id", payload.TenantID.String()).
+		Int("days_left", payload.DaysLeft).Int("attempt", retryCount).Msg("email sent")
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/google/uuid"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
 	"github.com/iamonah/merchcore/internal/sdk/errs"
 )
@@ -16,9 +17,29 @@ const (
 	AuthHeaderAuthorization AuthKey = "Authorization"
 	AuthTypeBearer          AuthKey = "Bearer"
 	AuthContextPayloadKey   AuthKey = "authorization_payload"
+	AuthContextPrincipalKey AuthKey = "authorization_principal"
+	AuthHeaderAPIKey        AuthKey = "X-API-Key"
 )
 
-func AuthBearer(authMaker authz.TokenMaker) Middleware {
+// SessionChecker reports whether the session a token was issued for has been
+// revoked (signed out, revoked from another device, refresh token reuse).
+type SessionChecker interface {
+	IsSessionRevoked(ctx context.Context, sessionID uuid.UUID) (bool, error)
+}
+
+// AuthBearer authenticates a user's bearer token. Tokens issued to
+// third-party apps are refused, see AuthAppBearer.
+func AuthBearer(authMaker authz.TokenMaker, sessions SessionChecker) Middleware {
+	return authBearer(authMaker, sessions, false)
+}
+
+// AuthAppBearer is AuthBearer that also lets app tokens through. Use it only
+// on routes guarded by Require, which holds apps to their store and scopes.
+func AuthAppBearer(authMaker authz.TokenMaker, sessions SessionChecker) Middleware {
+	return authBearer(authMaker, sessions, true)
+}
+
+func authBearer(authMaker authz.TokenMaker, sessions SessionChecker, apps bool) Middleware {
 	return func(next HTTPHandlerWithErr) HTTPHandlerWithErr {
 		return func(w http.ResponseWriter, r *http.Request) error {
 			authHeader := r.Header.Get(string(AuthHeaderAuthorization))
@@ -38,11 +59,59 @@ func AuthBearer(authMaker authz.TokenMaker) Middleware {
 				w.Header().Set("WWW-Authenticate", "Bearer")
 				return errs.New(errs.Unauthenticated, errors.New("invalid or expired token"))
 			}
+			if payload.IsApp() && !apps {
+				return errs.New(errs.PermissionDenied, errors.New("app tokens are not accepted here"))
+			}
+
+			revoked, err := sessions.IsSessionRevoked(r.Context(), payload.SessionID)
+			if err != nil {
+				return errs.Newf(errs.Unavailable, "issessionrevoked: %s", err)
+			}
+			if revoked {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				return errs.New(errs.Unauthenticated, errors.New("session has been revoked"))
+			}
 
 			ctx := context.WithValue(r.Context(), AuthContextPayloadKey, payload)
+			ctx = context.WithValue(ctx, AuthContextPrincipalKey, authz.PrincipalFromPayload(payload))
 			return next(w, r.WithContext(ctx))
 		}
 	}
 }
 
-//use redis for session midd()
+// APIKeyAuthenticator turns a raw API key into the principal it stands for.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(ctx context.Context, key string) (*authz.Principal, error)
+}
+
+// AuthAPIKey authenticates requests carrying an X-API-Key header. Requests
+// without one are handed to fallback, usually AuthBearer, so a route can
+// accept either; with a nil fallback the key is required.
+func AuthAPIKey(keys APIKeyAuthenticator, fallback Middleware) Middleware {
+	return func(next HTTPHandlerWithErr) HTTPHandlerWithErr {
+		var other HTTPHandlerWithErr
+		if fallback != nil {
+			other = fallback(next)
+		}
+		return func(w http.ResponseWriter, r *http.Request) error {
+			key := r.Header.Get(string(AuthHeaderAPIKey))
+			if key == "" {
+				if other != nil {
+					return other(w, r)
+				}
+				return errs.New(errs.Unauthenticated, errors.New("missing api key"))
+			}
+
+			principal, err := keys.AuthenticateAPIKey(r.Context(), key)
+			if err != nil {
+				if derr, ok := errs.IsDomainError(err); ok {
+					return errs.New(derr.Code, derr)
+				}
+				return errs.Newf(errs.Unavailable, "authenticateapikey: %s", err)
+			}
+
+			ctx := context.WithValue(r.Context(), AuthContextPrincipalKey, principal)
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
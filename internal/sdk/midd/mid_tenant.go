@@ -0,0 +1,40 @@
+package midd
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/tenant"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+)
+
+type TenantKey string
+
+// TenantContextKey holds the *tenant.TenantProfile a storefront request
+// was resolved to.
+const TenantContextKey TenantKey = "storefront_tenant"
+
+// TenantResolver maps the host a request was sent to onto its store.
+type TenantResolver interface {
+	ResolveTenant(ctx context.Context, host string) (*tenant.TenantProfile, error)
+}
+
+// ResolveTenant finds the store from the Host header, a subdomain such as
+// shoe.merchcore.com or a custom domain, for storefront routes. Unknown
+// hosts are not found, suspended and archived stores are refused.
+func ResolveTenant(resolver TenantResolver) Middleware {
+	return func(next HTTPHandlerWithErr) HTTPHandlerWithErr {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			te, err := resolver.ResolveTenant(r.Context(), r.Host)
+			if err != nil {
+				if derr, ok := errs.IsDomainError(err); ok {
+					return errs.New(derr.Code, derr)
+				}
+				return errs.Newf(errs.Internal, "resolvetenant: host[%s]: %s", r.Host, err)
+			}
+
+			ctx := context.WithValue(r.Context(), TenantContextKey, te)
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
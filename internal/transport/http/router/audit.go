@@ -0,0 +1,112 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/iamonah/merchcore/internal/domain/audit"
+	"github.com/iamonah/merchcore/internal/domain/users"
+	"github.com/iamonah/merchcore/internal/sdk/base"
+	"github.com/iamonah/merchcore/internal/sdk/errs"
+	"github.com/iamonah/merchcore/internal/sdk/midd"
+	"github.com/rs/zerolog"
+)
+
+// auditRequest tags the context with the request ID and client IP so audit
+// entries written while serving it can say where the change came from.
+func auditRequest() midd.Middleware {
+	return func(next midd.HTTPHandlerWithErr) midd.HTTPHandlerWithErr {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			reqID, _ := base.GetReqIDCTX(r)
+			ctx := audit.WithRequest(r.Context(), audit.Request{
+				ReqID:    reqID,
+				ClientIP: base.GetClientIP(r),
+			})
+			return next(w, r.WithContext(ctx))
+		}
+	}
+}
+
+// auditActor puts the authenticated caller on the context for the audit
+// log. It runs right after authentication.
+func auditActor() midd.Middleware {
+	return func(next midd.HTTPHandlerWithErr) midd.HTTPHandlerWithErr {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			principal, err := base.GetPrincipalCTX(r)
+			if err != nil {
+				return next(w, r)
+			}
+
+			actor := audit.Actor{
+				Kind:           audit.ActorUser,
+				UserID:         principal.UserID,
+				ImpersonatorID: principal.ImpersonatorID,
+			}
+			switch {
+			case principal.IsAPIKey():
+				actor.Kind = audit.ActorAPIKey
+				actor.APIKeyID = principal.APIKeyID
+			case principal.IsApp():
+				actor.Kind = audit.ActorApp
+			}
+			return next(w, r.WithContext(audit.WithActor(r.Context(), actor)))
+		}
+	}
+}
+
+// ImpersonationAuditor keeps the trail of requests made by admins acting as
+// another user.
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(ctx context.Context, req *users.ImpersonatedRequest) error
+	CompleteImpersonatedRequest(ctx context.Context, id int64, status int) error
+}
+
+// auditImpersonation records impersonated requests before serving them and
+// fills in the outcome afterwards. It runs right after authentication.
+func auditImpersonation(auditor ImpersonationAuditor, log *zerolog.Logger) midd.Middleware {
+	return func(next midd.HTTPHandlerWithErr) midd.HTTPHandlerWithErr {
+		return func(w http.ResponseWriter, r *http.Request) error {
+			principal, err := base.GetPrincipalCTX(r)
+			if err != nil || !principal.IsImpersonated() {
+				return next(w, r)
+			}
+
+			reqID, _ := base.GetReqIDCTX(r)
+			rec := &users.ImpersonatedRequest{
+				SessionID: principal.SessionID,
+				Method:    r.Method,
+				Path:      r.URL.Path,
+				ReqID:     reqID,
+				ClientIP:  base.GetClientIP(r),
+			}
+			if err := auditor.RecordImpersonatedRequest(r.Context(), rec); err != nil {
+				return errs.Newf(errs.Unavailable, "recordimpersonatedrequest: session[%s]: %s", principal.SessionID, err)
+			}
+
+			herr := next(w, r)
+
+			status := http.StatusOK
+			var appErr *errs.AppErr
+			switch {
+			case errors.As(herr, &appErr):
+				status = appErr.Code
+			case herr != nil:
+				status = http.StatusInternalServerError
+			default:
+				if rw, ok := w.(*midd.ResponseWriter); ok && rw.StatusCode != 0 {
+					status = rw.StatusCode
+				}
+			}
+			if err := auditor.CompleteImpersonatedRequest(context.WithoutCancel(r.Context()), rec.ID, status); err != nil {
+				log.Error().
+					Err(err).
+					Str("event", "admin.impersonation_audit").
+					Str("req_id", reqID).
+					Str("session_id", principal.SessionID.String()).
+					Msg("failed to record impersonated request status")
+			}
+			return herr
+		}
+	}
+}
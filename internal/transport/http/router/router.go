@@ -3,7 +3,9 @@ package router
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -75,6 +77,11 @@ func (app *App) handleError(w http.ResponseWriter, r *http.Request, err error) {
 
 	//by design I always expect an *errs.AppErr
 	if appErr, ok := err.(*errs.AppErr); ok {
+		if appErr.RetryAfter > 0 {
+			secs := int(math.Ceil(appErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(secs))
+		}
+
 		event := "http.request.failed"
 		if appErr.Code >= http.StatusInternalServerError {
 			log.Error().
@@ -3,7 +3,11 @@ package router
 import (
 	"net/http"
 
+	auditlog "github.com/iamonah/merchcore/internal/app/audit"
 	"github.com/iamonah/merchcore/internal/app/auth"
+	apps "github.com/iamonah/merchcore/internal/app/oauth"
+	store "github.com/iamonah/merchcore/internal/app/tenant"
+	"github.com/iamonah/merchcore/internal/domain/types/permission"
 	"github.com/iamonah/merchcore/internal/sdk/authz"
 	"github.com/iamonah/merchcore/internal/sdk/midd"
 	"github.com/rs/zerolog"
@@ -11,23 +15,86 @@ import (
 
 func SetupRouter(
 	us *auth.UserService,
+	te *store.TenantService,
+	al *auditlog.AuditService,
+	oa *apps.OAuthService,
 	log *zerolog.Logger,
-	maker *authz.JWTAuthMaker,
-	// te *store.TenantService,
+	maker authz.TokenMaker,
+	sessions midd.SessionChecker,
+	perms midd.PermissionResolver,
+	keys midd.APIKeyAuthenticator,
+	impersonations ImpersonationAuditor,
+	stores midd.TenantResolver,
 ) http.Handler {
-	app := NewApp(log, midd.RecoverPanic(log))
-
-	authbearer := midd.AuthBearer(maker)
+	app := NewApp(log, midd.RecoverPanic(log), auditRequest())
+
+	bearer := midd.AuthBearer(maker, sessions)
+	audited := auditImpersonation(impersonations, log)
+	actor := auditActor()
+	authed := func(auth midd.Middleware) midd.Middleware {
+		return func(next midd.HTTPHandlerWithErr) midd.HTTPHandlerWithErr { return auth(audited(actor(next))) }
+	}
+	authbearer := authed(bearer)
+	noimp := midd.DenyImpersonation()
+	// apikey accepts an X-API-Key and falls back to the bearer token, a user's
+	// or an installed app's
+	apikey := authed(midd.AuthAPIKey(keys, midd.AuthAppBearer(maker, sessions)))
+	require := func(p ...permission.Permission) midd.Middleware { return midd.Require(perms, p...) }
+	// storefront routes are served on the store's own host
+	storefront := midd.ResolveTenant(stores)
 	// version := "1"
+	app.HandleFunc(http.MethodGet, "/.well-known/jwks.json", us.JWKS)
 	app.HandleFunc(http.MethodPost, "/auth/signin", us.Authenticate)
+	app.HandleFunc(http.MethodPost, "/auth/signin/mfa", us.VerifyMFA)
+	app.HandleFunc(http.MethodPost, "/auth/google", us.AuthenticateGoogle)
+	app.HandleFunc(http.MethodPost, "/auth/magic-link", us.RequestMagicLink)
+	app.HandleFunc(http.MethodPost, "/auth/magic-link/verify", us.VerifyMagicLink)
 	app.HandleFunc(http.MethodPost, "/auth/register", us.RegisterUser)
 	app.HandleFunc(http.MethodPost, "/auth/signout", us.SignOut, authbearer)
 	app.HandleFunc(http.MethodPost, "/auth/reset-password", us.ResetPassword)
 	app.HandleFunc(http.MethodPost, "/auth/forgot-password", us.ForgotPassword)
 	app.HandleFunc(http.MethodPost, "/auth/activate", us.ActivateUser, authbearer)
-	app.HandleFunc(http.MethodPost, "/auth/change-password", us.ChangePassword, authbearer)
-	app.HandleFunc(http.MethodPost, "/auth/token/renew-access", us.RenewAccessToken, authbearer)
+	app.HandleFunc(http.MethodPost, "/auth/change-password", us.ChangePassword, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/auth/token/renew-access", us.RenewAccessToken, authbearer, noimp)
 	app.HandleFunc(http.MethodPost, "/auth/resend-token", us.ResendVerificationToken, authbearer)
+	app.HandleFunc(http.MethodPost, "/auth/mfa/enroll", us.EnrollMFA, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/auth/mfa/confirm", us.ConfirmMFA, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/auth/mfa/disable", us.DisableMFA, authbearer, noimp)
+	app.HandleFunc(http.MethodGet, "/me", us.GetMe, authbearer)
+	app.HandleFunc(http.MethodPatch, "/me", us.UpdateMe, authbearer)
+	app.HandleFunc(http.MethodDelete, "/me", us.DeleteMe, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/me/export", us.RequestDataExport, authbearer, noimp)
+	app.HandleFunc(http.MethodGet, "/me/exports/{id}", us.DownloadDataExport)
+	app.HandleFunc(http.MethodPost, "/me/email", us.RequestEmailChange, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/me/email/confirm", us.ConfirmEmailChange)
+	app.HandleFunc(http.MethodGet, "/auth/sessions", us.ListSessions, authbearer)
+	app.HandleFunc(http.MethodDelete, "/auth/sessions/{id}", us.RevokeSession, authbearer)
+	app.HandleFunc(http.MethodPost, "/auth/sessions/revoke-all", us.RevokeAllSessions, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/auth/signin-alerts/report", us.ReportSignIn)
+
+	// 🛒 Storefront
+	app.HandleFunc(http.MethodGet, "/storefront", te.GetStorefront, storefront)
+	// asked by the edge before it orders a certificate for a custom domain
+	app.HandleFunc(http.MethodGet, "/tls/ask", te.AskCertificate)
+
+	// 🔌 Third-party apps (OAuth2)
+	app.HandleFunc(http.MethodPost, "/oauth/clients", oa.RegisterClient, authbearer, noimp)
+	app.HandleFunc(http.MethodGet, "/oauth/clients", oa.ListClients, authbearer)
+	app.HandleFunc(http.MethodDelete, "/oauth/clients/{id}", oa.DeleteClient, authbearer, noimp)
+	app.HandleFunc(http.MethodGet, "/oauth/authorize", oa.Consent, authbearer)
+	app.HandleFunc(http.MethodPost, "/oauth/authorize", oa.Authorize, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/oauth/token", oa.Token)
+	app.HandleFunc(http.MethodPost, "/oauth/introspect", oa.Introspect)
+	app.HandleFunc(http.MethodPost, "/oauth/revoke", oa.Revoke)
+
+	// 🛡️ Platform Admin
+	app.HandleFunc(http.MethodPost, "/admin/users/{id}/impersonate", us.Impersonate, authbearer, noimp, require(permission.PlatformImpersonate))
+	app.HandleFunc(http.MethodDelete, "/admin/impersonations/{id}", us.StopImpersonation, authbearer)
+	app.HandleFunc(http.MethodGet, "/admin/audit-logs", al.ListAuditLogs, authbearer, require(permission.PlatformAuditRead))
+	app.HandleFunc(http.MethodPost, "/admin/stores/{tenant_id}/suspend", te.SuspendStore, authbearer, noimp, require(permission.PlatformTenantsManage))
+	app.HandleFunc(http.MethodPost, "/admin/stores/{tenant_id}/reinstate", te.ReinstateStore, authbearer, noimp, require(permission.PlatformTenantsManage))
+	app.HandleFunc(http.MethodPost, "/admin/stores/{tenant_id}/archive", te.ArchiveStore, authbearer, noimp, require(permission.PlatformTenantsManage))
+	app.HandleFunc(http.MethodGet, "/admin/stores/{tenant_id}/status-history", te.ListStatusHistory, authbearer, require(permission.PlatformTenantsRead))
 
 	// app.HandleFunc(http.MethodGet, "/api/stores/:id", us.GetStore)
 	// app.HandleFunc(http.MethodPut, "/api/stores/:id", us.UpdateStore)
@@ -112,13 +179,44 @@ func SetupRouter(
 	// app.HandleFunc(http.MethodGet, "/dashboard/export/:entity", ds.ExportData, authbearer)
 	// app.HandleFunc(http.MethodPost, "/dashboard/import/:entity", ds.ImportData, authbearer)
 
-	// // 👩‍💻 Team / Staff Management
-	// app.HandleFunc(http.MethodGet, "/dashboard/team", ds.ListTeamMembers, authbearer)
-	// app.HandleFunc(http.MethodPost, "/dashboard/team", ds.InviteTeamMember, authbearer)
-	// app.HandleFunc(http.MethodPut, "/dashboard/team/:id/role", ds.UpdateTeamMemberRole, authbearer)
-	// app.HandleFunc(http.MethodDelete, "/dashboard/team/:id", ds.RemoveTeamMember, authbearer)
-	// app.HandleFunc(http.MethodPost, "/dashboard/team/invitations/:token/accept", ds.AcceptInvitation)
-	// app.HandleFunc(http.MethodPost, "/dashboard/team/invitations/:token/reject", ds.RejectInvitation)
+	// 👩‍💻 Team / Staff Management
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/team", te.ListTeam, apikey, require(permission.TeamRead))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/team", te.InviteTeamMember, authbearer, require(permission.TeamManage))
+	app.HandleFunc(http.MethodPut, "/dashboard/stores/{tenant_id}/team/{user_id}/role", te.UpdateTeamMemberRole, authbearer, require(permission.TeamManage))
+	// registered first, the router takes the first route that matches
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/team/me", te.LeaveTeam, authbearer)
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/team/{user_id}", te.RemoveTeamMember, authbearer, require(permission.TeamManage))
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/team/invitations/{id}", te.RevokeInvitation, authbearer, require(permission.TeamManage))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/roles", te.ListRoles, authbearer, require(permission.TeamRead))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/roles", te.CreateRole, authbearer, require(permission.RolesManage))
+	app.HandleFunc(http.MethodPut, "/dashboard/stores/{tenant_id}/roles/{role_id}", te.UpdateRole, authbearer, require(permission.RolesManage))
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/roles/{role_id}", te.DeleteRole, authbearer, require(permission.RolesManage))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/api-keys", te.ListAPIKeys, authbearer, require(permission.APIKeysManage))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/api-keys", te.CreateAPIKey, authbearer, require(permission.APIKeysManage))
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/api-keys/{key_id}", te.RevokeAPIKey, authbearer, require(permission.APIKeysManage))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/go-live", te.GoLive, authbearer, noimp, require(permission.SettingsWrite))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/pause", te.PauseStore, authbearer, noimp, require(permission.SettingsWrite))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/status-history", te.ListStatusHistory, authbearer, require(permission.SettingsRead))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/trial", te.GetTrial, authbearer, require(permission.SettingsRead))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/usage", te.GetUsage, authbearer, require(permission.SettingsRead))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/billing", te.GetSubscription, authbearer, require(permission.BillingManage))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/billing/plan", te.ChangePlan, authbearer, noimp, require(permission.BillingManage))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/billing/invoices", te.ListInvoices, authbearer, require(permission.BillingManage))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/billing/invoices/{invoice_id}/pay", te.PayInvoice, authbearer, noimp, require(permission.BillingManage))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/domain", te.GetDomain, authbearer, require(permission.SettingsRead))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/domain", te.AddDomain, authbearer, require(permission.SettingsWrite))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/domain/verify", te.VerifyDomain, authbearer, require(permission.SettingsWrite))
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/domain", te.RemoveDomain, authbearer, require(permission.SettingsWrite))
+	app.HandleFunc(http.MethodPost, "/dashboard/stores/{tenant_id}/products", te.CreateProduct, authbearer, require(permission.ProductsWrite))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/audit-logs", al.ListStoreAuditLogs, apikey, require(permission.AuditRead))
+	app.HandleFunc(http.MethodGet, "/dashboard/stores/{tenant_id}/apps", oa.ListInstalledApps, authbearer, require(permission.AppsManage))
+	app.HandleFunc(http.MethodDelete, "/dashboard/stores/{tenant_id}/apps/{app_id}", oa.UninstallApp, authbearer, require(permission.AppsManage))
+	app.HandleFunc(http.MethodGet, "/dashboard/billing/plans", te.ListPlans, authbearer)
+	// the caller's own store, the business checks settings:read as there is
+	// no tenant_id in the path for require
+	app.HandleFunc(http.MethodGet, "/dashboard/usage", te.GetCallerUsage, authbearer)
+	app.HandleFunc(http.MethodPost, "/dashboard/team/invitations/accept", te.AcceptInvitation, authbearer, noimp)
+	app.HandleFunc(http.MethodPost, "/dashboard/team/invitations/reject", te.RejectInvitation)
 
 	return app.mux
 }